@@ -73,6 +73,84 @@ type DatabaseParameters struct {
 	// privileges; if false (the default), then only superusers or the owner of
 	// the database can clone it.
 	IsTemplate *bool `json:"isTemplate,omitempty"`
+
+	// Bootstrap configures statements that are run against the database
+	// immediately after it is created, and again whenever they change.
+	// +optional
+	Bootstrap *DatabaseBootstrap `json:"bootstrap,omitempty"`
+}
+
+// DatabaseBootstrap configures statements that are run against a database
+// immediately after it is created, and again whenever they change. Each
+// statement is idempotent, so the bootstrap may safely be retried if it fails
+// part way through.
+type DatabaseBootstrap struct {
+	// RevokePublicSchema revokes all privileges on the public schema from
+	// the PUBLIC role, i.e. from every role. Which privileges are revoked,
+	// and from which role, may be configured.
+	// +optional
+	RevokePublicSchema *bool `json:"revokePublicSchema,omitempty"`
+
+	// RevokePublicSchemaPrivileges are the privileges on the public schema
+	// that RevokePublicSchema revokes. Defaults to ALL.
+	// +optional
+	RevokePublicSchemaPrivileges []SchemaPrivilege `json:"revokePublicSchemaPrivileges,omitempty"`
+
+	// RevokePublicSchemaFrom is the role from which RevokePublicSchema
+	// revokes privileges. Defaults to PUBLIC, i.e. every role.
+	// +optional
+	RevokePublicSchemaFrom *string `json:"revokePublicSchemaFrom,omitempty"`
+
+	// PublicSchemaOwner is the role that should own the public schema.
+	// +optional
+	PublicSchemaOwner *string `json:"publicSchemaOwner,omitempty"`
+
+	// DefaultPrivileges to grant on objects that are created in the
+	// database in future.
+	// +optional
+	DefaultPrivileges []DefaultPrivileges `json:"defaultPrivileges,omitempty"`
+}
+
+// A SchemaPrivilege is a privilege that may be granted on a schema.
+// +kubebuilder:validation:Enum=CREATE;USAGE;ALL
+type SchemaPrivilege string
+
+// DefaultPrivilegesObjectType is a type of object to which default privileges
+// apply.
+type DefaultPrivilegesObjectType string
+
+// The possible types of object to which default privileges apply.
+const (
+	DefaultPrivilegesTables    DefaultPrivilegesObjectType = "TABLES"
+	DefaultPrivilegesSequences DefaultPrivilegesObjectType = "SEQUENCES"
+	DefaultPrivilegesFunctions DefaultPrivilegesObjectType = "FUNCTIONS"
+	DefaultPrivilegesTypes     DefaultPrivilegesObjectType = "TYPES"
+	DefaultPrivilegesSchemas   DefaultPrivilegesObjectType = "SCHEMAS"
+)
+
+// DefaultPrivileges are privileges granted to a role on objects that are
+// created in future.
+// See https://www.postgresql.org/docs/current/sql-alterdefaultprivileges.html
+type DefaultPrivileges struct {
+	// Role to which the privileges are granted.
+	Role string `json:"role"`
+
+	// Privileges to be granted.
+	Privileges GrantPrivileges `json:"privileges"`
+
+	// ObjectType to which the privileges apply.
+	// +kubebuilder:validation:Enum=TABLES;SEQUENCES;FUNCTIONS;TYPES;SCHEMAS
+	ObjectType DefaultPrivilegesObjectType `json:"objectType"`
+
+	// Schema in which the privileges apply to new objects. They apply in
+	// all schemas when unset.
+	// +optional
+	Schema *string `json:"schema,omitempty"`
+
+	// ForRole is the role whose new objects the privileges apply to. The
+	// role used by the provider is assumed when unset.
+	// +optional
+	ForRole *string `json:"forRole,omitempty"`
 }
 
 // A DatabaseSpec defines the desired state of a Database.
@@ -84,6 +162,22 @@ type DatabaseSpec struct {
 // A DatabaseStatus represents the observed state of a Database.
 type DatabaseStatus struct {
 	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          DatabaseObservation `json:"atProvider,omitempty"`
+}
+
+// A DatabaseObservation represents the observed state of a PostgreSQL
+// database.
+type DatabaseObservation struct {
+	// Bootstrapped is true once the database's bootstrap statements have
+	// been run successfully.
+	// +optional
+	Bootstrapped bool `json:"bootstrapped,omitempty"`
+
+	// BootstrapHash is a hash of the bootstrap statements that were last run
+	// successfully. The bootstrap is run again when its statements no longer
+	// match the hash.
+	// +optional
+	BootstrapHash string `json:"bootstrapHash,omitempty"`
 }
 
 // +kubebuilder:object:root=true
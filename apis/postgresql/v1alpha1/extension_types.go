@@ -19,32 +19,137 @@ package v1alpha1
 import (
 	"context"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/reference"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/pkg/errors"
 )
 
 // ExtensionParameters are the configurable fields of a Extension.
 type ExtensionParameters struct {
-	// Extension name to be installed.
+	// Extension name to be installed. The name identifies the extension in
+	// the database, so it cannot be late initialized. Extensions are always
+	// created using CREATE EXTENSION IF NOT EXISTS, so an extension that is
+	// already installed (e.g. by a base image) is adopted rather than
+	// failing to be created. Its version, schema, and comment are reconciled
+	// once it is observed. Every operation identifies the extension by this
+	// name, so the resource's name and external name may differ from it.
+	// +kubebuilder:validation:MinLength=1
 	Extension string `json:"extension"`
 
-	// Version of the extension to be installed.
-	// +immutable
+	// Version of the extension to be installed. This may also be a comma
+	// separated constraint such as '>=1.1,<2.0', in which case the highest
+	// available version that satisfies the constraint will be installed, and
+	// the extension will be upgraded as new matching versions become
+	// available. Constraints only match semver-like versions.
 	// +optional
 	Version *string `json:"version,omitempty"`
 
-	// Schema for extension install.
+	// VersionFallback causes the server's default version of the extension
+	// to be installed if the desired version is not packaged on the server.
+	// The VersionFallbackApplied condition reports when this happens. The
+	// extension fails to install when the desired version is not packaged
+	// unless this is true.
+	// +optional
+	VersionFallback *bool `json:"versionFallback,omitempty"`
+
+	// NoTransaction causes the statements that create, update, and drop the
+	// extension to run in autocommit mode rather than in a transaction. Some
+	// extensions cannot be created or updated in a transaction block. The
+	// provider retries in autocommit mode if the server reports a statement
+	// cannot run in a transaction block, so this is only needed to avoid the
+	// failed first attempt. Audit records are not written atomically with the
+	// statements they record when this is true.
+	// +optional
+	NoTransaction *bool `json:"noTransaction,omitempty"`
+
+	// ExpectedDefinitionHash enables an integrity check of the extension's
+	// functions. It is the hex encoded SHA-256 hash of the definitions of
+	// the functions and procedures that belong to the extension, as
+	// reported in status.atProvider.definitionHash. The
+	// IntegrityDriftDetected condition becomes true if the observed hash
+	// differs, for example because a function was replaced. The check is
+	// intended for custom extensions, and is not run when this is unset.
+	// +optional
+	ExpectedDefinitionHash *string `json:"expectedDefinitionHash,omitempty"`
+
+	// DropSnapshotConfigMapRef enables a snapshot of the extension's objects
+	// before it is dropped. The provider records each object that belongs to
+	// the extension, with the DDL of its functions and views, in the
+	// referenced ConfigMap so that they can be recreated if the drop was a
+	// mistake. The extension is not dropped if the snapshot cannot be taken.
+	// +optional
+	DropSnapshotConfigMapRef *ConfigMapReference `json:"dropSnapshotConfigMapRef,omitempty"`
+
+	// PreloadLibrary is the shared library the extension requires to be
+	// listed in the server's shared_preload_libraries, for example
+	// pg_stat_statements. The RestartRequired condition is true while the
+	// server has not preloaded it, i.e. until it is restarted.
+	// +optional
+	PreloadLibrary *string `json:"preloadLibrary,omitempty"`
+
+	// Comment on the extension, as set by COMMENT ON EXTENSION.
+	// +optional
+	Comment *string `json:"comment,omitempty"`
+
+	// Schema for extension install. An extension created without a schema
+	// lands in the default schema. The extension is moved back to its
+	// schema if it is relocated.
 	// +optional
 	Schema *string `json:"schema,omitempty"`
 
+	// UnmanagedSchemaPolicy determines how the provider handles an extension
+	// that is not in the default schema (i.e. current_schema()) when schema
+	// is unset. When set to Ignore, the default, the extension's schema is
+	// not managed. When set to Drift the extension is moved to the default
+	// schema. When set to Adopt schema is late initialized to the schema the
+	// extension is in, unless the provider created the extension in the
+	// default schema.
+	// +optional
+	// +kubebuilder:validation:Enum=Ignore;Drift;Adopt
+	UnmanagedSchemaPolicy *UnmanagedSchemaPolicy `json:"unmanagedSchemaPolicy,omitempty"`
+
 	// Database for extension install.
 	// +optional
 	Database *string `json:"database,omitempty"`
 
+	// SessionParameters are run-time parameters (GUCs) set for each session
+	// used to manage this extension, for example to raise
+	// maintenance_work_mem for an expensive install. Only
+	// maintenance_work_mem, work_mem, temp_buffers, statement_timeout,
+	// lock_timeout, and max_parallel_maintenance_workers may be set.
+	// +optional
+	SessionParameters map[string]string `json:"sessionParameters,omitempty"`
+
+	// ObserveUpdatePath causes the provider to report whether PostgreSQL
+	// knows an update path from the installed version of the extension to
+	// the desired version. See status.atProvider.updatePathAvailable.
+	// +optional
+	ObserveUpdatePath *bool `json:"observeUpdatePath,omitempty"`
+
+	// PostUpgradeUpdatePolicy determines how the provider handles an
+	// installed extension that is older than the server's default version
+	// of the extension, as is common after a PostgreSQL major upgrade. When
+	// set to Observe the PostUpgradeUpdateNeeded condition reports the skew.
+	// When set to Apply and spec.forProvider.version is unset the provider
+	// also updates the extension to the default version; the version is not
+	// late initialized so that it can track the default. An extension whose
+	// version is set is never updated automatically.
+	// +optional
+	// +kubebuilder:validation:Enum=Observe;Apply
+	PostUpgradeUpdatePolicy *PostUpgradeUpdatePolicy `json:"postUpgradeUpdatePolicy,omitempty"`
+
+	// DatabasePattern installs the extension in every database whose name
+	// matches this SQL LIKE pattern, for example 'tenant_%'. Databases that
+	// are created later are reconciled as they appear. Database is ignored
+	// when a pattern is specified.
+	// +optional
+	DatabasePattern *string `json:"databasePattern,omitempty"`
+
 	// DatabaseRef references the database object this extension is for.
 	// +immutable
 	// +optional
@@ -54,8 +159,88 @@ type ExtensionParameters struct {
 	// +immutable
 	// +optional
 	DatabaseSelector *xpv1.Selector `json:"databaseSelector,omitempty"`
+
+	// Requires lists extensions that must be installed before this
+	// extension will be created.
+	// +optional
+	Requires []string `json:"requires,omitempty"`
+
+	// Cascade causes the provider to install any extensions this extension
+	// depends on that are not already installed, using CREATE EXTENSION ...
+	// CASCADE. The extensions that were installed are reported in
+	// status.atProvider.installedDependencies. Cascade only affects how the
+	// extension is created; changing it has no effect once the extension
+	// exists.
+	// +optional
+	Cascade *bool `json:"cascade,omitempty"`
+
+	// DropPolicy determines how the extension is dropped when this resource
+	// is deleted. When set to Restrict, the default, the provider waits for
+	// objects that depend on the extension to be dropped before dropping
+	// it. When set to Cascade the extension is dropped using DROP EXTENSION
+	// ... CASCADE, which also drops any objects that depend on it.
+	// +optional
+	// +kubebuilder:validation:Enum=Restrict;Cascade
+	DropPolicy *DropPolicy `json:"dropPolicy,omitempty"`
+
+	// VerifyDrop causes the provider to confirm that the extension no longer
+	// exists after dropping it. The resource is not deleted while the
+	// extension is still installed.
+	// +optional
+	VerifyDrop *bool `json:"verifyDrop,omitempty"`
+
+	// RequiresRefs references Extensions that must be installed before this
+	// extension will be created. A reference resolves only once the
+	// referenced Extension is ready.
+	// +optional
+	RequiresRefs []xpv1.Reference `json:"requiresRefs,omitempty"`
+
+	// RequiresSelector selects references to Extensions that must be
+	// installed before this extension will be created.
+	// +optional
+	RequiresSelector *xpv1.Selector `json:"requiresSelector,omitempty"`
 }
 
+// A ConfigMapReference is a reference to a ConfigMap in an arbitrary
+// namespace.
+type ConfigMapReference struct {
+	// Name of the ConfigMap.
+	Name string `json:"name"`
+
+	// Namespace of the ConfigMap.
+	Namespace string `json:"namespace"`
+}
+
+// A PostUpgradeUpdatePolicy determines how the provider handles an extension
+// that is older than the server's default version of the extension.
+type PostUpgradeUpdatePolicy string
+
+// Post-upgrade update policies.
+const (
+	PostUpgradeUpdateObserve PostUpgradeUpdatePolicy = "Observe"
+	PostUpgradeUpdateApply   PostUpgradeUpdatePolicy = "Apply"
+)
+
+// An UnmanagedSchemaPolicy determines how the provider handles an extension
+// that is not in the default schema when its schema is unset.
+type UnmanagedSchemaPolicy string
+
+// Unmanaged schema policies.
+const (
+	UnmanagedSchemaIgnore UnmanagedSchemaPolicy = "Ignore"
+	UnmanagedSchemaDrift  UnmanagedSchemaPolicy = "Drift"
+	UnmanagedSchemaAdopt  UnmanagedSchemaPolicy = "Adopt"
+)
+
+// A DropPolicy determines how an extension is dropped.
+type DropPolicy string
+
+// Drop policies.
+const (
+	DropRestrict DropPolicy = "Restrict"
+	DropCascade  DropPolicy = "Cascade"
+)
+
 // ExtensionSpec defines the desired state of an Extension.
 type ExtensionSpec struct {
 	xpv1.ResourceSpec `json:",inline"`
@@ -65,6 +250,113 @@ type ExtensionSpec struct {
 // A ExtensionStatus represents the observed state of a Extension.
 type ExtensionStatus struct {
 	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ExtensionObservation `json:"atProvider,omitempty"`
+}
+
+// An ExtensionObservation represents the observed state of a PostgreSQL
+// extension.
+type ExtensionObservation struct {
+	// InstalledVersion is the version of the extension that is installed.
+	// +optional
+	InstalledVersion *string `json:"installedVersion,omitempty"`
+
+	// Owner is the role that owns the extension. It is omitted when the
+	// provider may not read the catalogs that record it; see the
+	// OwnerUnreadable condition.
+	// +optional
+	Owner *string `json:"owner,omitempty"`
+
+	// Schema is the schema that contains the extension's objects.
+	// +optional
+	Schema *string `json:"schema,omitempty"`
+
+	// Relocatable indicates whether the extension's objects can be moved to
+	// another schema after it is installed. When false the extension stays
+	// in the reported schema, and changing spec.forProvider.schema causes the
+	// provider to report an error rather than move it.
+	// +optional
+	Relocatable *bool `json:"relocatable,omitempty"`
+
+	// DefinitionHash is the hex encoded SHA-256 hash of the definitions of
+	// the functions and procedures that belong to the extension. It is only
+	// reported when spec.forProvider.expectedDefinitionHash is set.
+	// +optional
+	DefinitionHash *string `json:"definitionHash,omitempty"`
+
+	// PendingStatements are the SQL statements the provider will run to
+	// reconcile any drift between the desired and observed state of the
+	// extension. It is empty when the extension is up to date.
+	// +optional
+	PendingStatements []string `json:"pendingStatements,omitempty"`
+
+	// UpdatePathAvailable indicates whether PostgreSQL knows an update path
+	// from the installed version of the extension to the desired version, and
+	// thus whether an update can succeed. It is only reported when
+	// spec.forProvider.observeUpdatePath is true and a version is desired.
+	// +optional
+	UpdatePathAvailable *bool `json:"updatePathAvailable,omitempty"`
+
+	// UnavailableVersion is the desired version of the extension that was
+	// not packaged on the server when the extension was installed, causing
+	// the default version to be installed instead per
+	// spec.forProvider.versionFallback.
+	// +optional
+	UnavailableVersion *string `json:"unavailableVersion,omitempty"`
+
+	// DefaultVersion is the version of the extension the server would
+	// install by default. It is only reported when
+	// spec.forProvider.postUpgradeUpdatePolicy is set.
+	// +optional
+	DefaultVersion *string `json:"defaultVersion,omitempty"`
+
+	// InstalledDependencies are the extensions that were installed because
+	// this extension depends on them when the provider created it with
+	// spec.forProvider.cascade.
+	// +optional
+	InstalledDependencies []string `json:"installedDependencies,omitempty"`
+
+	// Adopted is false if the provider created the extension, and true if
+	// the extension already existed when the provider first observed it. It
+	// is not reported for extensions that span several databases.
+	// +optional
+	Adopted *bool `json:"adopted,omitempty"`
+
+	// Databases reports the state of the extension in each database matched
+	// by spec.forProvider.databasePattern.
+	// +optional
+	Databases []ExtensionDatabaseObservation `json:"databases,omitempty"`
+
+	// Progress reports how far the provider has got through a long-running
+	// operation, for example 'upgraded 3/5 steps' of a multi-step update, or
+	// 'installed in 7/10 databases' of an operation that spans several
+	// databases. It is updated as each step or database is handled, and
+	// cleared once the extension is up to date.
+	// +optional
+	Progress *string `json:"progress,omitempty"`
+}
+
+// An ExtensionDatabaseObservation represents the observed state of an
+// extension in one of the databases it targets.
+type ExtensionDatabaseObservation struct {
+	// Database in which the extension was observed.
+	Database string `json:"database"`
+
+	// Installed is true if the extension is installed in the database.
+	Installed bool `json:"installed"`
+
+	// Version of the extension installed in the database.
+	// +optional
+	Version *string `json:"version,omitempty"`
+
+	// UpToDate is true if the extension is installed in the database at the
+	// desired version.
+	UpToDate bool `json:"upToDate"`
+
+	// LastError is the error encountered the last time the provider tried
+	// to install or update the extension in the database. It is cleared once
+	// the extension is up to date in the database.
+	// +optional
+	LastError *string `json:"lastError,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -76,6 +368,7 @@ type ExtensionStatus struct {
 // +kubebuilder:printcolumn:name="DATABASE",type="string",JSONPath=".spec.forProvider.database"
 // +kubebuilder:printcolumn:name="EXTENSION",type="string",JSONPath=".spec.forProvider.extension"
 // +kubebuilder:printcolumn:name="VERSION",type="string",JSONPath=".spec.forProvider.version"
+// +kubebuilder:printcolumn:name="SCHEMA",type="string",JSONPath=".status.atProvider.schema"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
 type Extension struct {
@@ -112,5 +405,32 @@ func (mg *Extension) ResolveReferences(ctx context.Context, c client.Reader) err
 	}
 	mg.Spec.ForProvider.Database = reference.ToPtrValue(rsp.ResolvedValue)
 	mg.Spec.ForProvider.DatabaseRef = rsp.ResolvedReference
+
+	// Resolve spec.forProvider.requires
+	mrsp, err := r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.Requires,
+		References:    mg.Spec.ForProvider.RequiresRefs,
+		Selector:      mg.Spec.ForProvider.RequiresSelector,
+		To:            reference.To{Managed: &Extension{}, List: &ExtensionList{}},
+		Extract:       ReadyExtensionName(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "spec.forProvider.requires")
+	}
+	mg.Spec.ForProvider.Requires = mrsp.ResolvedValues
+	mg.Spec.ForProvider.RequiresRefs = mrsp.ResolvedReferences
 	return nil
 }
+
+// ReadyExtensionName extracts the name of the extension an Extension installs.
+// It extracts an empty string, which fails reference resolution, until the
+// Extension is ready. This ensures extensions are created in dependency order.
+func ReadyExtensionName() reference.ExtractValueFn {
+	return func(mg resource.Managed) string {
+		e, ok := mg.(*Extension)
+		if !ok || e.GetCondition(xpv1.TypeReady).Status != corev1.ConditionTrue {
+			return ""
+		}
+		return e.Spec.ForProvider.Extension
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestExtensionResolveRequires(t *testing.T) {
+	get := func(ready bool) test.MockGetFn {
+		return func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			e := obj.(*Extension)
+			e.Spec.ForProvider.Extension = "ext-" + key.Name
+			if ready {
+				e.SetConditions(xpv1.Available())
+			}
+			return nil
+		}
+	}
+
+	type want struct {
+		requires []string
+		err      bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		get    test.MockGetFn
+		refs   []xpv1.Reference
+		want   want
+	}{
+		"Ready": {
+			reason: "References to ready Extensions should resolve to the extensions they install",
+			get:    get(true),
+			refs:   []xpv1.Reference{{Name: "a"}, {Name: "b"}},
+			want:   want{requires: []string{"ext-a", "ext-b"}},
+		},
+		"NotReady": {
+			reason: "References to Extensions that are not yet ready should not resolve",
+			get:    get(false),
+			refs:   []xpv1.Reference{{Name: "a"}},
+			want:   want{err: true},
+		},
+		"GetError": {
+			reason: "Errors getting referenced Extensions should be returned",
+			get:    test.NewMockGetFn(errors.New("boom")),
+			refs:   []xpv1.Reference{{Name: "a"}},
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mg := &Extension{Spec: ExtensionSpec{ForProvider: ExtensionParameters{RequiresRefs: tc.refs}}}
+			err := mg.ResolveReferences(context.Background(), &test.MockClient{MockGet: tc.get})
+			if (err != nil) != tc.want.err {
+				t.Fatalf("\n%s\nmg.ResolveReferences(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.requires, mg.Spec.ForProvider.Requires); diff != "" {
+				t.Errorf("\n%s\nmg.ResolveReferences(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -26,6 +26,198 @@ import (
 type ProviderConfigSpec struct {
 	// Credentials required to authenticate to this provider.
 	Credentials ProviderCredentials `json:"credentials"`
+
+	// AllowedDatabases limits the databases that managed resources using this
+	// ProviderConfig may target. Resources that target any other database
+	// will not be reconciled. All databases are allowed when unset.
+	// +optional
+	AllowedDatabases []string `json:"allowedDatabases,omitempty"`
+
+	// ServerCertFingerprint pins the certificate the server must present. It
+	// is the hex encoded SHA-256 fingerprint of the DER encoded certificate,
+	// optionally colon separated. The certificate is verified on every
+	// connection, which always uses TLS when a certificate is pinned.
+	// Resources using this ProviderConfig will not be reconciled if the
+	// server presents any other certificate.
+	// +optional
+	ServerCertFingerprint *string `json:"serverCertFingerprint,omitempty"`
+
+	// SSLMode determines whether and how the connection to the server is
+	// secured using TLS. 'require' encrypts the connection without verifying
+	// the server's certificate, 'verify-ca' also verifies that the
+	// certificate is signed by a trusted CA, and 'verify-full' also verifies
+	// that it was issued for the server's hostname. Defaults to 'require'.
+	// +optional
+	// +kubebuilder:validation:Enum=disable;require;verify-ca;verify-full
+	SSLMode *string `json:"sslMode,omitempty"`
+
+	// TLS configures the certificates used to secure the connection to the
+	// server.
+	// +optional
+	TLS *TLSConfig `json:"tls,omitempty"`
+
+	// TCPKeepalive is the interval between TCP keepalive probes sent on
+	// connections to the server. Keepalives stop NAT gateways and firewalls
+	// from silently dropping idle connections. Defaults to 30s. Set to 0s to
+	// disable keepalive probes.
+	// +optional
+	TCPKeepalive *metav1.Duration `json:"tcpKeepalive,omitempty"`
+
+	// ConnectTimeout bounds how long the provider waits to connect to the
+	// server, so that an unreachable server cannot stall reconciles. It is
+	// rounded up to whole seconds. The provider waits as long as the
+	// operating system allows when unset.
+	// +optional
+	ConnectTimeout *metav1.Duration `json:"connectTimeout,omitempty"`
+
+	// StatementTimeout is the statement timeout the provider sets, using SET
+	// statement_timeout, at the start of every session. The server cancels
+	// any statement the provider runs that takes longer. The server's and
+	// role's default is used when unset.
+	// +optional
+	StatementTimeout *metav1.Duration `json:"statementTimeout,omitempty"`
+
+	// SessionAuthorization is a role the provider assumes, using SET
+	// SESSION AUTHORIZATION, after logging in with the credentials Secret.
+	// Objects the provider creates are then owned by that role, and audit
+	// trails attribute changes to it. Unlike SET ROLE, the provider has only
+	// the privileges of the assumed role. The credentials Secret must be for
+	// a superuser to assume another role this way.
+	// +optional
+	SessionAuthorization *string `json:"sessionAuthorization,omitempty"`
+
+	// AssumeRole is a role the provider assumes, using SET ROLE, after
+	// logging in and assuming any SessionAuthorization. Objects the provider
+	// creates are then owned by that role. Unlike SessionAuthorization the
+	// provider keeps the privileges of the role it logged in as, which must
+	// be a member of the assumed role.
+	// +optional
+	AssumeRole *string `json:"assumeRole,omitempty"`
+
+	// SearchPath is the schema search path the provider sets, using SET
+	// search_path, whenever it opens a database session. Setting it ensures
+	// objects are created in the expected schemas even if the default search
+	// path of the role the provider uses is changed. Most resources open a
+	// session per operation, but Extensions reuse pooled sessions, so a
+	// search path changed within a session persists until the session is
+	// closed. The server's default is used when unset.
+	// +optional
+	SearchPath []string `json:"searchPath,omitempty"`
+
+	// TimeZone is the time zone the provider sets, using SET TIME ZONE,
+	// whenever it opens a database session, for example 'UTC'. Setting it
+	// makes the timestamps the provider writes, for example to its audit
+	// table, and compares independent of the server's and role's default time
+	// zone. Like SearchPath it is set once per session, not per operation.
+	// The server's default is used when unset.
+	// +optional
+	TimeZone *string `json:"timeZone,omitempty"`
+
+	// ReadOnlyObserve causes the provider to observe Extensions using
+	// sessions whose transactions default to READ ONLY DEFERRABLE, so that
+	// observing can never change the server and, when the server's default
+	// isolation level is SERIALIZABLE, never causes serialization failures.
+	// This suits observing heavily loaded catalogs. Statements that change
+	// an Extension use ordinary sessions.
+	// +optional
+	ReadOnlyObserve *bool `json:"readOnlyObserve,omitempty"`
+
+	// Audit configures an audit table. When set, a row is inserted into the
+	// audit table in the same transaction as each extension is created or
+	// dropped.
+	// +optional
+	Audit *AuditConfig `json:"audit,omitempty"`
+
+	// DDLRateLimit limits the rate at which statements that change the
+	// server are executed by all resources using this ProviderConfig.
+	// Statements that exceed the limit wait for their turn, or are retried
+	// later if they would wait too long. Statements are not rate limited
+	// when unset.
+	// +optional
+	DDLRateLimit *DDLRateLimit `json:"ddlRateLimit,omitempty"`
+}
+
+// AllowsDatabase returns true if managed resources using this ProviderConfig
+// may target the supplied database.
+func (s *ProviderConfigSpec) AllowsDatabase(database string) bool {
+	if len(s.AllowedDatabases) == 0 {
+		return true
+	}
+	for _, a := range s.AllowedDatabases {
+		if a == database {
+			return true
+		}
+	}
+	return false
+}
+
+// A TLSConfig references Secrets containing PEM encoded TLS material.
+type TLSConfig struct {
+	// CASecretRef references the CA certificates used to verify the
+	// server's certificate when SSLMode is 'verify-ca' or 'verify-full'. The
+	// system's trusted CAs are used when unset.
+	// +optional
+	CASecretRef *xpv1.SecretKeySelector `json:"caSecretRef,omitempty"`
+
+	// ClientCertSecretRef references the certificate presented to the server
+	// to authenticate the provider.
+	// +optional
+	ClientCertSecretRef *xpv1.SecretKeySelector `json:"clientCertSecretRef,omitempty"`
+
+	// ClientKeySecretRef references the private key of the client
+	// certificate.
+	// +optional
+	ClientKeySecretRef *xpv1.SecretKeySelector `json:"clientKeySecretRef,omitempty"`
+}
+
+// A DDLRateLimit configures a token bucket rate limiter.
+type DDLRateLimit struct {
+	// OperationsPerSecond is the sustained rate at which statements may be
+	// executed. A transaction counts as one operation.
+	// +kubebuilder:validation:Minimum=1
+	OperationsPerSecond int `json:"operationsPerSecond"`
+
+	// Burst is the number of operations that may be executed at once before
+	// the sustained rate applies. Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Burst *int `json:"burst,omitempty"`
+}
+
+// Rate returns the operations per second and burst of the supplied limit, or
+// zero if it is nil.
+func (l *DDLRateLimit) Rate() (perSecond, burst int) {
+	if l == nil {
+		return 0, 0
+	}
+	burst = 1
+	if l.Burst != nil {
+		burst = *l.Burst
+	}
+	return l.OperationsPerSecond, burst
+}
+
+// An AuditConfig configures a table in which changes are recorded.
+type AuditConfig struct {
+	// Table into which audit records are inserted. The table may be schema
+	// qualified, and must already exist. Defaults to 'crossplane_audit'.
+	// +optional
+	Table *string `json:"table,omitempty"`
+
+	// UIDColumn is the column that records the UID of the managed resource
+	// that made the change. Defaults to 'resource_uid'.
+	// +optional
+	UIDColumn *string `json:"uidColumn,omitempty"`
+
+	// ActionColumn is the column that records the action taken, for example
+	// 'CREATE EXTENSION'. Defaults to 'action'.
+	// +optional
+	ActionColumn *string `json:"actionColumn,omitempty"`
+
+	// TimestampColumn is the column that records when the change was made.
+	// Defaults to 'created_at'.
+	// +optional
+	TimestampColumn *string `json:"timestampColumn,omitempty"`
 }
 
 const (
@@ -33,23 +225,185 @@ const (
 	// should acquire credentials from a connection secret written by a managed
 	// resource that represents a PostgreSQL server.
 	CredentialsSourcePostgreSQLConnectionSecret xpv1.CredentialsSource = "PostgreSQLConnectionSecret"
+
+	// CredentialsSourceAWSRDSIAMAuth indicates that a provider should
+	// authenticate to an AWS RDS server using short-lived IAM authentication
+	// tokens.
+	CredentialsSourceAWSRDSIAMAuth xpv1.CredentialsSource = "AWSRDSIAMAuth"
+
+	// CredentialsSourceGCPCloudSQLIAMAuth indicates that a provider should
+	// authenticate to a GCP Cloud SQL server using short-lived OAuth access
+	// tokens.
+	CredentialsSourceGCPCloudSQLIAMAuth xpv1.CredentialsSource = "GCPCloudSQLIAMAuth"
 )
 
 // ProviderCredentials required to authenticate.
 type ProviderCredentials struct {
 	// Source of the provider credentials.
-	// +kubebuilder:validation:Enum=PostgreSQLConnectionSecret
+	// +kubebuilder:validation:Enum=PostgreSQLConnectionSecret;AWSRDSIAMAuth;GCPCloudSQLIAMAuth
 	Source xpv1.CredentialsSource `json:"source"`
 
+	// AWSRDSIAMAuth configures authentication using AWS RDS IAM
+	// authentication tokens. It is required when the source is
+	// AWSRDSIAMAuth, in which case no connection secret is read.
+	// +optional
+	AWSRDSIAMAuth *AWSRDSIAMAuth `json:"awsRDSIAMAuth,omitempty"`
+
+	// GCPCloudSQLIAMAuth configures authentication using GCP Cloud SQL IAM
+	// database authentication. It is required when the source is
+	// GCPCloudSQLIAMAuth, in which case no connection secret is read.
+	// +optional
+	GCPCloudSQLIAMAuth *GCPCloudSQLIAMAuth `json:"gcpCloudSQLIAMAuth,omitempty"`
+
 	// A CredentialsSecretRef is a reference to a PostgreSQL connection secret
 	// that contains the credentials that must be used to connect to the
 	// provider. +optional
 	ConnectionSecretRef *xpv1.SecretReference `json:"connectionSecretRef,omitempty"`
+
+	// FailoverConnectionSecretRefs are references to PostgreSQL connection
+	// secrets that are tried in order when the provider cannot connect using
+	// the secret referenced by ConnectionSecretRef, for example to fail over
+	// to a disaster recovery server. Once a secret connects it is tried first
+	// until it fails to connect. Keys apply to every secret.
+	// +optional
+	FailoverConnectionSecretRefs []xpv1.SecretReference `json:"failoverConnectionSecretRefs,omitempty"`
+
+	// Keys are the keys of the connection secret that hold each connection
+	// detail, for secrets that don't use the standard keys.
+	// +optional
+	Keys *CredentialKeys `json:"keys,omitempty"`
+}
+
+// AWSRDSIAMAuth configures authentication to an AWS RDS server using IAM
+// authentication tokens. A token, valid for 15 minutes, is used in place of a
+// password and reused for up to 10 minutes. Tokens are signed using the AWS
+// credentials the provider runs with, found using the AWS SDK's default
+// credential chain: from the environment, the shared credentials file, a web
+// identity token (e.g. EKS IAM roles for service accounts), a container
+// credentials endpoint, or the EC2 instance metadata service.
+type AWSRDSIAMAuth struct {
+	// Region of the RDS server, for example 'us-east-1'.
+	Region string `json:"region"`
+
+	// Endpoint is the hostname of the RDS server.
+	Endpoint string `json:"endpoint"`
+
+	// Port of the RDS server. Defaults to 5432.
+	// +optional
+	Port *int `json:"port,omitempty"`
+
+	// Username of the database user to authenticate as. The user must be
+	// granted the rds_iam role.
+	Username string `json:"username"`
+}
+
+// GCPCloudSQLIAMAuth configures authentication to a GCP Cloud SQL server using
+// IAM database authentication. An OAuth access token for the service account
+// the provider runs as, for example via GKE Workload Identity, is used in
+// place of a password each time the provider connects.
+type GCPCloudSQLIAMAuth struct {
+	// Endpoint is the hostname or IP address of the Cloud SQL server.
+	Endpoint string `json:"endpoint"`
+
+	// Port of the Cloud SQL server. Defaults to 5432.
+	// +optional
+	Port *int `json:"port,omitempty"`
+
+	// Username of the IAM database user to authenticate as. For a service
+	// account this is its email address without the '.gserviceaccount.com'
+	// suffix.
+	Username string `json:"username"`
+}
+
+// CredentialKeys are the keys of a connection secret that hold each
+// connection detail.
+type CredentialKeys struct {
+	// Username key. Defaults to 'username'.
+	// +optional
+	Username *string `json:"username,omitempty"`
+
+	// Password key. Defaults to 'password'.
+	// +optional
+	Password *string `json:"password,omitempty"`
+
+	// Endpoint key. Defaults to 'endpoint'.
+	// +optional
+	Endpoint *string `json:"endpoint,omitempty"`
+
+	// Port key. Defaults to 'port'.
+	// +optional
+	Port *string `json:"port,omitempty"`
+
+	// Database key. When set, managed resources that don't specify a
+	// database connect to the database it holds, rather than to the server's
+	// default database.
+	// +optional
+	Database *string `json:"database,omitempty"`
+}
+
+// Resolve returns the supplied connection secret data with each connection
+// detail stored under its standard key, which is where the PostgreSQL client
+// reads it from. The data is returned unchanged if the keys are nil.
+func (k *CredentialKeys) Resolve(data map[string][]byte) map[string][]byte {
+	if k == nil {
+		return data
+	}
+	out := make(map[string][]byte, len(data))
+	for key, v := range data {
+		out[key] = v
+	}
+	for std, custom := range map[string]*string{
+		xpv1.ResourceCredentialsSecretUserKey:     k.Username,
+		xpv1.ResourceCredentialsSecretPasswordKey: k.Password,
+		xpv1.ResourceCredentialsSecretEndpointKey: k.Endpoint,
+		xpv1.ResourceCredentialsSecretPortKey:     k.Port,
+	} {
+		if custom != nil {
+			out[std] = data[*custom]
+		}
+	}
+	return out
+}
+
+// DefaultDatabase returns the database held by the supplied connection secret
+// data under the Database key, or an empty string if no Database key is
+// configured.
+func (k *CredentialKeys) DefaultDatabase(data map[string][]byte) string {
+	if k == nil || k.Database == nil {
+		return ""
+	}
+	return string(data[*k.Database])
 }
 
 // A ProviderConfigStatus reflects the observed state of a ProviderConfig.
 type ProviderConfigStatus struct {
 	xpv1.ProviderConfigStatus `json:",inline"`
+
+	// Server reports the capabilities of the server, as last detected by the
+	// provider when it connected using this ProviderConfig.
+	// +optional
+	Server *ServerCapabilities `json:"server,omitempty"`
+}
+
+// ServerCapabilities are the capabilities of a PostgreSQL server that affect
+// how the provider manages it.
+type ServerCapabilities struct {
+	// Version of the server, as reported by its server_version setting.
+	Version string `json:"version"`
+
+	// Superuser is true if the role the provider uses is a superuser. Some
+	// extensions may only be created by a superuser.
+	Superuser bool `json:"superuser"`
+
+	// Replica is true if the server is a read only replica (i.e. is in
+	// recovery), on which nothing can be created.
+	Replica bool `json:"replica"`
+
+	// TLS is true if the provider's connection to the server is encrypted.
+	TLS bool `json:"tls"`
+
+	// LastDetectedTime is when the capabilities were last detected.
+	LastDetectedTime metav1.Time `json:"lastDetectedTime"`
 }
 
 // +kubebuilder:object:root=true
@@ -58,6 +412,7 @@ type ProviderConfigStatus struct {
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:printcolumn:name="SECRET-NAME",type="string",JSONPath=".spec.credentialsSecretRef.name",priority=1
+// +kubebuilder:printcolumn:name="SERVER-VERSION",type="string",JSONPath=".status.server.version",priority=1
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,provider,sql}
 type ProviderConfig struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/pointer"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+func TestCredentialKeysResolve(t *testing.T) {
+	standard := map[string][]byte{
+		xpv1.ResourceCredentialsSecretUserKey:     []byte("cool"),
+		xpv1.ResourceCredentialsSecretPasswordKey: []byte("secret"),
+		xpv1.ResourceCredentialsSecretEndpointKey: []byte("example.org"),
+		xpv1.ResourceCredentialsSecretPortKey:     []byte("5432"),
+	}
+
+	cases := map[string]struct {
+		reason string
+		keys   *CredentialKeys
+		data   map[string][]byte
+		want   map[string][]byte
+	}{
+		"NilKeys": {
+			reason: "The data should be returned unchanged when no keys are configured",
+			data:   standard,
+			want:   standard,
+		},
+		"DefaultKeys": {
+			reason: "Connection details should be read from the standard keys when no custom key is configured",
+			keys:   &CredentialKeys{},
+			data:   standard,
+			want:   standard,
+		},
+		"CustomKeys": {
+			reason: "Connection details should be read from the configured keys, and stored under the standard keys",
+			keys: &CredentialKeys{
+				Username: pointer.StringPtr("user"),
+				Password: pointer.StringPtr("pass"),
+				Endpoint: pointer.StringPtr("host"),
+				Port:     pointer.StringPtr("dbport"),
+			},
+			data: map[string][]byte{
+				"user":   []byte("cool"),
+				"pass":   []byte("secret"),
+				"host":   []byte("example.org"),
+				"dbport": []byte("5432"),
+			},
+			want: map[string][]byte{
+				"user":                                []byte("cool"),
+				"pass":                                []byte("secret"),
+				"host":                                []byte("example.org"),
+				"dbport":                              []byte("5432"),
+				xpv1.ResourceCredentialsSecretUserKey: []byte("cool"),
+				xpv1.ResourceCredentialsSecretPasswordKey: []byte("secret"),
+				xpv1.ResourceCredentialsSecretEndpointKey: []byte("example.org"),
+				xpv1.ResourceCredentialsSecretPortKey:     []byte("5432"),
+			},
+		},
+		"SomeCustomKeys": {
+			reason: "Connection details without a configured key should be read from the standard key",
+			keys:   &CredentialKeys{Username: pointer.StringPtr("user")},
+			data: map[string][]byte{
+				"user":                                []byte("cool"),
+				xpv1.ResourceCredentialsSecretUserKey: []byte("ignored"),
+				xpv1.ResourceCredentialsSecretPasswordKey: []byte("secret"),
+			},
+			want: map[string][]byte{
+				"user":                                []byte("cool"),
+				xpv1.ResourceCredentialsSecretUserKey: []byte("cool"),
+				xpv1.ResourceCredentialsSecretPasswordKey: []byte("secret"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.keys.Resolve(tc.data)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nResolve(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCredentialKeysDefaultDatabase(t *testing.T) {
+	data := map[string][]byte{"dbname": []byte("cool")}
+
+	cases := map[string]struct {
+		reason string
+		keys   *CredentialKeys
+		want   string
+	}{
+		"NilKeys": {
+			reason: "No default database should be returned when no keys are configured",
+			want:   "",
+		},
+		"NoDatabaseKey": {
+			reason: "No default database should be returned when no Database key is configured",
+			keys:   &CredentialKeys{Username: pointer.StringPtr("user")},
+			want:   "",
+		},
+		"DatabaseKey": {
+			reason: "The database held under the configured Database key should be returned",
+			keys:   &CredentialKeys{Database: pointer.StringPtr("dbname")},
+			want:   "cool",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.keys.DefaultDatabase(data)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nDefaultDatabase(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
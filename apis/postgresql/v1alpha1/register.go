@@ -90,6 +90,14 @@ var (
 	GrantGroupVersionKind = SchemeGroupVersion.WithKind(GrantKind)
 )
 
+// Trigger type metadata.
+var (
+	TriggerKind             = reflect.TypeOf(Trigger{}).Name()
+	TriggerGroupKind        = schema.GroupKind{Group: Group, Kind: TriggerKind}.String()
+	TriggerKindAPIVersion   = TriggerKind + "." + SchemeGroupVersion.String()
+	TriggerGroupVersionKind = SchemeGroupVersion.WithKind(TriggerKind)
+)
+
 func init() {
 	SchemeBuilder.Register(&ProviderConfig{}, &ProviderConfigList{})
 	SchemeBuilder.Register(&ProviderConfigUsage{}, &ProviderConfigUsageList{})
@@ -97,4 +105,5 @@ func init() {
 	SchemeBuilder.Register(&Role{}, &RoleList{})
 	SchemeBuilder.Register(&Grant{}, &GrantList{})
 	SchemeBuilder.Register(&Extension{}, &ExtensionList{})
+	SchemeBuilder.Register(&Trigger{}, &TriggerList{})
 }
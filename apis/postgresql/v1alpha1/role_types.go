@@ -75,6 +75,14 @@ type RoleParameters struct {
 	// +optional
 	ConnectionLimit *int32 `json:"connectionLimit,omitempty"`
 
+	// MemberOf is the set of roles this role is a member of. The role is
+	// granted membership of any role it's missing from, and has its membership
+	// of any other role revoked. Memberships are not managed when MemberOf is
+	// unset, for example because they're managed using Grants; set it to an
+	// empty list to revoke all memberships.
+	// +optional
+	MemberOf []string `json:"memberOf"`
+
 	// Privileges to be granted.
 	// +optional
 	Privileges RolePrivilege `json:"privileges,omitempty"`
@@ -83,6 +91,13 @@ type RoleParameters struct {
 	// for this role. If no reference is given, a password will be auto-generated.
 	// +optional
 	PasswordSecretRef *xpv1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+
+	// ValidUntil is when the role's password expires, as an RFC 3339
+	// timestamp such as '2030-01-01T00:00:00Z', or 'infinity' if it never
+	// expires. Timestamps are compared at second precision, independent of
+	// their time zone and the server's.
+	// +optional
+	ValidUntil *string `json:"validUntil,omitempty"`
 }
 
 // A RoleObservation represents the observed state of a PostgreSQL role.
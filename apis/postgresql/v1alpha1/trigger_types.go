@@ -0,0 +1,198 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reference"
+)
+
+// TriggerTiming determines when a trigger fires relative to the event that
+// fires it.
+type TriggerTiming string
+
+// The possible trigger timings.
+const (
+	TriggerBefore    TriggerTiming = "BEFORE"
+	TriggerAfter     TriggerTiming = "AFTER"
+	TriggerInsteadOf TriggerTiming = "INSTEAD OF"
+)
+
+// TriggerEvent is an event that fires a trigger.
+// +kubebuilder:validation:Enum=INSERT;UPDATE;DELETE;TRUNCATE
+type TriggerEvent string
+
+// The possible trigger events.
+const (
+	TriggerInsert   TriggerEvent = "INSERT"
+	TriggerUpdate   TriggerEvent = "UPDATE"
+	TriggerDelete   TriggerEvent = "DELETE"
+	TriggerTruncate TriggerEvent = "TRUNCATE"
+)
+
+// TriggerLevel determines whether a trigger fires once for each row that is
+// modified, or once for each statement.
+type TriggerLevel string
+
+// The possible trigger levels.
+const (
+	TriggerForEachRow       TriggerLevel = "ROW"
+	TriggerForEachStatement TriggerLevel = "STATEMENT"
+)
+
+// TriggerParameters define the desired state of a PostgreSQL trigger. The
+// trigger is named for the managed resource's external name.
+// See https://www.postgresql.org/docs/current/sql-createtrigger.html
+type TriggerParameters struct {
+	// Table on which the trigger is created. The table must already exist.
+	// +immutable
+	Table string `json:"table"`
+
+	// Schema of the table on which the trigger is created. The table is
+	// found using the search path when unset.
+	// +immutable
+	// +optional
+	Schema *string `json:"schema,omitempty"`
+
+	// Timing determines whether the trigger fires before, after, or instead
+	// of the events that fire it.
+	// +immutable
+	// +kubebuilder:validation:Enum=BEFORE;AFTER;INSTEAD OF
+	Timing TriggerTiming `json:"timing"`
+
+	// Events that fire the trigger.
+	// +immutable
+	// +kubebuilder:validation:MinItems:=1
+	Events []TriggerEvent `json:"events"`
+
+	// ForEach determines whether the trigger fires once for each modified
+	// row, or once for each statement. Defaults to STATEMENT.
+	// +immutable
+	// +kubebuilder:validation:Enum=ROW;STATEMENT
+	// +optional
+	ForEach *TriggerLevel `json:"forEach,omitempty"`
+
+	// When is a boolean condition that determines whether the trigger
+	// function will actually be executed, for example
+	// 'OLD.* IS DISTINCT FROM NEW.*'. It must be a single expression with
+	// balanced parentheses, and may not contain semicolons, comments, or
+	// dollar quoted literals.
+	// +immutable
+	// +kubebuilder:validation:Pattern:=^[^;$]*$
+	// +optional
+	When *string `json:"when,omitempty"`
+
+	// Function executed when the trigger fires. The function must already
+	// exist, and take no arguments.
+	// +immutable
+	Function string `json:"function"`
+
+	// FunctionSchema is the schema of the function executed when the trigger
+	// fires. The function is found using the search path when unset.
+	// +immutable
+	// +optional
+	FunctionSchema *string `json:"functionSchema,omitempty"`
+
+	// Arguments passed to the function when the trigger fires.
+	// +immutable
+	// +optional
+	Arguments []string `json:"arguments,omitempty"`
+
+	// Enabled determines whether the trigger fires. Defaults to true.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Database in which the trigger is created.
+	// +immutable
+	// +optional
+	Database *string `json:"database,omitempty"`
+
+	// DatabaseRef references the database object this trigger is for.
+	// +immutable
+	// +optional
+	DatabaseRef *xpv1.Reference `json:"databaseRef,omitempty"`
+
+	// DatabaseSelector selects a reference to a Database this trigger is for.
+	// +immutable
+	// +optional
+	DatabaseSelector *xpv1.Selector `json:"databaseSelector,omitempty"`
+}
+
+// A TriggerSpec defines the desired state of a Trigger.
+type TriggerSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       TriggerParameters `json:"forProvider"`
+}
+
+// A TriggerStatus represents the observed state of a Trigger.
+type TriggerStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Trigger represents the declarative state of a PostgreSQL trigger.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="TABLE",type="string",JSONPath=".spec.forProvider.table"
+// +kubebuilder:printcolumn:name="FUNCTION",type="string",JSONPath=".spec.forProvider.function"
+// +kubebuilder:printcolumn:name="DATABASE",type="string",JSONPath=".spec.forProvider.database"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,sql}
+type Trigger struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TriggerSpec   `json:"spec"`
+	Status TriggerStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TriggerList contains a list of Trigger
+type TriggerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Trigger `json:"items"`
+}
+
+// ResolveReferences of this Trigger
+func (mg *Trigger) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	// Resolve spec.forProvider.database
+	rsp, err := r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.Database),
+		Reference:    mg.Spec.ForProvider.DatabaseRef,
+		Selector:     mg.Spec.ForProvider.DatabaseSelector,
+		To:           reference.To{Managed: &Database{}, List: &DatabaseList{}},
+		Extract:      reference.ExternalName(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "spec.forProvider.database")
+	}
+	mg.Spec.ForProvider.Database = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.DatabaseRef = rsp.ResolvedReference
+	return nil
+}
@@ -22,9 +22,140 @@ package v1alpha1
 
 import (
 	"github.com/crossplane/crossplane-runtime/apis/common/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSRDSIAMAuth) DeepCopyInto(out *AWSRDSIAMAuth) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSRDSIAMAuth.
+func (in *AWSRDSIAMAuth) DeepCopy() *AWSRDSIAMAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSRDSIAMAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditConfig) DeepCopyInto(out *AuditConfig) {
+	*out = *in
+	if in.Table != nil {
+		in, out := &in.Table, &out.Table
+		*out = new(string)
+		**out = **in
+	}
+	if in.UIDColumn != nil {
+		in, out := &in.UIDColumn, &out.UIDColumn
+		*out = new(string)
+		**out = **in
+	}
+	if in.ActionColumn != nil {
+		in, out := &in.ActionColumn, &out.ActionColumn
+		*out = new(string)
+		**out = **in
+	}
+	if in.TimestampColumn != nil {
+		in, out := &in.TimestampColumn, &out.TimestampColumn
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditConfig.
+func (in *AuditConfig) DeepCopy() *AuditConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapReference) DeepCopyInto(out *ConfigMapReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapReference.
+func (in *ConfigMapReference) DeepCopy() *ConfigMapReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialKeys) DeepCopyInto(out *CredentialKeys) {
+	*out = *in
+	if in.Username != nil {
+		in, out := &in.Username, &out.Username
+		*out = new(string)
+		**out = **in
+	}
+	if in.Password != nil {
+		in, out := &in.Password, &out.Password
+		*out = new(string)
+		**out = **in
+	}
+	if in.Endpoint != nil {
+		in, out := &in.Endpoint, &out.Endpoint
+		*out = new(string)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(string)
+		**out = **in
+	}
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialKeys.
+func (in *CredentialKeys) DeepCopy() *CredentialKeys {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialKeys)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DDLRateLimit) DeepCopyInto(out *DDLRateLimit) {
+	*out = *in
+	if in.Burst != nil {
+		in, out := &in.Burst, &out.Burst
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DDLRateLimit.
+func (in *DDLRateLimit) DeepCopy() *DDLRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(DDLRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Database) DeepCopyInto(out *Database) {
 	*out = *in
@@ -52,6 +183,48 @@ func (in *Database) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseBootstrap) DeepCopyInto(out *DatabaseBootstrap) {
+	*out = *in
+	if in.RevokePublicSchema != nil {
+		in, out := &in.RevokePublicSchema, &out.RevokePublicSchema
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RevokePublicSchemaPrivileges != nil {
+		in, out := &in.RevokePublicSchemaPrivileges, &out.RevokePublicSchemaPrivileges
+		*out = make([]SchemaPrivilege, len(*in))
+		copy(*out, *in)
+	}
+	if in.RevokePublicSchemaFrom != nil {
+		in, out := &in.RevokePublicSchemaFrom, &out.RevokePublicSchemaFrom
+		*out = new(string)
+		**out = **in
+	}
+	if in.PublicSchemaOwner != nil {
+		in, out := &in.PublicSchemaOwner, &out.PublicSchemaOwner
+		*out = new(string)
+		**out = **in
+	}
+	if in.DefaultPrivileges != nil {
+		in, out := &in.DefaultPrivileges, &out.DefaultPrivileges
+		*out = make([]DefaultPrivileges, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseBootstrap.
+func (in *DatabaseBootstrap) DeepCopy() *DatabaseBootstrap {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseBootstrap)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatabaseList) DeepCopyInto(out *DatabaseList) {
 	*out = *in
@@ -84,6 +257,21 @@ func (in *DatabaseList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseObservation) DeepCopyInto(out *DatabaseObservation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseObservation.
+func (in *DatabaseObservation) DeepCopy() *DatabaseObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatabaseParameters) DeepCopyInto(out *DatabaseParameters) {
 	*out = *in
@@ -132,6 +320,11 @@ func (in *DatabaseParameters) DeepCopyInto(out *DatabaseParameters) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Bootstrap != nil {
+		in, out := &in.Bootstrap, &out.Bootstrap
+		*out = new(DatabaseBootstrap)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseParameters.
@@ -165,6 +358,7 @@ func (in *DatabaseSpec) DeepCopy() *DatabaseSpec {
 func (in *DatabaseStatus) DeepCopyInto(out *DatabaseStatus) {
 	*out = *in
 	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	out.AtProvider = in.AtProvider
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseStatus.
@@ -177,6 +371,36 @@ func (in *DatabaseStatus) DeepCopy() *DatabaseStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultPrivileges) DeepCopyInto(out *DefaultPrivileges) {
+	*out = *in
+	if in.Privileges != nil {
+		in, out := &in.Privileges, &out.Privileges
+		*out = make(GrantPrivileges, len(*in))
+		copy(*out, *in)
+	}
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = new(string)
+		**out = **in
+	}
+	if in.ForRole != nil {
+		in, out := &in.ForRole, &out.ForRole
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultPrivileges.
+func (in *DefaultPrivileges) DeepCopy() *DefaultPrivileges {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultPrivileges)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Extension) DeepCopyInto(out *Extension) {
 	*out = *in
@@ -204,6 +428,31 @@ func (in *Extension) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExtensionDatabaseObservation) DeepCopyInto(out *ExtensionDatabaseObservation) {
+	*out = *in
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(string)
+		**out = **in
+	}
+	if in.LastError != nil {
+		in, out := &in.LastError, &out.LastError
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtensionDatabaseObservation.
+func (in *ExtensionDatabaseObservation) DeepCopy() *ExtensionDatabaseObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtensionDatabaseObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExtensionList) DeepCopyInto(out *ExtensionList) {
 	*out = *in
@@ -236,6 +485,88 @@ func (in *ExtensionList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExtensionObservation) DeepCopyInto(out *ExtensionObservation) {
+	*out = *in
+	if in.InstalledVersion != nil {
+		in, out := &in.InstalledVersion, &out.InstalledVersion
+		*out = new(string)
+		**out = **in
+	}
+	if in.Owner != nil {
+		in, out := &in.Owner, &out.Owner
+		*out = new(string)
+		**out = **in
+	}
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = new(string)
+		**out = **in
+	}
+	if in.Relocatable != nil {
+		in, out := &in.Relocatable, &out.Relocatable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DefinitionHash != nil {
+		in, out := &in.DefinitionHash, &out.DefinitionHash
+		*out = new(string)
+		**out = **in
+	}
+	if in.PendingStatements != nil {
+		in, out := &in.PendingStatements, &out.PendingStatements
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UpdatePathAvailable != nil {
+		in, out := &in.UpdatePathAvailable, &out.UpdatePathAvailable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UnavailableVersion != nil {
+		in, out := &in.UnavailableVersion, &out.UnavailableVersion
+		*out = new(string)
+		**out = **in
+	}
+	if in.DefaultVersion != nil {
+		in, out := &in.DefaultVersion, &out.DefaultVersion
+		*out = new(string)
+		**out = **in
+	}
+	if in.InstalledDependencies != nil {
+		in, out := &in.InstalledDependencies, &out.InstalledDependencies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Adopted != nil {
+		in, out := &in.Adopted, &out.Adopted
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]ExtensionDatabaseObservation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtensionObservation.
+func (in *ExtensionObservation) DeepCopy() *ExtensionObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtensionObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExtensionParameters) DeepCopyInto(out *ExtensionParameters) {
 	*out = *in
@@ -244,16 +575,73 @@ func (in *ExtensionParameters) DeepCopyInto(out *ExtensionParameters) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.VersionFallback != nil {
+		in, out := &in.VersionFallback, &out.VersionFallback
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NoTransaction != nil {
+		in, out := &in.NoTransaction, &out.NoTransaction
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExpectedDefinitionHash != nil {
+		in, out := &in.ExpectedDefinitionHash, &out.ExpectedDefinitionHash
+		*out = new(string)
+		**out = **in
+	}
+	if in.DropSnapshotConfigMapRef != nil {
+		in, out := &in.DropSnapshotConfigMapRef, &out.DropSnapshotConfigMapRef
+		*out = new(ConfigMapReference)
+		**out = **in
+	}
+	if in.PreloadLibrary != nil {
+		in, out := &in.PreloadLibrary, &out.PreloadLibrary
+		*out = new(string)
+		**out = **in
+	}
+	if in.Comment != nil {
+		in, out := &in.Comment, &out.Comment
+		*out = new(string)
+		**out = **in
+	}
 	if in.Schema != nil {
 		in, out := &in.Schema, &out.Schema
 		*out = new(string)
 		**out = **in
 	}
+	if in.UnmanagedSchemaPolicy != nil {
+		in, out := &in.UnmanagedSchemaPolicy, &out.UnmanagedSchemaPolicy
+		*out = new(UnmanagedSchemaPolicy)
+		**out = **in
+	}
 	if in.Database != nil {
 		in, out := &in.Database, &out.Database
 		*out = new(string)
 		**out = **in
 	}
+	if in.SessionParameters != nil {
+		in, out := &in.SessionParameters, &out.SessionParameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ObserveUpdatePath != nil {
+		in, out := &in.ObserveUpdatePath, &out.ObserveUpdatePath
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PostUpgradeUpdatePolicy != nil {
+		in, out := &in.PostUpgradeUpdatePolicy, &out.PostUpgradeUpdatePolicy
+		*out = new(PostUpgradeUpdatePolicy)
+		**out = **in
+	}
+	if in.DatabasePattern != nil {
+		in, out := &in.DatabasePattern, &out.DatabasePattern
+		*out = new(string)
+		**out = **in
+	}
 	if in.DatabaseRef != nil {
 		in, out := &in.DatabaseRef, &out.DatabaseRef
 		*out = new(v1.Reference)
@@ -264,6 +652,36 @@ func (in *ExtensionParameters) DeepCopyInto(out *ExtensionParameters) {
 		*out = new(v1.Selector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Requires != nil {
+		in, out := &in.Requires, &out.Requires
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Cascade != nil {
+		in, out := &in.Cascade, &out.Cascade
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DropPolicy != nil {
+		in, out := &in.DropPolicy, &out.DropPolicy
+		*out = new(DropPolicy)
+		**out = **in
+	}
+	if in.VerifyDrop != nil {
+		in, out := &in.VerifyDrop, &out.VerifyDrop
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RequiresRefs != nil {
+		in, out := &in.RequiresRefs, &out.RequiresRefs
+		*out = make([]v1.Reference, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequiresSelector != nil {
+		in, out := &in.RequiresSelector, &out.RequiresSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtensionParameters.
@@ -297,6 +715,7 @@ func (in *ExtensionSpec) DeepCopy() *ExtensionSpec {
 func (in *ExtensionStatus) DeepCopyInto(out *ExtensionStatus) {
 	*out = *in
 	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtensionStatus.
@@ -309,6 +728,26 @@ func (in *ExtensionStatus) DeepCopy() *ExtensionStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GCPCloudSQLIAMAuth) DeepCopyInto(out *GCPCloudSQLIAMAuth) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCPCloudSQLIAMAuth.
+func (in *GCPCloudSQLIAMAuth) DeepCopy() *GCPCloudSQLIAMAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(GCPCloudSQLIAMAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Grant) DeepCopyInto(out *Grant) {
 	*out = *in
@@ -553,6 +992,76 @@ func (in *ProviderConfigList) DeepCopyObject() runtime.Object {
 func (in *ProviderConfigSpec) DeepCopyInto(out *ProviderConfigSpec) {
 	*out = *in
 	in.Credentials.DeepCopyInto(&out.Credentials)
+	if in.AllowedDatabases != nil {
+		in, out := &in.AllowedDatabases, &out.AllowedDatabases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServerCertFingerprint != nil {
+		in, out := &in.ServerCertFingerprint, &out.ServerCertFingerprint
+		*out = new(string)
+		**out = **in
+	}
+	if in.SSLMode != nil {
+		in, out := &in.SSLMode, &out.SSLMode
+		*out = new(string)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(TLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TCPKeepalive != nil {
+		in, out := &in.TCPKeepalive, &out.TCPKeepalive
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ConnectTimeout != nil {
+		in, out := &in.ConnectTimeout, &out.ConnectTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.StatementTimeout != nil {
+		in, out := &in.StatementTimeout, &out.StatementTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.SessionAuthorization != nil {
+		in, out := &in.SessionAuthorization, &out.SessionAuthorization
+		*out = new(string)
+		**out = **in
+	}
+	if in.AssumeRole != nil {
+		in, out := &in.AssumeRole, &out.AssumeRole
+		*out = new(string)
+		**out = **in
+	}
+	if in.SearchPath != nil {
+		in, out := &in.SearchPath, &out.SearchPath
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
+	}
+	if in.ReadOnlyObserve != nil {
+		in, out := &in.ReadOnlyObserve, &out.ReadOnlyObserve
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		*out = new(AuditConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DDLRateLimit != nil {
+		in, out := &in.DDLRateLimit, &out.DDLRateLimit
+		*out = new(DDLRateLimit)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigSpec.
@@ -569,6 +1078,11 @@ func (in *ProviderConfigSpec) DeepCopy() *ProviderConfigSpec {
 func (in *ProviderConfigStatus) DeepCopyInto(out *ProviderConfigStatus) {
 	*out = *in
 	in.ProviderConfigStatus.DeepCopyInto(&out.ProviderConfigStatus)
+	if in.Server != nil {
+		in, out := &in.Server, &out.Server
+		*out = new(ServerCapabilities)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigStatus.
@@ -642,11 +1156,31 @@ func (in *ProviderConfigUsageList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProviderCredentials) DeepCopyInto(out *ProviderCredentials) {
 	*out = *in
+	if in.AWSRDSIAMAuth != nil {
+		in, out := &in.AWSRDSIAMAuth, &out.AWSRDSIAMAuth
+		*out = new(AWSRDSIAMAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GCPCloudSQLIAMAuth != nil {
+		in, out := &in.GCPCloudSQLIAMAuth, &out.GCPCloudSQLIAMAuth
+		*out = new(GCPCloudSQLIAMAuth)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ConnectionSecretRef != nil {
 		in, out := &in.ConnectionSecretRef, &out.ConnectionSecretRef
 		*out = new(v1.SecretReference)
 		**out = **in
 	}
+	if in.FailoverConnectionSecretRefs != nil {
+		in, out := &in.FailoverConnectionSecretRefs, &out.FailoverConnectionSecretRefs
+		*out = make([]v1.SecretReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = new(CredentialKeys)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderCredentials.
@@ -746,12 +1280,22 @@ func (in *RoleParameters) DeepCopyInto(out *RoleParameters) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.MemberOf != nil {
+		in, out := &in.MemberOf, &out.MemberOf
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	in.Privileges.DeepCopyInto(&out.Privileges)
 	if in.PasswordSecretRef != nil {
 		in, out := &in.PasswordSecretRef, &out.PasswordSecretRef
 		*out = new(v1.SecretKeySelector)
 		**out = **in
 	}
+	if in.ValidUntil != nil {
+		in, out := &in.ValidUntil, &out.ValidUntil
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleParameters.
@@ -847,3 +1391,206 @@ func (in *RoleStatus) DeepCopy() *RoleStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerCapabilities) DeepCopyInto(out *ServerCapabilities) {
+	*out = *in
+	in.LastDetectedTime.DeepCopyInto(&out.LastDetectedTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerCapabilities.
+func (in *ServerCapabilities) DeepCopy() *ServerCapabilities {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerCapabilities)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
+	*out = *in
+	if in.CASecretRef != nil {
+		in, out := &in.CASecretRef, &out.CASecretRef
+		*out = new(v1.SecretKeySelector)
+		**out = **in
+	}
+	if in.ClientCertSecretRef != nil {
+		in, out := &in.ClientCertSecretRef, &out.ClientCertSecretRef
+		*out = new(v1.SecretKeySelector)
+		**out = **in
+	}
+	if in.ClientKeySecretRef != nil {
+		in, out := &in.ClientKeySecretRef, &out.ClientKeySecretRef
+		*out = new(v1.SecretKeySelector)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSConfig.
+func (in *TLSConfig) DeepCopy() *TLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Trigger) DeepCopyInto(out *Trigger) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Trigger.
+func (in *Trigger) DeepCopy() *Trigger {
+	if in == nil {
+		return nil
+	}
+	out := new(Trigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Trigger) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerList) DeepCopyInto(out *TriggerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Trigger, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerList.
+func (in *TriggerList) DeepCopy() *TriggerList {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TriggerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerParameters) DeepCopyInto(out *TriggerParameters) {
+	*out = *in
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = new(string)
+		**out = **in
+	}
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]TriggerEvent, len(*in))
+		copy(*out, *in)
+	}
+	if in.ForEach != nil {
+		in, out := &in.ForEach, &out.ForEach
+		*out = new(TriggerLevel)
+		**out = **in
+	}
+	if in.When != nil {
+		in, out := &in.When, &out.When
+		*out = new(string)
+		**out = **in
+	}
+	if in.FunctionSchema != nil {
+		in, out := &in.FunctionSchema, &out.FunctionSchema
+		*out = new(string)
+		**out = **in
+	}
+	if in.Arguments != nil {
+		in, out := &in.Arguments, &out.Arguments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(string)
+		**out = **in
+	}
+	if in.DatabaseRef != nil {
+		in, out := &in.DatabaseRef, &out.DatabaseRef
+		*out = new(v1.Reference)
+		**out = **in
+	}
+	if in.DatabaseSelector != nil {
+		in, out := &in.DatabaseSelector, &out.DatabaseSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerParameters.
+func (in *TriggerParameters) DeepCopy() *TriggerParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerSpec) DeepCopyInto(out *TriggerSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerSpec.
+func (in *TriggerSpec) DeepCopy() *TriggerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerStatus) DeepCopyInto(out *TriggerStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerStatus.
+func (in *TriggerStatus) DeepCopy() *TriggerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
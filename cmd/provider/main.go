@@ -19,11 +19,13 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
 
 	"gopkg.in/alecthomas/kingpin.v2"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
@@ -31,6 +33,7 @@ import (
 
 	"github.com/crossplane-contrib/provider-sql/apis"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/options"
 )
 
 func main() {
@@ -39,6 +42,19 @@ func main() {
 		debug          = app.Flag("debug", "Run with debug logging.").Short('d').Bool()
 		syncPeriod     = app.Flag("sync", "Controller manager sync period such as 300ms, 1.5h, or 2h45m").Short('s').Default("1h").Duration()
 		leaderElection = app.Flag("leader-election", "Use leader election for the conroller manager.").Short('l').Default("false").OverrideDefaultFromEnvar("LEADER_ELECTION").Bool()
+		logSQL         = app.Flag("log-sql", "Log every SQL statement executed, with parameters redacted. Requires debug logging.").Default("false").Bool()
+		freeze         = app.Flag("freeze", "Observe managed resources, but never create, update, or delete them.").Default("false").Bool()
+		connLimit      = app.Flag("connection-limit-backoff", "How long to wait before retrying a resource when the server has too many connections. Disabled when 0.").Default("0").Duration()
+		repeatFailure  = app.Flag("repeated-failure-backoff", "How long to wait before retrying an extension that failed with the same error as last time, doubling with each further identical failure. Disabled when 0.").Default("0").Duration()
+		observe        = app.Flag("observe-interval", "How long to wait before re-observing an extension that is ready and synced. The default poll interval is used when 0.").Default("0").Duration()
+		createRetry    = app.Flag("create-retry-interval", "How long to wait before retrying an extension that failed to be created. The usual rate limited backoff is used when 0.").Default("0").Duration()
+		specSettle     = app.Flag("spec-edit-settle", "How long to wait after an extension's spec is edited before reconciling it, so that rapid edits are reconciled once. Disabled when 0.").Default("0").Duration()
+		slowOperation  = app.Flag("slow-operation-threshold", "Warn when observing, creating, updating, or deleting an extension takes longer than this. Disabled when 0.").Default("0").Duration()
+		queryTimeout   = app.Flag("query-timeout", "How long a SQL statement may run when it has no other deadline, so that a hung connection can't block a controller. Disabled when 0.").Default("0").Duration()
+		decisionLog    = app.Flag("decision-log", "Write a line of JSON describing each create, update, or delete to this file, or to stdout if '-'. Disabled when empty.").Default("").String()
+		eventSummary   = app.Flag("event-summary-interval", "Record a summary of managed resource events to each ProviderConfig at this interval, rather than individual Normal events. Disabled when 0.").Default("0").Duration()
+		capabilities   = app.Flag("capabilities-interval", "How often to detect and report the capabilities of each PostgreSQL ProviderConfig's server in its status. Disabled when 0.").Default("0").Duration()
+		inventory      = app.Flag("extension-inventory", "Maintain a summary of all PostgreSQL extensions in this namespace/name ConfigMap. Disabled when empty.").Default("").String()
 	)
 	kingpin.MustParse(app.Parse(os.Args[1:]))
 
@@ -64,6 +80,42 @@ func main() {
 	kingpin.FatalIfError(err, "Cannot create controller manager")
 
 	kingpin.FatalIfError(apis.AddToScheme(mgr.GetScheme()), "Cannot add SQL APIs to scheme")
-	kingpin.FatalIfError(controller.Setup(mgr, log), "Cannot setup SQL controllers")
+	o := options.Options{
+		Logger:                 log,
+		LogSQL:                 *logSQL,
+		Frozen:                 *freeze,
+		EventSummaryInterval:   *eventSummary,
+		ConnectionLimitBackoff: *connLimit,
+		RepeatedFailureBackoff: *repeatFailure,
+		ObserveInterval:        *observe,
+		CreateRetryInterval:    *createRetry,
+		SpecEditSettle:         *specSettle,
+		SlowOperationThreshold: *slowOperation,
+		QueryTimeout:           *queryTimeout,
+		DDLRateLimiter:         options.NewDDLRateLimiter(),
+		CapabilitiesInterval:   *capabilities,
+	}
+
+	switch *decisionLog {
+	case "":
+		// Decisions are not recorded.
+	case "-":
+		o.Decisions = options.NewJSONSink(os.Stdout)
+	default:
+		f, err := os.OpenFile(*decisionLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		kingpin.FatalIfError(err, "Cannot open decision log")
+		defer f.Close() //nolint:errcheck
+		o.Decisions = options.NewJSONSink(f)
+	}
+
+	if *inventory != "" {
+		parts := strings.SplitN(*inventory, "/", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			kingpin.Fatalf("Extension inventory %q must be of the form namespace/name", *inventory)
+		}
+		o.ExtensionInventory = &types.NamespacedName{Namespace: parts[0], Name: parts[1]}
+	}
+
+	kingpin.FatalIfError(controller.Setup(mgr, o), "Cannot setup SQL controllers")
 	kingpin.FatalIfError(mgr.Start(ctrl.SetupSignalHandler()), "Cannot start controller manager")
 }
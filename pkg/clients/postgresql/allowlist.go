@@ -0,0 +1,132 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+const errDatabaseNotAllowed = "ProviderConfig does not allow database %q"
+
+// A DatabaseNotAllowedError indicates that a managed resource targets a
+// database its ProviderConfig does not allow.
+type DatabaseNotAllowedError struct {
+	Database string
+}
+
+func (e *DatabaseNotAllowedError) Error() string {
+	return fmt.Sprintf(errDatabaseNotAllowed, e.Database)
+}
+
+// IsDatabaseNotAllowed returns true if the supplied error indicates that a
+// ProviderConfig does not allow a database.
+func IsDatabaseNotAllowed(err error) bool {
+	var dna *DatabaseNotAllowedError
+	return errors.As(err, &dna)
+}
+
+// AllowDatabase returns a Check that fails if the ProviderConfig does not
+// allow the supplied database. An empty database is allowed, because the
+// default database isn't known until the credentials are read; use
+// Connection's CheckDatabase once they are.
+func AllowDatabase(database string) Check {
+	return func(pc *v1alpha1.ProviderConfig) error {
+		if database != "" && !pc.Spec.AllowsDatabase(database) {
+			return &DatabaseNotAllowedError{Database: database}
+		}
+		return nil
+	}
+}
+
+// CheckDatabase returns a *DatabaseNotAllowedError if the Connection's
+// ProviderConfig does not allow the supplied database, or the database the
+// Connection uses by default when it is empty.
+func (c *Connection) CheckDatabase(database string) error {
+	database = c.DatabaseOrDefault(database)
+	if database == "" {
+		// PostgreSQL connects to the database named for the user by default.
+		database = string(c.Credentials[xpv1.ResourceCredentialsSecretUserKey])
+	}
+	if !c.ProviderConfig.Spec.AllowsDatabase(database) {
+		return &DatabaseNotAllowedError{Database: database}
+	}
+	return nil
+}
+
+// CheckDatabase returns a *DatabaseNotAllowedError if the ProviderConfig of
+// the supplied managed resource does not allow the supplied database, or the
+// database it connects to by default when it is empty. Unlike Resolve it
+// does not track the resource's usage of the ProviderConfig, or generate an
+// authentication token.
+func (c *Connector) CheckDatabase(ctx context.Context, mg resource.Managed, database string) error {
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: mg.GetProviderConfigReference().Name}, pc); err != nil {
+		return errors.Wrap(err, errGetPC)
+	}
+	if database == "" {
+		var err error
+		if database, err = c.defaultDatabase(ctx, pc); err != nil {
+			return err
+		}
+	}
+	if !pc.Spec.AllowsDatabase(database) {
+		return &DatabaseNotAllowedError{Database: database}
+	}
+	return nil
+}
+
+// defaultDatabase returns the database connections made using the supplied
+// ProviderConfig use by default.
+func (c *Connector) defaultDatabase(ctx context.Context, pc *v1alpha1.ProviderConfig) (string, error) {
+	// PostgreSQL connects to the database named for the user by default, and
+	// tokens authenticate a configured user.
+	switch pc.Spec.Credentials.Source {
+	case v1alpha1.CredentialsSourceAWSRDSIAMAuth:
+		if iam := pc.Spec.Credentials.AWSRDSIAMAuth; iam != nil {
+			return iam.Username, nil
+		}
+		return "", errors.New(errNoRDSIAMAuth)
+	case v1alpha1.CredentialsSourceGCPCloudSQLIAMAuth:
+		if iam := pc.Spec.Credentials.GCPCloudSQLIAMAuth; iam != nil {
+			return iam.Username, nil
+		}
+		return "", errors.New(errNoCloudSQLIAM)
+	}
+
+	ref := pc.Spec.Credentials.ConnectionSecretRef
+	if ref == nil {
+		return "", errors.New(errNoSecretRef)
+	}
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return "", errors.Wrap(err, errGetSecret)
+	}
+	if db := pc.Spec.Credentials.Keys.DefaultDatabase(s.Data); db != "" {
+		return db, nil
+	}
+	return string(pc.Spec.Credentials.Keys.Resolve(s.Data)[xpv1.ResourceCredentialsSecretUserKey]), nil
+}
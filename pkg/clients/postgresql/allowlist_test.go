@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+func TestConnectorCheckDatabase(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	mg := &v1alpha1.Database{
+		Spec: v1alpha1.DatabaseSpec{
+			ResourceSpec: xpv1.ResourceSpec{
+				ProviderConfigReference: &xpv1.Reference{Name: "cool"},
+			},
+		},
+	}
+
+	get := func(pc v1alpha1.ProviderConfigSpec, data map[string][]byte) test.MockGetFn {
+		return test.NewMockGetFn(nil, func(obj client.Object) error {
+			switch o := obj.(type) {
+			case *v1alpha1.ProviderConfig:
+				o.Spec = pc
+			case *corev1.Secret:
+				o.Data = data
+			}
+			return nil
+		})
+	}
+	secret := v1alpha1.ProviderConfigSpec{
+		Credentials:      v1alpha1.ProviderCredentials{ConnectionSecretRef: &xpv1.SecretReference{}},
+		AllowedDatabases: []string{"cool"},
+	}
+
+	cases := map[string]struct {
+		reason   string
+		get      test.MockGetFn
+		database string
+		want     error
+	}{
+		"ErrGetProviderConfig": {
+			reason: "An error should be returned if we can't get our ProviderConfig",
+			get:    test.NewMockGetFn(errBoom),
+			want:   errors.Wrap(errBoom, errGetPC),
+		},
+		"NoAllowlist": {
+			reason:   "Any database should be allowed when the ProviderConfig has no allowlist",
+			get:      get(v1alpha1.ProviderConfigSpec{}, nil),
+			database: "any",
+		},
+		"Allowed": {
+			reason:   "A database in the allowlist should be allowed",
+			get:      get(secret, nil),
+			database: "cool",
+		},
+		"NotAllowed": {
+			reason:   "A database missing from the allowlist should not be allowed",
+			get:      get(secret, nil),
+			database: "uncool",
+			want:     &DatabaseNotAllowedError{Database: "uncool"},
+		},
+		"ErrGetConnectionSecret": {
+			reason: "An error should be returned if we can't read the default database from the connection secret",
+			get: test.NewMockGetFn(nil, func(obj client.Object) error {
+				if o, ok := obj.(*v1alpha1.ProviderConfig); ok {
+					o.Spec = secret
+					return nil
+				}
+				return errBoom
+			}),
+			want: errors.Wrap(errBoom, errGetSecret),
+		},
+		"DefaultDatabaseNotAllowed": {
+			reason: "The default database configured by the credential keys should be checked when no database is supplied",
+			get: get(v1alpha1.ProviderConfigSpec{
+				Credentials: v1alpha1.ProviderCredentials{
+					ConnectionSecretRef: &xpv1.SecretReference{},
+					Keys:                &v1alpha1.CredentialKeys{Database: pointer.StringPtr("db")},
+				},
+				AllowedDatabases: []string{"cool"},
+			}, map[string][]byte{"db": []byte("uncool")}),
+			want: &DatabaseNotAllowedError{Database: "uncool"},
+		},
+		"UserDatabaseNotAllowed": {
+			reason: "The database named for the user should be checked when no default database is configured",
+			get:    get(secret, map[string][]byte{xpv1.ResourceCredentialsSecretUserKey: []byte("postgres")}),
+			want:   &DatabaseNotAllowedError{Database: "postgres"},
+		},
+		"IAMUserDatabaseNotAllowed": {
+			reason: "The database named for the IAM user should be checked when no database is supplied",
+			get: get(v1alpha1.ProviderConfigSpec{
+				Credentials: v1alpha1.ProviderCredentials{
+					Source:        v1alpha1.CredentialsSourceAWSRDSIAMAuth,
+					AWSRDSIAMAuth: &v1alpha1.AWSRDSIAMAuth{Username: "iam"},
+				},
+				AllowedDatabases: []string{"cool"},
+			}, nil),
+			want: &DatabaseNotAllowedError{Database: "iam"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := NewConnector(&test.MockClient{MockGet: tc.get}, nil)
+			err := c.CheckDatabase(context.Background(), mg, tc.database)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.CheckDatabase(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestIsDatabaseNotAllowed(t *testing.T) {
+	if !IsDatabaseNotAllowed(errors.Wrap(&DatabaseNotAllowedError{Database: "cool"}, "cannot connect")) {
+		t.Errorf("IsDatabaseNotAllowed(...): want true for a wrapped *DatabaseNotAllowedError")
+	}
+	if IsDatabaseNotAllowed(errors.New("boom")) {
+		t.Errorf("IsDatabaseNotAllowed(...): want false for an unrelated error")
+	}
+}
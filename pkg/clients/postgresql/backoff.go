@@ -0,0 +1,65 @@
+package postgresql
+
+import (
+	"database/sql/driver"
+	"io"
+	"net"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// Defaults for retrying connections that fail while a server is restarting,
+// for example during a rolling restart or failover. Each retry waits up to
+// twice as long as the last, with jitter, so that the connections of many
+// reconciles spread out rather than hitting a recovering primary at once.
+const (
+	DefaultConnectAttempts   = 4
+	DefaultConnectBackoff    = 250 * time.Millisecond
+	DefaultMaxConnectBackoff = 4 * time.Second
+)
+
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pqAdminShutdown = pq.ErrorCode("57P01")
+	pqCrashShutdown = pq.ErrorCode("57P02")
+	pqCannotConnect = pq.ErrorCode("57P03")
+)
+
+// defaultConnectBackoff returns how a connector retries connections that fail
+// for transient reasons by default.
+func defaultConnectBackoff() xsql.Backoff {
+	return xsql.Backoff{Attempts: DefaultConnectAttempts, Base: DefaultConnectBackoff, Max: DefaultMaxConnectBackoff}
+}
+
+// A connectError is returned by a connector that could not establish a
+// connection, after retrying any transient failures.
+type connectError struct{ error }
+
+func (e connectError) Unwrap() error { return e.error }
+
+// isConnectError returns true if the supplied error was returned by a
+// connector that could not establish a connection.
+func isConnectError(err error) bool {
+	var ce connectError
+	return errors.As(err, &ce)
+}
+
+// isTransientConnectError returns true if the supplied error indicates that a
+// connection failed because the server was unreachable, or was starting up or
+// shutting down, such that a later connection may succeed.
+func isTransientConnectError(err error) bool {
+	var pqe *pq.Error
+	if errors.As(err, &pqe) {
+		return IsTransient(err)
+	}
+
+	var ne net.Error
+	if errors.As(err, &ne) {
+		return true
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, driver.ErrBadConn)
+}
@@ -0,0 +1,122 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestConnectRetry(t *testing.T) {
+	errRefused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	errStarting := &pq.Error{Code: pqCannotConnect, Message: "the database system is starting up"}
+	errAuth := &pq.Error{Code: "28P01", Message: "password authentication failed"}
+
+	type want struct {
+		err    error
+		dials  int
+		sleeps int
+	}
+
+	cases := map[string]struct {
+		reason string
+		errs   []error
+		cancel bool
+		want   want
+	}{
+		"Success": {
+			reason: "A connection that succeeds should not be retried",
+			want:   want{dials: 1},
+		},
+		"RecoverAfterRestart": {
+			reason: "Connections should be retried while the server is unreachable or starting up",
+			errs:   []error{errRefused, errStarting},
+			want:   want{dials: 3, sleeps: 2},
+		},
+		"NotTransient": {
+			reason: "Connections that fail for reasons other than a restart should not be retried",
+			errs:   []error{errAuth},
+			want:   want{err: connectError{errAuth}, dials: 1},
+		},
+		"Exhausted": {
+			reason: "The last error should be returned once all attempts fail",
+			errs:   []error{errRefused, errRefused, errRefused, errStarting, errRefused},
+			want:   want{err: connectError{errStarting}, dials: DefaultConnectAttempts, sleeps: DefaultConnectAttempts - 1},
+		},
+		"ContextDone": {
+			reason: "Connections should not be retried once the context is done",
+			errs:   []error{errRefused, errRefused},
+			cancel: true,
+			want:   want{err: connectError{errRefused}, dials: 1, sleeps: 1},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dials, sleeps := 0, 0
+			b := defaultConnectBackoff()
+			b.Sleep = func(ctx context.Context, d time.Duration) error {
+				window := b.Base << uint(sleeps)
+				if d < window/2 || d > window {
+					t.Errorf("\n%s\nsleep(...): want delay in [%s, %s], got %s", tc.reason, window/2, window, d)
+				}
+				sleeps++
+				if tc.cancel {
+					return context.Canceled
+				}
+				return nil
+			}
+			c := connector{backoff: b, dial: func(_ pq.Dialer, _ string) (driver.Conn, error) {
+				dials++
+				if dials <= len(tc.errs) {
+					return nil, tc.errs[dials-1]
+				}
+				return recordingConn{execs: &[]string{}}, nil
+			}}
+
+			_, err := c.Connect(context.Background())
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.dials, dials); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want dials, +got dials:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.sleeps, sleeps); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want sleeps, +got sleeps:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestIsTransientConnectError(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		want bool
+	}{
+		"Network":        {err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, want: true},
+		"EOF":            {err: io.EOF, want: true},
+		"BadConn":        {err: driver.ErrBadConn, want: true},
+		"CannotConnect":  {err: &pq.Error{Code: pqCannotConnect}, want: true},
+		"AdminShutdown":  {err: &pq.Error{Code: pqAdminShutdown}, want: true},
+		"ConnectionFail": {err: &pq.Error{Code: "08006"}, want: true},
+		"Auth":           {err: &pq.Error{Code: "28P01"}, want: false},
+		"TooMany":        {err: &pq.Error{Code: pqTooManyConnections}, want: false},
+		"Other":          {err: errors.New("boom"), want: false},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := isTransientConnectError(tc.err); got != tc.want {
+				t.Errorf("isTransientConnectError(%v): want %t, got %t", tc.err, tc.want, got)
+			}
+		})
+	}
+}
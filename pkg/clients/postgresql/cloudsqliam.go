@@ -0,0 +1,96 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	// gcpSQLLoginScope permits logging in to Cloud SQL using IAM database
+	// authentication.
+	gcpSQLLoginScope = "https://www.googleapis.com/auth/sqlservice.login"
+
+	errFindGCPCredentials = "cannot find default GCP credentials"
+	errGetAccessToken     = "cannot get access token"
+)
+
+// An AccessTokenFn returns a short-lived OAuth access token that
+// authenticates an IAM principal to a GCP Cloud SQL server.
+type AccessTokenFn func(ctx context.Context) (string, error)
+
+// GCPAccessToken returns an OAuth access token for the service account the
+// provider runs as, found using Google's application default credentials.
+// These include the GCE metadata server, which is also how GKE Workload
+// Identity exposes a Kubernetes service account's bound Google service
+// account. Tokens are reused until shortly before they expire.
+func GCPAccessToken(ctx context.Context) (string, error) {
+	return defaultGCPTokens.Get(ctx)
+}
+
+var defaultGCPTokens = &gcpTokenSource{
+	new: func() (oauth2.TokenSource, error) {
+		// The token source makes requests using this context when it
+		// refreshes, so it must outlive any one reconcile.
+		return google.DefaultTokenSource(context.Background(), gcpSQLLoginScope)
+	},
+}
+
+// A gcpTokenSource lazily finds the default GCP credentials, and caches the
+// tokens they produce.
+type gcpTokenSource struct {
+	new func() (oauth2.TokenSource, error)
+
+	mx sync.Mutex
+	ts oauth2.TokenSource
+}
+
+// Get returns a cached access token, or a new one if the cached token has
+// expired.
+func (s *gcpTokenSource) Get(_ context.Context) (string, error) {
+	s.mx.Lock()
+	if s.ts == nil {
+		ts, err := s.new()
+		if err != nil {
+			s.mx.Unlock()
+			return "", errors.Wrap(err, errFindGCPCredentials)
+		}
+		s.ts = oauth2.ReuseTokenSource(nil, ts)
+	}
+	ts := s.ts
+	s.mx.Unlock()
+
+	t, err := ts.Token()
+	if err != nil {
+		return "", errors.Wrap(err, errGetAccessToken)
+	}
+	return t.AccessToken, nil
+}
+
+// tokenVersion returns a short digest of the supplied token, which changes
+// whenever the token does without revealing it.
+func tokenVersion(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+type tokenSourceFn func() (*oauth2.Token, error)
+
+func (fn tokenSourceFn) Token() (*oauth2.Token, error) { return fn() }
+
+func TestGCPTokenSource(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		tokens []string
+		calls  int
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		new    func(calls *int) func() (oauth2.TokenSource, error)
+		want   want
+	}{
+		"ErrFindCredentials": {
+			reason: "Errors finding the default credentials should be returned",
+			new: func(_ *int) func() (oauth2.TokenSource, error) {
+				return func() (oauth2.TokenSource, error) { return nil, errBoom }
+			},
+			want: want{tokens: []string{""}, err: errors.Wrap(errBoom, errFindGCPCredentials)},
+		},
+		"ErrGetAccessToken": {
+			reason: "Errors getting an access token should be returned",
+			new: func(_ *int) func() (oauth2.TokenSource, error) {
+				return func() (oauth2.TokenSource, error) {
+					return tokenSourceFn(func() (*oauth2.Token, error) { return nil, errBoom }), nil
+				}
+			},
+			want: want{tokens: []string{""}, err: errors.Wrap(errBoom, errGetAccessToken)},
+		},
+		"ReuseValidToken": {
+			reason: "A token should be reused until it expires",
+			new: func(calls *int) func() (oauth2.TokenSource, error) {
+				return func() (oauth2.TokenSource, error) {
+					return tokenSourceFn(func() (*oauth2.Token, error) {
+						*calls++
+						return &oauth2.Token{AccessToken: "token", Expiry: time.Now().Add(time.Hour)}, nil
+					}), nil
+				}
+			},
+			want: want{tokens: []string{"token", "token"}, calls: 1},
+		},
+		"RefreshExpiredToken": {
+			reason: "A new token should be requested once the cached token expires",
+			new: func(calls *int) func() (oauth2.TokenSource, error) {
+				return func() (oauth2.TokenSource, error) {
+					return tokenSourceFn(func() (*oauth2.Token, error) {
+						*calls++
+						return &oauth2.Token{AccessToken: "token", Expiry: time.Now().Add(-time.Minute)}, nil
+					}), nil
+				}
+			},
+			want: want{tokens: []string{"token", "token"}, calls: 2},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			s := &gcpTokenSource{new: tc.new(&calls)}
+
+			tokens := make([]string, 0, len(tc.want.tokens))
+			var err error
+			for range tc.want.tokens {
+				var token string
+				token, err = s.Get(context.Background())
+				tokens = append(tokens, token)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ns.Get(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.tokens, tokens); diff != "" {
+				t.Errorf("\n%s\ns.Get(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("\n%s\ns.Get(...): -want token requests, +got token requests:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package postgresql
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+const (
+	errTrackPCUsage  = "cannot track ProviderConfig usage"
+	errGetPC         = "cannot get ProviderConfig"
+	errNoSecretRef   = "ProviderConfig does not reference a credentials Secret"
+	errGetSecret     = "cannot get credentials Secret"
+	errGetFailover   = "cannot get failover credentials Secret"
+	errGetTLSSecret  = "cannot get TLS Secret"
+	errNoRDSIAMAuth  = "ProviderConfig does not configure AWS RDS IAM authentication"
+	errRDSAuthToken  = "cannot generate RDS IAM authentication token"
+	errNoCloudSQLIAM = "ProviderConfig does not configure GCP Cloud SQL IAM authentication"
+	errCloudSQLToken = "cannot get Cloud SQL IAM access token"
+)
+
+// A Check returns an error if a managed resource may not connect using the
+// supplied ProviderConfig.
+type Check func(pc *v1alpha1.ProviderConfig) error
+
+// A Connection is the ProviderConfig and resolved credentials a managed
+// resource uses to connect to a PostgreSQL server.
+type Connection struct {
+	ProviderConfig *v1alpha1.ProviderConfig
+	Credentials    map[string][]byte
+
+	// Failover credentials are used, in order, when the server identified by
+	// Credentials cannot be connected to.
+	Failover []map[string][]byte
+
+	// Database is the default database read from the credentials Secret, if
+	// any.
+	Database string
+
+	// TLSFiles contain the TLS material referenced by the ProviderConfig.
+	TLSFiles *TLSFiles
+
+	// Version changes whenever the spec of the ProviderConfig, or any Secret
+	// the connection was resolved from, changes.
+	Version string
+}
+
+// Options returns the connection options configured by the ProviderConfig.
+func (c *Connection) Options() []Option {
+	s := c.ProviderConfig.Spec
+	return []Option{
+		WithTCPKeepalive(s.TCPKeepalive),
+		WithConnectTimeout(s.ConnectTimeout),
+		WithStatementTimeout(s.StatementTimeout),
+		WithSessionAuthorization(s.SessionAuthorization),
+		WithAssumeRole(s.AssumeRole),
+		WithSSLMode(s.SSLMode),
+		WithTLSFiles(c.TLSFiles),
+		WithServerCertFingerprint(s.ServerCertFingerprint),
+		WithSearchPath(s.SearchPath),
+		WithTimeZone(s.TimeZone),
+		WithProviderConfig(c.ProviderConfig.GetName()),
+		WithFailover(c.Failover...),
+	}
+}
+
+// DatabaseOrDefault returns the supplied database, or the connection's default
+// database if the supplied database is empty.
+func (c *Connection) DatabaseOrDefault(database string) string {
+	if database == "" {
+		return c.Database
+	}
+	return database
+}
+
+// A Connector resolves the Connection a managed resource should use. It is
+// shared by all PostgreSQL controllers.
+type Connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	tlsDir      string
+	authToken   AuthTokenFn
+	accessToken AccessTokenFn
+}
+
+// NewConnector returns a Connector that reads ProviderConfigs and Secrets
+// using the supplied client.
+func NewConnector(kube client.Client, usage resource.Tracker) *Connector {
+	return &Connector{kube: kube, usage: usage, tlsDir: DefaultTLSDir, authToken: RDSAuthToken, accessToken: GCPAccessToken}
+}
+
+// Resolve tracks the supplied managed resource's usage of its ProviderConfig,
+// then returns the ProviderConfig and the credentials read from its Secret.
+// The supplied checks are run after the ProviderConfig is read, but before
+// the Secret is.
+func (c *Connector) Resolve(ctx context.Context, mg resource.Managed, checks ...Check) (*Connection, error) {
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	// ProviderConfigReference could theoretically be nil, but in practice the
+	// DefaultProviderConfig initializer will set it before we get here.
+	pc := &v1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: mg.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	return c.Credentials(ctx, pc, checks...)
+}
+
+// Credentials returns the credentials read from the supplied ProviderConfig's
+// Secret, and files containing any TLS material it references. The supplied
+// checks are run before the Secret is read.
+func (c *Connector) Credentials(ctx context.Context, pc *v1alpha1.ProviderConfig, checks ...Check) (*Connection, error) {
+	// The ProviderConfig schema enforces that the source is AWS RDS IAM
+	// authentication, GCP Cloud SQL IAM authentication, or a PostgreSQL
+	// connection Secret.
+	switch pc.Spec.Credentials.Source {
+	case v1alpha1.CredentialsSourceAWSRDSIAMAuth:
+		return c.rdsIAMCredentials(ctx, pc, checks...)
+	case v1alpha1.CredentialsSourceGCPCloudSQLIAMAuth:
+		return c.cloudSQLIAMCredentials(ctx, pc, checks...)
+	}
+
+	ref := pc.Spec.Credentials.ConnectionSecretRef
+	if ref == nil {
+		return nil, errors.New(errNoSecretRef)
+	}
+
+	for _, check := range checks {
+		if err := check(pc); err != nil {
+			return nil, err
+		}
+	}
+
+	nn := types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, nn, s); err != nil {
+		return nil, errors.Wrap(err, errGetSecret)
+	}
+	versions := []string{strconv.FormatInt(pc.GetGeneration(), 10), s.GetResourceVersion()}
+
+	// The Secret may store connection details under non-standard keys.
+	candidates := []map[string][]byte{pc.Spec.Credentials.Keys.Resolve(s.Data)}
+
+	for _, ref := range pc.Spec.Credentials.FailoverConnectionSecretRefs {
+		fs := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, fs); err != nil {
+			return nil, errors.Wrap(err, errGetFailover)
+		}
+		candidates = append(candidates, pc.Spec.Credentials.Keys.Resolve(fs.Data))
+		versions = append(versions, fs.GetResourceVersion())
+	}
+
+	files, tv, err := c.tlsFiles(ctx, pc.Spec.TLS)
+	if err != nil {
+		return nil, err
+	}
+	versions = append(versions, tv...)
+
+	conn := &Connection{
+		ProviderConfig: pc,
+		Credentials:    candidates[0],
+		Database:       pc.Spec.Credentials.Keys.DefaultDatabase(s.Data),
+		TLSFiles:       files,
+		Version:        strings.Join(versions, "/"),
+	}
+	if len(candidates) > 1 {
+		conn.Failover = candidates[1:]
+	}
+	return conn, nil
+}
+
+// rdsIAMCredentials returns credentials that authenticate using a new AWS RDS
+// IAM authentication token in place of a password, and files containing any
+// TLS material the supplied ProviderConfig references. The supplied checks
+// are run before the token is generated. Tokens are reused for less than
+// their lifetime, and the Connection's version changes with the token so
+// that clients cached by version are never used with an expired token.
+func (c *Connector) rdsIAMCredentials(ctx context.Context, pc *v1alpha1.ProviderConfig, checks ...Check) (*Connection, error) {
+	iam := pc.Spec.Credentials.AWSRDSIAMAuth
+	if iam == nil {
+		return nil, errors.New(errNoRDSIAMAuth)
+	}
+
+	for _, check := range checks {
+		if err := check(pc); err != nil {
+			return nil, err
+		}
+	}
+
+	port := "5432"
+	if iam.Port != nil {
+		port = strconv.Itoa(*iam.Port)
+	}
+	token, err := c.authToken(ctx, iam.Region, net.JoinHostPort(iam.Endpoint, port), iam.Username)
+	if err != nil {
+		return nil, errors.Wrap(err, errRDSAuthToken)
+	}
+	return c.tokenCredentials(ctx, pc, iam.Endpoint, port, iam.Username, token, tokenVersion(token))
+}
+
+// cloudSQLIAMCredentials returns credentials that authenticate using an OAuth
+// access token in place of a password, and files containing any TLS material
+// the supplied ProviderConfig references. The supplied checks are run before
+// the token is requested. The same token is reused until shortly before it
+// expires, so the Connection's version changes with the token to ensure
+// clients cached by version are never used with an expired token.
+func (c *Connector) cloudSQLIAMCredentials(ctx context.Context, pc *v1alpha1.ProviderConfig, checks ...Check) (*Connection, error) {
+	iam := pc.Spec.Credentials.GCPCloudSQLIAMAuth
+	if iam == nil {
+		return nil, errors.New(errNoCloudSQLIAM)
+	}
+
+	for _, check := range checks {
+		if err := check(pc); err != nil {
+			return nil, err
+		}
+	}
+
+	port := "5432"
+	if iam.Port != nil {
+		port = strconv.Itoa(*iam.Port)
+	}
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, errCloudSQLToken)
+	}
+	return c.tokenCredentials(ctx, pc, iam.Endpoint, port, iam.Username, token, tokenVersion(token))
+}
+
+// tokenCredentials returns credentials that authenticate the supplied user
+// to the supplied server using the supplied token as a password, and files
+// containing any TLS material the supplied ProviderConfig references.
+func (c *Connector) tokenCredentials(ctx context.Context, pc *v1alpha1.ProviderConfig, endpoint, port, user, token, version string) (*Connection, error) {
+	candidates := []map[string][]byte{{
+		xpv1.ResourceCredentialsSecretUserKey:     []byte(user),
+		xpv1.ResourceCredentialsSecretPasswordKey: []byte(token),
+		xpv1.ResourceCredentialsSecretEndpointKey: []byte(endpoint),
+		xpv1.ResourceCredentialsSecretPortKey:     []byte(port),
+	}}
+	versions := []string{strconv.FormatInt(pc.GetGeneration(), 10), version}
+
+	files, tv, err := c.tlsFiles(ctx, pc.Spec.TLS)
+	if err != nil {
+		return nil, err
+	}
+	versions = append(versions, tv...)
+
+	return &Connection{
+		ProviderConfig: pc,
+		Credentials:    candidates[0],
+		TLSFiles:       files,
+		Version:        strings.Join(versions, "/"),
+	}, nil
+}
+
+// tlsFiles writes the TLS material referenced by the supplied configuration
+// to files, because pq reads it only from files. It also returns the
+// versions of the Secrets the material was read from.
+func (c *Connector) tlsFiles(ctx context.Context, cfg *v1alpha1.TLSConfig) (*TLSFiles, []string, error) {
+	if cfg == nil {
+		return nil, nil, nil
+	}
+	f := &TLSFiles{}
+	versions := []string{}
+	for _, sel := range []struct {
+		ref  *xpv1.SecretKeySelector
+		path *string
+	}{
+		{ref: cfg.CASecretRef, path: &f.RootCert},
+		{ref: cfg.ClientCertSecretRef, path: &f.Cert},
+		{ref: cfg.ClientKeySecretRef, path: &f.Key},
+	} {
+		if sel.ref == nil {
+			continue
+		}
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: sel.ref.Namespace, Name: sel.ref.Name}, s); err != nil {
+			return nil, nil, errors.Wrap(err, errGetTLSSecret)
+		}
+		p, err := WriteTLSFile(c.tlsDir, s.Data[sel.ref.Key])
+		if err != nil {
+			return nil, nil, err
+		}
+		*sel.path = p
+		versions = append(versions, s.GetResourceVersion())
+	}
+	return f, versions, nil
+}
@@ -0,0 +1,544 @@
+package postgresql
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+func TestConnectorResolve(t *testing.T) {
+	errBoom := errors.New("boom")
+	errCheck := errors.New("check")
+
+	fp := "ab"
+	key := "pass"
+	noop := resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil })
+	mg := &v1alpha1.Database{
+		Spec: v1alpha1.DatabaseSpec{
+			ResourceSpec: xpv1.ResourceSpec{
+				ProviderConfigReference: &xpv1.Reference{Name: "cool"},
+			},
+		},
+	}
+
+	type fields struct {
+		kube  client.Client
+		usage resource.Tracker
+	}
+
+	type want struct {
+		conn *Connection
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		checks []Check
+		want   want
+	}{
+		"ErrTrackProviderConfigUsage": {
+			reason: "An error should be returned if we can't track our ProviderConfig usage",
+			fields: fields{
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return errBoom }),
+			},
+			want: want{err: errors.Wrap(errBoom, errTrackPCUsage)},
+		},
+		"ErrGetProviderConfig": {
+			reason: "An error should be returned if we can't get our ProviderConfig",
+			fields: fields{
+				kube:  &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+				usage: noop,
+			},
+			want: want{err: errors.Wrap(errBoom, errGetPC)},
+		},
+		"ErrMissingConnectionSecret": {
+			reason: "An error should be returned if our ProviderConfig doesn't specify a connection secret",
+			fields: fields{
+				kube:  &test.MockClient{MockGet: test.NewMockGetFn(nil)},
+				usage: noop,
+			},
+			want: want{err: errors.New(errNoSecretRef)},
+		},
+		"ErrCheck": {
+			reason: "An error returned by a check should be returned before the connection secret is read",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						switch o := obj.(type) {
+						case *v1alpha1.ProviderConfig:
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+						case *corev1.Secret:
+							t.Errorf("the connection secret should not be read if a check fails")
+						}
+						return nil
+					}),
+				},
+				usage: noop,
+			},
+			checks: []Check{
+				func(_ *v1alpha1.ProviderConfig) error { return nil },
+				func(_ *v1alpha1.ProviderConfig) error { return errCheck },
+			},
+			want: want{err: errCheck},
+		},
+		"ErrGetConnectionSecret": {
+			reason: "An error should be returned if we can't get our ProviderConfig's connection secret",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						switch o := obj.(type) {
+						case *v1alpha1.ProviderConfig:
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+						case *corev1.Secret:
+							return errBoom
+						}
+						return nil
+					}),
+				},
+				usage: noop,
+			},
+			want: want{err: errors.Wrap(errBoom, errGetSecret)},
+		},
+		"ErrGetFailoverSecret": {
+			reason: "An error should be returned if we can't get a failover connection secret",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+						if o, ok := obj.(*v1alpha1.ProviderConfig); ok {
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{Name: "primary"}
+							o.Spec.Credentials.FailoverConnectionSecretRefs = []xpv1.SecretReference{{Name: "dr"}}
+						}
+						if key.Name == "dr" {
+							return errBoom
+						}
+						return nil
+					},
+				},
+				usage: noop,
+			},
+			want: want{err: errors.Wrap(errBoom, errGetFailover)},
+		},
+		"Failover": {
+			reason: "Failover credentials should be returned in order",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+						switch o := obj.(type) {
+						case *v1alpha1.ProviderConfig:
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{Name: "primary"}
+							o.Spec.Credentials.FailoverConnectionSecretRefs = []xpv1.SecretReference{{Name: "dead"}, {Name: "dr"}, {Name: "dr2"}}
+							o.Spec.ServerCertFingerprint = &fp
+						case *corev1.Secret:
+							o.SetResourceVersion(key.Name)
+							o.Data = map[string][]byte{xpv1.ResourceCredentialsSecretEndpointKey: []byte(key.Name)}
+						}
+						return nil
+					},
+				},
+				usage: noop,
+			},
+			want: want{
+				conn: &Connection{
+					ProviderConfig: func() *v1alpha1.ProviderConfig {
+						pc := &v1alpha1.ProviderConfig{}
+						pc.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{Name: "primary"}
+						pc.Spec.Credentials.FailoverConnectionSecretRefs = []xpv1.SecretReference{{Name: "dead"}, {Name: "dr"}, {Name: "dr2"}}
+						pc.Spec.ServerCertFingerprint = &fp
+						return pc
+					}(),
+					Credentials: map[string][]byte{xpv1.ResourceCredentialsSecretEndpointKey: []byte("primary")},
+					Failover: []map[string][]byte{
+						{xpv1.ResourceCredentialsSecretEndpointKey: []byte("dead")},
+						{xpv1.ResourceCredentialsSecretEndpointKey: []byte("dr")},
+						{xpv1.ResourceCredentialsSecretEndpointKey: []byte("dr2")},
+					},
+					Version: "0/primary/dead/dr/dr2",
+				},
+			},
+		},
+		"ErrGetTLSSecret": {
+			reason: "An error should be returned if we can't get a TLS Secret",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+						if o, ok := obj.(*v1alpha1.ProviderConfig); ok {
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{Name: "creds"}
+							o.Spec.TLS = &v1alpha1.TLSConfig{CASecretRef: &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "ca"}}}
+						}
+						if key.Name == "ca" {
+							return errBoom
+						}
+						return nil
+					},
+				},
+				usage: noop,
+			},
+			want: want{err: errors.Wrap(errBoom, errGetTLSSecret)},
+		},
+		"Success": {
+			reason: "The ProviderConfig and credentials resolved using its keys should be returned",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						switch o := obj.(type) {
+						case *v1alpha1.ProviderConfig:
+							o.SetName("cool")
+							o.SetGeneration(2)
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{Namespace: "ns", Name: "creds"}
+							o.Spec.Credentials.Keys = &v1alpha1.CredentialKeys{Password: &key}
+							o.Spec.ServerCertFingerprint = &fp
+						case *corev1.Secret:
+							o.SetResourceVersion("3")
+							o.Data = map[string][]byte{key: []byte("secret")}
+						}
+						return nil
+					}),
+				},
+				usage: noop,
+			},
+			want: want{
+				conn: &Connection{
+					ProviderConfig: func() *v1alpha1.ProviderConfig {
+						pc := &v1alpha1.ProviderConfig{}
+						pc.SetName("cool")
+						pc.SetGeneration(2)
+						pc.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{Namespace: "ns", Name: "creds"}
+						pc.Spec.Credentials.Keys = &v1alpha1.CredentialKeys{Password: &key}
+						pc.Spec.ServerCertFingerprint = &fp
+						return pc
+					}(),
+					Credentials: map[string][]byte{
+						key: []byte("secret"),
+						xpv1.ResourceCredentialsSecretPasswordKey: []byte("secret"),
+					},
+					Version: "2/3",
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := NewConnector(tc.fields.kube, tc.fields.usage)
+			got, err := c.Resolve(context.Background(), mg, tc.checks...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.Resolve(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.conn, got); diff != "" {
+				t.Errorf("\n%s\nc.Resolve(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConnectorTLSFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	sel := func(name, key string) *xpv1.SecretKeySelector {
+		return &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Namespace: "ns", Name: name}, Key: key}
+	}
+	pc := &v1alpha1.ProviderConfig{}
+	pc.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{Namespace: "ns", Name: "creds"}
+	pc.Spec.TLS = &v1alpha1.TLSConfig{
+		CASecretRef:         sel("ca", "ca.crt"),
+		ClientCertSecretRef: sel("client", "tls.crt"),
+		ClientKeySecretRef:  sel("client", "tls.key"),
+	}
+
+	kube := &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			s := obj.(*corev1.Secret)
+			switch key.Name {
+			case "ca":
+				s.Data = map[string][]byte{"ca.crt": []byte("ca")}
+			case "client":
+				s.Data = map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")}
+			}
+			return nil
+		},
+	}
+
+	c := NewConnector(kube, nil)
+	c.tlsDir = dir
+	conn, err := c.Credentials(context.Background(), pc)
+	if err != nil {
+		t.Fatalf("c.Credentials(...): %v", err)
+	}
+
+	got := map[string]string{}
+	for name, path := range map[string]string{"ca": conn.TLSFiles.RootCert, "cert": conn.TLSFiles.Cert, "key": conn.TLSFiles.Key} {
+		if filepath.Dir(path) != dir {
+			t.Errorf("c.Credentials(...): want %s file in %q, got %q", name, dir, path)
+		}
+		b, err := ioutil.ReadFile(path) //nolint:gosec
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[name] = string(b)
+	}
+	want := map[string]string{"ca": "ca", "cert": "cert", "key": "key"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("c.Credentials(...): -want file content, +got file content:\n%s\n", diff)
+	}
+}
+
+func TestConnectorRDSIAMCredentials(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	iam := func() *v1alpha1.ProviderConfig {
+		pc := &v1alpha1.ProviderConfig{}
+		pc.Spec.Credentials.Source = v1alpha1.CredentialsSourceAWSRDSIAMAuth
+		pc.Spec.Credentials.AWSRDSIAMAuth = &v1alpha1.AWSRDSIAMAuth{Region: "us-east-1", Endpoint: "db.example.org", Username: "iam_user"}
+		return pc
+	}
+
+	type want struct {
+		creds map[string][]byte
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		pc        *v1alpha1.ProviderConfig
+		authToken AuthTokenFn
+		want      want
+	}{
+		"ErrNoRDSIAMAuth": {
+			reason: "An error should be returned if the source is AWSRDSIAMAuth but no configuration is supplied",
+			pc: func() *v1alpha1.ProviderConfig {
+				pc := iam()
+				pc.Spec.Credentials.AWSRDSIAMAuth = nil
+				return pc
+			}(),
+			want: want{err: errors.New(errNoRDSIAMAuth)},
+		},
+		"ErrAuthToken": {
+			reason: "Errors generating an authentication token should be returned",
+			pc:     iam(),
+			authToken: func(_ context.Context, _, _, _ string) (string, error) {
+				return "", errBoom
+			},
+			want: want{err: errors.Wrap(errBoom, errRDSAuthToken)},
+		},
+		"Success": {
+			reason: "A new authentication token should be generated for the configured server and user, and used as the password",
+			pc:     iam(),
+			authToken: func(_ context.Context, region, endpoint, user string) (string, error) {
+				if region != "us-east-1" || endpoint != "db.example.org:5432" || user != "iam_user" {
+					return "", errors.Errorf("unexpected token request for %s@%s in %s", user, endpoint, region)
+				}
+				return "token", nil
+			},
+			want: want{creds: map[string][]byte{
+				xpv1.ResourceCredentialsSecretUserKey:     []byte("iam_user"),
+				xpv1.ResourceCredentialsSecretPasswordKey: []byte("token"),
+				xpv1.ResourceCredentialsSecretEndpointKey: []byte("db.example.org"),
+				xpv1.ResourceCredentialsSecretPortKey:     []byte("5432"),
+			}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// No Secret should be read.
+			kube := &test.MockClient{MockGet: test.NewMockGetFn(errBoom)}
+			c := NewConnector(kube, nil)
+			c.authToken = tc.authToken
+
+			conn, err := c.Credentials(context.Background(), tc.pc)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.Credentials(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			var creds map[string][]byte
+			if conn != nil {
+				creds = conn.Credentials
+			}
+			if diff := cmp.Diff(tc.want.creds, creds); diff != "" {
+				t.Errorf("\n%s\nc.Credentials(...): -want credentials, +got credentials:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConnectorCloudSQLIAMCredentials(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	iam := func() *v1alpha1.ProviderConfig {
+		pc := &v1alpha1.ProviderConfig{}
+		pc.Spec.Credentials.Source = v1alpha1.CredentialsSourceGCPCloudSQLIAMAuth
+		pc.Spec.Credentials.GCPCloudSQLIAMAuth = &v1alpha1.GCPCloudSQLIAMAuth{Endpoint: "10.0.0.1", Username: "sa@project.iam"}
+		return pc
+	}
+
+	type want struct {
+		creds map[string][]byte
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason      string
+		pc          *v1alpha1.ProviderConfig
+		accessToken AccessTokenFn
+		want        want
+	}{
+		"ErrNoCloudSQLIAM": {
+			reason: "An error should be returned if the source is GCPCloudSQLIAMAuth but no configuration is supplied",
+			pc: func() *v1alpha1.ProviderConfig {
+				pc := iam()
+				pc.Spec.Credentials.GCPCloudSQLIAMAuth = nil
+				return pc
+			}(),
+			want: want{err: errors.New(errNoCloudSQLIAM)},
+		},
+		"ErrAccessToken": {
+			reason: "Errors getting an access token should be returned",
+			pc:     iam(),
+			accessToken: func(_ context.Context) (string, error) {
+				return "", errBoom
+			},
+			want: want{err: errors.Wrap(errBoom, errCloudSQLToken)},
+		},
+		"Success": {
+			reason: "An access token should be used as the password of the configured user",
+			pc:     iam(),
+			accessToken: func(_ context.Context) (string, error) {
+				return "token", nil
+			},
+			want: want{creds: map[string][]byte{
+				xpv1.ResourceCredentialsSecretUserKey:     []byte("sa@project.iam"),
+				xpv1.ResourceCredentialsSecretPasswordKey: []byte("token"),
+				xpv1.ResourceCredentialsSecretEndpointKey: []byte("10.0.0.1"),
+				xpv1.ResourceCredentialsSecretPortKey:     []byte("5432"),
+			}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// No Secret should be read.
+			kube := &test.MockClient{MockGet: test.NewMockGetFn(errBoom)}
+			c := NewConnector(kube, nil)
+			c.accessToken = tc.accessToken
+
+			conn, err := c.Credentials(context.Background(), tc.pc)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.Credentials(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			var creds map[string][]byte
+			if conn != nil {
+				creds = conn.Credentials
+			}
+			if diff := cmp.Diff(tc.want.creds, creds); diff != "" {
+				t.Errorf("\n%s\nc.Credentials(...): -want credentials, +got credentials:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConnectorCloudSQLIAMVersion(t *testing.T) {
+	pc := &v1alpha1.ProviderConfig{}
+	pc.Spec.Credentials.Source = v1alpha1.CredentialsSourceGCPCloudSQLIAMAuth
+	pc.Spec.Credentials.GCPCloudSQLIAMAuth = &v1alpha1.GCPCloudSQLIAMAuth{Endpoint: "10.0.0.1", Username: "sa@project.iam"}
+
+	token := "a"
+	c := NewConnector(&test.MockClient{}, nil)
+	c.accessToken = func(_ context.Context) (string, error) { return token, nil }
+
+	version := func() string {
+		conn, err := c.Credentials(context.Background(), pc)
+		if err != nil {
+			t.Fatalf("c.Credentials(...): unexpected error: %s", err)
+		}
+		return conn.Version
+	}
+
+	first := version()
+	if again := version(); again != first {
+		t.Errorf("c.Credentials(...): version changed from %q to %q while the access token did not", first, again)
+	}
+	token = "b"
+	if rotated := version(); rotated == first {
+		t.Errorf("c.Credentials(...): version %q did not change when the access token did", rotated)
+	}
+}
+
+func TestConnectionOptions(t *testing.T) {
+	role := "owner"
+	assume := "app"
+	tz := "UTC"
+	mode := "verify-full"
+	files := &TLSFiles{RootCert: "/tls/ca.pem"}
+	c := &Connection{TLSFiles: files, ProviderConfig: &v1alpha1.ProviderConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "cool"},
+		Spec: v1alpha1.ProviderConfigSpec{
+			SessionAuthorization: &role,
+			AssumeRole:           &assume,
+			SearchPath:           []string{"app"},
+			TimeZone:             &tz,
+			SSLMode:              &mode,
+			ConnectTimeout:       &metav1.Duration{Duration: 5 * time.Second},
+			StatementTimeout:     &metav1.Duration{Duration: time.Minute},
+		},
+	}}
+
+	c.Failover = []map[string][]byte{{"endpoint": []byte("dr")}}
+
+	o := &options{}
+	for _, fn := range c.Options() {
+		fn(o)
+	}
+
+	if diff := cmp.Diff(DefaultTCPKeepalive, o.keepalive); diff != "" {
+		t.Errorf("c.Options(): keepalive: -want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(5*time.Second, o.connectTimeout); diff != "" {
+		t.Errorf("c.Options(): connect timeout: -want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(time.Minute, o.stmtTimeout); diff != "" {
+		t.Errorf("c.Options(): statement timeout: -want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(mode, o.sslMode); diff != "" {
+		t.Errorf("c.Options(): sslmode: -want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(*files, o.tls); diff != "" {
+		t.Errorf("c.Options(): TLS files: -want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(role, o.role); diff != "" {
+		t.Errorf("c.Options(): session authorization: -want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(assume, o.assumeRole); diff != "" {
+		t.Errorf("c.Options(): assume role: -want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff([]string{"app"}, o.search); diff != "" {
+		t.Errorf("c.Options(): search path: -want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(tz, o.timeZone); diff != "" {
+		t.Errorf("c.Options(): time zone: -want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff("cool", o.providerConfig); diff != "" {
+		t.Errorf("c.Options(): provider config: -want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(c.Failover, o.failover); diff != "" {
+		t.Errorf("c.Options(): failover: -want, +got:\n%s\n", diff)
+	}
+}
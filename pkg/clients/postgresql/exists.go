@@ -0,0 +1,65 @@
+package postgresql
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+const errCheckExists = "cannot check whether object exists"
+
+// An ObjectKind is a kind of object whose existence may be checked. Each kind
+// is the name of the PostgreSQL function that resolves a textual name of that
+// kind to an OID. Unlike a cast to regclass et al, these functions return NULL
+// rather than raising an error when the named object does not exist.
+type ObjectKind string
+
+// Kinds of object whose existence may be checked.
+const (
+	// A Relation is a table, view, sequence, or index.
+	Relation ObjectKind = "to_regclass"
+
+	// A Function is a function or procedure named without its argument
+	// types. It is considered absent if its name is overloaded.
+	Function ObjectKind = "to_regproc"
+
+	// A FunctionSignature is a function or procedure named with its argument
+	// types, e.g. audit.log_change(text).
+	FunctionSignature ObjectKind = "to_regprocedure"
+
+	// A Type is a data type.
+	Type ObjectKind = "to_regtype"
+
+	// A Schema is a namespace.
+	Schema ObjectKind = "to_regnamespace"
+)
+
+// QualifiedName returns the quoted, optionally schema qualified, name of an
+// object. The object is resolved using the search_path when no schema is
+// supplied.
+func QualifiedName(schema *string, name string) string {
+	if schema == nil {
+		return DefaultQuoter.QuoteIdentifier(name)
+	}
+	return DefaultQuoter.QuoteIdentifier(*schema) + "." + DefaultQuoter.QuoteIdentifier(name)
+}
+
+// ExistsQuery returns a query that scans true if an object of the supplied
+// kind and (quoted) name exists. The name is passed as a parameter, so it
+// need not be escaped.
+func ExistsQuery(k ObjectKind, name string) xsql.Query {
+	return xsql.Query{
+		String:     "SELECT " + string(k) + "($1) IS NOT NULL",
+		Parameters: []interface{}{name},
+	}
+}
+
+// Exists returns true if an object of the supplied kind and (quoted) name
+// exists in the database the supplied DB is connected to.
+func Exists(ctx context.Context, db xsql.DB, k ObjectKind, name string) (bool, error) {
+	exists := false
+	err := db.Scan(ctx, ExistsQuery(k, name), &exists)
+	return exists, errors.Wrap(err, errCheckExists)
+}
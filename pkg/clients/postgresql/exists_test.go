@@ -0,0 +1,183 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// A scanDB is an xsql.DB that only supports Scan.
+type scanDB struct {
+	xsql.DB
+	scan func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+}
+
+func (d scanDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return d.scan(ctx, q, dest...)
+}
+
+func TestQualifiedName(t *testing.T) {
+	schema := "audit"
+	quoted := `my"schema`
+
+	cases := map[string]struct {
+		reason string
+		schema *string
+		name   string
+		want   string
+	}{
+		"Unqualified": {
+			reason: "An unqualified name should be quoted, and resolved using the search_path",
+			name:   "changes",
+			want:   `"changes"`,
+		},
+		"Qualified": {
+			reason: "Each part of a qualified name should be quoted",
+			schema: &schema,
+			name:   "changes",
+			want:   `"audit"."changes"`,
+		},
+		"QualifiedWithQuotes": {
+			reason: "Double quotes in either part of a qualified name should be doubled",
+			schema: &quoted,
+			name:   `a.b`,
+			want:   `"my""schema"."a.b"`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := QualifiedName(tc.schema, tc.name)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nQualifiedName(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestExists(t *testing.T) {
+	errBoom := errors.New("boom")
+	schema := "audit"
+
+	type args struct {
+		kind ObjectKind
+		name string
+		err  error
+		oid  bool
+	}
+
+	type want struct {
+		query  xsql.Query
+		exists bool
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"UnqualifiedRelation": {
+			reason: "An unqualified relation should be checked using to_regclass",
+			args: args{
+				kind: Relation,
+				name: QualifiedName(nil, "changes"),
+				oid:  true,
+			},
+			want: want{
+				query: xsql.Query{
+					String:     "SELECT to_regclass($1) IS NOT NULL",
+					Parameters: []interface{}{`"changes"`},
+				},
+				exists: true,
+			},
+		},
+		"QualifiedRelation": {
+			reason: "A qualified relation should be passed to to_regclass with its schema",
+			args: args{
+				kind: Relation,
+				name: QualifiedName(&schema, "changes"),
+				oid:  true,
+			},
+			want: want{
+				query: xsql.Query{
+					String:     "SELECT to_regclass($1) IS NOT NULL",
+					Parameters: []interface{}{`"audit"."changes"`},
+				},
+				exists: true,
+			},
+		},
+		"QualifiedFunctionSignature": {
+			reason: "A function signature should be checked using to_regprocedure",
+			args: args{
+				kind: FunctionSignature,
+				name: QualifiedName(&schema, "log_change") + "(text)",
+			},
+			want: want{
+				query: xsql.Query{
+					String:     "SELECT to_regprocedure($1) IS NOT NULL",
+					Parameters: []interface{}{`"audit"."log_change"(text)`},
+				},
+				exists: false,
+			},
+		},
+		"UnqualifiedFunction": {
+			reason: "An unqualified function should be checked using to_regproc",
+			args: args{
+				kind: Function,
+				name: QualifiedName(nil, "log_change"),
+			},
+			want: want{
+				query: xsql.Query{
+					String:     "SELECT to_regproc($1) IS NOT NULL",
+					Parameters: []interface{}{`"log_change"`},
+				},
+				exists: false,
+			},
+		},
+		"ErrScan": {
+			reason: "Errors checking whether an object exists should be returned",
+			args: args{
+				kind: Schema,
+				name: QualifiedName(nil, "audit"),
+				err:  errBoom,
+			},
+			want: want{
+				query: xsql.Query{
+					String:     "SELECT to_regnamespace($1) IS NOT NULL",
+					Parameters: []interface{}{`"audit"`},
+				},
+				err: errors.Wrap(errBoom, errCheckExists),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			db := scanDB{scan: func(_ context.Context, q xsql.Query, dest ...interface{}) error {
+				if diff := cmp.Diff(tc.want.query, q); diff != "" {
+					t.Errorf("\n%s\nExists(...): -want query, +got query:\n%s\n", tc.reason, diff)
+				}
+				if tc.args.err != nil {
+					return tc.args.err
+				}
+				*dest[0].(*bool) = tc.args.oid
+				return nil
+			}}
+
+			got, err := Exists(context.Background(), db, tc.args.kind, tc.args.name)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nExists(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.exists, got); diff != "" {
+				t.Errorf("\n%s\nExists(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package postgresql
+
+import (
+	"net/url"
+	"sync"
+)
+
+// preferred records the server each ProviderConfig last connected to, so that
+// connections keep using a server that was failed over to rather than first
+// waiting for an unreachable one to time out.
+var preferred = &serverCache{servers: map[string]string{}}
+
+// A serverCache records the server (host:port) that last connected for each
+// ProviderConfig. Servers are recorded rather than DSNs because a
+// ProviderConfig's DSNs differ by database, while its servers don't.
+type serverCache struct {
+	mu      sync.RWMutex
+	servers map[string]string
+}
+
+// order returns the supplied DSNs with those for the server that last
+// connected for the supplied ProviderConfig first. The DSNs are otherwise
+// returned in order.
+func (c *serverCache) order(providerConfig string, dsns []string) []string {
+	c.mu.RLock()
+	last, ok := c.servers[providerConfig]
+	c.mu.RUnlock()
+
+	if !ok {
+		return dsns
+	}
+
+	first := make([]string, 0, len(dsns))
+	rest := make([]string, 0, len(dsns))
+	for _, dsn := range dsns {
+		if server(dsn) == last {
+			first = append(first, dsn)
+			continue
+		}
+		rest = append(rest, dsn)
+	}
+	return append(first, rest...)
+}
+
+// set records that the server of the supplied DSN connected for the supplied
+// ProviderConfig.
+func (c *serverCache) set(providerConfig, dsn string) {
+	c.mu.Lock()
+	c.servers[providerConfig] = server(dsn)
+	c.mu.Unlock()
+}
+
+// server returns the host:port of the supplied DSN, or the DSN itself if it
+// can't be parsed.
+func server(dsn string) string {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return dsn
+	}
+	return u.Host
+}
@@ -0,0 +1,159 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestConnectFailover(t *testing.T) {
+	errDead := &pq.Error{Code: "28P01", Message: "password authentication failed"}
+	errDR := errors.New("dr is down too")
+
+	primary := map[string][]byte{
+		xpv1.ResourceCredentialsSecretUserKey:     []byte("primary"),
+		xpv1.ResourceCredentialsSecretEndpointKey: []byte("primary.example.org"),
+		xpv1.ResourceCredentialsSecretPortKey:     []byte("5432"),
+	}
+	dr := map[string][]byte{
+		xpv1.ResourceCredentialsSecretUserKey:     []byte("dr"),
+		xpv1.ResourceCredentialsSecretEndpointKey: []byte("dr.example.org"),
+		xpv1.ResourceCredentialsSecretPortKey:     []byte("5432"),
+	}
+	primaryDSN := dsn(primary, "db", &options{})
+	drDSN := dsn(dr, "db", &options{})
+	drOtherDSN := dsn(dr, "other", &options{})
+
+	type want struct {
+		database string
+		err      error
+		dials    []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		failed map[string]error
+		want   []want
+	}{
+		"NoFailover": {
+			reason: "Only the primary server should be dialed while it connects",
+			want: []want{
+				{dials: []string{primaryDSN}},
+				{dials: []string{primaryDSN}},
+			},
+		},
+		"FailoverToSecond": {
+			reason: "A dead first server should fail over to the second, which should then be dialed first",
+			failed: map[string]error{primaryDSN: errDead},
+			want: []want{
+				{dials: []string{primaryDSN, drDSN}},
+				{dials: []string{drDSN}},
+			},
+		},
+		"FailoverAcrossDatabases": {
+			reason: "A server that was failed over to should be dialed first for any database",
+			failed: map[string]error{primaryDSN: errDead},
+			want: []want{
+				{dials: []string{primaryDSN, drDSN}},
+				{database: "other", dials: []string{drOtherDSN}},
+			},
+		},
+		"AllDead": {
+			reason: "The error dialing the first server should be returned if no server connects",
+			failed: map[string]error{primaryDSN: errDead, drDSN: errDR},
+			want: []want{
+				{err: connectError{errDead}, dials: []string{primaryDSN, drDSN}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			for i, w := range tc.want {
+				database := "db"
+				if w.database != "" {
+					database = w.database
+				}
+				c := New(primary, database, WithProviderConfig("failover-"+name), WithFailover(dr)).(postgresDB)
+				dials := []string{}
+				cn := connector{
+					dsn:            c.dsn,
+					failover:       c.failover,
+					providerConfig: c.providerConfig,
+					dial: func(_ pq.Dialer, dsn string) (driver.Conn, error) {
+						dials = append(dials, dsn)
+						if err := tc.failed[dsn]; err != nil {
+							return nil, err
+						}
+						return recordingConn{execs: &[]string{}}, nil
+					},
+				}
+
+				_, err := cn.Connect(context.Background())
+				if diff := cmp.Diff(w.err, err, test.EquateErrors()); diff != "" {
+					t.Errorf("\n%s\nConnect #%d: -want error, +got error:\n%s\n", tc.reason, i, diff)
+				}
+				if diff := cmp.Diff(w.dials, dials); diff != "" {
+					t.Errorf("\n%s\nConnect #%d: -want dials, +got dials:\n%s\n", tc.reason, i, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestServerCacheOrder(t *testing.T) {
+	a := "postgres://u:p@a:5432/db"
+	b := "postgres://u:p@b:5432/db"
+	c := "postgres://u:p@c:5432/db"
+
+	cases := map[string]struct {
+		reason string
+		dsns   map[string]string
+		in     []string
+		want   []string
+	}{
+		"Unknown": {
+			reason: "DSNs should be returned in order if none has connected",
+			in:     []string{a, b, c},
+			want:   []string{a, b, c},
+		},
+		"Preferred": {
+			reason: "The DSN for the server that last connected should be first",
+			dsns:   map[string]string{"pc": c},
+			in:     []string{a, b, c},
+			want:   []string{c, a, b},
+		},
+		"PreferredOtherDatabase": {
+			reason: "The DSN for the server that last connected should be first, whichever database it connected to",
+			dsns:   map[string]string{"pc": "postgres://u:p@c:5432/other"},
+			in:     []string{a, b, c},
+			want:   []string{c, a, b},
+		},
+		"Removed": {
+			reason: "DSNs should be returned in order if the server that last connected is no longer supplied",
+			dsns:   map[string]string{"pc": "postgres://u:p@d:5432/db"},
+			in:     []string{a, b, c},
+			want:   []string{a, b, c},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			sc := &serverCache{servers: map[string]string{}}
+			for pc, dsn := range tc.dsns {
+				sc.set(pc, dsn)
+			}
+			got := sc.order("pc", tc.in)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nsc.order(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package postgresql
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// connectDuration records how long it takes to establish a connection,
+// including any retries. A slow connection usually points to DNS, a TLS
+// handshake, or a proxy between the provider and the server.
+var connectDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "provider_sql_postgresql_connect_duration_seconds",
+	Help:    "Time taken to establish a connection to a PostgreSQL server, including retries.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"provider_config"})
+
+func init() {
+	metrics.Registry.MustRegister(connectDuration)
+}
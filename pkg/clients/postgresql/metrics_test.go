@@ -0,0 +1,81 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// connects returns the number of connections observed for the supplied
+// ProviderConfig.
+func connects(t *testing.T, pc string) uint64 {
+	t.Helper()
+	m := &dto.Metric{}
+	if err := connectDuration.WithLabelValues(pc).(prometheus.Histogram).Write(m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestConnectDuration(t *testing.T) {
+	errRefused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	errAuth := &pq.Error{Code: "28P01", Message: "password authentication failed"}
+
+	cases := map[string]struct {
+		reason string
+		pc     string
+		errs   []error
+	}{
+		"Success": {
+			reason: "The time taken to connect should be observed",
+			pc:     "connect-success",
+		},
+		"Retried": {
+			reason: "A connection that was retried should be observed once",
+			pc:     "connect-retried",
+			errs:   []error{errRefused},
+		},
+		"Failed": {
+			reason: "The time taken to fail to connect should be observed",
+			pc:     "connect-failed",
+			errs:   []error{errAuth},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := defaultConnectBackoff()
+			b.Sleep = func(_ context.Context, _ time.Duration) error { return nil }
+
+			dials := 0
+			c := connector{backoff: b, providerConfig: tc.pc, dial: func(_ pq.Dialer, _ string) (driver.Conn, error) {
+				dials++
+				if dials <= len(tc.errs) {
+					return nil, tc.errs[dials-1]
+				}
+				return recordingConn{execs: &[]string{}}, nil
+			}}
+
+			before := connects(t, tc.pc)
+			_, _ = c.Connect(context.Background())
+			if diff := cmp.Diff(before+1, connects(t, tc.pc)); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want observations, +got observations:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithProviderConfig(t *testing.T) {
+	db := New(nil, "", WithProviderConfig("cool")).(postgresDB)
+	if diff := cmp.Diff("cool", db.providerConfig); diff != "" {
+		t.Errorf("New(...): -want provider config, +got provider config:\n%s\n", diff)
+	}
+}
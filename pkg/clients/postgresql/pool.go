@@ -0,0 +1,51 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"database/sql"
+	"time"
+)
+
+// PoolConnMaxLifetime is how long a pooled client reuses a connection before
+// closing it, so that connections don't stay open indefinitely.
+const PoolConnMaxLifetime = 5 * time.Minute
+
+// A PooledDB is a PostgreSQL database client that keeps its connections open
+// between operations, so that they may be reused. Unlike a client returned by
+// New, successive operations may share a session. Each session is configured
+// when its connection is opened, not before each operation, so changes to the
+// defaults of the role used to connect take effect only once the connection is
+// replaced, within PoolConnMaxLifetime. A PooledDB must be closed when it is
+// no longer needed.
+type PooledDB struct {
+	postgresDB
+}
+
+// NewPooled returns a new pooled PostgreSQL database client. Its database name
+// and options are interpreted as they are by New.
+func NewPooled(creds map[string][]byte, database string, o ...Option) *PooledDB {
+	c := New(creds, database, o...).(postgresDB)
+	c.pool = sql.OpenDB(c.connector())
+	c.pool.SetConnMaxLifetime(PoolConnMaxLifetime)
+	return &PooledDB{postgresDB: c}
+}
+
+// Close the client's connections.
+func (c *PooledDB) Close() error {
+	return c.pool.Close()
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestPooledDB(t *testing.T) {
+	dials := 0
+	execs := []string{}
+
+	c := New(nil, "db", WithSessionAuthorization(pointer.StringPtr("auditor"))).(postgresDB)
+	c.dial = func(_ pq.Dialer, _ string) (driver.Conn, error) {
+		dials++
+		return recordingConn{execs: &execs}, nil
+	}
+	c.pool = sql.OpenDB(c.connector())
+	db := &PooledDB{postgresDB: c}
+
+	for _, q := range []string{"CREATE DATABASE a", "CREATE DATABASE b"} {
+		if err := db.Exec(context.Background(), xsql.Query{String: q}); err != nil {
+			t.Fatalf("db.Exec(...): %v", err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("db.Close(): %v", err)
+	}
+
+	if dials != 1 {
+		t.Errorf("db.Exec(...): want operations to reuse 1 connection, got %d connections", dials)
+	}
+	want := []string{`SET SESSION AUTHORIZATION "auditor"`, "CREATE DATABASE a", "CREATE DATABASE b"}
+	if diff := cmp.Diff(want, execs); diff != "" {
+		t.Errorf("db.Exec(...): -want statements, +got statements:\n%s\n", diff)
+	}
+}
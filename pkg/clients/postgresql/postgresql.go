@@ -3,62 +3,594 @@ package postgresql
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
 	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 )
 
+// DefaultTCPKeepalive is the default interval between TCP keepalive probes.
+// It's short enough to keep connections alive through most NAT gateways and
+// firewalls, which commonly drop connections after a few minutes idle.
+const DefaultTCPKeepalive = 30 * time.Second
+
 const (
 	// https://www.postgresql.org/docs/current/errcodes-appendix.html
 	// These are not available as part of the pq library.
-	pqInvalidCatalog = pq.ErrorCode("3D000")
+	pqInvalidCatalog     = pq.ErrorCode("3D000")
+	pqTooManyConnections = pq.ErrorCode("53300")
+	pqInsufficientPriv   = pq.ErrorCode("42501")
+	pqUndefinedObject    = pq.ErrorCode("42704")
+	pqUndefinedColumn    = pq.ErrorCode("42703")
+)
+
+const (
+	errSetSessionAuthorization = "cannot set session authorization"
+	errSetRole                 = "cannot set role"
+	errSetSearchPath           = "cannot set search_path"
+	errSetTimeZone             = "cannot set time zone"
+	errSetStatementTimeout     = "cannot set statement timeout"
+	errSetReadOnly             = "cannot set read only session characteristics"
 )
 
+// readOnlyQuery sets the default characteristics of the transactions run in a
+// session, including the implicit transaction of each single statement.
+const readOnlyQuery = "SET SESSION CHARACTERISTICS AS TRANSACTION READ ONLY, DEFERRABLE"
+
 type postgresDB struct {
-	dsn      string
-	endpoint string
-	port     string
+	dsn         string
+	failover    []string
+	endpoint    string
+	port        string
+	dialer      dialer
+	role        string
+	assumeRole  string
+	search      []string
+	timeZone    string
+	stmtTimeout time.Duration
+	readOnly    bool
+	backoff     xsql.Backoff
+
+	// providerConfig labels the connection metrics.
+	providerConfig string
+
+	// dial is passed to each connector. It is only overridden in tests.
+	dial func(d pq.Dialer, dsn string) (driver.Conn, error)
+
+	// pool is shared by all operations of a pooled client. Each operation
+	// of other clients opens its own connection.
+	pool *sql.DB
+}
+
+type options struct {
+	params         map[string]string
+	sslMode        string
+	tls            TLSFiles
+	fingerprint    string
+	keepalive      time.Duration
+	connectTimeout time.Duration
+	stmtTimeout    time.Duration
+	role           string
+	assumeRole     string
+	search         []string
+	timeZone       string
+	readOnly       bool
+	providerConfig string
+	failover       []map[string][]byte
+}
+
+// An Option configures a PostgreSQL database client.
+type Option func(o *options)
+
+// WithRuntimeParameters sets the supplied run-time parameters (GUCs) for every
+// session the client opens, using the connection's 'options' parameter.
+func WithRuntimeParameters(p map[string]string) Option {
+	return func(o *options) {
+		o.params = p
+	}
+}
+
+// WithSSLMode sets the sslmode of each connection the client opens, i.e.
+// disable, require, verify-ca, or verify-full. The pq library's default,
+// require, is used when the supplied mode is nil.
+func WithSSLMode(mode *string) Option {
+	return func(o *options) {
+		if mode != nil {
+			o.sslMode = *mode
+		}
+	}
+}
+
+// WithTLSFiles sets the files containing the CA certificates used to verify
+// the server's certificate, and the client certificate and key presented to
+// the server, by each connection the client opens. No files are used when the
+// supplied files are nil.
+func WithTLSFiles(f *TLSFiles) Option {
+	return func(o *options) {
+		if f != nil {
+			o.tls = *f
+		}
+	}
+}
+
+// WithServerCertFingerprint pins the certificate the server must present to
+// each connection the client opens, identified by its SHA-256 fingerprint.
+// The provider negotiates TLS on these connections itself, rather than pq, so
+// that it can verify the certificate. Connections always use TLS when a
+// certificate is pinned. Nothing is pinned when the supplied fingerprint is
+// nil.
+func WithServerCertFingerprint(fp *string) Option {
+	return func(o *options) {
+		if fp != nil {
+			o.fingerprint = *fp
+		}
+	}
+}
+
+// WithTCPKeepalive sets the interval between TCP keepalive probes sent on each
+// connection the client opens. DefaultTCPKeepalive is used when the supplied
+// interval is nil. A zero interval disables keepalive probes.
+func WithTCPKeepalive(d *metav1.Duration) Option {
+	return func(o *options) {
+		switch {
+		case d == nil:
+			o.keepalive = DefaultTCPKeepalive
+		case d.Duration == 0:
+			// A negative KeepAlive disables keepalive probes.
+			o.keepalive = -1
+		default:
+			o.keepalive = d.Duration
+		}
+	}
+}
+
+// WithConnectTimeout bounds how long each connection the client opens waits
+// for the server, using the connect_timeout parameter. The timeout is rounded
+// up to whole seconds. No timeout is used when the supplied timeout is nil.
+func WithConnectTimeout(d *metav1.Duration) Option {
+	return func(o *options) {
+		if d != nil {
+			o.connectTimeout = d.Duration
+		}
+	}
+}
+
+// WithStatementTimeout causes every session the client opens to set the
+// supplied statement timeout before running any other statement, after
+// setting any time zone supplied by WithTimeZone. The server's default
+// statement timeout is used when the supplied timeout is nil.
+func WithStatementTimeout(d *metav1.Duration) Option {
+	return func(o *options) {
+		if d != nil {
+			o.stmtTimeout = d.Duration
+		}
+	}
+}
+
+// WithSessionAuthorization causes every session the client opens to assume
+// the supplied role using SET SESSION AUTHORIZATION before running any other
+// statement, such that objects the client creates are owned by, and audit
+// trails attribute changes to, that role. Unlike SET ROLE, the session then
+// has only the privileges of the supplied role. The role is assumed for the
+// life of the session, which ends when the client closes the connection.
+// Only superusers may use SET SESSION AUTHORIZATION. No role is assumed when
+// the supplied role is nil.
+func WithSessionAuthorization(role *string) Option {
+	return func(o *options) {
+		if role != nil {
+			o.role = *role
+		}
+	}
+}
+
+// WithAssumeRole causes every session the client opens to assume the supplied
+// role using SET ROLE before running any other statement, after assuming any
+// role supplied by WithSessionAuthorization, such that objects the client
+// creates are owned by that role. The session keeps the privileges of the
+// role it logged in as, which must be a member of the supplied role. No role
+// is assumed when the supplied role is nil.
+func WithAssumeRole(role *string) Option {
+	return func(o *options) {
+		if role != nil {
+			o.assumeRole = *role
+		}
+	}
+}
+
+// WithSearchPath causes every session the client opens to set the supplied
+// schema search path before running any other statement, after assuming any
+// roles supplied by WithSessionAuthorization and WithAssumeRole. The search
+// path is set explicitly rather than relying on the defaults of the role used
+// to connect. A client returned by New opens a new session per operation, but
+// a pooled client reuses a session, and the search path it set, for up to
+// PoolConnMaxLifetime. The server's default search path is used when the
+// supplied path is empty.
+func WithSearchPath(schemas []string) Option {
+	return func(o *options) {
+		o.search = schemas
+	}
+}
+
+// WithTimeZone causes every session the client opens to set the supplied time
+// zone before running any other statement, after setting any search path
+// supplied by WithSearchPath. Like the search path, a pooled client sets the
+// time zone once per session rather than per operation. The server's default
+// time zone is used when the supplied time zone is nil.
+func WithTimeZone(tz *string) Option {
+	return func(o *options) {
+		if tz != nil {
+			o.timeZone = *tz
+		}
+	}
+}
+
+// WithReadOnlyDeferrable causes every session the client opens to default to
+// READ ONLY DEFERRABLE transactions, after setting any search path, time zone,
+// and statement timeout supplied by other options. The server rejects any
+// statement that would change it. A
+// DEFERRABLE transaction only waits for a safe snapshot, and thus never fails
+// to serialize, when it is also SERIALIZABLE.
+func WithReadOnlyDeferrable() Option {
+	return func(o *options) {
+		o.readOnly = true
+	}
+}
+
+// WithProviderConfig sets the name of the ProviderConfig the client connects
+// with, which is used to label its connection metrics.
+func WithProviderConfig(name string) Option {
+	return func(o *options) {
+		o.providerConfig = name
+	}
+}
+
+// WithFailover supplies credentials for servers the client tries, in order,
+// when it cannot connect to the server identified by the credentials passed
+// to New. Once a server connects it is tried first by later connections
+// using the same ProviderConfig, until it fails to connect. Failover
+// credentials are connected to using the same database.
+func WithFailover(creds ...map[string][]byte) Option {
+	return func(o *options) {
+		o.failover = creds
+	}
 }
 
 // New returns a new PostgreSQL database client. The default database name is
 // an empty string. The underlying pq library will default to either using the
 // value of PGDATABASE, or if unset, the hardcoded string 'postgres'.
-func New(creds map[string][]byte, database string) xsql.DB {
+func New(creds map[string][]byte, database string, o ...Option) xsql.DB {
+	opts := &options{keepalive: DefaultTCPKeepalive}
+	for _, fn := range o {
+		fn(opts)
+	}
+
 	// TODO(negz): Support alternative connection secret formats?
 	endpoint := string(creds[xpv1.ResourceCredentialsSecretEndpointKey])
 	port := string(creds[xpv1.ResourceCredentialsSecretPortKey])
+
+	var failover []string
+	for _, fc := range opts.failover {
+		failover = append(failover, dsn(fc, database, opts))
+	}
+
 	return postgresDB{
-		dsn: "postgres://" +
-			string(creds[xpv1.ResourceCredentialsSecretUserKey]) + ":" +
-			string(creds[xpv1.ResourceCredentialsSecretPasswordKey]) + "@" +
-			endpoint + ":" +
-			port + "/" +
-			database,
-		endpoint: endpoint,
-		port:     port,
+		dsn:         dsn(creds, database, opts),
+		failover:    failover,
+		endpoint:    endpoint,
+		port:        port,
+		dialer:      dialer{Dialer: net.Dialer{KeepAlive: opts.keepalive}, pin: opts.pin()},
+		role:        opts.role,
+		assumeRole:  opts.assumeRole,
+		search:      opts.search,
+		timeZone:    opts.timeZone,
+		stmtTimeout: opts.stmtTimeout,
+		readOnly:    opts.readOnly,
+		backoff:     defaultConnectBackoff(),
+
+		providerConfig: opts.providerConfig,
+	}
+}
+
+// dsn returns a DSN that connects to the supplied database using the supplied
+// credentials, and the run-time parameters, TLS configuration, and connect
+// timeout of the supplied options.
+//
+// Every DSN parameter comes from a typed option. A ProviderConfig can't supply
+// arbitrary connection parameters, so there is no connection parameter
+// allowlist to enforce when one is applied: its SSLMode is limited to known
+// modes by the CRD's schema, its TLS material is read from Secrets, and the
+// run-time parameters an Extension may set are checked against an allowlist
+// before it connects.
+func dsn(creds map[string][]byte, database string, o *options) string {
+	// Build the DSN as a URL so that credentials and the database name are
+	// percent-encoded. Passwords often contain characters (e.g. '@', '/',
+	// '#', or spaces) that would otherwise produce a malformed DSN.
+	u := url.URL{
+		Scheme: "postgres",
+		User: url.UserPassword(
+			string(creds[xpv1.ResourceCredentialsSecretUserKey]),
+			string(creds[xpv1.ResourceCredentialsSecretPasswordKey])),
+		Host: net.JoinHostPort(
+			string(creds[xpv1.ResourceCredentialsSecretEndpointKey]),
+			string(creds[xpv1.ResourceCredentialsSecretPortKey])),
+		Path: "/" + database,
+	}
+	q := url.Values{}
+	if len(o.params) > 0 {
+		q.Set("options", runtimeOptions(o.params))
+	}
+
+	// The dialer negotiates TLS when a server certificate is pinned, so pq
+	// mustn't negotiate it again.
+	sslMode, files := o.sslMode, o.tls
+	if o.fingerprint != "" {
+		sslMode, files = "disable", TLSFiles{}
+	}
+	for k, v := range map[string]string{
+		"sslmode":         sslMode,
+		"sslrootcert":     files.RootCert,
+		"sslcert":         files.Cert,
+		"sslkey":          files.Key,
+		"connect_timeout": connectTimeout(o.connectTimeout),
+	} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// connectTimeout formats the supplied timeout as the whole number of seconds
+// expected by the connect_timeout parameter, rounding up so that a timeout
+// under a second does not disable the timeout. It returns an empty string if
+// the supplied timeout is not positive.
+func connectTimeout(d time.Duration) string {
+	if d <= 0 {
+		return ""
 	}
+	return strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10)
+}
+
+// A dialer satisfies pq.Dialer. The pq library version we use does not
+// support the libpq keepalives connection parameters, so we configure TCP
+// keepalives on the dialer instead.
+type dialer struct {
+	net.Dialer
+
+	// pin negotiates TLS on each connection, and verifies the server's
+	// certificate, if set.
+	pin *serverCertPin
 }
 
-// ExecTx executes an array of queries, committing if all are successful and
-// rolling back immediately on failure.
-func (c postgresDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
-	d, err := sql.Open("postgres", c.dsn)
+func (d dialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+func (d dialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d.DialContext(ctx, network, address)
+}
+
+func (d dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := d.Dialer.DialContext(ctx, network, address)
+	if err != nil || d.pin == nil {
+		return conn, err
+	}
+	tc, err := d.pin.secure(ctx, conn, address)
 	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return tc, nil
+}
+
+// A connector opens pq connections using a specific dialer, optionally
+// assuming roles, setting a search path, time zone, and statement timeout,
+// and making the session read only.
+// Connections that fail for transient reasons are retried with jittered
+// backoff.
+type connector struct {
+	dsn         string
+	failover    []string
+	dialer      dialer
+	role        string
+	assumeRole  string
+	search      []string
+	timeZone    string
+	stmtTimeout time.Duration
+	readOnly    bool
+	backoff     xsql.Backoff
+
+	// providerConfig labels the connection metrics.
+	providerConfig string
+
+	// dial opens a connection. It is pq.DialOpen unless overridden in tests.
+	dial func(d pq.Dialer, dsn string) (driver.Conn, error)
+}
+
+// Connect returns a new connection. Like pq's own connector it does not use
+// the supplied context to open the connection, but it does stop retrying
+// when the context is done. Dial errors are wrapped in a connectError, so
+// that failures the connector already retried are not retried by callers.
+func (c connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dial := c.dial
+	if dial == nil {
+		dial = pq.DialOpen
+	}
+	start := time.Now()
+	conn, err := c.dialAny(ctx, dial)
+	connectDuration.WithLabelValues(c.providerConfig).Observe(time.Since(start).Seconds())
+	if err != nil {
+		return nil, connectError{err}
+	}
+	if err := c.setup(ctx, conn); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// dialRetry dials the supplied DSN, retrying transient failures.
+func (c connector) dialRetry(ctx context.Context, dial func(d pq.Dialer, dsn string) (driver.Conn, error), dsn string) (driver.Conn, error) {
+	var conn driver.Conn
+	err := c.backoff.Retry(ctx, isTransientConnectError, func() error {
+		var err error
+		conn, err = dial(c.dialer, dsn)
 		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// dialAny dials the connector's DSN, then each of its failover DSNs in turn,
+// until one connects. The server that last connected is dialed first. The
+// error dialing the first DSN is returned if none connect.
+func (c connector) dialAny(ctx context.Context, dial func(d pq.Dialer, dsn string) (driver.Conn, error)) (driver.Conn, error) {
+	if len(c.failover) == 0 {
+		return c.dialRetry(ctx, dial, c.dsn)
+	}
+
+	var first error
+	for _, d := range preferred.order(c.providerConfig, append([]string{c.dsn}, c.failover...)) {
+		conn, err := c.dialRetry(ctx, dial, d)
+		if err == nil {
+			preferred.set(c.providerConfig, d)
+			return conn, nil
+		}
+		if first == nil {
+			first = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, first
+}
+
+// setup runs the statements that configure a new session.
+func (c connector) setup(ctx context.Context, conn driver.Conn) error {
+	// pq connections always support ExecerContext.
+	ex := conn.(driver.ExecerContext)
+	if c.role != "" {
+		if _, err := ex.ExecContext(ctx, "SET SESSION AUTHORIZATION "+pq.QuoteIdentifier(c.role), nil); err != nil {
+			return errors.Wrap(err, errSetSessionAuthorization)
+		}
+	}
+	if c.assumeRole != "" {
+		if _, err := ex.ExecContext(ctx, "SET ROLE "+pq.QuoteIdentifier(c.assumeRole), nil); err != nil {
+			return errors.Wrap(err, errSetRole)
+		}
+	}
+	if len(c.search) > 0 {
+		if _, err := ex.ExecContext(ctx, searchPathQuery(c.search), nil); err != nil {
+			return errors.Wrap(err, errSetSearchPath)
+		}
+	}
+	if c.timeZone != "" {
+		// SET does not support parameters, so the time zone must be quoted.
+		if _, err := ex.ExecContext(ctx, "SET TIME ZONE "+pq.QuoteLiteral(c.timeZone), nil); err != nil {
+			return errors.Wrap(err, errSetTimeZone)
+		}
+	}
+	if c.stmtTimeout > 0 {
+		if _, err := ex.ExecContext(ctx, statementTimeoutQuery(c.stmtTimeout), nil); err != nil {
+			return errors.Wrap(err, errSetStatementTimeout)
+		}
+	}
+	if c.readOnly {
+		if _, err := ex.ExecContext(ctx, readOnlyQuery, nil); err != nil {
+			return errors.Wrap(err, errSetReadOnly)
+		}
+	}
+	return nil
+}
+
+// searchPathQuery returns a statement that sets the supplied search path.
+// Schemas are quoted, which PostgreSQL permits for the special "$user" schema.
+func searchPathQuery(schemas []string) string {
+	quoted := make([]string, len(schemas))
+	for i, s := range schemas {
+		quoted[i] = pq.QuoteIdentifier(s)
+	}
+	return "SET search_path TO " + strings.Join(quoted, ", ")
+}
+
+// statementTimeoutQuery returns a statement that sets the supplied statement
+// timeout in whole milliseconds, rounding up so that a timeout under a
+// millisecond does not disable the timeout.
+func statementTimeoutQuery(d time.Duration) string {
+	ms := (d + time.Millisecond - 1) / time.Millisecond
+	return "SET statement_timeout = " + strconv.FormatInt(int64(ms), 10)
+}
+
+func (c connector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+func (c postgresDB) connector() connector {
+	return connector{dsn: c.dsn, failover: c.failover, dialer: c.dialer, role: c.role, assumeRole: c.assumeRole, search: c.search, timeZone: c.timeZone, stmtTimeout: c.stmtTimeout, readOnly: c.readOnly, backoff: c.backoff, providerConfig: c.providerConfig, dial: c.dial}
+}
+
+// open returns a database handle, and a function that must be called to
+// release it once the operation is done.
+func (c postgresDB) open() (*sql.DB, func()) {
+	if c.pool != nil {
+		return c.pool, func() {}
+	}
+	d := sql.OpenDB(c.connector())
+	return d, func() { d.Close() } //nolint:errcheck
+}
+
+// runtimeOptions formats the supplied parameters as command-line options
+// (e.g. '-c work_mem=64MB'), sorted by name so that the DSN is stable.
+// Backslashes and spaces are escaped per the PostgreSQL options syntax.
+func runtimeOptions(params map[string]string) string {
+	names := make([]string, 0, len(params))
+	for n := range params {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	esc := strings.NewReplacer(`\`, `\\`, " ", `\ `)
+	opts := make([]string, len(names))
+	for i, n := range names {
+		opts[i] = "-c " + esc.Replace(n+"="+params[n])
 	}
+	return strings.Join(opts, " ")
+}
+
+// ExecTx executes an array of queries in a single transaction, committing if
+// all are successful and rolling back immediately on failure. An error
+// committing the transaction is returned.
+func (c postgresDB) ExecTx(ctx context.Context, ql []xsql.Query) (err error) {
+	d, release := c.open()
 
 	tx, err := d.BeginTx(ctx, nil)
 	if err != nil {
+		release()
 		return err
 	}
 
-	// Rollback or Commit based on error state. Defer close in defer to make
-	// sure the connection is always closed.
+	// Rollback or Commit based on error state. Defer release in defer to
+	// make sure the connection is always released.
 	defer func() {
-		defer d.Close() //nolint:errcheck
+		defer release()
 		if err != nil {
 			tx.Rollback() //nolint:errcheck
 			return
@@ -67,7 +599,7 @@ func (c postgresDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
 	}()
 
 	for _, q := range ql {
-		if _, err = tx.Exec(q.String, q.Parameters...); err != nil {
+		if _, err = tx.ExecContext(ctx, q.String, q.Parameters...); err != nil {
 			return err
 		}
 	}
@@ -76,23 +608,17 @@ func (c postgresDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
 
 // Exec the supplied query.
 func (c postgresDB) Exec(ctx context.Context, q xsql.Query) error {
-	d, err := sql.Open("postgres", c.dsn)
-	if err != nil {
-		return err
-	}
-	defer d.Close() //nolint:errcheck
+	d, release := c.open()
+	defer release()
 
-	_, err = d.ExecContext(ctx, q.String, q.Parameters...)
+	_, err := d.ExecContext(ctx, q.String, q.Parameters...)
 	return err
 }
 
 // Query the supplied query.
 func (c postgresDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
-	d, err := sql.Open("postgres", c.dsn)
-	if err != nil {
-		return nil, err
-	}
-	defer d.Close() //nolint:errcheck
+	d, release := c.open()
+	defer release()
 
 	rows, err := d.QueryContext(ctx, q.String, q.Parameters...)
 	return rows, err
@@ -100,13 +626,10 @@ func (c postgresDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error)
 
 // Scan the results of the supplied query into the supplied destination.
 func (c postgresDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
-	db, err := sql.Open("postgres", c.dsn)
-	if err != nil {
-		return err
-	}
-	defer db.Close() //nolint:errcheck
+	d, release := c.open()
+	defer release()
 
-	return db.QueryRowContext(ctx, q.String, q.Parameters...).Scan(dest...)
+	return d.QueryRowContext(ctx, q.String, q.Parameters...).Scan(dest...)
 }
 
 // GetConnectionDetails returns the connection details for a user of this DB
@@ -122,8 +645,73 @@ func (c postgresDB) GetConnectionDetails(username, password string) managed.Conn
 // IsInvalidCatalog returns true if passed a pq error indicating
 // that the database does not exist.
 func IsInvalidCatalog(err error) bool {
-	if pqe, ok := err.(*pq.Error); ok {
+	var pqe *pq.Error
+	if errors.As(err, &pqe) {
 		return pqe.Code == pqInvalidCatalog
 	}
 	return false
 }
+
+// IsTooManyConnections returns true if passed a pq error indicating that the
+// server refused a connection because it has too many connections.
+func IsTooManyConnections(err error) bool {
+	var pqe *pq.Error
+	if errors.As(err, &pqe) {
+		return pqe.Code == pqTooManyConnections
+	}
+	return false
+}
+
+// IsInsufficientPrivilege returns true if passed a pq error indicating that
+// the connected role lacks the privilege to perform an operation, for example
+// to read a restricted catalog.
+func IsInsufficientPrivilege(err error) bool {
+	var pqe *pq.Error
+	if errors.As(err, &pqe) {
+		return pqe.Code == pqInsufficientPriv
+	}
+	return false
+}
+
+// IsUndefinedColumn returns true if passed a pq error indicating that a
+// column a statement referred to does not exist, for example a catalog column
+// that the server predates.
+func IsUndefinedColumn(err error) bool {
+	var pqe *pq.Error
+	if errors.As(err, &pqe) {
+		return pqe.Code == pqUndefinedColumn
+	}
+	return false
+}
+
+// IsUndefinedObject returns true if passed a pq error indicating that an
+// object a statement referred to does not exist, for example an extension
+// that was dropped after it was observed.
+func IsUndefinedObject(err error) bool {
+	var pqe *pq.Error
+	if errors.As(err, &pqe) {
+		return pqe.Code == pqUndefinedObject
+	}
+	return false
+}
+
+// IsTransient returns true if passed a pq error indicating that the server
+// dropped or refused the connection a statement ran on, for example because it
+// was shutting down or failing over, such that the statement may succeed if
+// retried. Failures to establish a connection are not transient; the
+// connector has already retried them.
+func IsTransient(err error) bool {
+	if isConnectError(err) {
+		return false
+	}
+	var pqe *pq.Error
+	if errors.As(err, &pqe) {
+		switch pqe.Code {
+		case pqAdminShutdown, pqCrashShutdown, pqCannotConnect:
+			return true
+		}
+		// Class 08 - Connection Exception.
+		return pqe.Code.Class() == "08"
+	}
+	return false
+}
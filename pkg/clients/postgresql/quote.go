@@ -0,0 +1,50 @@
+package postgresql
+
+import (
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// A Quoter quotes identifiers and literals so that they may be included in
+// SQL statements that do not support parameters, such as DDL. Dialects of
+// PostgreSQL with different quoting or escaping rules may supply their own.
+type Quoter interface {
+	// QuoteIdentifier quotes the supplied identifier, e.g. a table name.
+	QuoteIdentifier(name string) string
+
+	// QuoteLiteral quotes the supplied string literal.
+	QuoteLiteral(literal string) string
+}
+
+// StandardQuoter quotes identifiers and literals per the rules of standard
+// PostgreSQL.
+type StandardQuoter struct{}
+
+// QuoteIdentifier quotes the supplied identifier by surrounding it with double
+// quotes and doubling any double quotes it contains.
+func (StandardQuoter) QuoteIdentifier(name string) string {
+	return pq.QuoteIdentifier(name)
+}
+
+// QuoteLiteral quotes the supplied literal by surrounding it with single
+// quotes and doubling any single quotes it contains. Literals that contain
+// backslashes are quoted as escape string constants (e.g. E'a\\b') so that
+// they are interpreted the same regardless of standard_conforming_strings.
+func (StandardQuoter) QuoteLiteral(literal string) string {
+	return pq.QuoteLiteral(literal)
+}
+
+// DefaultQuoter is the Quoter used when no other is supplied.
+var DefaultQuoter Quoter = StandardQuoter{}
+
+// QuoteQualifiedIdentifier uses the supplied Quoter to quote each part of a
+// possibly schema qualified identifier, e.g. audit.changes becomes
+// "audit"."changes".
+func QuoteQualifiedIdentifier(q Quoter, id string) string {
+	parts := strings.Split(id, ".")
+	for i := range parts {
+		parts[i] = q.QuoteIdentifier(parts[i])
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,91 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// A bracketQuoter is a sample alternate Quoter that quotes identifiers with
+// square brackets.
+type bracketQuoter struct{ StandardQuoter }
+
+func (bracketQuoter) QuoteIdentifier(name string) string { return "[" + name + "]" }
+
+func TestStandardQuoter(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		fn     func(string) string
+		in     string
+		want   string
+	}{
+		"Identifier": {
+			reason: "Identifiers should be surrounded with double quotes",
+			fn:     DefaultQuoter.QuoteIdentifier,
+			in:     "hstore",
+			want:   `"hstore"`,
+		},
+		"IdentifierWithQuote": {
+			reason: "Double quotes in identifiers should be doubled",
+			fn:     DefaultQuoter.QuoteIdentifier,
+			in:     `a"b`,
+			want:   `"a""b"`,
+		},
+		"Literal": {
+			reason: "Literals should be surrounded with single quotes, and single quotes doubled",
+			fn:     DefaultQuoter.QuoteLiteral,
+			in:     "it's",
+			want:   `'it''s'`,
+		},
+		"LiteralWithBackslash": {
+			reason: "Literals containing backslashes should be quoted as escape string constants",
+			fn:     DefaultQuoter.QuoteLiteral,
+			in:     `a\b`,
+			want:   ` E'a\\b'`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.fn(tc.in)); diff != "" {
+				t.Errorf("\n%s\nQuote(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestQuoteQualifiedIdentifier(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		q      Quoter
+		id     string
+		want   string
+	}{
+		"Unqualified": {
+			reason: "An unqualified identifier should be quoted as a whole",
+			q:      DefaultQuoter,
+			id:     "changes",
+			want:   `"changes"`,
+		},
+		"Qualified": {
+			reason: "Each part of a qualified identifier should be quoted",
+			q:      DefaultQuoter,
+			id:     "audit.changes",
+			want:   `"audit"."changes"`,
+		},
+		"AlternateQuoter": {
+			reason: "The supplied Quoter should be used to quote each part",
+			q:      bracketQuoter{},
+			id:     "audit.changes",
+			want:   "[audit].[changes]",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, QuoteQualifiedIdentifier(tc.q, tc.id)); diff != "" {
+				t.Errorf("\n%s\nQuoteQualifiedIdentifier(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
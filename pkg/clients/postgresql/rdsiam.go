@@ -0,0 +1,123 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/rds/rdsutils"
+	"github.com/pkg/errors"
+)
+
+const (
+	// RDSAuthTokenLifetime is how long an RDS IAM authentication token may be
+	// used to open new connections.
+	RDSAuthTokenLifetime = 15 * time.Minute
+
+	// rdsAuthTokenRefresh is how long an authentication token is reused. It's
+	// shorter than the token's lifetime so that clients created with a token
+	// never outlive it.
+	rdsAuthTokenRefresh = 10 * time.Minute
+
+	errNewAWSSession  = "cannot create AWS session"
+	errAWSCredentials = "cannot get AWS credentials"
+	errBuildAuthToken = "cannot build RDS IAM authentication token"
+)
+
+// An AuthTokenFn returns a short-lived token that authenticates the supplied
+// user to the RDS server at the supplied endpoint (host:port) in the supplied
+// region.
+type AuthTokenFn func(ctx context.Context, region, endpoint, user string) (string, error)
+
+// RDSAuthToken returns an RDS IAM authentication token signed using the AWS
+// credentials the provider runs with, found using the AWS SDK's default
+// credential chain. Tokens are reused until they're due to be refreshed.
+func RDSAuthToken(ctx context.Context, region, endpoint, user string) (string, error) {
+	return defaultRDSAuthTokens.Get(ctx, region, endpoint, user)
+}
+
+var defaultRDSAuthTokens = &rdsAuthTokenCache{
+	credentials: func() (*credentials.Credentials, error) {
+		s, err := session.NewSession()
+		if err != nil {
+			return nil, errors.Wrap(err, errNewAWSSession)
+		}
+		return s.Config.Credentials, nil
+	},
+	build: rdsutils.BuildAuthToken,
+	now:   time.Now,
+}
+
+type rdsAuthToken struct {
+	token  string
+	issued time.Time
+}
+
+// An rdsAuthTokenCache builds and caches RDS IAM authentication tokens. The
+// SDK's credentials are loaded once, and cache and refresh themselves.
+type rdsAuthTokenCache struct {
+	credentials func() (*credentials.Credentials, error)
+	build       func(endpoint, region, user string, creds *credentials.Credentials) (string, error)
+	now         func() time.Time
+
+	mx     sync.Mutex
+	creds  *credentials.Credentials
+	tokens map[string]rdsAuthToken
+}
+
+// Get returns a cached authentication token for the supplied user, endpoint,
+// and region, or builds a new one if none is cached or the cached token is
+// due to be refreshed.
+func (c *rdsAuthTokenCache) Get(ctx context.Context, region, endpoint, user string) (string, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	key := strings.Join([]string{region, endpoint, user}, "/")
+	if t, ok := c.tokens[key]; ok && c.now().Before(t.issued.Add(rdsAuthTokenRefresh)) {
+		return t.token, nil
+	}
+
+	if c.creds == nil {
+		creds, err := c.credentials()
+		if err != nil {
+			return "", err
+		}
+		c.creds = creds
+	}
+
+	// Retrieve credentials up front so that retrieval honours the context.
+	if _, err := c.creds.GetWithContext(ctx); err != nil {
+		return "", errors.Wrap(err, errAWSCredentials)
+	}
+
+	issued := c.now()
+	token, err := c.build(endpoint, region, user, c.creds)
+	if err != nil {
+		return "", errors.Wrap(err, errBuildAuthToken)
+	}
+
+	if c.tokens == nil {
+		c.tokens = map[string]rdsAuthToken{}
+	}
+	c.tokens[key] = rdsAuthToken{token: token, issued: issued}
+	return token, nil
+}
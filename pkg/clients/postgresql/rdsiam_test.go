@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestRDSAuthTokenCache(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	now := time.Date(2020, 10, 15, 12, 0, 0, 0, time.UTC)
+	built := 0
+	c := &rdsAuthTokenCache{
+		credentials: func() (*credentials.Credentials, error) {
+			return credentials.NewStaticCredentials("AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", ""), nil
+		},
+		build: func(endpoint, region, user string, creds *credentials.Credentials) (string, error) {
+			if user == "boom" {
+				return "", errBoom
+			}
+			built++
+			return strings.Join([]string{region, endpoint, user, now.Format(time.RFC3339)}, "/"), nil
+		},
+		now: func() time.Time { return now },
+	}
+
+	type want struct {
+		token string
+		built int
+		err   error
+	}
+
+	steps := []struct {
+		reason string
+		at     time.Time
+		user   string
+		want   want
+	}{
+		{
+			reason: "A token should be built when none is cached",
+			at:     now,
+			user:   "iam_user",
+			want:   want{token: "us-east-1/db.example.org:5432/iam_user/2020-10-15T12:00:00Z", built: 1},
+		},
+		{
+			reason: "A cached token should be reused until it's due to be refreshed",
+			at:     now.Add(rdsAuthTokenRefresh - time.Second),
+			user:   "iam_user",
+			want:   want{token: "us-east-1/db.example.org:5432/iam_user/2020-10-15T12:00:00Z", built: 1},
+		},
+		{
+			reason: "Tokens should be cached per user",
+			at:     now.Add(rdsAuthTokenRefresh - time.Second),
+			user:   "other_user",
+			want:   want{token: "us-east-1/db.example.org:5432/other_user/2020-10-15T12:09:59Z", built: 2},
+		},
+		{
+			reason: "A new token should be built once the cached token is due to be refreshed",
+			at:     now.Add(rdsAuthTokenRefresh),
+			user:   "iam_user",
+			want:   want{token: "us-east-1/db.example.org:5432/iam_user/2020-10-15T12:10:00Z", built: 3},
+		},
+		{
+			reason: "Errors building a token should be returned",
+			at:     now.Add(rdsAuthTokenRefresh),
+			user:   "boom",
+			want:   want{err: errors.Wrap(errBoom, errBuildAuthToken), built: 3},
+		},
+	}
+
+	for _, s := range steps {
+		now = s.at
+		got, err := c.Get(context.Background(), "us-east-1", "db.example.org:5432", s.user)
+		if diff := cmp.Diff(s.want.err, err, test.EquateErrors()); diff != "" {
+			t.Errorf("\n%s\nc.Get(...): -want error, +got error:\n%s\n", s.reason, diff)
+		}
+		if diff := cmp.Diff(s.want.token, got); diff != "" {
+			t.Errorf("\n%s\nc.Get(...): -want, +got:\n%s\n", s.reason, diff)
+		}
+		if diff := cmp.Diff(s.want.built, built); diff != "" {
+			t.Errorf("\n%s\nc.Get(...): -want tokens built, +got tokens built:\n%s\n", s.reason, diff)
+		}
+	}
+}
+
+func TestRDSAuthTokenCacheBuildsSignedToken(t *testing.T) {
+	c := &rdsAuthTokenCache{
+		credentials: func() (*credentials.Credentials, error) {
+			return credentials.NewStaticCredentials("AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "session token"), nil
+		},
+		build: defaultRDSAuthTokens.build,
+		now:   time.Now,
+	}
+
+	got, err := c.Get(context.Background(), "us-east-1", "db.example.org:5432", "iam_user")
+	if err != nil {
+		t.Fatalf("c.Get(...): unexpected error: %s", err)
+	}
+	for _, want := range []string{"db.example.org:5432?Action=connect&DBUser=iam_user", "X-Amz-Credential=AKIDEXAMPLE%2F", "%2Fus-east-1%2Frds-db%2Faws4_request", "X-Amz-Expires=900", "X-Amz-Security-Token=session%20token", "X-Amz-Signature="} {
+		if !strings.Contains(got, want) {
+			t.Errorf("c.Get(...): token %q does not contain %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+const errRevokeSchema = "cannot revoke schema privileges"
+
+// rolePublic is the keyword that refers to every role. It must not be quoted,
+// and can't be the name of a role.
+const rolePublic = "PUBLIC"
+
+type revocation struct {
+	privileges []string
+	from       string
+}
+
+// A RevokeOption configures the privileges revoked by RevokeSchemaQuery.
+type RevokeOption func(r *revocation)
+
+// RevokePrivileges revokes the supplied privileges, for example CREATE or
+// USAGE, rather than ALL privileges. Privileges are keywords and can't be
+// quoted, so callers must validate them. ALL privileges are revoked when none
+// are supplied.
+func RevokePrivileges(p ...string) RevokeOption {
+	return func(r *revocation) {
+		if len(p) > 0 {
+			r.privileges = p
+		}
+	}
+}
+
+// RevokeFrom revokes privileges from the supplied role rather than from
+// PUBLIC, i.e. every role. Privileges are revoked from PUBLIC when the
+// supplied role is nil.
+func RevokeFrom(role *string) RevokeOption {
+	return func(r *revocation) {
+		if role != nil {
+			r.from = *role
+		}
+	}
+}
+
+// RevokeSchemaQuery returns a statement that revokes privileges on the
+// supplied schema. By default it revokes ALL privileges from PUBLIC. The
+// statement is idempotent; revoking privileges that were never granted does
+// nothing.
+func RevokeSchemaQuery(schema string, o ...RevokeOption) xsql.Query {
+	r := &revocation{privileges: []string{"ALL"}, from: rolePublic}
+	for _, fn := range o {
+		fn(r)
+	}
+
+	from := rolePublic
+	if !strings.EqualFold(r.from, rolePublic) {
+		from = pq.QuoteIdentifier(r.from)
+	}
+	return xsql.Query{String: "REVOKE " + strings.Join(r.privileges, ", ") + " ON SCHEMA " + pq.QuoteIdentifier(schema) + " FROM " + from}
+}
+
+// RevokeSchema revokes privileges on the supplied schema in the database the
+// supplied DB is connected to. See RevokeSchemaQuery.
+func RevokeSchema(ctx context.Context, db xsql.DB, schema string, o ...RevokeOption) error {
+	return errors.Wrap(db.Exec(ctx, RevokeSchemaQuery(schema, o...)), errRevokeSchema)
+}
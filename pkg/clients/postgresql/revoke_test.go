@@ -0,0 +1,123 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type execDB struct {
+	xsql.DB
+	exec func(ctx context.Context, q xsql.Query) error
+}
+
+func (d execDB) Exec(ctx context.Context, q xsql.Query) error {
+	return d.exec(ctx, q)
+}
+
+func TestRevokeSchemaQuery(t *testing.T) {
+	app := `app"role`
+	public := "public"
+
+	cases := map[string]struct {
+		reason string
+		schema string
+		o      []RevokeOption
+		want   string
+	}{
+		"Defaults": {
+			reason: "ALL privileges should be revoked from PUBLIC by default",
+			schema: "public",
+			want:   `REVOKE ALL ON SCHEMA "public" FROM PUBLIC`,
+		},
+		"EmptyOptions": {
+			reason: "Empty options should leave the defaults in place",
+			schema: "public",
+			o:      []RevokeOption{RevokePrivileges(), RevokeFrom(nil)},
+			want:   `REVOKE ALL ON SCHEMA "public" FROM PUBLIC`,
+		},
+		"Configured": {
+			reason: "The supplied privileges should be revoked from the supplied role, which should be quoted",
+			schema: "app",
+			o:      []RevokeOption{RevokePrivileges("CREATE", "USAGE"), RevokeFrom(&app)},
+			want:   `REVOKE CREATE, USAGE ON SCHEMA "app" FROM "app""role"`,
+		},
+		"FromPublic": {
+			reason: "The PUBLIC keyword should never be quoted, regardless of case",
+			schema: "public",
+			o:      []RevokeOption{RevokeFrom(&public)},
+			want:   `REVOKE ALL ON SCHEMA "public" FROM PUBLIC`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := RevokeSchemaQuery(tc.schema, tc.o...)
+			if diff := cmp.Diff(xsql.Query{String: tc.want}, got); diff != "" {
+				t.Errorf("\n%s\nRevokeSchemaQuery(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRevokeSchema(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   error
+	}{
+		"Success": {
+			reason: "Revoking privileges again should run the same statement, and succeed",
+		},
+		"ErrExec": {
+			reason: "Errors running the statement should be returned",
+			err:    errBoom,
+			want:   errors.Wrap(errBoom, errRevokeSchema),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			db := execDB{exec: func(_ context.Context, q xsql.Query) error {
+				got = append(got, q.String)
+				return tc.err
+			}}
+
+			for i := 0; i < 2; i++ {
+				err := RevokeSchema(context.Background(), db, "public", RevokePrivileges("CREATE"))
+				if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+					t.Errorf("\n%s\nRevokeSchema(...): -want error, +got error:\n%s\n", tc.reason, diff)
+				}
+			}
+			want := []string{`REVOKE CREATE ON SCHEMA "public" FROM PUBLIC`, `REVOKE CREATE ON SCHEMA "public" FROM PUBLIC`}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("\n%s\nRevokeSchema(...): -want statements, +got statements:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package postgresql
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// sslRequestCode is sent by a client to ask a PostgreSQL server to
+	// upgrade the connection to TLS.
+	// https://www.postgresql.org/docs/current/protocol-message-formats.html
+	sslRequestCode = 80877103
+
+	errRequestSSL       = "cannot request SSL"
+	errSSLNotSupported  = "server does not support SSL"
+	errTLSHandshake     = "cannot complete TLS handshake"
+	errNoPeerCert       = "server did not present a certificate"
+	errServerCertFormat = "ServerCertMismatch: server certificate fingerprint %s does not match expected fingerprint %s"
+	errWriteTLSFile     = "cannot write TLS file"
+	errLoadClientCert   = "cannot load client certificate"
+	errReadRootCert     = "cannot read root certificate file"
+	errParseRootCert    = "cannot parse root certificate file"
+	errParseServerCert  = "cannot parse server certificate"
+	errVerifyCertChain  = "cannot verify server certificate chain"
+)
+
+// DefaultTLSDir is the directory in which TLS files are written.
+var DefaultTLSDir = filepath.Join(os.TempDir(), "provider-sql-tls")
+
+// TLSFiles are the paths of files containing PEM encoded TLS material. The pq
+// library reads TLS material only from files.
+type TLSFiles struct {
+	// RootCert contains the CA certificates used to verify the server.
+	RootCert string
+
+	// Cert contains the client certificate presented to the server.
+	Cert string
+
+	// Key contains the private key of the client certificate.
+	Key string
+}
+
+// WriteTLSFile writes the supplied PEM encoded TLS material to a file in the
+// supplied directory, and returns its path. Files are named for the SHA-256
+// hash of their content, so the same material is written only once however
+// many connections use it. Files are readable only by their owner, as pq
+// requires of private keys.
+func WriteTLSFile(dir string, pem []byte) (string, error) {
+	sum := sha256.Sum256(pem)
+	path := filepath.Join(dir, hex.EncodeToString(sum[:])+".pem")
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", errors.Wrap(err, errWriteTLSFile)
+	}
+
+	// Write to a temporary file and rename it into place, so that concurrent
+	// connections never read a partially written file.
+	f, err := ioutil.TempFile(dir, ".tmp-")
+	if err != nil {
+		return "", errors.Wrap(err, errWriteTLSFile)
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck
+	if _, err := f.Write(pem); err != nil {
+		_ = f.Close()
+		return "", errors.Wrap(err, errWriteTLSFile)
+	}
+	if err := f.Close(); err != nil {
+		return "", errors.Wrap(err, errWriteTLSFile)
+	}
+	return path, errors.Wrap(os.Rename(f.Name(), path), errWriteTLSFile)
+}
+
+// A ServerCertMismatchError indicates that the certificate presented by a
+// server did not match the pinned fingerprint.
+type ServerCertMismatchError struct {
+	Expected string
+	Actual   string
+}
+
+func (e *ServerCertMismatchError) Error() string {
+	return fmt.Sprintf(errServerCertFormat, e.Actual, e.Expected)
+}
+
+// IsServerCertMismatch returns true if passed an error indicating that a
+// server presented an unexpected certificate.
+func IsServerCertMismatch(err error) bool {
+	var e *ServerCertMismatchError
+	return errors.As(err, &e)
+}
+
+// Fingerprint returns the hex encoded SHA-256 fingerprint of the supplied DER
+// encoded certificate.
+func Fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeFingerprint allows fingerprints to be supplied in upper or lower
+// case, optionally with colon separated octets (e.g. as printed by openssl).
+func normalizeFingerprint(fp string) string {
+	return strings.ToLower(strings.ReplaceAll(fp, ":", ""))
+}
+
+// requestSSL asks the server at the other end of the supplied connection to
+// upgrade it to TLS.
+func requestSSL(conn net.Conn) error {
+	req := make([]byte, 8)
+	binary.BigEndian.PutUint32(req[0:4], 8)
+	binary.BigEndian.PutUint32(req[4:8], sslRequestCode)
+	if _, err := conn.Write(req); err != nil {
+		return errors.Wrap(err, errRequestSSL)
+	}
+
+	rsp := make([]byte, 1)
+	if _, err := conn.Read(rsp); err != nil {
+		return errors.Wrap(err, errRequestSSL)
+	}
+	if rsp[0] != 'S' {
+		return errors.New(errSSLNotSupported)
+	}
+	return nil
+}
+
+// verifyFingerprint returns a *ServerCertMismatchError if the supplied DER
+// encoded certificate does not have the supplied fingerprint.
+func verifyFingerprint(der []byte, fingerprint string) error {
+	if actual := Fingerprint(der); actual != normalizeFingerprint(fingerprint) {
+		return &ServerCertMismatchError{Expected: fingerprint, Actual: actual}
+	}
+	return nil
+}
+
+// A serverCertPin negotiates TLS on connections to a server whose certificate
+// is pinned, and rejects connections to a server that presents any other
+// certificate. The pq library negotiates TLS itself without exposing the
+// certificate the server presents, so when a certificate is pinned pq is told
+// not to use TLS, and the dialer negotiates it instead. The sslmode and TLS
+// files are honoured as pq would honour them, except that TLS is always used.
+type serverCertPin struct {
+	fingerprint string
+	sslMode     string
+	files       TLSFiles
+}
+
+// pin returns the server certificate pin configured by the options, if any.
+func (o *options) pin() *serverCertPin {
+	if o.fingerprint == "" {
+		return nil
+	}
+	return &serverCertPin{fingerprint: o.fingerprint, sslMode: o.sslMode, files: o.tls}
+}
+
+// secure upgrades the supplied connection to the supplied address to TLS,
+// returning an error if the server's certificate is not the pinned one.
+func (p *serverCertPin) secure(ctx context.Context, conn net.Conn, address string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	cfg, err := p.config(host)
+	if err != nil {
+		return nil, err
+	}
+
+	// pq sets its own deadline for the rest of the connection's startup once
+	// it has been dialed.
+	if dl, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(dl)
+		defer conn.SetDeadline(time.Time{}) //nolint:errcheck
+	}
+
+	if err := requestSSL(conn); err != nil {
+		return nil, err
+	}
+	tc := tls.Client(conn, cfg)
+	if err := tc.Handshake(); err != nil {
+		return nil, errors.Wrap(err, errTLSHandshake)
+	}
+	return tc, nil
+}
+
+// config returns the TLS configuration used to connect to the supplied host.
+// Like pq, sslmode require verifies the certificate chain only if a root
+// certificate file is supplied, verify-ca verifies the chain, and
+// verify-full also verifies that the certificate is for the host. The system
+// roots are used when no root certificate file is supplied.
+func (p *serverCertPin) config(host string) (*tls.Config, error) {
+	verifyChain := p.sslMode == "verify-ca" || p.sslMode == "verify-full" || p.files.RootCert != ""
+
+	cfg := &tls.Config{
+		ServerName: host,
+
+		// The certificate is verified by VerifyPeerCertificate.
+		InsecureSkipVerify: true, //nolint:gosec
+
+		// pq accepts renegotiation, which older PostgreSQL servers and
+		// Redshift initiate.
+		Renegotiation: tls.RenegotiateFreelyAsClient,
+	}
+
+	if p.files.Cert != "" && p.files.Key != "" {
+		c, err := tls.LoadX509KeyPair(p.files.Cert, p.files.Key)
+		if err != nil {
+			return nil, errors.Wrap(err, errLoadClientCert)
+		}
+		cfg.Certificates = []tls.Certificate{c}
+	}
+
+	var roots *x509.CertPool
+	if verifyChain && p.files.RootCert != "" {
+		pem, err := ioutil.ReadFile(p.files.RootCert)
+		if err != nil {
+			return nil, errors.Wrap(err, errReadRootCert)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, errors.New(errParseRootCert)
+		}
+	}
+
+	cfg.VerifyPeerCertificate = func(raw [][]byte, _ [][]*x509.Certificate) error {
+		if len(raw) == 0 {
+			return errors.New(errNoPeerCert)
+		}
+		if err := verifyFingerprint(raw[0], p.fingerprint); err != nil {
+			return err
+		}
+		if !verifyChain {
+			return nil
+		}
+
+		certs := make([]*x509.Certificate, len(raw))
+		for i := range raw {
+			c, err := x509.ParseCertificate(raw[i])
+			if err != nil {
+				return errors.Wrap(err, errParseServerCert)
+			}
+			certs[i] = c
+		}
+		opts := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+		for _, c := range certs[1:] {
+			opts.Intermediates.AddCert(c)
+		}
+		if p.sslMode == "verify-full" {
+			opts.DNSName = host
+		}
+		_, err := certs[0].Verify(opts)
+		return errors.Wrap(err, errVerifyCertChain)
+	}
+
+	return cfg, nil
+}
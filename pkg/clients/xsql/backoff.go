@@ -0,0 +1,63 @@
+package xsql
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// A Backoff determines how an operation that fails for transient reasons is
+// retried. Each retry waits up to twice as long as the last, with jitter, up
+// to Max. The operation is attempted only once when Attempts is zero.
+type Backoff struct {
+	Attempts int
+	Base     time.Duration
+	Max      time.Duration
+
+	// Sleep waits for the supplied duration, or until the context is done.
+	// It is only overridden in tests.
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Delay returns how long to wait before the supplied retry, counting from
+// zero. The delay is chosen at random from the upper half of an exponentially
+// increasing window, so it is never shorter than half the window.
+func (b Backoff) Delay(retry int) time.Duration {
+	window := b.Max
+	if retry < 32 && b.Base<<uint(retry) < b.Max {
+		window = b.Base << uint(retry)
+	}
+	half := window / 2
+	// Jitter needn't be cryptographically random.
+	return half + time.Duration(rand.Int63n(int64(window-half)+1)) //nolint:gosec
+}
+
+// Wait for the supplied duration, or until the context is done.
+func (b Backoff) Wait(ctx context.Context, d time.Duration) error {
+	if b.Sleep != nil {
+		return b.Sleep(ctx, d)
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Retry calls the supplied function until it succeeds, returns an error for
+// which the supplied transient function returns false, the attempts are
+// exhausted, or the context is done. The last error is returned.
+func (b Backoff) Retry(ctx context.Context, transient func(err error) bool, fn func() error) error {
+	for i := 0; ; i++ {
+		err := fn()
+		if err == nil || i+1 >= b.Attempts || !transient(err) {
+			return err
+		}
+		if werr := b.Wait(ctx, b.Delay(i)); werr != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package xsql
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	b := Backoff{Base: DefaultRetryBackoff, Max: DefaultMaxRetryBackoff}
+
+	for retry := 0; retry < 40; retry++ {
+		window := DefaultMaxRetryBackoff
+		if retry < 32 && b.Base<<uint(retry) < b.Max {
+			window = b.Base << uint(retry)
+		}
+
+		seen := map[time.Duration]bool{}
+		for i := 0; i < 100; i++ {
+			d := b.Delay(retry)
+			if d < window/2 || d > window {
+				t.Fatalf("b.Delay(%d): want delay in [%s, %s], got %s", retry, window/2, window, d)
+			}
+			seen[d] = true
+		}
+
+		// Retries that all wait the same time would synchronise.
+		if len(seen) < 2 {
+			t.Errorf("b.Delay(%d): want jittered delays, got the same delay 100 times", retry)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package xsql
 import (
 	"context"
 	"errors"
+	"io"
 
 	"database/sql"
 
@@ -18,7 +19,7 @@ type Query struct {
 // A DB client.
 type DB interface {
 	Exec(ctx context.Context, q Query) error
-	ExecTx(cts context.Context, ql []Query) error
+	ExecTx(ctx context.Context, ql []Query) error
 	Scan(ctx context.Context, q Query, dest ...interface{}) error
 	Query(ctx context.Context, q Query) (*sql.Rows, error)
 	GetConnectionDetails(username, password string) managed.ConnectionDetails
@@ -28,3 +29,18 @@ type DB interface {
 func IsNoRows(err error) bool {
 	return errors.Is(err, sql.ErrNoRows)
 }
+
+// Close the supplied DB if it, or any DB it wraps, holds connections open
+// between queries. DBs that wrap another DB expose it using an Unwrap method.
+func Close(db DB) error {
+	for {
+		if c, ok := db.(io.Closer); ok {
+			return c.Close()
+		}
+		w, ok := db.(interface{ Unwrap() DB })
+		if !ok {
+			return nil
+		}
+		db = w.Unwrap()
+	}
+}
@@ -0,0 +1,33 @@
+package xsql
+
+import (
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+)
+
+type closingDB struct {
+	mockDB
+	closed *bool
+}
+
+func (d closingDB) Close() error {
+	*d.closed = true
+	return nil
+}
+
+func TestClose(t *testing.T) {
+	closed := false
+	db := NewLoggingDB(closingDB{closed: &closed}, logging.NewNopLogger())
+
+	if err := Close(db); err != nil {
+		t.Fatalf("Close(...): %v", err)
+	}
+	if !closed {
+		t.Errorf("Close(...): want the wrapped DB to be closed")
+	}
+
+	if err := Close(mockDB{}); err != nil {
+		t.Errorf("Close(...): want DBs that hold no connections to be ignored, got %v", err)
+	}
+}
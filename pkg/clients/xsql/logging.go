@@ -0,0 +1,142 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+)
+
+// Redacted is logged in place of any query parameter or literal value.
+const Redacted = "<redacted>"
+
+// Matches the opening delimiter of a dollar quoted SQL string literal, for
+// example $$ or $body$.
+var dollarTag = regexp.MustCompile(`^\$(?:[A-Za-z_][A-Za-z0-9_]*)?\$`)
+
+// Redact returns a loggable representation of the supplied query. Positional
+// parameter placeholders (e.g. $1) are left untouched, but the values of any
+// parameters and any literals in the query string are masked. Literals may
+// contain credentials, for example when a role's password is set.
+func Redact(q Query) (string, []string) {
+	p := make([]string, len(q.Parameters))
+	for i := range q.Parameters {
+		p[i] = fmt.Sprintf("$%d=%s", i+1, Redacted)
+	}
+	return redactLiterals(q.String), p
+}
+
+// redactLiterals masks the single quoted ('...'), escape (E'...'), and dollar
+// quoted ($$...$$ or $tag$...$tag$) string literals in the supplied query.
+// Quoted identifiers are skipped. Comments are not, so a quote in a comment
+// masks more of the query than necessary, but never less.
+func redactLiterals(s string) string {
+	b := &strings.Builder{}
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '"':
+			j := closingQuote(s, i+1, '"', false)
+			b.WriteString(s[i:j])
+			i = j
+		case '\'':
+			// Backslashes escape characters only in E'...' literals. The E
+			// has already been written.
+			escapes := i > 0 && (s[i-1] == 'E' || s[i-1] == 'e') && (i < 2 || !isIdentifierChar(s[i-2]))
+			b.WriteString("'" + Redacted + "'")
+			i = closingQuote(s, i+1, '\'', escapes)
+		case '$':
+			tag := dollarTag.FindString(s[i:])
+			if tag == "" || (i > 0 && isIdentifierChar(s[i-1])) {
+				b.WriteByte(s[i])
+				i++
+				continue
+			}
+			b.WriteString(tag + Redacted + tag)
+			end := strings.Index(s[i+len(tag):], tag)
+			if end < 0 {
+				return b.String()
+			}
+			i += len(tag) + end + len(tag)
+		default:
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// closingQuote returns the index just past the supplied quote character that
+// closes the quoted string starting at the supplied index, or the length of
+// the supplied string if it's never closed. A doubled quote character is an
+// escaped quote, as is a quote character after a backslash if escapes is true.
+func closingQuote(s string, from int, quote byte, escapes bool) int {
+	for j := from; j < len(s); j++ {
+		switch {
+		case escapes && s[j] == '\\':
+			j++
+		case s[j] == quote && j+1 < len(s) && s[j+1] == quote:
+			j++
+		case s[j] == quote:
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// isIdentifierChar returns true if the supplied byte may appear after the
+// first character of an unquoted identifier.
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// A LoggingDB logs each query at debug level before delegating it to the DB it
+// wraps.
+type LoggingDB struct {
+	DB
+	log logging.Logger
+}
+
+// NewLoggingDB returns a DB that logs all queries run against the supplied DB.
+func NewLoggingDB(db DB, l logging.Logger) *LoggingDB {
+	return &LoggingDB{DB: db, log: l}
+}
+
+func (d *LoggingDB) logQuery(op string, q Query) {
+	s, p := Redact(q)
+	d.log.Debug("Running SQL statement", "operation", op, "statement", s, "parameters", p)
+}
+
+// Exec the supplied query.
+func (d *LoggingDB) Exec(ctx context.Context, q Query) error {
+	d.logQuery("exec", q)
+	return d.DB.Exec(ctx, q)
+}
+
+// ExecTx executes the supplied queries in a transaction.
+func (d *LoggingDB) ExecTx(ctx context.Context, ql []Query) error {
+	for _, q := range ql {
+		d.logQuery("exectx", q)
+	}
+	return d.DB.ExecTx(ctx, ql)
+}
+
+// Scan the results of the supplied query into the supplied destination.
+func (d *LoggingDB) Scan(ctx context.Context, q Query, dest ...interface{}) error {
+	d.logQuery("scan", q)
+	return d.DB.Scan(ctx, q, dest...)
+}
+
+// Query the supplied query.
+func (d *LoggingDB) Query(ctx context.Context, q Query) (*sql.Rows, error) {
+	d.logQuery("query", q)
+	return d.DB.Query(ctx, q)
+}
+
+// Unwrap returns the DB this DB wraps.
+func (d *LoggingDB) Unwrap() DB {
+	return d.DB
+}
@@ -0,0 +1,163 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+)
+
+type mockDB struct {
+	MockExec func(ctx context.Context, q Query) error
+	MockScan func(ctx context.Context, q Query, dest ...interface{}) error
+}
+
+func (m mockDB) Exec(ctx context.Context, q Query) error      { return m.MockExec(ctx, q) }
+func (m mockDB) ExecTx(ctx context.Context, ql []Query) error { return nil }
+func (m mockDB) Scan(ctx context.Context, q Query, dest ...interface{}) error {
+	if m.MockScan == nil {
+		return nil
+	}
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return nil
+}
+
+// A recordingLogger records the key/value pairs of all debug messages.
+type recordingLogger struct {
+	debug *[][]interface{}
+}
+
+func (l recordingLogger) Info(msg string, keysAndValues ...interface{}) {}
+func (l recordingLogger) Debug(msg string, keysAndValues ...interface{}) {
+	*l.debug = append(*l.debug, keysAndValues)
+}
+func (l recordingLogger) WithValues(keysAndValues ...interface{}) logging.Logger { return l }
+
+func TestRedact(t *testing.T) {
+	type want struct {
+		statement  string
+		parameters []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		q      Query
+		want   want
+	}{
+		"Parameters": {
+			reason: "Positional placeholders should be kept while parameter values are masked",
+			q: Query{
+				String:     "SELECT extversion FROM pg_extension WHERE extname = $1 AND extowner = $2",
+				Parameters: []interface{}{"hstore", 10},
+			},
+			want: want{
+				statement:  "SELECT extversion FROM pg_extension WHERE extname = $1 AND extowner = $2",
+				parameters: []string{"$1=" + Redacted, "$2=" + Redacted},
+			},
+		},
+		"Literals": {
+			reason: "String literals, including those with escaped quotes, should be masked",
+			q: Query{
+				String: `CREATE ROLE "example" PASSWORD 'sup''er' LOGIN`,
+			},
+			want: want{
+				statement:  `CREATE ROLE "example" PASSWORD '` + Redacted + `' LOGIN`,
+				parameters: []string{},
+			},
+		},
+		"EscapeLiterals": {
+			reason: "Escape string literals, including those with backslash escaped quotes, should be masked",
+			q: Query{
+				String: `ALTER ROLE "example" PASSWORD E'sup\'er' LOGIN`,
+			},
+			want: want{
+				statement:  `ALTER ROLE "example" PASSWORD E'` + Redacted + `' LOGIN`,
+				parameters: []string{},
+			},
+		},
+		"DollarQuotedLiterals": {
+			reason: "Dollar quoted literals, with or without a tag, should be masked",
+			q: Query{
+				String: `DO $body$ BEGIN PERFORM 'secret'; END $body$; COMMENT ON ROLE "example" IS $$it's secret$$`,
+			},
+			want: want{
+				statement:  `DO $body$` + Redacted + `$body$; COMMENT ON ROLE "example" IS $$` + Redacted + `$$`,
+				parameters: []string{},
+			},
+		},
+		"QuotedIdentifiers": {
+			reason: "Quoted identifiers, and positional placeholders, should not be masked",
+			q: Query{
+				String: `CREATE ROLE "it's" PASSWORD $1`,
+			},
+			want: want{
+				statement:  `CREATE ROLE "it's" PASSWORD $1`,
+				parameters: []string{},
+			},
+		},
+		"Unterminated": {
+			reason: "Everything after an unterminated literal should be masked",
+			q: Query{
+				String: `CREATE ROLE "example" PASSWORD 'secret`,
+			},
+			want: want{
+				statement:  `CREATE ROLE "example" PASSWORD '` + Redacted + `'`,
+				parameters: []string{},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s, p := Redact(tc.q)
+			if diff := cmp.Diff(tc.want.statement, s); diff != "" {
+				t.Errorf("\n%s\nRedact(...): -want statement, +got statement:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.parameters, p); diff != "" {
+				t.Errorf("\n%s\nRedact(...): -want parameters, +got parameters:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestLoggingDBExec(t *testing.T) {
+	ran := false
+	logged := [][]interface{}{}
+
+	db := NewLoggingDB(mockDB{
+		MockExec: func(ctx context.Context, q Query) error {
+			ran = true
+			return nil
+		},
+	}, recordingLogger{debug: &logged})
+
+	q := Query{
+		String:     "ALTER ROLE \"example\" PASSWORD $1",
+		Parameters: []interface{}{"hunter2"},
+	}
+	if err := db.Exec(context.Background(), q); err != nil {
+		t.Fatalf("db.Exec(...): unexpected error: %s", err)
+	}
+
+	if !ran {
+		t.Errorf("db.Exec(...): query was not passed to the wrapped DB")
+	}
+
+	want := [][]interface{}{{
+		"operation", "exec",
+		"statement", "ALTER ROLE \"example\" PASSWORD $1",
+		"parameters", []string{"$1=" + Redacted},
+	}}
+	if diff := cmp.Diff(want, logged); diff != "" {
+		t.Errorf("db.Exec(...): -want logged, +got logged:\n%s\n", diff)
+	}
+}
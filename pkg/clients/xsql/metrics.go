@@ -0,0 +1,74 @@
+package xsql
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Outcomes of a query, used to label query metrics.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// queries counts the queries run, by operation and outcome.
+var queries = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "provider_sql_queries_total",
+	Help: "Number of SQL queries run, by operation (exec or scan) and outcome.",
+}, []string{"operation", "outcome"})
+
+// queryDuration records how long queries take, by operation and outcome.
+var queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "provider_sql_query_duration_seconds",
+	Help:    "Time taken to run a SQL query, by operation (exec or scan) and outcome.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"operation", "outcome"})
+
+func init() {
+	metrics.Registry.MustRegister(queries, queryDuration)
+}
+
+// An InstrumentedDB records the number and latency of the queries run
+// against the DB it wraps.
+type InstrumentedDB struct {
+	DB
+}
+
+// NewInstrumentedDB returns a DB that records metrics for the queries run
+// against the supplied DB.
+func NewInstrumentedDB(db DB) *InstrumentedDB {
+	return &InstrumentedDB{DB: db}
+}
+
+func observe(op string, start time.Time, err error) {
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeError
+	}
+	queries.WithLabelValues(op, outcome).Inc()
+	queryDuration.WithLabelValues(op, outcome).Observe(time.Since(start).Seconds())
+}
+
+// Exec the supplied query.
+func (d *InstrumentedDB) Exec(ctx context.Context, q Query) error {
+	start := time.Now()
+	err := d.DB.Exec(ctx, q)
+	observe("exec", start, err)
+	return err
+}
+
+// Scan the results of the supplied query into the supplied destination.
+func (d *InstrumentedDB) Scan(ctx context.Context, q Query, dest ...interface{}) error {
+	start := time.Now()
+	err := d.DB.Scan(ctx, q, dest...)
+	observe("scan", start, err)
+	return err
+}
+
+// Unwrap returns the DB this DB wraps.
+func (d *InstrumentedDB) Unwrap() DB {
+	return d.DB
+}
@@ -0,0 +1,91 @@
+package xsql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// counts returns the number of queries counted, and observed, with the
+// supplied labels.
+func counts(t *testing.T, op, outcome string) (float64, uint64) {
+	t.Helper()
+	c := &dto.Metric{}
+	if err := queries.WithLabelValues(op, outcome).Write(c); err != nil {
+		t.Fatal(err)
+	}
+	h := &dto.Metric{}
+	if err := queryDuration.WithLabelValues(op, outcome).(prometheus.Histogram).Write(h); err != nil {
+		t.Fatal(err)
+	}
+	return c.GetCounter().GetValue(), h.GetHistogram().GetSampleCount()
+}
+
+func TestInstrumentedDB(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason  string
+		op      string
+		err     error
+		outcome string
+	}{
+		"ExecSuccess": {
+			reason:  "A successful exec should be counted and observed",
+			op:      "exec",
+			outcome: OutcomeSuccess,
+		},
+		"ExecError": {
+			reason:  "A failed exec should be counted and observed as an error",
+			op:      "exec",
+			err:     errBoom,
+			outcome: OutcomeError,
+		},
+		"ScanSuccess": {
+			reason:  "A successful scan should be counted and observed",
+			op:      "scan",
+			outcome: OutcomeSuccess,
+		},
+		"ScanError": {
+			reason:  "A failed scan should be counted and observed as an error",
+			op:      "scan",
+			err:     errBoom,
+			outcome: OutcomeError,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := NewInstrumentedDB(mockDB{
+				MockExec: func(_ context.Context, _ Query) error { return tc.err },
+				MockScan: func(_ context.Context, _ Query, _ ...interface{}) error { return tc.err },
+			})
+
+			count, observed := counts(t, tc.op, tc.outcome)
+			for i := 0; i < 2; i++ {
+				var err error
+				switch tc.op {
+				case "exec":
+					err = d.Exec(context.Background(), Query{String: "CREATE EXTENSION hstore"})
+				case "scan":
+					err = d.Scan(context.Background(), Query{String: "SELECT 1"})
+				}
+				if !errors.Is(err, tc.err) {
+					t.Errorf("\n%s\nd.%s(...): want error %v, got %v", tc.reason, tc.op, tc.err, err)
+				}
+			}
+
+			gotCount, gotObserved := counts(t, tc.op, tc.outcome)
+			if diff := cmp.Diff(count+2, gotCount); diff != "" {
+				t.Errorf("\n%s\nd.%s(...): -want queries counted, +got:\n%s\n", tc.reason, tc.op, diff)
+			}
+			if diff := cmp.Diff(observed+2, gotObserved); diff != "" {
+				t.Errorf("\n%s\nd.%s(...): -want queries observed, +got:\n%s\n", tc.reason, tc.op, diff)
+			}
+		})
+	}
+}
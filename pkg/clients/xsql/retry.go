@@ -0,0 +1,60 @@
+package xsql
+
+import (
+	"context"
+	"time"
+)
+
+// Statements that fail for transient reasons are retried up to
+// DefaultRetryAttempts times in total. Each retry waits up to twice as long as
+// the last, with jitter, up to DefaultMaxRetryBackoff.
+const (
+	DefaultRetryAttempts   = 3
+	DefaultRetryBackoff    = 100 * time.Millisecond
+	DefaultMaxRetryBackoff = 2 * time.Second
+)
+
+// A RetryingDB retries statements that fail for transient reasons, for
+// example because the server dropped the connection while failing over, with
+// exponential backoff. Only Exec and Scan are retried; a transaction or a
+// set of rows that fails part way through is left to the caller.
+type RetryingDB struct {
+	DB
+
+	transient func(err error) bool
+	backoff   Backoff
+}
+
+// NewRetryingDB returns a DB that retries statements run against the supplied
+// DB when they fail with an error for which the supplied function returns
+// true. Other errors are returned immediately.
+func NewRetryingDB(db DB, transient func(err error) bool) *RetryingDB {
+	return &RetryingDB{
+		DB:        db,
+		transient: transient,
+		backoff:   Backoff{Attempts: DefaultRetryAttempts, Base: DefaultRetryBackoff, Max: DefaultMaxRetryBackoff},
+	}
+}
+
+// retry calls the supplied function until it succeeds, returns an error that
+// is not transient, the attempts are exhausted, or the context is done. The
+// last error is returned.
+func (d *RetryingDB) retry(ctx context.Context, fn func() error) error {
+	return d.backoff.Retry(ctx, d.transient, fn)
+}
+
+// Exec the supplied query, retrying transient failures.
+func (d *RetryingDB) Exec(ctx context.Context, q Query) error {
+	return d.retry(ctx, func() error { return d.DB.Exec(ctx, q) })
+}
+
+// Scan the results of the supplied query into the supplied destination,
+// retrying transient failures.
+func (d *RetryingDB) Scan(ctx context.Context, q Query, dest ...interface{}) error {
+	return d.retry(ctx, func() error { return d.DB.Scan(ctx, q, dest...) })
+}
+
+// Unwrap returns the DB this DB wraps.
+func (d *RetryingDB) Unwrap() DB {
+	return d.DB
+}
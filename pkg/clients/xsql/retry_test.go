@@ -0,0 +1,100 @@
+package xsql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestRetryingDB(t *testing.T) {
+	errTransient := errors.New("terminating connection due to administrator command")
+	errSyntax := errors.New("syntax error")
+	transient := func(err error) bool { return errors.Is(err, errTransient) }
+
+	type want struct {
+		err      error
+		attempts int
+		waits    []time.Duration
+	}
+
+	cases := map[string]struct {
+		reason string
+		errs   []error
+		ctxErr error
+		want   want
+	}{
+		"FailsTwiceThenSucceeds": {
+			reason: "A statement that fails transiently twice should be retried with increasing backoff until it succeeds",
+			errs:   []error{errTransient, errTransient, nil},
+			want:   want{attempts: 3, waits: []time.Duration{DefaultRetryBackoff, 2 * DefaultRetryBackoff}},
+		},
+		"AttemptsExhausted": {
+			reason: "The last error should be returned once the attempts are exhausted",
+			errs:   []error{errTransient, errTransient, errTransient, nil},
+			want:   want{err: errTransient, attempts: DefaultRetryAttempts, waits: []time.Duration{DefaultRetryBackoff, 2 * DefaultRetryBackoff}},
+		},
+		"NotTransient": {
+			reason: "A statement that fails for a reason that isn't transient should not be retried",
+			errs:   []error{errSyntax, nil},
+			want:   want{err: errSyntax, attempts: 1},
+		},
+		"ContextDone": {
+			reason: "Retries should stop, returning the statement's error, when the context is done",
+			errs:   []error{errTransient, nil},
+			ctxErr: context.Canceled,
+			want:   want{err: errTransient, attempts: 1, waits: []time.Duration{DefaultRetryBackoff}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			for _, op := range []string{"Exec", "Scan"} {
+				attempts := 0
+				fail := func() error {
+					err := tc.errs[attempts]
+					attempts++
+					return err
+				}
+
+				d := NewRetryingDB(mockDB{
+					MockExec: func(_ context.Context, _ Query) error { return fail() },
+					MockScan: func(_ context.Context, _ Query, _ ...interface{}) error { return fail() },
+				}, transient)
+
+				// Record each wait rather than sleeping.
+				var waits []time.Duration
+				d.backoff.Sleep = func(_ context.Context, dur time.Duration) error {
+					waits = append(waits, dur)
+					return tc.ctxErr
+				}
+
+				var err error
+				switch op {
+				case "Exec":
+					err = d.Exec(context.Background(), Query{String: "CREATE EXTENSION hstore"})
+				case "Scan":
+					err = d.Scan(context.Background(), Query{String: "SELECT 1"})
+				}
+				if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+					t.Errorf("\n%s\nd.%s(...): -want error, +got error:\n%s\n", tc.reason, op, diff)
+				}
+				if diff := cmp.Diff(tc.want.attempts, attempts); diff != "" {
+					t.Errorf("\n%s\nd.%s(...): -want attempts, +got attempts:\n%s\n", tc.reason, op, diff)
+				}
+				if len(waits) != len(tc.want.waits) {
+					t.Fatalf("\n%s\nd.%s(...): want %d waits, got %d", tc.reason, op, len(tc.want.waits), len(waits))
+				}
+				for i, w := range waits {
+					if w < tc.want.waits[i]/2 || w > tc.want.waits[i] {
+						t.Errorf("\n%s\nd.%s(...): want wait %d between %s and %s, got %s", tc.reason, op, i, tc.want.waits[i]/2, tc.want.waits[i], w)
+					}
+				}
+			}
+		})
+	}
+}
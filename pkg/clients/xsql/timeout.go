@@ -0,0 +1,57 @@
+package xsql
+
+import (
+	"context"
+	"time"
+)
+
+// A TimeoutDB bounds how long each statement may run when its caller has not
+// set a deadline, so that a hung connection can't block the caller
+// indefinitely. Query is not bounded, because the rows it returns are read
+// after it returns.
+type TimeoutDB struct {
+	DB
+	timeout time.Duration
+}
+
+// NewTimeoutDB returns a DB that cancels statements run against the supplied
+// DB once they've run for the supplied timeout, unless their context already
+// has a deadline.
+func NewTimeoutDB(db DB, timeout time.Duration) *TimeoutDB {
+	return &TimeoutDB{DB: db, timeout: timeout}
+}
+
+// context returns a child of the supplied context with the DB's timeout, if
+// the supplied context has no deadline.
+func (d *TimeoutDB) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || d.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d.timeout)
+}
+
+// Exec the supplied query.
+func (d *TimeoutDB) Exec(ctx context.Context, q Query) error {
+	ctx, cancel := d.context(ctx)
+	defer cancel()
+	return d.DB.Exec(ctx, q)
+}
+
+// ExecTx executes the supplied queries in a transaction.
+func (d *TimeoutDB) ExecTx(ctx context.Context, ql []Query) error {
+	ctx, cancel := d.context(ctx)
+	defer cancel()
+	return d.DB.ExecTx(ctx, ql)
+}
+
+// Scan the results of the supplied query into the supplied destination.
+func (d *TimeoutDB) Scan(ctx context.Context, q Query, dest ...interface{}) error {
+	ctx, cancel := d.context(ctx)
+	defer cancel()
+	return d.DB.Scan(ctx, q, dest...)
+}
+
+// Unwrap returns the DB this DB wraps.
+func (d *TimeoutDB) Unwrap() DB {
+	return d.DB
+}
@@ -0,0 +1,69 @@
+package xsql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+// hang blocks until the supplied context is done, like a query on a hung
+// connection would.
+func hang(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(10 * time.Second):
+		return nil
+	}
+}
+
+func TestTimeoutDB(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		timeout time.Duration
+		ctx     func() (context.Context, context.CancelFunc)
+		want    error
+	}{
+		"NoDeadline": {
+			reason:  "A hung query should fail with the DB's timeout when its context has no deadline",
+			timeout: 10 * time.Millisecond,
+			ctx:     func() (context.Context, context.CancelFunc) { return context.Background(), func() {} },
+			want:    context.DeadlineExceeded,
+		},
+		"CallerDeadline": {
+			reason:  "The caller's deadline should be used when it has one",
+			timeout: time.Hour,
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 10*time.Millisecond)
+			},
+			want: context.DeadlineExceeded,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := NewTimeoutDB(mockDB{
+				MockExec: func(ctx context.Context, _ Query) error { return hang(ctx) },
+				MockScan: func(ctx context.Context, _ Query, _ ...interface{}) error { return hang(ctx) },
+			}, tc.timeout)
+
+			ctx, cancel := tc.ctx()
+			defer cancel()
+			err := d.Exec(ctx, Query{String: "CREATE EXTENSION postgis"})
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nd.Exec(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+
+			ctx, cancel = tc.ctx()
+			defer cancel()
+			err = d.Scan(ctx, Query{String: "SELECT 1"})
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nd.Scan(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+// Package xsqltest provides a fake xsql.DB for testing controllers.
+package xsqltest
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// ErrUnexpectedQuery is returned for queries that match no programmed result,
+// so that a test fails if a controller runs a query it didn't expect.
+var ErrUnexpectedQuery = errors.New("unexpected query")
+
+const (
+	errScanDest  = "cannot scan value %d into destination of type %T"
+	errScanValue = "cannot scan %T into destination of type %T"
+)
+
+// A Result is the canned result of the queries that match a pattern.
+type Result struct {
+	// Err is returned by any query that matches.
+	Err error
+
+	// Values are scanned, in order, into the destinations supplied to Scan.
+	// Destinations without a corresponding value are left untouched.
+	Values []interface{}
+
+	// Rows are returned by Query.
+	Rows *sqlmock.Rows
+}
+
+type rule struct {
+	pattern *regexp.Regexp
+	result  Result
+}
+
+// A DB is a fake xsql.DB. It records every query run against it, and returns
+// the results programmed for queries whose string matches a pattern.
+type DB struct {
+	mu       sync.Mutex
+	rules    []rule
+	executed []xsql.Query
+}
+
+var _ xsql.DB = &DB{}
+
+// New returns a fake DB with no programmed results.
+func New() *DB {
+	return &DB{}
+}
+
+// On programs the result of queries whose string matches the supplied regular
+// expression. Patterns are tried in the order they were programmed. Use
+// regexp.QuoteMeta to match a query string exactly.
+func (d *DB) On(pattern string, r Result) *DB {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rules = append(d.rules, rule{pattern: regexp.MustCompile(pattern), result: r})
+	return d
+}
+
+// result records the supplied query and returns its programmed result.
+func (d *DB) result(q xsql.Query) Result {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.executed = append(d.executed, q)
+	for _, r := range d.rules {
+		if r.pattern.MatchString(q.String) {
+			return r.result
+		}
+	}
+	return Result{Err: errors.Wrapf(ErrUnexpectedQuery, "%q", q.String)}
+}
+
+// Exec records the supplied query and returns its programmed error.
+func (d *DB) Exec(_ context.Context, q xsql.Query) error {
+	return d.result(q).Err
+}
+
+// ExecTx records each of the supplied queries, stopping at the first that is
+// programmed to return an error, like a transaction would.
+func (d *DB) ExecTx(_ context.Context, ql []xsql.Query) error {
+	for _, q := range ql {
+		if err := d.result(q).Err; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Scan records the supplied query and scans its programmed values into the
+// supplied destinations, or returns its programmed error.
+func (d *DB) Scan(_ context.Context, q xsql.Query, dest ...interface{}) error {
+	r := d.result(q)
+	if r.Err != nil {
+		return r.Err
+	}
+	for i, v := range r.Values {
+		if i >= len(dest) {
+			break
+		}
+		dv := reflect.ValueOf(dest[i])
+		if dv.Kind() != reflect.Ptr || dv.IsNil() {
+			return errors.Errorf(errScanDest, i, dest[i])
+		}
+		if v == nil {
+			dv.Elem().Set(reflect.Zero(dv.Elem().Type()))
+			continue
+		}
+		vv := reflect.ValueOf(v)
+		if !vv.Type().AssignableTo(dv.Elem().Type()) {
+			return errors.Errorf(errScanValue, v, dest[i])
+		}
+		dv.Elem().Set(vv)
+	}
+	return nil
+}
+
+// Query records the supplied query and returns its programmed rows, or its
+// programmed error.
+func (d *DB) Query(_ context.Context, q xsql.Query) (*sql.Rows, error) {
+	r := d.result(q)
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	rows := r.Rows
+	if rows == nil {
+		rows = sqlmock.NewRows(nil)
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		return nil, err
+	}
+	mock.ExpectQuery(".*").WillReturnRows(rows)
+	return db.Query(q.String)
+}
+
+// GetConnectionDetails returns no connection details.
+func (d *DB) GetConnectionDetails(_, _ string) managed.ConnectionDetails {
+	return nil
+}
+
+// Queries returns every query run against the DB, in order.
+func (d *DB) Queries() []xsql.Query {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]xsql.Query{}, d.executed...)
+}
+
+// Statements returns the string of every query run against the DB, in order.
+func (d *DB) Statements() []string {
+	qs := d.Queries()
+	s := make([]string, len(qs))
+	for i, q := range qs {
+		s[i] = q.String
+	}
+	return s
+}
+
+// AssertQuery fails the supplied test if the Nth query run against the DB,
+// counting from zero, is not the supplied query.
+func (d *DB) AssertQuery(t testing.TB, n int, want xsql.Query) {
+	t.Helper()
+	qs := d.Queries()
+	if n >= len(qs) {
+		t.Errorf("want query %d to be %q, but only %d queries were run", n, want.String, len(qs))
+		return
+	}
+	if diff := cmp.Diff(want, qs[n]); diff != "" {
+		t.Errorf("query %d: -want, +got:\n%s", n, diff)
+	}
+}
+
+// AssertStatements fails the supplied test if the strings of the queries run
+// against the DB are not exactly the supplied statements, in order.
+func (d *DB) AssertStatements(t testing.TB, want ...string) {
+	t.Helper()
+	if diff := cmp.Diff(want, d.Statements()); diff != "" {
+		t.Errorf("statements: -want, +got:\n%s", diff)
+	}
+}
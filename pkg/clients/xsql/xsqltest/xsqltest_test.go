@@ -0,0 +1,131 @@
+package xsqltest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestScan(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		err  error
+		s    string
+		b    bool
+		stmt []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		db     *DB
+		q      xsql.Query
+		want   want
+	}{
+		"Values": {
+			reason: "Programmed values should be scanned into the destinations in order",
+			db:     New().On("^SELECT", Result{Values: []interface{}{"hstore", true}}),
+			q:      xsql.Query{String: "SELECT extname, extrelocatable FROM pg_extension"},
+			want: want{
+				s:    "hstore",
+				b:    true,
+				stmt: []string{"SELECT extname, extrelocatable FROM pg_extension"},
+			},
+		},
+		"FirstMatchWins": {
+			reason: "Patterns should be tried in the order they were programmed",
+			db: New().
+				On("pg_extension", Result{Err: errBoom}).
+				On("^SELECT", Result{Values: []interface{}{"hstore"}}),
+			q: xsql.Query{String: "SELECT extname FROM pg_extension"},
+			want: want{
+				err:  errBoom,
+				stmt: []string{"SELECT extname FROM pg_extension"},
+			},
+		},
+		"Unexpected": {
+			reason: "A query that matches no pattern should fail, and still be recorded",
+			db:     New().On("^DROP", Result{}),
+			q:      xsql.Query{String: "SELECT 1"},
+			want: want{
+				err:  errors.Wrapf(ErrUnexpectedQuery, "%q", "SELECT 1"),
+				stmt: []string{"SELECT 1"},
+			},
+		},
+		"WrongType": {
+			reason: "A value that can't be assigned to its destination should fail",
+			db:     New().On("", Result{Values: []interface{}{1}}),
+			q:      xsql.Query{String: "SELECT 1"},
+			want: want{
+				err:  errors.Errorf(errScanValue, 1, new(string)),
+				stmt: []string{"SELECT 1"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var s string
+			var b bool
+			err := tc.db.Scan(context.Background(), tc.q, &s, &b)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nScan(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.s, s); diff != "" {
+				t.Errorf("\n%s\nScan(...): -want string, +got string:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.b, b); diff != "" {
+				t.Errorf("\n%s\nScan(...): -want bool, +got bool:\n%s\n", tc.reason, diff)
+			}
+			tc.db.AssertStatements(t, tc.want.stmt...)
+		})
+	}
+}
+
+func TestExecTx(t *testing.T) {
+	errBoom := errors.New("boom")
+	db := New().
+		On("^BEGIN|^COMMIT", Result{}).
+		On("^DROP", Result{Err: errBoom})
+
+	ql := []xsql.Query{{String: "BEGIN"}, {String: "DROP EXTENSION hstore"}, {String: "COMMIT"}}
+	if diff := cmp.Diff(errBoom, db.ExecTx(context.Background(), ql), test.EquateErrors()); diff != "" {
+		t.Errorf("ExecTx(...): -want error, +got error:\n%s\n", diff)
+	}
+
+	// The transaction should stop at the first failed query.
+	db.AssertStatements(t, "BEGIN", "DROP EXTENSION hstore")
+	db.AssertQuery(t, 1, xsql.Query{String: "DROP EXTENSION hstore"})
+}
+
+func TestQuery(t *testing.T) {
+	db := New().On("^SELECT", Result{Rows: sqlmock.NewRows([]string{"name"}).AddRow("hstore").AddRow("citext")})
+
+	q := xsql.Query{String: "SELECT name FROM pg_available_extensions WHERE name = $1", Parameters: []interface{}{"hstore"}}
+	rows, err := db.Query(context.Background(), q)
+	if err != nil {
+		t.Fatalf("Query(...): unexpected error: %s", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	got := []string{}
+	for rows.Next() {
+		var n string
+		if err := rows.Scan(&n); err != nil {
+			t.Fatalf("rows.Scan(...): unexpected error: %s", err)
+		}
+		got = append(got, n)
+	}
+	if diff := cmp.Diff([]string{"hstore", "citext"}, got); diff != "" {
+		t.Errorf("Query(...): -want rows, +got rows:\n%s\n", diff)
+	}
+
+	db.AssertQuery(t, 0, q)
+}
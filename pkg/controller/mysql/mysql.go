@@ -19,24 +19,23 @@ package mysql
 import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
-	"github.com/crossplane/crossplane-runtime/pkg/logging"
-
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/mysql/config"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/mysql/database"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/mysql/grant"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/mysql/user"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/options"
 )
 
-// Setup creates all MySQL controllers with the supplied logger and adds
+// Setup creates all MySQL controllers with the supplied options and adds
 // them to the supplied manager.
-func Setup(mgr ctrl.Manager, l logging.Logger) error {
-	for _, setup := range []func(ctrl.Manager, logging.Logger) error{
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	for _, setup := range []func(ctrl.Manager, options.Options) error{
 		config.Setup,
 		database.Setup,
 		user.Setup,
 		grant.Setup,
 	} {
-		if err := setup(mgr, l); err != nil {
+		if err := setup(mgr, o); err != nil {
 			return err
 		}
 	}
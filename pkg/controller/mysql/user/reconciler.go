@@ -30,7 +30,6 @@ import (
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
-	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/password"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
@@ -39,6 +38,7 @@ import (
 	"github.com/crossplane-contrib/provider-sql/apis/mysql/v1alpha1"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/mysql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/options"
 )
 
 const (
@@ -59,16 +59,25 @@ const (
 )
 
 // Setup adds a controller that reconciles User managed resources.
-func Setup(mgr ctrl.Manager, l logging.Logger) error {
+func Setup(mgr ctrl.Manager, o options.Options) error {
 	name := managed.ControllerName(v1alpha1.UserGroupKind)
 
+	db := func(creds map[string][]byte) xsql.DB {
+		return o.DB(mysql.New(creds))
+	}
+
+	rec, err := o.Recorder(mgr, v1alpha1.UserGroupVersionKind, func() resource.ProviderConfig { return &v1alpha1.ProviderConfig{} }, event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))
+	if err != nil {
+		return err
+	}
+
 	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1alpha1.UserGroupVersionKind),
-		managed.WithExternalConnecter(&connector{kube: mgr.GetClient(), usage: t, newDB: mysql.New}),
-		managed.WithLogger(l.WithValues("controller", name)),
+		managed.WithExternalConnecter(o.ExternalConnecter(&connector{kube: mgr.GetClient(), usage: t, newDB: db})),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithPollInterval(10*time.Minute),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		managed.WithRecorder(rec))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
@@ -0,0 +1,87 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+const errUpdateNotAllowedStatus = "cannot update status of managed resource targeting a disallowed database"
+
+// ReasonDatabaseNotAllowed indicates a resource is not synced because its
+// ProviderConfig does not allow the database it targets.
+const ReasonDatabaseNotAllowed xpv1.ConditionReason = "DatabaseNotAllowed"
+
+// DatabaseNotAllowed returns a condition that indicates a resource could not
+// be synced because its ProviderConfig does not allow the database it targets.
+func DatabaseNotAllowed(err error) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeSynced,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDatabaseNotAllowed,
+		Message:            err.Error(),
+	}
+}
+
+// A DatabaseAllowlistReconciler wraps a managed resource reconciler, refusing
+// to reconcile resources that target a database their ProviderConfig does not
+// allow. The managed reconciler would report these as a ReconcileError and
+// requeue them with backoff; they won't succeed until someone edits either the
+// resource or the ProviderConfig.
+type DatabaseAllowlistReconciler struct {
+	reconcile.Reconciler
+	kube       client.Client
+	newObj     func() resource.Managed
+	check      func(ctx context.Context, mg resource.Managed) error
+	notAllowed func(err error) bool
+	wait       time.Duration
+}
+
+// NewDatabaseAllowlistReconciler returns a reconciler that sets the Synced
+// condition to DatabaseNotAllowed and requeues after the supplied wait when
+// check returns an error that notAllowed classifies as the resource targeting
+// a disallowed database. Other errors are left to the supplied reconciler.
+func NewDatabaseAllowlistReconciler(r reconcile.Reconciler, kube client.Client, newObj func() resource.Managed, check func(ctx context.Context, mg resource.Managed) error, notAllowed func(err error) bool, wait time.Duration) *DatabaseAllowlistReconciler {
+	return &DatabaseAllowlistReconciler{Reconciler: r, kube: kube, newObj: newObj, check: check, notAllowed: notAllowed, wait: wait}
+}
+
+// Reconcile the supplied request.
+func (r *DatabaseAllowlistReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	mg := r.newObj()
+	if err := r.kube.Get(ctx, req.NamespacedName, mg); err != nil {
+		return r.Reconciler.Reconcile(ctx, req)
+	}
+
+	err := r.check(ctx, mg)
+	if err == nil || !r.notAllowed(err) {
+		return r.Reconciler.Reconcile(ctx, req)
+	}
+
+	mg.SetConditions(DatabaseNotAllowed(err))
+	return reconcile.Result{RequeueAfter: r.wait}, errors.Wrap(r.kube.Status().Update(ctx, mg), errUpdateNotAllowedStatus)
+}
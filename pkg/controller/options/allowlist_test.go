@@ -0,0 +1,132 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+var errNotAllowed = errors.New("not allowed")
+
+func isNotAllowed(err error) bool { return errors.Is(err, errNotAllowed) }
+
+func TestDatabaseAllowlistReconciler(t *testing.T) {
+	errBoom := errors.New("boom")
+	wait := 2 * time.Minute
+	inner := reconcile.Result{Requeue: true}
+
+	type args struct {
+		get    test.MockGetFn
+		update test.MockStatusUpdateFn
+		check  error
+	}
+
+	type want struct {
+		result     reconcile.Result
+		err        error
+		reconciled bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NotAllowed": {
+			reason: "Resources targeting a disallowed database should be marked DatabaseNotAllowed and requeued after the wait",
+			args: args{
+				get: test.NewMockGetFn(nil),
+				update: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+					if got := obj.(resource.Managed).GetCondition(xpv1.TypeSynced); got.Reason != ReasonDatabaseNotAllowed || got.Status != corev1.ConditionFalse {
+						t.Errorf("Update(...): want Synced condition with reason %q, got %q", ReasonDatabaseNotAllowed, got.Reason)
+					}
+					return nil
+				},
+				check: errors.Wrap(errNotAllowed, "cannot connect"),
+			},
+			want: want{result: reconcile.Result{RequeueAfter: wait}},
+		},
+		"ErrUpdateStatus": {
+			reason: "An error should be returned if we can't mark a resource targeting a disallowed database",
+			args: args{
+				get:    test.NewMockGetFn(nil),
+				update: test.NewMockStatusUpdateFn(errBoom),
+				check:  errNotAllowed,
+			},
+			want: want{result: reconcile.Result{RequeueAfter: wait}, err: errors.Wrap(errBoom, errUpdateNotAllowedStatus)},
+		},
+		"Allowed": {
+			reason: "Resources targeting an allowed database should be reconciled as usual",
+			args: args{
+				get: test.NewMockGetFn(nil),
+			},
+			want: want{result: inner, reconciled: true},
+		},
+		"OtherError": {
+			reason: "Resources whose database can't be checked should be reconciled as usual",
+			args: args{
+				get:   test.NewMockGetFn(nil),
+				check: errBoom,
+			},
+			want: want{result: inner, reconciled: true},
+		},
+		"GetError": {
+			reason: "Resources that can't be read should be reconciled as usual",
+			args: args{
+				get: test.NewMockGetFn(errBoom),
+			},
+			want: want{result: inner, reconciled: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			reconciled := false
+			in := reconcile.Func(func(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+				reconciled = true
+				return inner, nil
+			})
+			check := func(_ context.Context, _ resource.Managed) error { return tc.args.check }
+			kube := &test.MockClient{MockGet: tc.args.get, MockStatusUpdate: tc.args.update}
+			r := NewDatabaseAllowlistReconciler(in, kube, func() resource.Managed { return &fake.Managed{} }, check, isNotAllowed, wait)
+
+			got, err := r.Reconcile(context.Background(), reconcile.Request{})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.reconciled, reconciled); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want reconciled, +got reconciled:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
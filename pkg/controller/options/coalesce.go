@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// isSpecEdit returns true if the supplied update changed an object's spec,
+// and the object is not being deleted.
+func isSpecEdit(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil || e.ObjectNew == nil {
+		return false
+	}
+	if e.ObjectNew.GetDeletionTimestamp() != nil {
+		return false
+	}
+	return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+}
+
+// NotSpecEdit returns a predicate that is true for all events except spec
+// edits, which are handled by a CoalescingHandler.
+func NotSpecEdit() predicate.Predicate {
+	return predicate.Funcs{UpdateFunc: func(e event.UpdateEvent) bool { return !isSpecEdit(e) }}
+}
+
+// NewCoalescingHandler returns an event handler that enqueues a reconcile
+// request for each object whose spec is edited once the supplied settle
+// period has passed. The work queue holds only one delayed request per
+// object, so edits made within the settle period of the first are reconciled
+// once, using the latest spec. All other events are ignored.
+func NewCoalescingHandler(settle time.Duration) handler.EventHandler {
+	return handler.Funcs{
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			if !isSpecEdit(e) {
+				return
+			}
+			q.AddAfter(reconcile.Request{NamespacedName: client.ObjectKeyFromObject(e.ObjectNew)}, settle)
+		},
+	}
+}
+
+// For configures the supplied builder to reconcile the supplied kind of
+// object. Spec edits are coalesced per SpecEditSettle.
+func (o Options) For(b *builder.Builder, obj client.Object) *builder.Builder {
+	if o.SpecEditSettle == 0 {
+		return b.For(obj)
+	}
+	return b.
+		For(obj, builder.WithPredicates(NotSpecEdit())).
+		Watches(&source.Kind{Type: obj}, NewCoalescingHandler(o.SpecEditSettle))
+}
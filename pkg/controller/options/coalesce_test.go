@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+)
+
+func managedWithGeneration(g int64, deleting bool) *fake.Managed {
+	mg := &fake.Managed{ObjectMeta: metav1.ObjectMeta{Name: "cool", Generation: g}}
+	if deleting {
+		now := metav1.Now()
+		mg.SetDeletionTimestamp(&now)
+	}
+	return mg
+}
+
+func TestNotSpecEdit(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		e      event.UpdateEvent
+		want   bool
+	}{
+		"SpecEdit": {
+			reason: "Spec edits should be left to the coalescing handler",
+			e:      event.UpdateEvent{ObjectOld: managedWithGeneration(1, false), ObjectNew: managedWithGeneration(2, false)},
+			want:   false,
+		},
+		"StatusUpdate": {
+			reason: "Updates that don't change the spec should be reconciled immediately",
+			e:      event.UpdateEvent{ObjectOld: managedWithGeneration(1, false), ObjectNew: managedWithGeneration(1, false)},
+			want:   true,
+		},
+		"Deleting": {
+			reason: "Objects that are being deleted should be reconciled immediately",
+			e:      event.UpdateEvent{ObjectOld: managedWithGeneration(1, false), ObjectNew: managedWithGeneration(2, true)},
+			want:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := NotSpecEdit().Update(tc.e)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nNotSpecEdit().Update(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCoalescingHandler(t *testing.T) {
+	settle := 50 * time.Millisecond
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer q.ShutDown()
+
+	h := NewCoalescingHandler(settle)
+
+	// Edit the same object several times in quick succession.
+	for g := int64(1); g <= 5; g++ {
+		h.Update(event.UpdateEvent{ObjectOld: managedWithGeneration(g, false), ObjectNew: managedWithGeneration(g+1, false)}, q)
+	}
+
+	// Updates that don't change the spec are ignored; they're enqueued by the
+	// controller's usual handler.
+	h.Update(event.UpdateEvent{ObjectOld: managedWithGeneration(6, false), ObjectNew: managedWithGeneration(6, false)}, q)
+
+	if got := q.Len(); got != 0 {
+		t.Errorf("q.Len(): want no requests before the settle period has passed, got %d", got)
+	}
+
+	deadline := time.Now().Add(10 * settle)
+	for q.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(settle / 10)
+	}
+
+	// Give any (incorrectly) uncoalesced requests a chance to arrive.
+	time.Sleep(settle)
+
+	if diff := cmp.Diff(1, q.Len()); diff != "" {
+		t.Errorf("q.Len(): want rapid edits coalesced into one request: -want, +got:\n%s\n", diff)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// TypeServerConnectionLimitReached resources could not be reconciled because
+// the server refused a connection; it has too many connections.
+const TypeServerConnectionLimitReached xpv1.ConditionType = "ServerConnectionLimitReached"
+
+// Reasons a server's connection limit has or has not been reached.
+const (
+	ReasonTooManyConnections xpv1.ConditionReason = "TooManyConnections"
+	ReasonConnectionAccepted xpv1.ConditionReason = "ConnectionAccepted"
+)
+
+// ServerConnectionLimitReached returns a condition that indicates the server
+// refused a connection because it has too many connections.
+func ServerConnectionLimitReached() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeServerConnectionLimitReached,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonTooManyConnections,
+		Message:            "The server has too many connections; backing off before retrying",
+	}
+}
+
+// ServerConnectionAccepted returns a condition that indicates the server is
+// accepting connections again.
+func ServerConnectionAccepted() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeServerConnectionLimitReached,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonConnectionAccepted,
+	}
+}
+
+// A ConnectionLimitConnecter wraps the ExternalClients produced by another
+// connecter, marking resources whose operations fail because the server has
+// too many connections.
+type ConnectionLimitConnecter struct {
+	managed.ExternalConnecter
+	isLimit func(err error) bool
+}
+
+// NewConnectionLimitConnecter returns an ExternalConnecter that sets the
+// ServerConnectionLimitReached condition when an operation of the clients
+// produced by the supplied connecter returns an error that isLimit classifies
+// as the server having too many connections.
+func NewConnectionLimitConnecter(c managed.ExternalConnecter, isLimit func(err error) bool) *ConnectionLimitConnecter {
+	return &ConnectionLimitConnecter{ExternalConnecter: c, isLimit: isLimit}
+}
+
+// Connect to the external system.
+func (c *ConnectionLimitConnecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	e, err := c.ExternalConnecter.Connect(ctx, mg)
+	if err != nil {
+		c.check(mg, err)
+		return nil, err
+	}
+	return &connectionLimitClient{ExternalClient: e, check: c.check}, nil
+}
+
+func (c *ConnectionLimitConnecter) check(mg resource.Managed, err error) {
+	switch {
+	case err != nil && c.isLimit(err):
+		mg.SetConditions(ServerConnectionLimitReached())
+	case err == nil && mg.GetCondition(TypeServerConnectionLimitReached).Status == corev1.ConditionTrue:
+		mg.SetConditions(ServerConnectionAccepted())
+	}
+}
+
+type connectionLimitClient struct {
+	managed.ExternalClient
+	check func(mg resource.Managed, err error)
+}
+
+func (c *connectionLimitClient) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	o, err := c.ExternalClient.Observe(ctx, mg)
+	c.check(mg, err)
+	return o, err
+}
+
+func (c *connectionLimitClient) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, err := c.ExternalClient.Create(ctx, mg)
+	c.check(mg, err)
+	return cr, err
+}
+
+func (c *connectionLimitClient) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	u, err := c.ExternalClient.Update(ctx, mg)
+	c.check(mg, err)
+	return u, err
+}
+
+func (c *connectionLimitClient) Delete(ctx context.Context, mg resource.Managed) error {
+	err := c.ExternalClient.Delete(ctx, mg)
+	c.check(mg, err)
+	return err
+}
+
+// A ConnectionLimitReconciler wraps a managed resource reconciler, replacing
+// its usual rate limited requeue with an extended backoff when the resource
+// could not be reconciled because the server has too many connections.
+// Retrying quickly would only add to the server's load.
+type ConnectionLimitReconciler struct {
+	reconcile.Reconciler
+	kube    client.Reader
+	newObj  func() resource.Managed
+	backoff time.Duration
+}
+
+// NewConnectionLimitReconciler returns a reconciler that requeues resources
+// with the ServerConnectionLimitReached condition after the supplied backoff.
+// The supplied reconciler's result is used unchanged when backoff is zero.
+func NewConnectionLimitReconciler(r reconcile.Reconciler, kube client.Reader, newObj func() resource.Managed, backoff time.Duration) *ConnectionLimitReconciler {
+	return &ConnectionLimitReconciler{Reconciler: r, kube: kube, newObj: newObj, backoff: backoff}
+}
+
+// Reconcile the supplied request.
+func (r *ConnectionLimitReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	res, err := r.Reconciler.Reconcile(ctx, req)
+	if err != nil || !res.Requeue || r.backoff == 0 {
+		return res, err
+	}
+
+	mg := r.newObj()
+	if err := r.kube.Get(ctx, req.NamespacedName, mg); err != nil {
+		// We can't tell why the resource was requeued, so fall back to the
+		// usual behaviour.
+		return res, nil
+	}
+
+	if mg.GetCondition(TypeServerConnectionLimitReached).Status != corev1.ConditionTrue {
+		return res, nil
+	}
+
+	return reconcile.Result{RequeueAfter: r.backoff}, nil
+}
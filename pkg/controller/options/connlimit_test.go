@@ -0,0 +1,175 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+var errTooMany = errors.New("too many connections")
+
+func isTooMany(err error) bool { return errors.Is(err, errTooMany) }
+
+func TestConnectionLimitConnecter(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		err    error
+		mg     resource.Managed
+		want   corev1.ConditionStatus
+	}{
+		"LimitReached": {
+			reason: "Resources should be marked when the server has too many connections",
+			err:    errors.Wrap(errTooMany, "cannot select"),
+			mg:     &fake.Managed{},
+			want:   corev1.ConditionTrue,
+		},
+		"OtherError": {
+			reason: "Resources should not be marked when an unrelated error occurs",
+			err:    errors.New("boom"),
+			mg:     &fake.Managed{},
+			want:   corev1.ConditionUnknown,
+		},
+		"Accepted": {
+			reason: "A previously marked resource should be unmarked when the server accepts connections",
+			mg: func() resource.Managed {
+				mg := &fake.Managed{}
+				mg.SetConditions(ServerConnectionLimitReached())
+				return mg
+			}(),
+			want: corev1.ConditionFalse,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := managed.ExternalClientFns{
+				ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{}, tc.err
+				},
+			}
+			c := NewConnectionLimitConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return e, nil
+			}), isTooMany)
+
+			ec, err := c.Connect(context.Background(), tc.mg)
+			if err != nil {
+				t.Fatalf("\n%s\nc.Connect(...): %s", tc.reason, err)
+			}
+			_, _ = ec.Observe(context.Background(), tc.mg)
+
+			if diff := cmp.Diff(tc.want, tc.mg.GetCondition(TypeServerConnectionLimitReached).Status); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want status, +got status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConnectionLimitReconciler(t *testing.T) {
+	backoff := 2 * time.Minute
+
+	limited := func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+		obj.(resource.Managed).SetConditions(ServerConnectionLimitReached())
+		return nil
+	}
+
+	type args struct {
+		result  reconcile.Result
+		get     test.MockGetFn
+		backoff time.Duration
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   reconcile.Result
+	}{
+		"LimitReached": {
+			reason: "Resources that hit the server's connection limit should be requeued after the extended backoff",
+			args: args{
+				result:  reconcile.Result{Requeue: true},
+				get:     limited,
+				backoff: backoff,
+			},
+			want: reconcile.Result{RequeueAfter: backoff},
+		},
+		"OtherError": {
+			reason: "Resources that failed for any other reason should be requeued as usual",
+			args: args{
+				result:  reconcile.Result{Requeue: true},
+				get:     test.NewMockGetFn(nil),
+				backoff: backoff,
+			},
+			want: reconcile.Result{Requeue: true},
+		},
+		"NotRequeued": {
+			reason: "Results that don't requeue immediately should be returned unchanged",
+			args: args{
+				result:  reconcile.Result{RequeueAfter: time.Minute},
+				get:     limited,
+				backoff: backoff,
+			},
+			want: reconcile.Result{RequeueAfter: time.Minute},
+		},
+		"Disabled": {
+			reason: "Results should be returned unchanged when no backoff is configured",
+			args: args{
+				result: reconcile.Result{Requeue: true},
+				get:    limited,
+			},
+			want: reconcile.Result{Requeue: true},
+		},
+		"GetError": {
+			reason: "Results should be returned unchanged when the resource can't be read",
+			args: args{
+				result:  reconcile.Result{Requeue: true},
+				get:     test.NewMockGetFn(errors.New("boom")),
+				backoff: backoff,
+			},
+			want: reconcile.Result{Requeue: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			inner := reconcile.Func(func(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+				return tc.args.result, nil
+			})
+			r := NewConnectionLimitReconciler(inner, &test.MockClient{MockGet: tc.args.get}, func() resource.Managed { return &fake.Managed{} }, tc.args.backoff)
+
+			got, err := r.Reconcile(context.Background(), reconcile.Request{})
+			if err != nil {
+				t.Fatalf("\n%s\nr.Reconcile(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// Actions that may be recorded as decisions.
+const (
+	ActionCreate = "Create"
+	ActionUpdate = "Update"
+	ActionDelete = "Delete"
+)
+
+// Results of recorded decisions.
+const (
+	ResultSuccess = "Success"
+	ResultFailure = "Failure"
+)
+
+// A Decision describes an action a controller took to reconcile a managed
+// resource. Decisions are intended to be consumed by external systems, for
+// example change management pipelines.
+type Decision struct {
+	Time   time.Time `json:"time"`
+	Kind   string    `json:"kind"`
+	Name   string    `json:"name"`
+	Action string    `json:"action"`
+
+	// SQL statements executed while taking the action. Statements are
+	// recorded without their parameters, and with any literals redacted
+	// because they may contain credentials. Queries that only read, such as
+	// those made to observe the current state, are not recorded.
+	SQL []string `json:"sql,omitempty"`
+
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// A DecisionSink records decisions.
+type DecisionSink interface {
+	Record(d Decision) error
+}
+
+// A DecisionSinkFn is a function that satisfies DecisionSink.
+type DecisionSinkFn func(d Decision) error
+
+// Record the supplied decision.
+func (fn DecisionSinkFn) Record(d Decision) error {
+	return fn(d)
+}
+
+// A JSONSink records each decision as a line of JSON.
+type JSONSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONSink returns a DecisionSink that writes each decision to the supplied
+// writer as a line of JSON.
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{enc: json.NewEncoder(w)}
+}
+
+// Record the supplied decision.
+func (s *JSONSink) Record(d Decision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(d)
+}
+
+type statementsKey struct{}
+
+// statements collects the SQL statements executed during an action.
+type statements struct {
+	mu  sync.Mutex
+	sql []string
+}
+
+func (s *statements) add(sql string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sql = append(s.sql, sql)
+}
+
+// A recordingDB records the statements it executes to any collector found in
+// the context of each call. Only statements run by Exec and ExecTx are
+// recorded; reads made by Scan and Query pass through unrecorded.
+type recordingDB struct {
+	xsql.DB
+}
+
+func record(ctx context.Context, ql ...xsql.Query) {
+	s, ok := ctx.Value(statementsKey{}).(*statements)
+	if !ok {
+		return
+	}
+	for _, q := range ql {
+		// Literals may contain credentials, for example a role's password.
+		stmt, _ := xsql.Redact(q)
+		s.add(stmt)
+	}
+}
+
+func (r recordingDB) Exec(ctx context.Context, q xsql.Query) error {
+	record(ctx, q)
+	return r.DB.Exec(ctx, q)
+}
+
+func (r recordingDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	record(ctx, ql...)
+	return r.DB.ExecTx(ctx, ql)
+}
+
+func (r recordingDB) Unwrap() xsql.DB {
+	return r.DB
+}
+
+// A DecisionConnecter wraps the ExternalClients produced by another connecter,
+// recording a decision for each mutating operation they perform.
+type DecisionConnecter struct {
+	managed.ExternalConnecter
+	sink DecisionSink
+}
+
+// NewDecisionConnecter returns an ExternalConnecter that records each Create,
+// Update, and Delete call to the clients produced by the supplied connecter
+// to the supplied sink.
+func NewDecisionConnecter(c managed.ExternalConnecter, s DecisionSink) *DecisionConnecter {
+	return &DecisionConnecter{ExternalConnecter: c, sink: s}
+}
+
+// Connect to the external system.
+func (c *DecisionConnecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	e, err := c.ExternalConnecter.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+	return &decisionClient{ExternalClient: e, sink: c.sink}, nil
+}
+
+type decisionClient struct {
+	managed.ExternalClient
+	sink DecisionSink
+}
+
+// do runs the supplied action and records it as a decision. Failing to record
+// a decision does not fail the action.
+func (c *decisionClient) do(ctx context.Context, mg resource.Managed, action string, fn func(ctx context.Context) error) error {
+	s := &statements{}
+	err := fn(context.WithValue(ctx, statementsKey{}, s))
+
+	d := Decision{
+		Time:   time.Now().UTC(),
+		Kind:   kind(mg),
+		Name:   mg.GetName(),
+		Action: action,
+		SQL:    s.sql,
+		Result: ResultSuccess,
+	}
+	if err != nil {
+		d.Result = ResultFailure
+		d.Error = err.Error()
+	}
+	_ = c.sink.Record(d)
+
+	return err
+}
+
+// kind returns the kind of the supplied managed resource. Objects read from
+// the API server don't always have their kind set, so we fall back to the
+// name of the type.
+func kind(mg resource.Managed) string {
+	if k := mg.GetObjectKind().GroupVersionKind().Kind; k != "" {
+		return k
+	}
+	return reflect.TypeOf(mg).Elem().Name()
+}
+
+func (c *decisionClient) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	var cr managed.ExternalCreation
+	err := c.do(ctx, mg, ActionCreate, func(ctx context.Context) error {
+		var err error
+		cr, err = c.ExternalClient.Create(ctx, mg)
+		return err
+	})
+	return cr, err
+}
+
+func (c *decisionClient) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	var u managed.ExternalUpdate
+	err := c.do(ctx, mg, ActionUpdate, func(ctx context.Context) error {
+		var err error
+		u, err = c.ExternalClient.Update(ctx, mg)
+		return err
+	})
+	return u, err
+}
+
+func (c *decisionClient) Delete(ctx context.Context, mg resource.Managed) error {
+	return c.do(ctx, mg, ActionDelete, func(ctx context.Context) error {
+		return c.ExternalClient.Delete(ctx, mg)
+	})
+}
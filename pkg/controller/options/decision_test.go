@@ -0,0 +1,133 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestDecisionConnecter(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		reads  bool
+		want   Decision
+	}{
+		"CreateSucceeded": {
+			reason: "A successful create should be recorded with the SQL it ran",
+			want: Decision{
+				Kind:   "Managed",
+				Name:   "cool",
+				Action: ActionCreate,
+				SQL:    []string{`CREATE EXTENSION "cool"`},
+				Result: ResultSuccess,
+			},
+		},
+		"ReadsNotRecorded": {
+			reason: "Queries that only read should not be recorded with the SQL the action ran",
+			reads:  true,
+			want: Decision{
+				Kind:   "Managed",
+				Name:   "cool",
+				Action: ActionCreate,
+				SQL:    []string{`CREATE EXTENSION "cool"`},
+				Result: ResultSuccess,
+			},
+		},
+		"CreateFailed": {
+			reason: "A failed create should be recorded with its error",
+			err:    errBoom,
+			want: Decision{
+				Kind:   "Managed",
+				Name:   "cool",
+				Action: ActionCreate,
+				SQL:    []string{`CREATE EXTENSION "cool"`},
+				Result: ResultFailure,
+				Error:  "boom",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			o := Options{Decisions: NewJSONSink(buf)}
+
+			db := o.DB(&mockDB{exec: func(ctx context.Context, q xsql.Query) error { return tc.err }})
+			e := managed.ExternalClientFns{
+				CreateFn: func(ctx context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+					if tc.reads {
+						var name string
+						_ = db.Scan(ctx, xsql.Query{String: "SELECT extname FROM pg_extension"}, &name)
+						_, _ = db.Query(ctx, xsql.Query{String: "SELECT name FROM pg_available_extensions"})
+					}
+					return managed.ExternalCreation{}, db.Exec(ctx, xsql.Query{String: `CREATE EXTENSION "cool"`})
+				},
+			}
+			c := NewDecisionConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return e, nil
+			}), o.Decisions)
+
+			mg := &fake.Managed{}
+			mg.SetName("cool")
+			ec, err := c.Connect(context.Background(), mg)
+			if err != nil {
+				t.Fatalf("\n%s\nc.Connect(...): %s", tc.reason, err)
+			}
+			_, _ = ec.Create(context.Background(), mg)
+
+			got := Decision{}
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("\n%s\njson.Unmarshal(...): %s", tc.reason, err)
+			}
+			if got.Time.IsZero() || time.Since(got.Time) > time.Minute {
+				t.Errorf("\n%s\nDecision.Time: want about now, got %s", tc.reason, got.Time)
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreFields(Decision{}, "Time")); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want decision, +got decision:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+type mockDB struct {
+	xsql.DB
+	exec func(ctx context.Context, q xsql.Query) error
+}
+
+func (m *mockDB) Exec(ctx context.Context, q xsql.Query) error { return m.exec(ctx, q) }
+
+func (m *mockDB) Scan(_ context.Context, _ xsql.Query, _ ...interface{}) error { return nil }
+
+func (m *mockDB) Query(_ context.Context, _ xsql.Query) (*sql.Rows, error) { return nil, nil }
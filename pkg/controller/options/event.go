@@ -0,0 +1,160 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+const reasonReconcileSummary event.Reason = "ReconcileSummary"
+
+// An AggregatingRecorder counts the Normal events it is asked to record for
+// the managed resources of one kind, and periodically records a single
+// summary event in their place against each ProviderConfig they use. Warning
+// events are recorded individually, as well as being counted, so that the
+// failures of each resource remain visible.
+type AggregatingRecorder struct {
+	wrapped  event.Recorder
+	kind     string
+	newPC    func() resource.ProviderConfig
+	interval time.Duration
+
+	// The summary is shared by every recorder derived by WithAnnotations.
+	*summary
+}
+
+type summary struct {
+	mu sync.Mutex
+
+	// Counts of events, by ProviderConfig name.
+	counts map[string]*tally
+}
+
+type tally struct {
+	reasons  map[event.Reason]int
+	warnings int
+}
+
+// NewAggregatingRecorder returns a Recorder that records a summary of the
+// events of the supplied kind of managed resource to each ProviderConfig
+// every interval.
+func NewAggregatingRecorder(r event.Recorder, kind string, newPC func() resource.ProviderConfig, interval time.Duration) *AggregatingRecorder {
+	return &AggregatingRecorder{
+		wrapped:  r,
+		kind:     kind,
+		newPC:    newPC,
+		interval: interval,
+		summary:  &summary{counts: map[string]*tally{}},
+	}
+}
+
+// Event counts the supplied event, to be included in the next summary.
+// Warning events, and events for objects that don't reference a
+// ProviderConfig, are also recorded immediately.
+func (r *AggregatingRecorder) Event(obj runtime.Object, e event.Event) {
+	mg, ok := obj.(resource.Managed)
+	if !ok || mg.GetProviderConfigReference() == nil {
+		r.wrapped.Event(obj, e)
+		return
+	}
+	if e.Type == event.TypeWarning {
+		r.wrapped.Event(obj, e)
+	}
+
+	name := mg.GetProviderConfigReference().Name
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.counts[name]
+	if !ok {
+		t = &tally{reasons: map[event.Reason]int{}}
+		r.counts[name] = t
+	}
+	t.reasons[e.Reason]++
+	if e.Type == event.TypeWarning {
+		t.warnings++
+	}
+}
+
+// WithAnnotations returns a recorder that adds the supplied annotations to the
+// events it records individually. It shares this recorder's summary.
+func (r *AggregatingRecorder) WithAnnotations(keysAndValues ...string) event.Recorder {
+	return &AggregatingRecorder{
+		wrapped:  r.wrapped.WithAnnotations(keysAndValues...),
+		kind:     r.kind,
+		newPC:    r.newPC,
+		interval: r.interval,
+		summary:  r.summary,
+	}
+}
+
+// Flush records a summary of all events counted since the last flush, if any,
+// to each ProviderConfig. A summary that counts any Warning events is itself
+// a Warning event.
+func (r *AggregatingRecorder) Flush() {
+	r.mu.Lock()
+	counts := r.counts
+	r.counts = map[string]*tally{}
+	r.mu.Unlock()
+
+	for name, t := range counts {
+		reasons := make([]string, 0, len(t.reasons))
+		for reason := range t.reasons {
+			reasons = append(reasons, string(reason))
+		}
+		sort.Strings(reasons)
+
+		s := make([]string, len(reasons))
+		for i, reason := range reasons {
+			s[i] = fmt.Sprintf("%d %s", t.reasons[event.Reason(reason)], reason)
+		}
+
+		e := event.Normal(reasonReconcileSummary, fmt.Sprintf("%s events in the last %s: %s", r.kind, r.interval, strings.Join(s, ", ")))
+		if t.warnings > 0 {
+			e.Type = event.TypeWarning
+		}
+
+		pc := r.newPC()
+		pc.SetName(name)
+		r.wrapped.Event(pc, e)
+	}
+}
+
+// Start flushing a summary every interval until the supplied context is done.
+func (r *AggregatingRecorder) Start(ctx context.Context) error {
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.Flush()
+			return nil
+		case <-t.C:
+			r.Flush()
+		}
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+)
+
+type recordedEvent struct {
+	obj runtime.Object
+	e   event.Event
+}
+
+type recordingRecorder struct {
+	events *[]recordedEvent
+}
+
+func (r recordingRecorder) Event(obj runtime.Object, e event.Event) {
+	*r.events = append(*r.events, recordedEvent{obj: obj, e: e})
+}
+
+func (r recordingRecorder) WithAnnotations(_ ...string) event.Recorder { return r }
+
+func TestAggregatingRecorder(t *testing.T) {
+	pc := func(name string) resource.ProviderConfig {
+		p := &fake.ProviderConfig{}
+		p.SetName(name)
+		return p
+	}
+	using := func(name string) resource.Managed {
+		mg := &fake.Managed{}
+		mg.SetProviderConfigReference(&xpv1.Reference{Name: name})
+		return mg
+	}
+	warning := event.Warning("CannotCreateExternalResource", errors.New("boom"))
+	asWarning := func(e event.Event) event.Event {
+		e.Type = event.TypeWarning
+		return e
+	}
+
+	type recorded struct {
+		obj runtime.Object
+		e   event.Event
+	}
+
+	cases := map[string]struct {
+		reason string
+		events []recorded
+		want   []recordedEvent
+	}{
+		"NoEvents": {
+			reason: "No summary should be recorded if no events were recorded",
+			want:   []recordedEvent{},
+		},
+		"Summary": {
+			reason: "Normal events should be summarised by reason to the ProviderConfig their resource uses",
+			events: []recorded{
+				{obj: using("cool"), e: event.Normal("CreatedExternalResource", "created")},
+				{obj: using("cool"), e: event.Normal("CreatedExternalResource", "created")},
+				{obj: using("cool"), e: event.Normal("UpdatedExternalResource", "updated")},
+			},
+			want: []recordedEvent{{
+				obj: pc("cool"),
+				e: event.Normal(reasonReconcileSummary,
+					"Database events in the last 1m0s: 2 CreatedExternalResource, 1 UpdatedExternalResource"),
+			}},
+		},
+		"Warnings": {
+			reason: "Warning events should be recorded individually, and make the summary a Warning",
+			events: []recorded{
+				{obj: using("cool"), e: event.Normal("CreatedExternalResource", "created")},
+				{obj: using("cool"), e: warning},
+			},
+			want: []recordedEvent{
+				{obj: using("cool"), e: warning},
+				{
+					obj: pc("cool"),
+					e: asWarning(event.Normal(reasonReconcileSummary,
+						"Database events in the last 1m0s: 1 CannotCreateExternalResource, 1 CreatedExternalResource")),
+				},
+			},
+		},
+		"NoProviderConfig": {
+			reason: "Events for objects that don't reference a ProviderConfig should be recorded individually",
+			events: []recorded{
+				{obj: &fake.Managed{}, e: event.Normal("CreatedExternalResource", "created")},
+			},
+			want: []recordedEvent{
+				{obj: &fake.Managed{}, e: event.Normal("CreatedExternalResource", "created")},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := []recordedEvent{}
+			newPC := func() resource.ProviderConfig { return &fake.ProviderConfig{} }
+			r := NewAggregatingRecorder(recordingRecorder{events: &got}, "Database", newPC, 1*time.Minute)
+			for _, e := range tc.events {
+				r.WithAnnotations("external-name", "example").Event(e.obj, e.e)
+			}
+			r.Flush()
+
+			// A second flush should record nothing; the counts were reset.
+			r.Flush()
+
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(recordedEvent{})); diff != "" {
+				t.Errorf("\n%s\nr.Flush(): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// TypeProviderFrozen resources will not be created, updated, or deleted
+// because the provider is frozen.
+const TypeProviderFrozen xpv1.ConditionType = "ProviderFrozen"
+
+// errFrozen is returned instead of creating, updating, or deleting a resource.
+// Reporting success would cause the managed reconciler to record events that
+// claim the operation happened.
+const errFrozen = "provider is frozen"
+
+// Reasons a resource is or is not frozen.
+const (
+	ReasonFrozen xpv1.ConditionReason = "Frozen"
+	ReasonThawed xpv1.ConditionReason = "Thawed"
+)
+
+// Frozen returns a condition that indicates a mutating operation was skipped
+// because the provider is frozen.
+func Frozen() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeProviderFrozen,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonFrozen,
+		Message:            "The provider is frozen; external resources will be observed but not created, updated, or deleted",
+	}
+}
+
+// Thawed returns a condition that indicates the provider is no longer frozen.
+func Thawed() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeProviderFrozen,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonThawed,
+	}
+}
+
+// A FreezeConnecter wraps the ExternalClients produced by another connecter,
+// skipping all mutating operations when frozen. Skipped operations fail, so
+// that the managed reconciler backs off and reports why rather than recording
+// that they succeeded.
+type FreezeConnecter struct {
+	managed.ExternalConnecter
+	frozen bool
+}
+
+// NewFreezeConnecter returns an ExternalConnecter that skips Create, Update,
+// and Delete calls to the clients produced by the supplied connecter when
+// frozen is true.
+func NewFreezeConnecter(c managed.ExternalConnecter, frozen bool) *FreezeConnecter {
+	return &FreezeConnecter{ExternalConnecter: c, frozen: frozen}
+}
+
+// Connect to the external system.
+func (c *FreezeConnecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	e, err := c.ExternalConnecter.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+	return &freezeClient{ExternalClient: e, frozen: c.frozen}, nil
+}
+
+type freezeClient struct {
+	managed.ExternalClient
+	frozen bool
+}
+
+func (c *freezeClient) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	// Conditions can't be removed, so we mark any resource we previously
+	// skipped as thawed rather than leave a stale frozen condition behind.
+	if !c.frozen && mg.GetCondition(TypeProviderFrozen).Status == corev1.ConditionTrue {
+		mg.SetConditions(Thawed())
+	}
+	return c.ExternalClient.Observe(ctx, mg)
+}
+
+func (c *freezeClient) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	if c.frozen {
+		mg.SetConditions(Frozen())
+		return managed.ExternalCreation{}, errors.New(errFrozen)
+	}
+	return c.ExternalClient.Create(ctx, mg)
+}
+
+func (c *freezeClient) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	if c.frozen {
+		mg.SetConditions(Frozen())
+		return managed.ExternalUpdate{}, errors.New(errFrozen)
+	}
+	return c.ExternalClient.Update(ctx, mg)
+}
+
+func (c *freezeClient) Delete(ctx context.Context, mg resource.Managed) error {
+	if c.frozen {
+		mg.SetConditions(Frozen())
+		return errors.New(errFrozen)
+	}
+	return c.ExternalClient.Delete(ctx, mg)
+}
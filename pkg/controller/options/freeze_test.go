@@ -0,0 +1,230 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestFreezeConnecter(t *testing.T) {
+	type want struct {
+		calls  []string
+		err    error
+		frozen corev1.ConditionStatus
+	}
+
+	cases := map[string]struct {
+		reason string
+		frozen bool
+		mg     resource.Managed
+		want   want
+	}{
+		"Frozen": {
+			reason: "No mutating calls should be made when the provider is frozen",
+			frozen: true,
+			mg:     &fake.Managed{},
+			want: want{
+				calls:  []string{"Observe"},
+				err:    errors.New(errFrozen),
+				frozen: corev1.ConditionTrue,
+			},
+		},
+		"NotFrozen": {
+			reason: "All calls should be made when the provider is not frozen",
+			frozen: false,
+			mg:     &fake.Managed{},
+			want: want{
+				calls:  []string{"Observe", "Create", "Update", "Delete"},
+				frozen: corev1.ConditionUnknown,
+			},
+		},
+		"Thawed": {
+			reason: "A previously frozen resource should be marked as thawed",
+			frozen: false,
+			mg: func() resource.Managed {
+				mg := &fake.Managed{}
+				mg.SetConditions(Frozen())
+				return mg
+			}(),
+			want: want{
+				calls:  []string{"Observe", "Create", "Update", "Delete"},
+				frozen: corev1.ConditionFalse,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			calls := []string{}
+			e := managed.ExternalClientFns{
+				ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					calls = append(calls, "Observe")
+					return managed.ExternalObservation{}, nil
+				},
+				CreateFn: func(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+					calls = append(calls, "Create")
+					return managed.ExternalCreation{}, nil
+				},
+				UpdateFn: func(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+					calls = append(calls, "Update")
+					return managed.ExternalUpdate{}, nil
+				},
+				DeleteFn: func(_ context.Context, _ resource.Managed) error {
+					calls = append(calls, "Delete")
+					return nil
+				},
+			}
+			c := NewFreezeConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return e, nil
+			}), tc.frozen)
+
+			ctx := context.Background()
+			ec, err := c.Connect(ctx, tc.mg)
+			if err != nil {
+				t.Fatalf("\n%s\nc.Connect(...): unexpected error: %s", tc.reason, err)
+			}
+			_, _ = ec.Observe(ctx, tc.mg)
+			_, cerr := ec.Create(ctx, tc.mg)
+			_, uerr := ec.Update(ctx, tc.mg)
+			derr := ec.Delete(ctx, tc.mg)
+
+			for op, err := range map[string]error{"Create": cerr, "Update": uerr, "Delete": derr} {
+				if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+					t.Errorf("\n%s\nec.%s(...): -want error, +got error:\n%s\n", tc.reason, op, diff)
+				}
+			}
+
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("\n%s\nExternalClient calls: -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.frozen, tc.mg.GetCondition(TypeProviderFrozen).Status); diff != "" {
+				t.Errorf("\n%s\nProviderFrozen condition: -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFreezeConnecterReconcile(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		exists   bool
+		deleting bool
+	}{
+		"Create": {
+			reason: "A frozen create should not be reported as successful",
+		},
+		"Update": {
+			reason: "A frozen update should not be reported as successful",
+			exists: true,
+		},
+		"Delete": {
+			reason:   "A frozen delete should not be reported as successful",
+			exists:   true,
+			deleting: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mutated := false
+			e := managed.ExternalClientFns{
+				ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{ResourceExists: tc.exists, ResourceUpToDate: false}, nil
+				},
+				CreateFn: func(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+					mutated = true
+					return managed.ExternalCreation{}, nil
+				},
+				UpdateFn: func(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+					mutated = true
+					return managed.ExternalUpdate{}, nil
+				},
+				DeleteFn: func(_ context.Context, _ resource.Managed) error {
+					mutated = true
+					return nil
+				},
+			}
+			c := NewFreezeConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return e, nil
+			}), true)
+
+			var got *fake.Managed
+			m := &fake.Manager{
+				Client: &test.MockClient{
+					MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+						if tc.deleting {
+							now := metav1.Now()
+							obj.(*fake.Managed).SetDeletionTimestamp(&now)
+						}
+						return nil
+					},
+					MockStatusUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+						got = obj.(*fake.Managed)
+						return nil
+					},
+				},
+				Scheme: fake.SchemeWith(&fake.Managed{}),
+			}
+
+			events := []recordedEvent{}
+			r := managed.NewReconciler(m, resource.ManagedKind(fake.GVK(&fake.Managed{})),
+				managed.WithInitializers(),
+				managed.WithReferenceResolver(managed.ReferenceResolverFn(func(_ context.Context, _ resource.Managed) error { return nil })),
+				managed.WithExternalConnecter(c),
+				managed.WithConnectionPublishers(),
+				managed.WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error { return nil }}),
+				managed.WithRecorder(recordingRecorder{events: &events}),
+			)
+
+			if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "cool"}}); err != nil {
+				t.Fatalf("\n%s\nr.Reconcile(...): unexpected error: %s", tc.reason, err)
+			}
+			if mutated {
+				t.Errorf("\n%s\nr.Reconcile(...): want no mutating calls while frozen", tc.reason)
+			}
+			for _, re := range events {
+				if re.e.Type == event.TypeNormal {
+					t.Errorf("\n%s\nr.Reconcile(...): want no success events while frozen, got %q: %s", tc.reason, re.e.Reason, re.e.Message)
+				}
+			}
+			if len(events) == 0 {
+				t.Errorf("\n%s\nr.Reconcile(...): want a warning event explaining the frozen operation", tc.reason)
+			}
+			if got == nil {
+				t.Fatalf("\n%s\nr.Reconcile(...): want status to be updated", tc.reason)
+			}
+			if diff := cmp.Diff(corev1.ConditionTrue, got.GetCondition(TypeProviderFrozen).Status); diff != "" {
+				t.Errorf("\n%s\nProviderFrozen condition: -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// An IntervalReconciler wraps a managed resource reconciler, choosing when a
+// resource is next reconciled based on its state. A healthy, up-to-date
+// resource rarely changes, so it can be re-observed infrequently to reduce
+// load on the server. A resource that could not be created doesn't exist yet,
+// so it should be retried promptly.
+type IntervalReconciler struct {
+	reconcile.Reconciler
+	kube    client.Reader
+	newObj  func() resource.Managed
+	observe time.Duration
+	retry   time.Duration
+}
+
+// NewIntervalReconciler returns a reconciler that requeues resources that are
+// ready and synced after the supplied observe interval, and resources that
+// failed to be created after the supplied retry interval. The supplied
+// reconciler's result is used unchanged for resources in any other state, or
+// when the relevant interval is zero.
+func NewIntervalReconciler(r reconcile.Reconciler, kube client.Reader, newObj func() resource.Managed, observe, retry time.Duration) *IntervalReconciler {
+	return &IntervalReconciler{Reconciler: r, kube: kube, newObj: newObj, observe: observe, retry: retry}
+}
+
+// Reconcile the supplied request.
+func (r *IntervalReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	res, err := r.Reconciler.Reconcile(ctx, req)
+	if err != nil || (r.observe == 0 && r.retry == 0) {
+		return res, err
+	}
+
+	mg := r.newObj()
+	if err := r.kube.Get(ctx, req.NamespacedName, mg); err != nil {
+		// We can't tell what state the resource is in, so fall back to the
+		// usual behaviour.
+		return res, nil
+	}
+
+	if i := r.interval(mg, res); i != 0 {
+		return reconcile.Result{RequeueAfter: i}, nil
+	}
+	return res, nil
+}
+
+// interval returns how long to wait before reconciling the supplied resource
+// again, or zero if the supplied result should be used.
+func (r *IntervalReconciler) interval(mg resource.Managed, res reconcile.Result) time.Duration {
+	ready := mg.GetCondition(xpv1.TypeReady)
+	synced := mg.GetCondition(xpv1.TypeSynced)
+
+	switch {
+	case ready.Status == corev1.ConditionTrue && synced.Status == corev1.ConditionTrue:
+		return r.observe
+
+	// A resource that isn't ready and failed to reconcile is one that could
+	// not be created. We only replace the usual rate limited requeue, not a
+	// backoff another reconciler chose, for example because the server has
+	// too many connections.
+	case ready.Status != corev1.ConditionTrue && synced.Reason == xpv1.ReasonReconcileError && res.Requeue:
+		return r.retry
+	}
+	return 0
+}
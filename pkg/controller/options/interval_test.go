@@ -0,0 +1,169 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestIntervalReconciler(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	observe := 10 * time.Minute
+	retry := 5 * time.Second
+
+	type args struct {
+		result     reconcile.Result
+		err        error
+		getErr     error
+		conditions []xpv1.Condition
+		observe    time.Duration
+		retry      time.Duration
+	}
+
+	type want struct {
+		result reconcile.Result
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Healthy": {
+			reason: "A ready, synced resource should be re-observed after the observe interval",
+			args: args{
+				result:     reconcile.Result{RequeueAfter: time.Minute},
+				conditions: []xpv1.Condition{xpv1.Available(), xpv1.ReconcileSuccess()},
+				observe:    observe,
+				retry:      retry,
+			},
+			want: want{result: reconcile.Result{RequeueAfter: observe}},
+		},
+		"CreateFailed": {
+			reason: "A resource that failed to be created should be retried after the retry interval",
+			args: args{
+				result:     reconcile.Result{Requeue: true},
+				conditions: []xpv1.Condition{xpv1.ReconcileError(errBoom)},
+				observe:    observe,
+				retry:      retry,
+			},
+			want: want{result: reconcile.Result{RequeueAfter: retry}},
+		},
+		"UpdateFailed": {
+			reason: "A ready resource that failed to be updated should use the usual requeue",
+			args: args{
+				result:     reconcile.Result{Requeue: true},
+				conditions: []xpv1.Condition{xpv1.Available(), xpv1.ReconcileError(errBoom)},
+				observe:    observe,
+				retry:      retry,
+			},
+			want: want{result: reconcile.Result{Requeue: true}},
+		},
+		"Creating": {
+			reason: "A resource that was just created should use the usual requeue",
+			args: args{
+				result:     reconcile.Result{Requeue: true},
+				conditions: []xpv1.Condition{xpv1.Creating(), xpv1.ReconcileSuccess()},
+				observe:    observe,
+				retry:      retry,
+			},
+			want: want{result: reconcile.Result{Requeue: true}},
+		},
+		"BackoffChosen": {
+			reason: "A backoff another reconciler chose for a resource that failed to be created should be preserved",
+			args: args{
+				result:     reconcile.Result{RequeueAfter: 2 * time.Minute},
+				conditions: []xpv1.Condition{xpv1.ReconcileError(errBoom)},
+				observe:    observe,
+				retry:      retry,
+			},
+			want: want{result: reconcile.Result{RequeueAfter: 2 * time.Minute}},
+		},
+		"ObserveDisabled": {
+			reason: "The usual requeue should be used for a healthy resource when no observe interval is configured",
+			args: args{
+				result:     reconcile.Result{RequeueAfter: time.Minute},
+				conditions: []xpv1.Condition{xpv1.Available(), xpv1.ReconcileSuccess()},
+				retry:      retry,
+			},
+			want: want{result: reconcile.Result{RequeueAfter: time.Minute}},
+		},
+		"RetryDisabled": {
+			reason: "The usual requeue should be used for a failed create when no retry interval is configured",
+			args: args{
+				result:     reconcile.Result{Requeue: true},
+				conditions: []xpv1.Condition{xpv1.ReconcileError(errBoom)},
+				observe:    observe,
+			},
+			want: want{result: reconcile.Result{Requeue: true}},
+		},
+		"ErrGet": {
+			reason: "The usual requeue should be used if the resource can't be read",
+			args: args{
+				result:  reconcile.Result{RequeueAfter: time.Minute},
+				getErr:  errBoom,
+				observe: observe,
+				retry:   retry,
+			},
+			want: want{result: reconcile.Result{RequeueAfter: time.Minute}},
+		},
+		"ErrReconcile": {
+			reason: "Errors returned by the wrapped reconciler should be returned unchanged",
+			args: args{
+				err:     errBoom,
+				observe: observe,
+				retry:   retry,
+			},
+			want: want{err: errBoom},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			inner := reconcile.Func(func(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+				return tc.args.result, tc.args.err
+			})
+			get := func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+				obj.(resource.Managed).SetConditions(tc.args.conditions...)
+				return tc.args.getErr
+			}
+			r := NewIntervalReconciler(inner, &test.MockClient{MockGet: get}, func() resource.Managed { return &fake.Managed{} }, tc.args.observe, tc.args.retry)
+
+			got, err := r.Reconcile(context.Background(), reconcile.Request{})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package options contains configuration shared by all SQL controllers.
+package options
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// Options configure the behaviour of all SQL controllers.
+type Options struct {
+	// Logger used by the controllers.
+	Logger logging.Logger
+
+	// LogSQL causes every statement executed against a database to be logged
+	// at debug level, with any parameters or literals redacted.
+	LogSQL bool
+
+	// Frozen causes all controllers to observe external resources without
+	// ever creating, updating, or deleting them.
+	Frozen bool
+
+	// EventSummaryInterval causes managed resource controllers to record a
+	// periodic summary event to each ProviderConfig in place of individual
+	// Normal events for each managed resource. Warning events are always
+	// recorded individually. Individual events are recorded when it is zero.
+	EventSummaryInterval time.Duration
+
+	// ConnectionLimitBackoff is how long controllers wait before retrying a
+	// managed resource that could not be reconciled because the server had
+	// too many connections. The usual rate limited backoff is used when it
+	// is zero.
+	ConnectionLimitBackoff time.Duration
+
+	// RepeatedFailureBackoff is how long the Extension controller waits
+	// before retrying a resource that failed to reconcile with the same error
+	// as the last time it was reconciled. The wait doubles with each further
+	// identical failure, up to MaxRepeatedFailureBackoff. The usual rate
+	// limited backoff is used when it is zero.
+	RepeatedFailureBackoff time.Duration
+
+	// ObserveInterval is how long the Extension controller waits before
+	// re-observing a resource that is ready and synced. The usual poll
+	// interval is used when it is zero.
+	ObserveInterval time.Duration
+
+	// CreateRetryInterval is how long the Extension controller waits before
+	// retrying a resource that failed to be created. The usual rate limited
+	// backoff is used when it is zero.
+	CreateRetryInterval time.Duration
+
+	// SlowOperationThreshold is how long the Extension controller lets an
+	// operation on an external resource take before logging a warning and
+	// recording a SlowOperation event. Slow operations are not warned about
+	// when it is zero.
+	SlowOperationThreshold time.Duration
+
+	// QueryTimeout is how long a statement may run when the caller has not
+	// set a deadline. Statements are not bounded when it is zero.
+	QueryTimeout time.Duration
+
+	// SpecEditSettle is how long the Extension controller waits after a spec
+	// is edited before reconciling it, so that several edits made in quick
+	// succession are reconciled once. Edits are reconciled immediately when
+	// it is zero.
+	SpecEditSettle time.Duration
+
+	// Decisions records each action controllers take to reconcile managed
+	// resources, if set.
+	Decisions DecisionSink
+
+	// DDLRateLimiter limits the rate at which controllers execute statements
+	// that change a server, per the ProviderConfig they use.
+	DDLRateLimiter *DDLRateLimiter
+
+	// CapabilitiesInterval is how often the capabilities of the server each
+	// PostgreSQL ProviderConfig connects to are detected and reported in its
+	// status. Capabilities are not reported when it is zero.
+	CapabilitiesInterval time.Duration
+
+	// ExtensionInventory is the ConfigMap in which to maintain a summary of
+	// all Extension managed resources, if set.
+	ExtensionInventory *types.NamespacedName
+}
+
+// DB decorates the supplied DB client per these options.
+func (o Options) DB(db xsql.DB) xsql.DB {
+	if o.QueryTimeout > 0 {
+		db = xsql.NewTimeoutDB(db, o.QueryTimeout)
+	}
+	db = xsql.NewInstrumentedDB(db)
+	if o.LogSQL {
+		db = xsql.NewLoggingDB(db, o.Logger)
+	}
+	if o.Decisions != nil {
+		db = recordingDB{DB: db}
+	}
+	return db
+}
+
+// ExternalConnecter decorates the supplied ExternalConnecter per these options.
+func (o Options) ExternalConnecter(c managed.ExternalConnecter) managed.ExternalConnecter {
+	if o.Decisions != nil {
+		c = NewDecisionConnecter(c, o.Decisions)
+	}
+	return NewFreezeConnecter(c, o.Frozen)
+}
+
+// Recorder decorates the supplied event recorder for a controller of the
+// supplied kind per these options. Any summary events are recorded against the
+// ProviderConfigs returned by newPC, because they don't concern a single
+// resource.
+func (o Options) Recorder(mgr ctrl.Manager, gvk schema.GroupVersionKind, newPC func() resource.ProviderConfig, r event.Recorder) (event.Recorder, error) {
+	if o.EventSummaryInterval == 0 {
+		return r, nil
+	}
+
+	ar := NewAggregatingRecorder(r, gvk.Kind, newPC, o.EventSummaryInterval)
+	return ar, mgr.Add(ar)
+}
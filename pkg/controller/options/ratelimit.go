@@ -0,0 +1,98 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+const errWaitRateLimit = "cannot wait for DDL rate limit"
+
+// A DDLRateLimiter limits the rate at which statements that change a server
+// are executed. It maintains a token bucket per key, typically the name of a
+// ProviderConfig, which is shared by every controller.
+type DDLRateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*rate.Limiter
+}
+
+// NewDDLRateLimiter returns a DDLRateLimiter with no buckets.
+func NewDDLRateLimiter() *DDLRateLimiter {
+	return &DDLRateLimiter{buckets: make(map[string]*rate.Limiter)}
+}
+
+// DB returns a DB client that waits for a token from the bucket for the
+// supplied key before each Exec or ExecTx. The bucket is reconfigured if the
+// supplied rate or burst have changed. The supplied DB is returned unmodified
+// if the limiter is nil, or the supplied rate is not positive.
+func (l *DDLRateLimiter) DB(key string, perSecond, burst int, db xsql.DB) xsql.DB {
+	if l == nil || perSecond <= 0 {
+		return db
+	}
+	return rateLimitedDB{DB: db, bucket: l.bucket(key, rate.Limit(perSecond), burst)}
+}
+
+func (l *DDLRateLimiter) bucket(key string, r rate.Limit, burst int) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = rate.NewLimiter(r, burst)
+		l.buckets[key] = b
+		return b
+	}
+	if b.Limit() != r {
+		b.SetLimit(r)
+	}
+	if b.Burst() != burst {
+		b.SetBurst(burst)
+	}
+	return b
+}
+
+// A rateLimitedDB waits for a token before executing statements. Scan and
+// Query are typically used to read state, and are not rate limited.
+type rateLimitedDB struct {
+	xsql.DB
+	bucket *rate.Limiter
+}
+
+// Exec waits for a token, then executes the supplied query. It returns an
+// error without executing the query if the wait would outlast the supplied
+// context.
+func (r rateLimitedDB) Exec(ctx context.Context, q xsql.Query) error {
+	if err := r.bucket.Wait(ctx); err != nil {
+		return errors.Wrap(err, errWaitRateLimit)
+	}
+	return r.DB.Exec(ctx, q)
+}
+
+// ExecTx waits for a single token, then executes the supplied queries in a
+// transaction.
+func (r rateLimitedDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	if err := r.bucket.Wait(ctx); err != nil {
+		return errors.Wrap(err, errWaitRateLimit)
+	}
+	return r.DB.ExecTx(ctx, ql)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestDDLRateLimiter(t *testing.T) {
+	cases := map[string]struct {
+		reason     string
+		limiter    *DDLRateLimiter
+		perSecond  int
+		burst      int
+		ops        int
+		timeout    time.Duration
+		minElapsed time.Duration
+		maxElapsed time.Duration
+		wantExecs  int
+	}{
+		"NilLimiter": {
+			reason:     "A nil limiter should not throttle operations",
+			perSecond:  1,
+			burst:      1,
+			ops:        5,
+			maxElapsed: 500 * time.Millisecond,
+			wantExecs:  5,
+		},
+		"NoRate": {
+			reason:     "Operations should not be throttled when no rate is configured",
+			limiter:    NewDDLRateLimiter(),
+			ops:        5,
+			maxElapsed: 500 * time.Millisecond,
+			wantExecs:  5,
+		},
+		"Throttled": {
+			reason:     "Operations beyond the burst should be throttled to the configured rate",
+			limiter:    NewDDLRateLimiter(),
+			perSecond:  20,
+			burst:      2,
+			ops:        8,
+			minElapsed: 250 * time.Millisecond,
+			wantExecs:  8,
+		},
+		"WouldExceedDeadline": {
+			reason:    "Operations that would wait beyond their deadline should not be executed",
+			limiter:   NewDDLRateLimiter(),
+			perSecond: 1,
+			burst:     1,
+			ops:       3,
+			timeout:   100 * time.Millisecond,
+			wantExecs: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			execs := 0
+			db := tc.limiter.DB("cool", tc.perSecond, tc.burst, &mockDB{exec: func(_ context.Context, _ xsql.Query) error {
+				execs++
+				return nil
+			}})
+
+			start := time.Now()
+			for i := 0; i < tc.ops; i++ {
+				_ = db.Exec(ctx, xsql.Query{String: "CREATE ROLE cool"})
+			}
+			elapsed := time.Since(start)
+
+			if diff := cmp.Diff(tc.wantExecs, execs); diff != "" {
+				t.Errorf("\n%s\nExec(...): -want executions, +got executions:\n%s\n", tc.reason, diff)
+			}
+			if elapsed < tc.minElapsed {
+				t.Errorf("\n%s\nExec(...): want at least %s elapsed, got %s", tc.reason, tc.minElapsed, elapsed)
+			}
+			if tc.maxElapsed > 0 && elapsed > tc.maxElapsed {
+				t.Errorf("\n%s\nExec(...): want at most %s elapsed, got %s", tc.reason, tc.maxElapsed, elapsed)
+			}
+		})
+	}
+}
+
+func TestDDLRateLimiterSharesBuckets(t *testing.T) {
+	l := NewDDLRateLimiter()
+	a := l.DB("cool", 1, 1, &mockDB{exec: func(_ context.Context, _ xsql.Query) error { return nil }})
+	b := l.DB("cool", 1, 1, &mockDB{exec: func(_ context.Context, _ xsql.Query) error { return nil }})
+	other := l.DB("other", 1, 1, &mockDB{exec: func(_ context.Context, _ xsql.Query) error { return nil }})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := a.Exec(ctx, xsql.Query{}); err != nil {
+		t.Fatalf("a.Exec(...): %s", err)
+	}
+	if err := other.Exec(ctx, xsql.Query{}); err != nil {
+		t.Errorf("Clients with different keys should not share a bucket: other.Exec(...): %s", err)
+	}
+	if err := b.Exec(ctx, xsql.Query{}); err == nil {
+		t.Errorf("Clients with the same key should share a bucket: b.Exec(...): want error, got nil")
+	}
+}
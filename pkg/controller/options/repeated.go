@@ -0,0 +1,137 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// MaxRepeatedFailureBackoff is the longest a RepeatedFailureReconciler waits
+// before retrying a resource, so that resources are still re-checked
+// periodically in case someone fixes the underlying problem.
+const MaxRepeatedFailureBackoff = 30 * time.Minute
+
+// A failure is the error a resource last failed to reconcile with, and how many
+// consecutive times it has failed with it.
+type failure struct {
+	message string
+	count   int
+}
+
+// A RepeatedFailureReconciler wraps a managed resource reconciler, replacing
+// its usual rate limited requeue with a progressively longer backoff when a
+// resource fails to reconcile with the same error several times in a row.
+// Such errors, for example a missing extension control file, rarely resolve
+// themselves, so retrying quickly is wasted effort.
+type RepeatedFailureReconciler struct {
+	reconcile.Reconciler
+	kube   client.Reader
+	newObj func() resource.Managed
+	base   time.Duration
+	max    time.Duration
+
+	mx       sync.Mutex
+	failures map[string]failure
+}
+
+// NewRepeatedFailureReconciler returns a reconciler that requeues a resource
+// that failed to reconcile with the same error as last time after the supplied
+// base backoff, doubling the backoff for each further identical failure up to
+// the supplied maximum. The supplied reconciler's result is used unchanged
+// when base is zero.
+func NewRepeatedFailureReconciler(r reconcile.Reconciler, kube client.Reader, newObj func() resource.Managed, base, max time.Duration) *RepeatedFailureReconciler {
+	return &RepeatedFailureReconciler{Reconciler: r, kube: kube, newObj: newObj, base: base, max: max, failures: map[string]failure{}}
+}
+
+// Reconcile the supplied request.
+func (r *RepeatedFailureReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	res, err := r.Reconciler.Reconcile(ctx, req)
+	if err != nil || r.base == 0 {
+		return res, err
+	}
+
+	mg := r.newObj()
+	if err := r.kube.Get(ctx, req.NamespacedName, mg); err != nil {
+		if kerrors.IsNotFound(err) {
+			r.forget(req.String())
+		}
+		// We can't tell whether the resource failed, so fall back to the
+		// usual behaviour.
+		return res, nil
+	}
+
+	c := mg.GetCondition(xpv1.TypeSynced)
+	if c.Status != corev1.ConditionFalse || c.Reason != xpv1.ReasonReconcileError {
+		r.forget(req.String())
+		return res, nil
+	}
+
+	n := r.record(req.String(), c.Message)
+	if n < 2 {
+		// This is the first time the resource failed this way.
+		return res, nil
+	}
+
+	if b := r.backoff(n); b > res.RequeueAfter {
+		return reconcile.Result{RequeueAfter: b}, nil
+	}
+	return res, nil
+}
+
+// backoff returns how long to wait after the supplied number of consecutive
+// identical failures, which must be at least two.
+func (r *RepeatedFailureReconciler) backoff(n int) time.Duration {
+	b := r.base
+	for i := 2; i < n && b < r.max; i++ {
+		b *= 2
+	}
+	if b > r.max {
+		return r.max
+	}
+	return b
+}
+
+// record the supplied failure, returning how many consecutive times the
+// resource has failed with it.
+func (r *RepeatedFailureReconciler) record(key, message string) int {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	f := r.failures[key]
+	if f.message != message {
+		f = failure{message: message}
+	}
+	f.count++
+	r.failures[key] = f
+	return f.count
+}
+
+func (r *RepeatedFailureReconciler) forget(key string) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	delete(r.failures, key)
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestRepeatedFailureReconciler(t *testing.T) {
+	base := 30 * time.Second
+	max := 4 * time.Minute
+
+	errControl := errors.New("could not open extension control file")
+	errLibrary := errors.New("could not load library")
+
+	// A nil error is a successful reconcile.
+	type step struct {
+		err  error
+		want reconcile.Result
+	}
+
+	cases := map[string]struct {
+		reason string
+		base   time.Duration
+		steps  []step
+	}{
+		"Escalating": {
+			reason: "The backoff should double with each identical failure, up to the maximum",
+			base:   base,
+			steps: []step{
+				{err: errControl, want: reconcile.Result{Requeue: true}},
+				{err: errControl, want: reconcile.Result{RequeueAfter: 30 * time.Second}},
+				{err: errControl, want: reconcile.Result{RequeueAfter: 1 * time.Minute}},
+				{err: errControl, want: reconcile.Result{RequeueAfter: 2 * time.Minute}},
+				{err: errControl, want: reconcile.Result{RequeueAfter: 4 * time.Minute}},
+				{err: errControl, want: reconcile.Result{RequeueAfter: 4 * time.Minute}},
+			},
+		},
+		"DifferentError": {
+			reason: "A different error should reset the backoff",
+			base:   base,
+			steps: []step{
+				{err: errControl, want: reconcile.Result{Requeue: true}},
+				{err: errControl, want: reconcile.Result{RequeueAfter: 30 * time.Second}},
+				{err: errLibrary, want: reconcile.Result{Requeue: true}},
+				{err: errLibrary, want: reconcile.Result{RequeueAfter: 30 * time.Second}},
+			},
+		},
+		"Success": {
+			reason: "A successful reconcile should reset the backoff",
+			base:   base,
+			steps: []step{
+				{err: errControl, want: reconcile.Result{Requeue: true}},
+				{err: errControl, want: reconcile.Result{RequeueAfter: 30 * time.Second}},
+				{want: reconcile.Result{RequeueAfter: time.Hour}},
+				{err: errControl, want: reconcile.Result{Requeue: true}},
+			},
+		},
+		"Disabled": {
+			reason: "Results should be returned unchanged when no backoff is configured",
+			steps: []step{
+				{err: errControl, want: reconcile.Result{Requeue: true}},
+				{err: errControl, want: reconcile.Result{Requeue: true}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var current error
+			inner := reconcile.Func(func(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+				if current != nil {
+					return reconcile.Result{Requeue: true}, nil
+				}
+				return reconcile.Result{RequeueAfter: time.Hour}, nil
+			})
+			get := func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+				if current != nil {
+					obj.(resource.Managed).SetConditions(xpv1.ReconcileError(current))
+					return nil
+				}
+				obj.(resource.Managed).SetConditions(xpv1.ReconcileSuccess())
+				return nil
+			}
+			r := NewRepeatedFailureReconciler(inner, &test.MockClient{MockGet: get}, func() resource.Managed { return &fake.Managed{} }, tc.base, max)
+
+			for i, s := range tc.steps {
+				current = s.err
+				got, err := r.Reconcile(context.Background(), reconcile.Request{})
+				if err != nil {
+					t.Fatalf("\n%s\nr.Reconcile(...) #%d: %s", tc.reason, i, err)
+				}
+				if diff := cmp.Diff(s.want, got); diff != "" {
+					t.Errorf("\n%s\nr.Reconcile(...) #%d: -want, +got:\n%s\n", tc.reason, i, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestRepeatedFailureReconcilerDeleted(t *testing.T) {
+	inner := reconcile.Func(func(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+		return reconcile.Result{}, nil
+	})
+	nf := kerrors.NewNotFound(schema.GroupResource{}, "cool")
+	r := NewRepeatedFailureReconciler(inner, &test.MockClient{MockGet: test.NewMockGetFn(nf)}, func() resource.Managed { return &fake.Managed{} }, time.Second, time.Minute)
+	r.record(reconcile.Request{}.String(), "boom")
+
+	if _, err := r.Reconcile(context.Background(), reconcile.Request{}); err != nil {
+		t.Fatalf("r.Reconcile(...): %s", err)
+	}
+	if len(r.failures) != 0 {
+		t.Errorf("r.Reconcile(...): want failures of deleted resources to be forgotten, got %v", r.failures)
+	}
+}
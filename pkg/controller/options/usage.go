@@ -0,0 +1,69 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+const errReleaseUsage = "cannot release ProviderConfig usage"
+
+// ManagedFinalizer is the finalizer the managed reconciler adds to managed
+// resources by default.
+const ManagedFinalizer = "finalizer.managedresource.crossplane.io"
+
+// A ReleasingFinalizer wraps a managed resource finalizer, releasing the
+// managed resource's usage of its ProviderConfig once the finalizer is
+// removed. The managed reconciler removes the finalizer only once the external
+// resource has been deleted, or if it is orphaned.
+//
+// A ProviderConfigUsage is owned by the managed resource that uses the
+// ProviderConfig, so it's garbage collected once the managed resource is gone,
+// but garbage collection may lag. Releasing the usage promptly lets the
+// ProviderConfig be deleted as soon as the last resource using it is. A
+// managed resource whose external resource can't be deleted, for example
+// because its server is unreachable, keeps its usage; deleting its
+// ProviderConfig would prevent it from ever deleting its external resource.
+type ReleasingFinalizer struct {
+	resource.Finalizer
+	kube client.Client
+	of   resource.ProviderConfigUsage
+}
+
+// NewReleasingFinalizer returns a finalizer that uses the supplied finalizer,
+// and deletes the usage of the supplied kind after removing it.
+func NewReleasingFinalizer(f resource.Finalizer, kube client.Client, of resource.ProviderConfigUsage) *ReleasingFinalizer {
+	return &ReleasingFinalizer{Finalizer: f, kube: kube, of: of}
+}
+
+// RemoveFinalizer from the supplied managed resource, then release its usage
+// of its ProviderConfig.
+func (f *ReleasingFinalizer) RemoveFinalizer(ctx context.Context, obj resource.Object) error {
+	if err := f.Finalizer.RemoveFinalizer(ctx, obj); err != nil {
+		return err
+	}
+
+	// Usages are named for the UID of the managed resource that owns them.
+	pcu := f.of.DeepCopyObject().(resource.ProviderConfigUsage)
+	pcu.SetName(string(obj.GetUID()))
+	return errors.Wrap(resource.IgnoreNotFound(f.kube.Delete(ctx, pcu)), errReleaseUsage)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestReleasingFinalizer(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	mg := &fake.Managed{}
+	mg.SetUID(types.UID("cool-uid"))
+
+	type want struct {
+		removed  bool
+		released string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		removeErr error
+		deleteErr error
+		want      want
+	}{
+		"Released": {
+			reason: "The usage of a managed resource should be released once its finalizer is removed",
+			want:   want{removed: true, released: "cool-uid"},
+		},
+		"AlreadyReleased": {
+			reason:    "A usage that was already released should not cause an error",
+			deleteErr: kerrors.NewNotFound(schema.GroupResource{}, "cool-uid"),
+			want:      want{removed: true, released: "cool-uid"},
+		},
+		"ErrRemoveFinalizer": {
+			reason:    "The usage of a managed resource should not be released if its finalizer can't be removed",
+			removeErr: errBoom,
+			want:      want{removed: true, err: errBoom},
+		},
+		"ErrRelease": {
+			reason:    "Errors releasing a usage should be returned",
+			deleteErr: errBoom,
+			want:      want{removed: true, released: "cool-uid", err: errors.Wrap(errBoom, errReleaseUsage)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := want{}
+			fin := resource.FinalizerFns{
+				RemoveFinalizerFn: func(_ context.Context, _ resource.Object) error {
+					got.removed = true
+					return tc.removeErr
+				},
+			}
+			kube := &test.MockClient{MockDelete: func(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+				got.released = obj.GetName()
+				return tc.deleteErr
+			}}
+
+			got.err = NewReleasingFinalizer(fin, kube, &fake.ProviderConfigUsage{}).RemoveFinalizer(context.Background(), mg)
+			if diff := cmp.Diff(tc.want, got, test.EquateErrors(), cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nRemoveFinalizer(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
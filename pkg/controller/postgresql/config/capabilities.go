@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/options"
+)
+
+const (
+	errGetProviderConfig    = "cannot get ProviderConfig"
+	errDetectCapabilities   = "cannot detect server capabilities"
+	errUpdateProviderConfig = "cannot update ProviderConfig status"
+)
+
+// capabilitiesQuery selects the server's version, whether the role we use is
+// a superuser, whether the server is a replica, and whether our connection is
+// encrypted. is_superuser reflects any role assumed using SET SESSION
+// AUTHORIZATION.
+const capabilitiesQuery = "SELECT " +
+	"current_setting('server_version'), " +
+	"current_setting('is_superuser') = 'on', " +
+	"pg_is_in_recovery(), " +
+	"COALESCE((SELECT ssl FROM pg_stat_ssl WHERE pid = pg_backend_pid()), false)"
+
+// SetupCapabilities adds a controller that reports the capabilities of the
+// server each ProviderConfig connects to in its status, refreshing them at
+// the supplied interval.
+func SetupCapabilities(mgr ctrl.Manager, o options.Options) error {
+	name := "capabilities/" + v1alpha1.ProviderConfigGroupKind
+
+	db := func(creds map[string][]byte, database string, po ...postgresql.Option) xsql.DB {
+		return o.DB(xsql.NewRetryingDB(postgresql.New(creds, database, po...), postgresql.IsTransient))
+	}
+
+	// Our own status updates don't change a ProviderConfig's generation, so
+	// they don't trigger another detection.
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.ProviderConfig{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(&capabilities{
+			kube:     mgr.GetClient(),
+			conn:     postgresql.NewConnector(mgr.GetClient(), nil),
+			newDB:    db,
+			interval: o.CapabilitiesInterval,
+			log:      o.Logger.WithValues("controller", name),
+		})
+}
+
+// capabilities reconciles the server capabilities reported by a
+// ProviderConfig.
+type capabilities struct {
+	kube     client.Client
+	conn     *postgresql.Connector
+	newDB    func(creds map[string][]byte, database string, o ...postgresql.Option) xsql.DB
+	interval time.Duration
+	log      logging.Logger
+}
+
+// Reconcile detects the capabilities of the server the ProviderConfig
+// connects to, and reports them in its status.
+func (r *capabilities) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	pc := &v1alpha1.ProviderConfig{}
+	if err := r.kube.Get(ctx, req.NamespacedName, pc); err != nil {
+		return reconcile.Result{}, errors.Wrap(client.IgnoreNotFound(err), errGetProviderConfig)
+	}
+
+	conn, err := r.conn.Credentials(ctx, pc)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	s, err := detectCapabilities(ctx, r.newDB(conn.Credentials, conn.DatabaseOrDefault(""), conn.Options()...))
+	if err != nil {
+		r.log.Debug("Cannot detect server capabilities", "name", pc.GetName(), "error", err)
+		return reconcile.Result{}, err
+	}
+
+	pc.Status.Server = s
+	if err := r.kube.Status().Update(ctx, pc); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errUpdateProviderConfig)
+	}
+	return reconcile.Result{RequeueAfter: r.interval}, nil
+}
+
+// detectCapabilities returns the capabilities of the server the supplied DB
+// connects to.
+func detectCapabilities(ctx context.Context, db xsql.DB) (*v1alpha1.ServerCapabilities, error) {
+	s := &v1alpha1.ServerCapabilities{}
+	if err := db.Scan(ctx, xsql.Query{String: capabilitiesQuery}, &s.Version, &s.Superuser, &s.Replica, &s.TLS); err != nil {
+		return nil, errors.Wrap(err, errDetectCapabilities)
+	}
+	s.LastDetectedTime = metav1.Now()
+	return s, nil
+}
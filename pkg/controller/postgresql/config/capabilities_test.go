@@ -0,0 +1,213 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockScan func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+}
+
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error      { return nil }
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error { return nil }
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) { return nil, nil }
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return nil
+}
+
+// scanCapabilities scans the capabilities of a TLS encrypted, non-superuser
+// connection to a primary PostgreSQL 13.4 server.
+func scanCapabilities(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	*dest[0].(*string) = "13.4"
+	*dest[1].(*bool) = false
+	*dest[2].(*bool) = false
+	*dest[3].(*bool) = true
+	return nil
+}
+
+// ignoreTime ignores when capabilities were detected.
+var ignoreTime = cmpopts.IgnoreFields(v1alpha1.ServerCapabilities{}, "LastDetectedTime")
+
+func TestDetectCapabilities(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		s   *v1alpha1.ServerCapabilities
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		db     xsql.DB
+		want   want
+	}{
+		"Detected": {
+			reason: "The server's capabilities should be scanned in one query",
+			db: mockDB{MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+				if q.String != capabilitiesQuery {
+					return errors.Errorf("unexpected query %q", q.String)
+				}
+				return scanCapabilities(ctx, q, dest...)
+			}},
+			want: want{s: &v1alpha1.ServerCapabilities{Version: "13.4", TLS: true}},
+		},
+		"ErrScan": {
+			reason: "Errors detecting the server's capabilities should be returned",
+			db:     mockDB{MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom }},
+			want:   want{err: errors.Wrap(errBoom, errDetectCapabilities)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s, err := detectCapabilities(context.Background(), tc.db)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ndetectCapabilities(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.s, s, ignoreTime); diff != "" {
+				t.Errorf("\n%s\ndetectCapabilities(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCapabilitiesReconcile(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	// get returns a ProviderConfig named cool that references a Secret.
+	get := test.NewMockGetFn(nil, func(obj client.Object) error {
+		switch o := obj.(type) {
+		case *v1alpha1.ProviderConfig:
+			o.SetName("cool")
+			o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{Name: "creds", Namespace: "ns"}
+		case *corev1.Secret:
+			o.Data = map[string][]byte{xpv1.ResourceCredentialsSecretEndpointKey: []byte("db.example.org")}
+		}
+		return nil
+	})
+
+	type want struct {
+		r      reconcile.Result
+		err    error
+		status *v1alpha1.ServerCapabilities
+	}
+
+	cases := map[string]struct {
+		reason string
+		kube   func(updated **v1alpha1.ProviderConfig) client.Client
+		scan   func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+		want   want
+	}{
+		"NotFound": {
+			reason: "We should stop reconciling a ProviderConfig that no longer exists",
+			kube: func(_ **v1alpha1.ProviderConfig) client.Client {
+				return &test.MockClient{MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "cool"))}
+			},
+			want: want{},
+		},
+		"ErrDetect": {
+			reason: "Errors detecting capabilities should be returned, so that detection is retried with backoff",
+			kube: func(_ **v1alpha1.ProviderConfig) client.Client {
+				return &test.MockClient{MockGet: get}
+			},
+			scan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+			want: want{err: errors.Wrap(errBoom, errDetectCapabilities)},
+		},
+		"ErrUpdateStatus": {
+			reason: "Errors updating the ProviderConfig's status should be returned",
+			kube: func(_ **v1alpha1.ProviderConfig) client.Client {
+				return &test.MockClient{MockGet: get, MockStatusUpdate: test.NewMockStatusUpdateFn(errBoom)}
+			},
+			scan: scanCapabilities,
+			want: want{err: errors.Wrap(errBoom, errUpdateProviderConfig)},
+		},
+		"Success": {
+			reason: "Detected capabilities should be reported in the ProviderConfig's status, and refreshed after the interval",
+			kube: func(updated **v1alpha1.ProviderConfig) client.Client {
+				return &test.MockClient{
+					MockGet: get,
+					MockStatusUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+						*updated = obj.(*v1alpha1.ProviderConfig)
+						return nil
+					},
+				}
+			},
+			scan: scanCapabilities,
+			want: want{
+				r:      reconcile.Result{RequeueAfter: 10 * time.Minute},
+				status: &v1alpha1.ServerCapabilities{Version: "13.4", TLS: true},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var updated *v1alpha1.ProviderConfig
+			kube := tc.kube(&updated)
+			r := &capabilities{
+				kube:     kube,
+				conn:     postgresql.NewConnector(kube, nil),
+				newDB:    func(map[string][]byte, string, ...postgresql.Option) xsql.DB { return mockDB{MockScan: tc.scan} },
+				interval: 10 * time.Minute,
+				log:      logging.NewNopLogger(),
+			}
+
+			got, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "cool"}})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.r, got); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+
+			var status *v1alpha1.ServerCapabilities
+			if updated != nil {
+				status = updated.Status.Server
+			}
+			if diff := cmp.Diff(tc.want.status, status, ignoreTime); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want status, +got status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+const errBootstrapDB = "cannot bootstrap database"
+
+// bootstrapQueries returns the statements that bootstrap a database per the
+// supplied configuration. Each statement is idempotent.
+func bootstrapQueries(b *v1alpha1.DatabaseBootstrap) []xsql.Query {
+	if b == nil {
+		return nil
+	}
+
+	ql := []xsql.Query{}
+	if b.RevokePublicSchema != nil && *b.RevokePublicSchema {
+		privs := make([]string, len(b.RevokePublicSchemaPrivileges))
+		for i, p := range b.RevokePublicSchemaPrivileges {
+			privs[i] = string(p)
+		}
+		ql = append(ql, postgresql.RevokeSchemaQuery("public", postgresql.RevokePrivileges(privs...), postgresql.RevokeFrom(b.RevokePublicSchemaFrom)))
+	}
+	if b.PublicSchemaOwner != nil {
+		ql = append(ql, xsql.Query{String: "ALTER SCHEMA public OWNER TO " + pq.QuoteIdentifier(*b.PublicSchemaOwner)})
+	}
+	for _, dp := range b.DefaultPrivileges {
+		ql = append(ql, defaultPrivilegesQuery(dp))
+	}
+	return ql
+}
+
+func defaultPrivilegesQuery(dp v1alpha1.DefaultPrivileges) xsql.Query {
+	var b strings.Builder
+	b.WriteString("ALTER DEFAULT PRIVILEGES")
+	if dp.ForRole != nil {
+		b.WriteString(" FOR ROLE ")
+		b.WriteString(pq.QuoteIdentifier(*dp.ForRole))
+	}
+	if dp.Schema != nil {
+		b.WriteString(" IN SCHEMA ")
+		b.WriteString(pq.QuoteIdentifier(*dp.Schema))
+	}
+
+	// Privileges and object types are validated by the CRD schema, and can't
+	// be quoted.
+	b.WriteString(" GRANT ")
+	b.WriteString(strings.Join(dp.Privileges.ToStringSlice(), ", "))
+	b.WriteString(" ON ")
+	b.WriteString(string(dp.ObjectType))
+	b.WriteString(" TO ")
+	b.WriteString(pq.QuoteIdentifier(dp.Role))
+	return xsql.Query{String: b.String()}
+}
+
+// bootstrap runs any bootstrap statements against the supplied database, in a
+// single transaction.
+func (c *external) bootstrap(ctx context.Context, cr *v1alpha1.Database) error {
+	ql := bootstrapQueries(cr.Spec.ForProvider.Bootstrap)
+	if len(ql) == 0 {
+		return nil
+	}
+
+	if err := c.dbFor(meta.GetExternalName(cr)).ExecTx(ctx, ql); err != nil {
+		return errors.Wrap(err, errBootstrapDB)
+	}
+
+	cr.Status.AtProvider.Bootstrapped = true
+	cr.Status.AtProvider.BootstrapHash = bootstrapHash(ql)
+	return nil
+}
+
+// bootstrapHash returns a hash of the supplied bootstrap statements.
+func bootstrapHash(ql []xsql.Query) string {
+	h := sha256.New()
+	for _, q := range ql {
+		// Bootstrap statements have no parameters.
+		_, _ = h.Write([]byte(q.String))
+		_, _ = h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// adoptBootstrap records the hash of the bootstrap statements of a database
+// that was bootstrapped before hashes were recorded. We assume it was
+// bootstrapped per its current spec, rather than running the statements again.
+func adoptBootstrap(cr *v1alpha1.Database) {
+	s := &cr.Status.AtProvider
+	if !s.Bootstrapped || s.BootstrapHash != "" {
+		return
+	}
+	if ql := bootstrapQueries(cr.Spec.ForProvider.Bootstrap); len(ql) > 0 {
+		s.BootstrapHash = bootstrapHash(ql)
+	}
+}
+
+// bootstrapped returns true if the supplied database needs no bootstrapping,
+// either because none is configured or because its current bootstrap
+// statements were the last to run successfully.
+func bootstrapped(cr *v1alpha1.Database) bool {
+	ql := bootstrapQueries(cr.Spec.ForProvider.Bootstrap)
+	return len(ql) == 0 || cr.Status.AtProvider.BootstrapHash == bootstrapHash(ql)
+}
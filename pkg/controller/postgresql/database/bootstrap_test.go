@@ -0,0 +1,234 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestBootstrapQueries(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		b      *v1alpha1.DatabaseBootstrap
+		want   []xsql.Query
+	}{
+		"NoBootstrap": {
+			reason: "No statements should be run when no bootstrap is configured",
+			b:      nil,
+			want:   nil,
+		},
+		"FullBootstrap": {
+			reason: "Statements should be run in a predictable order",
+			b: &v1alpha1.DatabaseBootstrap{
+				RevokePublicSchema: pointer.BoolPtr(true),
+				PublicSchemaOwner:  pointer.StringPtr("owner"),
+				DefaultPrivileges: []v1alpha1.DefaultPrivileges{
+					{
+						Role:       "reader",
+						Privileges: v1alpha1.GrantPrivileges{"SELECT"},
+						ObjectType: v1alpha1.DefaultPrivilegesTables,
+					},
+					{
+						Role:       "writer",
+						Privileges: v1alpha1.GrantPrivileges{"USAGE", "SELECT"},
+						ObjectType: v1alpha1.DefaultPrivilegesSequences,
+						Schema:     pointer.StringPtr("app"),
+						ForRole:    pointer.StringPtr("owner"),
+					},
+				},
+			},
+			want: []xsql.Query{
+				{String: `REVOKE ALL ON SCHEMA "public" FROM PUBLIC`},
+				{String: `ALTER SCHEMA public OWNER TO "owner"`},
+				{String: `ALTER DEFAULT PRIVILEGES GRANT SELECT ON TABLES TO "reader"`},
+				{String: `ALTER DEFAULT PRIVILEGES FOR ROLE "owner" IN SCHEMA "app" GRANT USAGE, SELECT ON SEQUENCES TO "writer"`},
+			},
+		},
+		"RevokeConfigured": {
+			reason: "The configured privileges should be revoked from the configured role",
+			b: &v1alpha1.DatabaseBootstrap{
+				RevokePublicSchema:           pointer.BoolPtr(true),
+				RevokePublicSchemaPrivileges: []v1alpha1.SchemaPrivilege{"CREATE"},
+				RevokePublicSchemaFrom:       pointer.StringPtr("app"),
+			},
+			want: []xsql.Query{{String: `REVOKE CREATE ON SCHEMA "public" FROM "app"`}},
+		},
+		"RevokeDisabled": {
+			reason: "Privileges should not be revoked from PUBLIC unless asked",
+			b: &v1alpha1.DatabaseBootstrap{
+				RevokePublicSchema: pointer.BoolPtr(false),
+			},
+			want: []xsql.Query{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := bootstrapQueries(tc.b)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nbootstrapQueries(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBootstrap(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	db := &v1alpha1.Database{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{meta.AnnotationKeyExternalName: "cool"},
+		},
+		Spec: v1alpha1.DatabaseSpec{
+			ForProvider: v1alpha1.DatabaseParameters{
+				Bootstrap: &v1alpha1.DatabaseBootstrap{RevokePublicSchema: pointer.BoolPtr(true)},
+			},
+		},
+	}
+
+	type want struct {
+		err          error
+		bootstrapped bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.Database
+		dbFor  func(t *testing.T) func(database string) xsql.DB
+		want   want
+	}{
+		"NoBootstrap": {
+			reason: "Databases with no bootstrap configured should not be connected to",
+			cr:     &v1alpha1.Database{},
+			dbFor: func(t *testing.T) func(database string) xsql.DB {
+				return func(database string) xsql.DB {
+					t.Errorf("dbFor(%q): unexpected connection", database)
+					return nil
+				}
+			},
+			want: want{},
+		},
+		"Success": {
+			reason: "Bootstrap statements should run in one transaction against the new database",
+			cr:     db.DeepCopy(),
+			dbFor: func(t *testing.T) func(database string) xsql.DB {
+				return func(database string) xsql.DB {
+					if database != "cool" {
+						t.Errorf("dbFor(...): want database %q, got %q", "cool", database)
+					}
+					return mockDB{MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return nil }}
+				}
+			},
+			want: want{bootstrapped: true},
+		},
+		"ErrBootstrap": {
+			reason: "Errors running bootstrap statements should be returned",
+			cr:     db.DeepCopy(),
+			dbFor: func(t *testing.T) func(database string) xsql.DB {
+				return func(database string) xsql.DB {
+					return mockDB{MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return errBoom }}
+				}
+			},
+			want: want{err: errors.Wrap(errBoom, errBootstrapDB)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{dbFor: tc.dbFor(t)}
+			err := e.bootstrap(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.bootstrap(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.bootstrapped, tc.cr.Status.AtProvider.Bootstrapped); diff != "" {
+				t.Errorf("\n%s\ne.bootstrap(...): -want bootstrapped, +got bootstrapped:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.bootstrapped, tc.cr.Status.AtProvider.BootstrapHash != ""); diff != "" {
+				t.Errorf("\n%s\ne.bootstrap(...): -want bootstrap hash recorded, +got bootstrap hash recorded:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBootstrapped(t *testing.T) {
+	revoke := &v1alpha1.DatabaseBootstrap{RevokePublicSchema: pointer.BoolPtr(true)}
+	owner := &v1alpha1.DatabaseBootstrap{RevokePublicSchema: pointer.BoolPtr(true), PublicSchemaOwner: pointer.StringPtr("owner")}
+	applied := bootstrapHash(bootstrapQueries(revoke))
+
+	database := func(b *v1alpha1.DatabaseBootstrap, s v1alpha1.DatabaseObservation) *v1alpha1.Database {
+		return &v1alpha1.Database{
+			Spec:   v1alpha1.DatabaseSpec{ForProvider: v1alpha1.DatabaseParameters{Bootstrap: b}},
+			Status: v1alpha1.DatabaseStatus{AtProvider: s},
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.Database
+		want   bool
+	}{
+		"NoBootstrap": {
+			reason: "A database with no bootstrap configured needs no bootstrapping",
+			cr:     database(nil, v1alpha1.DatabaseObservation{}),
+			want:   true,
+		},
+		"NotBootstrapped": {
+			reason: "A database that was never bootstrapped needs bootstrapping",
+			cr:     database(revoke, v1alpha1.DatabaseObservation{}),
+			want:   false,
+		},
+		"Bootstrapped": {
+			reason: "A database whose current bootstrap statements were last to run needs no bootstrapping",
+			cr:     database(revoke, v1alpha1.DatabaseObservation{Bootstrapped: true, BootstrapHash: applied}),
+			want:   true,
+		},
+		"BootstrapChanged": {
+			reason: "A database whose bootstrap statements changed since they last ran needs bootstrapping again",
+			cr:     database(owner, v1alpha1.DatabaseObservation{Bootstrapped: true, BootstrapHash: applied}),
+			want:   false,
+		},
+		"Adopted": {
+			reason: "A database bootstrapped before hashes were recorded should be assumed to be bootstrapped per its current spec",
+			cr: func() *v1alpha1.Database {
+				cr := database(owner, v1alpha1.DatabaseObservation{Bootstrapped: true})
+				adoptBootstrap(cr)
+				return cr
+			}(),
+			want: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, bootstrapped(tc.cr)); diff != "" {
+				t.Errorf("\n%s\nbootstrapped(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
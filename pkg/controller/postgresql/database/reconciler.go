@@ -26,15 +26,12 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/lib/pq"
 	"github.com/pkg/errors"
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
-	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
@@ -42,14 +39,10 @@ import (
 	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/options"
 )
 
 const (
-	errTrackPCUsage = "cannot track ProviderConfig usage"
-	errGetPC        = "cannot get ProviderConfig"
-	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
-	errGetSecret    = "cannot get credentials Secret"
-
 	errNotDatabase       = "managed resource is not a Database custom resource"
 	errSelectDB          = "cannot select database"
 	errCreateDB          = "cannot create database"
@@ -63,16 +56,25 @@ const (
 )
 
 // Setup adds a controller that reconciles Database managed resources.
-func Setup(mgr ctrl.Manager, l logging.Logger) error {
+func Setup(mgr ctrl.Manager, o options.Options) error {
 	name := managed.ControllerName(v1alpha1.DatabaseGroupKind)
 
+	db := func(creds map[string][]byte, database string, po ...postgresql.Option) xsql.DB {
+		return o.DB(xsql.NewRetryingDB(postgresql.New(creds, database, po...), postgresql.IsTransient))
+	}
+
+	rec, err := o.Recorder(mgr, v1alpha1.DatabaseGroupVersionKind, func() resource.ProviderConfig { return &v1alpha1.ProviderConfig{} }, event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))
+	if err != nil {
+		return err
+	}
+
 	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1alpha1.DatabaseGroupVersionKind),
-		managed.WithExternalConnecter(&connector{kube: mgr.GetClient(), usage: t, newDB: postgresql.New}),
-		managed.WithLogger(l.WithValues("controller", name)),
+		managed.WithExternalConnecter(o.ExternalConnecter(options.NewConnectionLimitConnecter(&connector{kube: mgr.GetClient(), usage: t, newDB: db, ddl: o.DDLRateLimiter}, postgresql.IsTooManyConnections))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithPollInterval(10*time.Minute),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		managed.WithRecorder(rec))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
@@ -80,50 +82,45 @@ func Setup(mgr ctrl.Manager, l logging.Logger) error {
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: maxConcurrency,
 		}).
-		Complete(r)
+		Complete(options.NewConnectionLimitReconciler(r, mgr.GetClient(), func() resource.Managed { return &v1alpha1.Database{} }, o.ConnectionLimitBackoff))
 }
 
 type connector struct {
 	kube  client.Client
 	usage resource.Tracker
-	newDB func(creds map[string][]byte, database string) xsql.DB
+	newDB func(creds map[string][]byte, database string, o ...postgresql.Option) xsql.DB
+	ddl   *options.DDLRateLimiter
 }
 
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
-	cr, ok := mg.(*v1alpha1.Database)
-	if !ok {
+	if _, ok := mg.(*v1alpha1.Database); !ok {
 		return nil, errors.New(errNotDatabase)
 	}
 
-	if err := c.usage.Track(ctx, mg); err != nil {
-		return nil, errors.Wrap(err, errTrackPCUsage)
+	conn, err := postgresql.NewConnector(c.kube, c.usage).Resolve(ctx, mg)
+	if err != nil {
+		return nil, err
 	}
+	pc, creds := conn.ProviderConfig, conn.Credentials
 
-	// ProviderConfigReference could theoretically be nil, but in practice the
-	// DefaultProviderConfig initializer will set it before we get here.
-	pc := &v1alpha1.ProviderConfig{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
-		return nil, errors.Wrap(err, errGetPC)
-	}
+	// All resources using this ProviderConfig share a DDL rate limit.
+	ops, burst := pc.Spec.DDLRateLimit.Rate()
 
-	// We don't need to check the credentials source because we currently only
-	// support one source (PostgreSQLConnectionSecret), which is required and
-	// enforced by the ProviderConfig schema.
-	ref := pc.Spec.Credentials.ConnectionSecretRef
-	if ref == nil {
-		return nil, errors.New(errNoSecretRef)
-	}
+	return &external{
+		db: c.ddl.DB(pc.GetName(), ops, burst, c.newDB(creds, conn.DatabaseOrDefault(""), conn.Options()...)),
+		dbFor: func(database string) xsql.DB {
+			return c.ddl.DB(pc.GetName(), ops, burst, c.newDB(creds, database, conn.Options()...))
+		},
+	}, nil
+}
 
-	s := &corev1.Secret{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
-		return nil, errors.Wrap(err, errGetSecret)
-	}
+type external struct {
+	db xsql.DB
 
-	return &external{db: c.newDB(s.Data, "")}, nil
+	// dbFor returns a client connected to the supplied database.
+	dbFor func(database string) xsql.DB
 }
 
-type external struct{ db xsql.DB }
-
 func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
 	cr, ok := mg.(*v1alpha1.Database)
 	if !ok {
@@ -172,6 +169,7 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	}
 
 	cr.SetConditions(xpv1.Available())
+	adoptBootstrap(cr)
 
 	return managed.ExternalObservation{
 		ResourceExists: true,
@@ -180,7 +178,7 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		// values that weren't supplied before we determine if an update is
 		// required.
 		ResourceLateInitialized: lateInit(observed, &cr.Spec.ForProvider),
-		ResourceUpToDate:        upToDate(observed, cr.Spec.ForProvider),
+		ResourceUpToDate:        upToDate(observed, cr.Spec.ForProvider) && bootstrapped(cr),
 	}, nil
 }
 
@@ -231,7 +229,13 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		b.WriteString(fmt.Sprintf(" IS_TEMPLATE %t", *cr.Spec.ForProvider.IsTemplate))
 	}
 
-	return managed.ExternalCreation{}, errors.Wrap(c.db.Exec(ctx, xsql.Query{String: b.String()}), errCreateDB)
+	if err := c.db.Exec(ctx, xsql.Query{String: b.String()}); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateDB)
+	}
+
+	// If bootstrapping fails the database will be reported as not up to date
+	// until it succeeds, so we retry it in Update.
+	return managed.ExternalCreation{}, c.bootstrap(ctx, cr)
 }
 
 func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) { //nolint:gocyclo
@@ -279,6 +283,12 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		}
 	}
 
+	if !bootstrapped(cr) {
+		if err := c.bootstrap(ctx, cr); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+
 	return managed.ExternalUpdate{}, nil
 }
 
@@ -293,8 +303,9 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 }
 
 func upToDate(observed, desired v1alpha1.DatabaseParameters) bool {
-	// Template is only used at create time.
-	return cmp.Equal(desired, observed, cmpopts.IgnoreFields(v1alpha1.DatabaseParameters{}, "Template"))
+	// Template is only used at create time. Bootstrap is compared to the
+	// statements that were last run by bootstrapped.
+	return cmp.Equal(desired, observed, cmpopts.IgnoreFields(v1alpha1.DatabaseParameters{}, "Template", "Bootstrap"))
 }
 
 func lateInit(observed v1alpha1.DatabaseParameters, desired *v1alpha1.DatabaseParameters) bool {
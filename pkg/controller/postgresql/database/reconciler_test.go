@@ -32,6 +32,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
 )
 
@@ -64,7 +65,7 @@ func TestConnect(t *testing.T) {
 	type fields struct {
 		kube  client.Client
 		usage resource.Tracker
-		newDB func(creds map[string][]byte, database string) xsql.DB
+		newDB func(creds map[string][]byte, database string, o ...postgresql.Option) xsql.DB
 	}
 
 	type args struct {
@@ -93,7 +94,7 @@ func TestConnect(t *testing.T) {
 			args: args{
 				mg: &v1alpha1.Database{},
 			},
-			want: errors.Wrap(errBoom, errTrackPCUsage),
+			want: errors.Wrap(errBoom, "cannot track ProviderConfig usage"),
 		},
 		"ErrGetProviderConfig": {
 			reason: "An error should be returned if we can't get our ProviderConfig",
@@ -112,7 +113,7 @@ func TestConnect(t *testing.T) {
 					},
 				},
 			},
-			want: errors.Wrap(errBoom, errGetPC),
+			want: errors.Wrap(errBoom, "cannot get ProviderConfig"),
 		},
 		"ErrMissingConnectionSecret": {
 			reason: "An error should be returned if our ProviderConfig doesn't specify a connection secret",
@@ -134,7 +135,7 @@ func TestConnect(t *testing.T) {
 					},
 				},
 			},
-			want: errors.New(errNoSecretRef),
+			want: errors.New("ProviderConfig does not reference a credentials Secret"),
 		},
 		"ErrGetConnectionSecret": {
 			reason: "An error should be returned if we can't get our ProviderConfig's connection secret",
@@ -161,7 +162,7 @@ func TestConnect(t *testing.T) {
 					},
 				},
 			},
-			want: errors.Wrap(errBoom, errGetSecret),
+			want: errors.Wrap(errBoom, "cannot get credentials Secret"),
 		},
 	}
 
@@ -0,0 +1,63 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+const errConfirmAbsent = "cannot confirm extension is absent"
+
+// catalogReadableQuery selects whether the connected role may read the
+// catalog in which extensions are recorded.
+const catalogReadableQuery = "SELECT has_table_privilege('pg_catalog.pg_extension', 'SELECT')"
+
+// A catalogUnreadableError indicates that an extension could not be found,
+// but may exist, because the connected role may not read pg_extension.
+type catalogUnreadableError struct {
+	extension string
+}
+
+func (e *catalogUnreadableError) Error() string {
+	return fmt.Sprintf("extension %q was not found, but the connected role may not read pg_extension so it may exist; "+
+		"grant the role SELECT on pg_catalog.pg_extension", e.extension)
+}
+
+// confirmAbsent returns nil if an extension that could not be found really
+// does not exist, and an error if the connected role could not have seen it.
+// Some servers return no rows, rather than an error, from catalogs the
+// connected role may not read.
+func (c *external) confirmAbsent(ctx context.Context, extension string) error {
+	readable := false
+	err := c.db.Scan(ctx, xsql.Query{String: catalogReadableQuery}, &readable)
+	if postgresql.IsInsufficientPrivilege(err) {
+		return errors.Wrap(&catalogUnreadableError{extension: extension}, errConfirmAbsent)
+	}
+	if err != nil {
+		return errors.Wrap(err, errConfirmAbsent)
+	}
+	if !readable {
+		return errors.Wrap(&catalogUnreadableError{extension: extension}, errConfirmAbsent)
+	}
+	return nil
+}
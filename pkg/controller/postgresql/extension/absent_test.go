@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// scanAbsent mocks a server on which the extension does not exist, and the
+// connected role may read pg_extension.
+func scanAbsent(_ context.Context, q xsql.Query, dest ...interface{}) error {
+	if q.String == catalogReadableQuery {
+		*dest[0].(*bool) = true
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+func TestObserveAbsent(t *testing.T) {
+	errBoom := errors.New("boom")
+	errDenied := &pq.Error{Code: "42501", Message: "permission denied for table pg_extension"}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		scan   func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+		want   want
+	}{
+		"Absent": {
+			reason: "An extension that is not found in a readable catalog should not exist",
+			scan:   scanAbsent,
+			want:   want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"CatalogUnreadable": {
+			reason: "An extension that is not found in a catalog the role may not read should not be treated as absent",
+			scan: func(_ context.Context, q xsql.Query, dest ...interface{}) error {
+				if q.String == catalogReadableQuery {
+					*dest[0].(*bool) = false
+					return nil
+				}
+				return sql.ErrNoRows
+			},
+			want: want{err: errors.Wrap(&catalogUnreadableError{extension: "hstore"}, errConfirmAbsent)},
+		},
+		"PermissionDenied": {
+			reason: "An extension whose catalog the role is denied should not be treated as absent",
+			scan: func(_ context.Context, q xsql.Query, dest ...interface{}) error {
+				return errDenied
+			},
+			want: want{err: errors.Wrap(errDenied, errSelectExtension)},
+		},
+		"ConfirmPermissionDenied": {
+			reason: "An extension should not be treated as absent if we're denied confirming the catalog is readable",
+			scan: func(_ context.Context, q xsql.Query, dest ...interface{}) error {
+				if q.String == catalogReadableQuery {
+					return errDenied
+				}
+				return sql.ErrNoRows
+			},
+			want: want{err: errors.Wrap(&catalogUnreadableError{extension: "hstore"}, errConfirmAbsent)},
+		},
+		"ErrConfirm": {
+			reason: "Errors confirming the extension is absent should be returned",
+			scan: func(_ context.Context, q xsql.Query, dest ...interface{}) error {
+				if q.String == catalogReadableQuery {
+					return errBoom
+				}
+				return sql.ErrNoRows
+			},
+			want: want{err: errors.Wrap(errBoom, errConfirmAbsent)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{db: mockDB{MockScan: tc.scan}}
+			cr := &v1alpha1.Extension{Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{
+				Extension: "hstore",
+				Version:   new(string),
+			}}}
+
+			o, err := e.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, o); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+const (
+	defaultAuditTable           = "crossplane_audit"
+	defaultAuditUIDColumn       = "resource_uid"
+	defaultAuditActionColumn    = "action"
+	defaultAuditTimestampColumn = "created_at"
+
+	auditActionCreate = "CREATE EXTENSION"
+	auditActionDrop   = "DROP EXTENSION"
+)
+
+func valueOr(s *string, def string) string {
+	if s == nil || *s == "" {
+		return def
+	}
+	return *s
+}
+
+// auditQuery returns a query that records the supplied action taken by the
+// supplied managed resource in the configured audit table.
+func auditQuery(q postgresql.Quoter, cfg *v1alpha1.AuditConfig, uid types.UID, action string) xsql.Query {
+	return xsql.Query{
+		String: "INSERT INTO " + postgresql.QuoteQualifiedIdentifier(q, valueOr(cfg.Table, defaultAuditTable)) + " (" +
+			q.QuoteIdentifier(valueOr(cfg.UIDColumn, defaultAuditUIDColumn)) + ", " +
+			q.QuoteIdentifier(valueOr(cfg.ActionColumn, defaultAuditActionColumn)) + ", " +
+			q.QuoteIdentifier(valueOr(cfg.TimestampColumn, defaultAuditTimestampColumn)) +
+			") VALUES ($1, $2, now())",
+		Parameters: []interface{}{string(uid), action},
+	}
+}
+
+// exec runs the supplied query. If auditing is enabled the query runs in a
+// transaction alongside an insert into the audit table, so that the audit
+// record is written if and only if the query succeeds.
+func (c *external) exec(ctx context.Context, cr *v1alpha1.Extension, q xsql.Query, action string) error {
+	if c.audit == nil {
+		return c.db.Exec(ctx, q)
+	}
+	_, err := c.execTx(ctx, cr.Spec.ForProvider, []xsql.Query{q, auditQuery(c.quoter(), c.audit, cr.GetUID(), action)})
+	return err
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// A dbKey identifies a cached DB client.
+type dbKey struct {
+	providerConfig string
+	version        string
+	database       string
+	params         string
+	readOnly       bool
+}
+
+// newDBKey returns the key of a DB client that connects to the supplied
+// database with the supplied session parameters, using the supplied version
+// of the supplied ProviderConfig.
+func newDBKey(providerConfig, version, database string, params map[string]string, readOnly bool) dbKey {
+	p := make([]string, 0, len(params))
+	for k, v := range params {
+		p = append(p, k+"="+v)
+	}
+	sort.Strings(p)
+	return dbKey{providerConfig: providerConfig, version: version, database: database, params: strings.Join(p, ","), readOnly: readOnly}
+}
+
+const (
+	// dbRetireGrace is how long a retired client stays open after it was
+	// last handed out. It exceeds the managed reconciler's one minute
+	// timeout, so reconciles still using the client can finish with it.
+	dbRetireGrace = 2 * time.Minute
+
+	// dbIdleTimeout is how long a client may go unused before it's retired,
+	// so that clients for databases and session parameters that are no
+	// longer used don't accumulate.
+	dbIdleTimeout = 10 * time.Minute
+)
+
+// A dbEntry is a cached DB client.
+type dbEntry struct {
+	db xsql.DB
+
+	// refs is the number of operations in flight using the client.
+	refs int
+
+	// used is when the client was last handed out.
+	used time.Time
+}
+
+// A dbCache caches DB clients, so that reconciles reuse the connections of
+// earlier reconciles rather than opening their own. Each ProviderConfig has a
+// client per database it connects to. Clients are cached for the version of
+// the ProviderConfig they were created with. They're retired when it changes,
+// or when they go unused for dbIdleTimeout. A retired client is closed once no
+// operations are using it and dbRetireGrace has passed since it was last
+// handed out, so it isn't closed under a reconcile that's still using it.
+type dbCache struct {
+	mx      sync.Mutex
+	dbs     map[dbKey]*dbEntry
+	retired []*dbEntry
+
+	now func() time.Time
+}
+
+func newDBCache() *dbCache {
+	return &dbCache{dbs: map[dbKey]*dbEntry{}, now: time.Now}
+}
+
+// Get the client with the supplied key, creating it using the supplied
+// function if it isn't cached. Any clients for other versions of the same
+// ProviderConfig, and any idle clients, are retired.
+func (c *dbCache) Get(k dbKey, newDB func() xsql.DB) xsql.DB {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	now := c.now()
+	for ek, e := range c.dbs {
+		if (ek.providerConfig == k.providerConfig && ek.version != k.version) || now.Sub(e.used) >= dbIdleTimeout {
+			delete(c.dbs, ek)
+			c.retired = append(c.retired, e)
+		}
+	}
+	c.closeRetired(now)
+
+	e, ok := c.dbs[k]
+	if !ok {
+		e = &dbEntry{db: newDB()}
+		c.dbs[k] = e
+	}
+	e.used = now
+	return &cachedDB{DB: e.db, entry: e, cache: c}
+}
+
+// closeRetired closes the retired clients that are no longer in use. The
+// cache's mutex must be held.
+func (c *dbCache) closeRetired(now time.Time) {
+	open := c.retired[:0]
+	for _, e := range c.retired {
+		if e.refs > 0 || now.Sub(e.used) < dbRetireGrace {
+			open = append(open, e)
+			continue
+		}
+		_ = xsql.Close(e.db)
+	}
+	c.retired = open
+}
+
+func (c *dbCache) acquire(e *dbEntry) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	e.refs++
+}
+
+func (c *dbCache) release(e *dbEntry) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	e.refs--
+	c.closeRetired(c.now())
+}
+
+// A cachedDB counts the operations in flight using a cached client, so that
+// the cache doesn't close the client while they run. Rows returned by Query
+// keep their connection open until they're closed, even if the client is.
+type cachedDB struct {
+	xsql.DB
+	entry *dbEntry
+	cache *dbCache
+}
+
+// Exec the supplied query.
+func (d *cachedDB) Exec(ctx context.Context, q xsql.Query) error {
+	d.cache.acquire(d.entry)
+	defer d.cache.release(d.entry)
+	return d.DB.Exec(ctx, q)
+}
+
+// ExecTx executes the supplied queries in a transaction.
+func (d *cachedDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	d.cache.acquire(d.entry)
+	defer d.cache.release(d.entry)
+	return d.DB.ExecTx(ctx, ql)
+}
+
+// Scan the results of the supplied query into the supplied destination.
+func (d *cachedDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	d.cache.acquire(d.entry)
+	defer d.cache.release(d.entry)
+	return d.DB.Scan(ctx, q, dest...)
+}
+
+// Query the supplied query.
+func (d *cachedDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	d.cache.acquire(d.entry)
+	defer d.cache.release(d.entry)
+	return d.DB.Query(ctx, q)
+}
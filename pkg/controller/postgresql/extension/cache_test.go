@@ -0,0 +1,252 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// A closingDB records whether it was closed.
+type closingDB struct {
+	mockDB
+	closed bool
+}
+
+func (d *closingDB) Close() error {
+	d.closed = true
+	return nil
+}
+
+// uncached returns the client a cached client wraps.
+func uncached(db xsql.DB) xsql.DB {
+	return db.(*cachedDB).DB
+}
+
+func TestDBCache(t *testing.T) {
+	now := time.Now()
+	c := newDBCache()
+	c.now = func() time.Time { return now }
+
+	v1 := &closingDB{}
+	if got := uncached(c.Get(newDBKey("cool", "1", "db", nil, false), func() xsql.DB { return v1 })); got != v1 {
+		t.Errorf("c.Get(...): want a new DB")
+	}
+	if got := uncached(c.Get(newDBKey("cool", "1", "db", nil, false), func() xsql.DB { return &closingDB{} })); got != v1 {
+		t.Errorf("c.Get(...): want the cached DB for the same Secret version")
+	}
+
+	ro := &closingDB{}
+	if got := uncached(c.Get(newDBKey("cool", "1", "db", nil, true), func() xsql.DB { return ro })); got != ro {
+		t.Errorf("c.Get(...): want a different DB for read only sessions")
+	}
+
+	params := &closingDB{}
+	if got := uncached(c.Get(newDBKey("cool", "1", "db", map[string]string{"work_mem": "64MB"}, false), func() xsql.DB { return params })); got != params {
+		t.Errorf("c.Get(...): want a different DB for different session parameters")
+	}
+
+	other := &closingDB{}
+	if got := uncached(c.Get(newDBKey("cool", "1", "other", nil, false), func() xsql.DB { return other })); got != other {
+		t.Errorf("c.Get(...): want a different DB for a different database")
+	}
+
+	pc := &closingDB{}
+	if got := uncached(c.Get(newDBKey("other", "3", "db", nil, false), func() xsql.DB { return pc })); got != pc {
+		t.Errorf("c.Get(...): want a different DB for a different ProviderConfig")
+	}
+
+	v2 := &closingDB{}
+	if got := uncached(c.Get(newDBKey("cool", "2", "db", nil, false), func() xsql.DB { return v2 })); got != v2 {
+		t.Errorf("c.Get(...): want a new DB for a new Secret version")
+	}
+	if v1.closed || ro.closed || params.closed || other.closed {
+		t.Errorf("c.Get(...): want DBs for the previous Secret version to stay open while reconciles may be using them")
+	}
+
+	now = now.Add(dbRetireGrace)
+	_ = c.Get(newDBKey("cool", "2", "db", nil, false), func() xsql.DB { return &closingDB{} })
+	if !v1.closed || !ro.closed || !params.closed || !other.closed {
+		t.Errorf("c.Get(...): want DBs for the previous Secret version to be closed after the grace period")
+	}
+	if v2.closed {
+		t.Errorf("c.Get(...): want the DB for the current Secret version to stay open")
+	}
+	if pc.closed {
+		t.Errorf("c.Get(...): want DBs for other ProviderConfigs to stay open")
+	}
+
+	now = now.Add(dbIdleTimeout)
+	_ = c.Get(newDBKey("cool", "2", "db", nil, false), func() xsql.DB { return &closingDB{} })
+	if !pc.closed {
+		t.Errorf("c.Get(...): want idle DBs to be closed")
+	}
+	if len(c.dbs) != 1 {
+		t.Errorf("c.Get(...): want idle DBs to be removed from the cache, got %d cached DBs", len(c.dbs))
+	}
+}
+
+func TestDBCacheInFlight(t *testing.T) {
+	now := time.Now()
+	c := newDBCache()
+	c.now = func() time.Time { return now }
+
+	v1 := &closingDB{}
+	v1.MockExec = func(_ context.Context, _ xsql.Query) error {
+		// The Secret is updated and the grace period passes while this
+		// operation is still running.
+		now = now.Add(dbRetireGrace)
+		_ = c.Get(newDBKey("cool", "2", "db", nil, false), func() xsql.DB { return &closingDB{} })
+		if v1.closed {
+			t.Errorf("c.Get(...): want a DB to stay open while an operation is using it")
+		}
+		return nil
+	}
+
+	db := c.Get(newDBKey("cool", "1", "db", nil, false), func() xsql.DB { return v1 })
+	if err := db.Exec(context.Background(), xsql.Query{String: "SELECT 1"}); err != nil {
+		t.Fatalf("db.Exec(...): %v", err)
+	}
+	if !v1.closed {
+		t.Errorf("db.Exec(...): want a retired DB to be closed once its last operation finishes")
+	}
+}
+
+func TestConnectCachesDB(t *testing.T) {
+	version := "1"
+	kube := &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			switch o := obj.(type) {
+			case *v1alpha1.ProviderConfig:
+				o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{Namespace: "ns", Name: "creds"}
+			case *corev1.Secret:
+				o.SetResourceVersion(version)
+			}
+			return nil
+		}),
+	}
+
+	created := []*closingDB{}
+	c := &connector{
+		kube:  kube,
+		usage: resource.TrackerFn(func(_ context.Context, _ resource.Managed) error { return nil }),
+		newDB: func(_ map[string][]byte, _ string, _ map[string]string, _ ...postgresql.Option) xsql.DB {
+			db := &closingDB{mockDB: pingableDB()}
+			created = append(created, db)
+			return db
+		},
+		dbs: newDBCache(),
+	}
+
+	cr := &v1alpha1.Extension{
+		Spec: v1alpha1.ExtensionSpec{
+			ResourceSpec: xpv1.ResourceSpec{ProviderConfigReference: &xpv1.Reference{Name: "cool"}},
+			ForProvider:  v1alpha1.ExtensionParameters{Extension: "cool"},
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Connect(context.Background(), cr); err != nil {
+			t.Fatalf("c.Connect(...): %v", err)
+		}
+	}
+	if len(created) != 1 {
+		t.Errorf("c.Connect(...): want a second connect using the same Secret to reuse the cached DB, got %d DBs", len(created))
+	}
+
+	version = "2"
+	if _, err := c.Connect(context.Background(), cr); err != nil {
+		t.Fatalf("c.Connect(...): %v", err)
+	}
+	if len(created) != 2 {
+		t.Fatalf("c.Connect(...): want an updated Secret to bust the cache, got %d DBs", len(created))
+	}
+	if created[0].closed {
+		t.Errorf("c.Connect(...): want the DB for the previous Secret version to stay open while reconciles may be using it")
+	}
+}
+
+func TestConnectCachesDBPerDatabase(t *testing.T) {
+	kube := &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			switch o := obj.(type) {
+			case *v1alpha1.ProviderConfig:
+				o.SetName("cool")
+				o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{Namespace: "ns", Name: "creds"}
+				o.Spec.Credentials.Keys = &v1alpha1.CredentialKeys{Database: pointer.StringPtr("dbname")}
+			case *corev1.Secret:
+				o.Data = map[string][]byte{"dbname": []byte("default")}
+			}
+			return nil
+		}),
+	}
+
+	created := map[string]int{}
+	c := &connector{
+		kube:  kube,
+		usage: resource.TrackerFn(func(_ context.Context, _ resource.Managed) error { return nil }),
+		newDB: func(_ map[string][]byte, database string, _ map[string]string, _ ...postgresql.Option) xsql.DB {
+			created[database]++
+			return &closingDB{mockDB: pingableDB()}
+		},
+		dbs: newDBCache(),
+	}
+
+	extension := func(database *string) *v1alpha1.Extension {
+		return &v1alpha1.Extension{
+			Spec: v1alpha1.ExtensionSpec{
+				ResourceSpec: xpv1.ResourceSpec{ProviderConfigReference: &xpv1.Reference{Name: "cool"}},
+				ForProvider:  v1alpha1.ExtensionParameters{Extension: "cool", Database: database},
+			},
+		}
+	}
+
+	// The extension that specifies no database connects to the default
+	// database, and should share its DB with the one that names it.
+	crs := []*v1alpha1.Extension{
+		extension(pointer.StringPtr("a")),
+		extension(pointer.StringPtr("b")),
+		extension(pointer.StringPtr("default")),
+		extension(nil),
+	}
+	for i := 0; i < 2; i++ {
+		for _, cr := range crs {
+			if _, err := c.Connect(context.Background(), cr); err != nil {
+				t.Fatalf("c.Connect(...): %v", err)
+			}
+		}
+	}
+
+	want := map[string]int{"a": 1, "b": 1, "default": 1}
+	if diff := cmp.Diff(want, created); diff != "" {
+		t.Errorf("c.Connect(...): -want DBs created per database, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+const (
+	errSelectInstalled    = "cannot select installed extensions"
+	errSelectDependencies = "cannot select extension dependencies"
+	errScanExtensionName  = "cannot scan extension name"
+)
+
+const installedQuery = "SELECT extname FROM pg_extension ORDER BY extname"
+
+// dependenciesQuery selects the extensions an extension depends on, directly
+// or transitively.
+const dependenciesQuery = "WITH RECURSIVE deps(oid) AS (" +
+	"SELECT d.refobjid FROM pg_depend d JOIN pg_extension e ON e.oid = d.objid " +
+	"WHERE d.classid = 'pg_extension'::regclass AND d.refclassid = 'pg_extension'::regclass AND e.extname = $1 " +
+	"UNION " +
+	"SELECT d.refobjid FROM pg_depend d JOIN deps ON deps.oid = d.objid " +
+	"WHERE d.classid = 'pg_extension'::regclass AND d.refclassid = 'pg_extension'::regclass" +
+	") SELECT e.extname FROM pg_extension e JOIN deps ON deps.oid = e.oid ORDER BY e.extname"
+
+func (c *external) extensionNames(ctx context.Context, q xsql.Query) ([]string, error) {
+	rows, err := c.db.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var names []string
+	for rows.Next() {
+		var n string
+		if err := rows.Scan(&n); err != nil {
+			return nil, errors.Wrap(err, errScanExtensionName)
+		}
+		names = append(names, n)
+	}
+	return names, rows.Err()
+}
+
+// cascadeBaseline returns the extensions that are installed before an
+// extension is created with CASCADE, or nil if it won't be.
+func (c *external) cascadeBaseline(ctx context.Context, p v1alpha1.ExtensionParameters) (map[string]bool, error) {
+	if p.Cascade == nil || !*p.Cascade {
+		return nil, nil
+	}
+	names, err := c.extensionNames(ctx, xsql.Query{String: installedQuery})
+	if err != nil {
+		return nil, errors.Wrap(err, errSelectInstalled)
+	}
+	installed := make(map[string]bool, len(names))
+	for _, n := range names {
+		installed[n] = true
+	}
+	return installed, nil
+}
+
+// observeInstalledDependencies reports the dependencies of an extension that
+// were not installed before it was created with CASCADE. The supplied
+// baseline is nil if the extension was not created with CASCADE.
+func (c *external) observeInstalledDependencies(ctx context.Context, cr *v1alpha1.Extension, baseline map[string]bool) error {
+	if baseline == nil {
+		return nil
+	}
+	deps, err := c.extensionNames(ctx, xsql.Query{String: dependenciesQuery, Parameters: []interface{}{cr.Spec.ForProvider.Extension}})
+	if err != nil {
+		return errors.Wrap(err, errSelectDependencies)
+	}
+
+	cr.Status.AtProvider.InstalledDependencies = nil
+	for _, d := range deps {
+		if !baseline[d] {
+			cr.Status.AtProvider.InstalledDependencies = append(cr.Status.AtProvider.InstalledDependencies, d)
+		}
+	}
+	return nil
+}
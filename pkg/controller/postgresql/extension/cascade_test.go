@@ -0,0 +1,157 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestCreateCascade(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	names := func(n ...string) (*sql.Rows, error) {
+		r := sqlmock.NewRows([]string{"extname"})
+		for _, s := range n {
+			r.AddRow(s)
+		}
+		return mockRowsToSQLRows(r), nil
+	}
+
+	type want struct {
+		execs []string
+		deps  []string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		cascade *bool
+		query   func(ctx context.Context, q xsql.Query) (*sql.Rows, error)
+		want    want
+	}{
+		"NoCascade": {
+			reason: "Dependencies should not be captured when the extension is not created with CASCADE",
+			query: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+				t.Errorf("MockQuery: unexpected query: %s", q.String)
+				return nil, errBoom
+			},
+			want: want{execs: []string{`CREATE EXTENSION IF NOT EXISTS "earthdistance"`}},
+		},
+		"Cascade": {
+			reason:  "Only the dependencies that were not installed before CASCADE should be reported",
+			cascade: pointer.BoolPtr(true),
+			query: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+				switch q.String {
+				case installedQuery:
+					return names("plpgsql")
+				case dependenciesQuery:
+					return names("cube", "plpgsql")
+				}
+				t.Errorf("MockQuery: unexpected query: %s", q.String)
+				return nil, errBoom
+			},
+			want: want{
+				execs: []string{`CREATE EXTENSION IF NOT EXISTS "earthdistance" CASCADE`},
+				deps:  []string{"cube"},
+			},
+		},
+		"CascadeNothingInstalled": {
+			reason:  "No dependencies should be reported when all were already installed",
+			cascade: pointer.BoolPtr(true),
+			query: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+				return names("cube")
+			},
+			want: want{execs: []string{`CREATE EXTENSION IF NOT EXISTS "earthdistance" CASCADE`}},
+		},
+		"ErrSelectInstalled": {
+			reason:  "The extension should not be created if we cannot capture the installed extensions",
+			cascade: pointer.BoolPtr(true),
+			query: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+				return nil, errBoom
+			},
+			want: want{err: errors.Wrap(errors.Wrap(errBoom, errSelectInstalled), errCreateExtension)},
+		},
+		"ErrSelectDependencies": {
+			reason:  "Errors selecting the extension's dependencies should be returned",
+			cascade: pointer.BoolPtr(true),
+			query: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+				if q.String == installedQuery {
+					return names()
+				}
+				return nil, errBoom
+			},
+			want: want{
+				execs: []string{`CREATE EXTENSION IF NOT EXISTS "earthdistance" CASCADE`},
+				err:   errors.Wrap(errBoom, errSelectDependencies),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			execs := []string{}
+			e := &external{db: mockDB{
+				MockQuery: tc.query,
+				MockExec: func(ctx context.Context, q xsql.Query) error {
+					execs = append(execs, q.String)
+					return nil
+				},
+			}}
+			cr := &v1alpha1.Extension{Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{
+				Extension: "earthdistance",
+				Cascade:   tc.cascade,
+			}}}
+
+			_, err := e.Create(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.execs, execs, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want statements, +got statements:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.deps, cr.Status.AtProvider.InstalledDependencies); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want installed dependencies, +got installed dependencies:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCascadeUpToDate(t *testing.T) {
+	observed := v1alpha1.ExtensionParameters{Extension: "earthdistance", Version: pointer.StringPtr("1.1")}
+	desired := v1alpha1.ExtensionParameters{Extension: "earthdistance", Version: pointer.StringPtr("1.1"), Cascade: pointer.BoolPtr(true)}
+
+	if !upToDate(observed, desired) {
+		t.Errorf("upToDate(...): Cascade only affects creation, and should not make an existing extension out of date")
+	}
+	if q := driftQueries(postgresql.DefaultQuoter, observed, desired); len(q) != 0 {
+		t.Errorf("driftQueries(...): want no queries, got %v", q)
+	}
+}
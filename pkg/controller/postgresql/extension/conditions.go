@@ -0,0 +1,198 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// Condition types that help diagnose why an extension could not be
+// installed. Each is true while the problem it describes is present.
+const (
+	TypeControlFileMissing xpv1.ConditionType = "ControlFileMissing"
+	TypeLibraryLoadFailed  xpv1.ConditionType = "LibraryLoadFailed"
+	TypeVersionRequired    xpv1.ConditionType = "VersionRequired"
+	TypeVersionUnavailable xpv1.ConditionType = "VersionUnavailable"
+)
+
+// Reasons for the diagnostic conditions.
+const (
+	ReasonCreateFailed xpv1.ConditionReason = "CreateFailed"
+	ReasonResolved     xpv1.ConditionReason = "Resolved"
+)
+
+const errSelectDefaultVersion = "cannot select extension default version"
+
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const pqUndefinedFile = pq.ErrorCode("58P01")
+
+// diagnosticTypes are cleared once an extension is observed to exist.
+var diagnosticTypes = []xpv1.ConditionType{
+	TypeControlFileMissing,
+	TypeLibraryLoadFailed,
+	TypeVersionRequired,
+	TypeVersionUnavailable,
+}
+
+func diagnostic(t xpv1.ConditionType, message string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               t,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonCreateFailed,
+		Message:            message,
+	}
+}
+
+// ControlFileMissing returns a condition that indicates the extension's
+// control file is not installed on the server.
+func ControlFileMissing() xpv1.Condition {
+	return diagnostic(TypeControlFileMissing, "The extension's control file is not installed on the database server. "+
+		"Install the package that provides the extension (e.g. postgresql-contrib) on the server.")
+}
+
+// LibraryLoadFailed returns a condition that indicates the extension's shared
+// library could not be loaded by the server.
+func LibraryLoadFailed() xpv1.Condition {
+	return diagnostic(TypeLibraryLoadFailed, "The extension's shared library could not be loaded by the database server. "+
+		"Ensure the library is installed in the server's $libdir, and added to shared_preload_libraries if the extension requires it.")
+}
+
+// VersionRequired returns a condition that indicates the extension has no
+// default version, so a version must be specified.
+func VersionRequired() xpv1.Condition {
+	return diagnostic(TypeVersionRequired, "The extension has no default version. "+
+		"Specify the version to install in spec.forProvider.version.")
+}
+
+// VersionUnavailable returns a condition that indicates the supplied version
+// of the extension is not packaged on the server, which packages only the
+// supplied versions.
+func VersionUnavailable(version string, available []string) xpv1.Condition {
+	return diagnostic(TypeVersionUnavailable, fmt.Sprintf("Version %s of the extension is not packaged on the database server. "+
+		"Specify one of the available versions in spec.forProvider.version: %s.", version, strings.Join(available, ", ")))
+}
+
+// A versionRequiredError indicates an extension could not be created because
+// it has no default version, and no version was specified.
+type versionRequiredError struct {
+	extension string
+}
+
+func (e *versionRequiredError) Error() string {
+	return fmt.Sprintf("extension %q has no default version; a version must be specified", e.extension)
+}
+
+// IsVersionRequired returns true if the supplied error indicates an extension
+// could not be created because no version was specified.
+func IsVersionRequired(err error) bool {
+	var e *versionRequiredError
+	return errors.As(err, &e)
+}
+
+// hasDefaultVersion returns false if the supplied extension is available, but
+// has no default version.
+func (c *external) hasDefaultVersion(ctx context.Context, extension string) (bool, error) {
+	var missing bool
+	err := c.db.Scan(ctx, xsql.Query{
+		String:     "SELECT default_version IS NULL FROM pg_available_extensions WHERE name = $1",
+		Parameters: []interface{}{extension},
+	}, &missing)
+	if xsql.IsNoRows(err) {
+		// The extension isn't available at all. That's a different problem.
+		return true, nil
+	}
+	return !missing, errors.Wrap(err, errSelectDefaultVersion)
+}
+
+// diagnoseCreateError sets a condition that helps diagnose the supplied
+// CREATE EXTENSION error, if possible, and returns the error to surface.
+func (c *external) diagnoseCreateError(ctx context.Context, cr *v1alpha1.Extension, version *string, err error) error {
+	if cond, ok := classifyCreateError(err); ok {
+		cr.SetConditions(cond)
+	}
+
+	pqe := &pq.Error{}
+	isPQ := errors.As(err, &pqe)
+	if isPQ && isPreloadError(pqe.Message, pqe.Hint) {
+		setRestartRequired(cr, preloadRequired(pqe.Message))
+	}
+
+	if version != nil && isVersionUnavailable(err) {
+		return errors.Wrap(c.diagnoseUnavailableVersion(ctx, cr, *version, err), errCreateExtension)
+	}
+
+	// Only a version-less CREATE EXTENSION rejected by the server can fail
+	// for want of a default version.
+	if version != nil || !isPQ {
+		return errors.Wrap(err, errCreateExtension)
+	}
+
+	if ok, derr := c.hasDefaultVersion(ctx, cr.Spec.ForProvider.Extension); derr == nil && !ok {
+		cr.SetConditions(VersionRequired())
+		return errors.Wrap(&versionRequiredError{extension: cr.Spec.ForProvider.Extension}, errCreateExtension)
+	}
+
+	return errors.Wrap(err, errCreateExtension)
+}
+
+// classifyCreateError returns a condition that describes the supplied
+// CREATE EXTENSION error, if it is one we recognise.
+func classifyCreateError(err error) (xpv1.Condition, bool) {
+	pqe := &pq.Error{}
+	if !errors.As(err, &pqe) {
+		return xpv1.Condition{}, false
+	}
+
+	msg := strings.ToLower(pqe.Message)
+	switch {
+	case strings.Contains(msg, "extension control file"):
+		return ControlFileMissing(), true
+	case strings.Contains(msg, "could not load library"),
+		pqe.Code == pqUndefinedFile && strings.Contains(msg, "could not access file"):
+		return LibraryLoadFailed(), true
+	}
+	return xpv1.Condition{}, false
+}
+
+// clearDiagnostics marks any true diagnostic conditions as resolved.
+func clearDiagnostics(o resource.Conditioned) {
+	for _, t := range diagnosticTypes {
+		if o.GetCondition(t).Status != corev1.ConditionTrue {
+			continue
+		}
+		o.SetConditions(xpv1.Condition{
+			Type:               t,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Reason:             ReasonResolved,
+		})
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestClassifyCreateError(t *testing.T) {
+	type want struct {
+		t  xpv1.ConditionType
+		ok bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   want
+	}{
+		"ControlFileMissing": {
+			reason: "A missing control file should be classified as ControlFileMissing",
+			err: errors.Wrap(&pq.Error{
+				Code:    pqUndefinedFile,
+				Message: `could not open extension control file "/usr/share/postgresql/13/extension/postgis.control": No such file or directory`,
+			}, "boom"),
+			want: want{t: TypeControlFileMissing, ok: true},
+		},
+		"LibraryAccessFailed": {
+			reason: "A missing shared library should be classified as LibraryLoadFailed",
+			err: &pq.Error{
+				Code:    pqUndefinedFile,
+				Message: `could not access file "$libdir/postgis-3": No such file or directory`,
+			},
+			want: want{t: TypeLibraryLoadFailed, ok: true},
+		},
+		"LibraryLoadFailed": {
+			reason: "A shared library that cannot be loaded should be classified as LibraryLoadFailed",
+			err: &pq.Error{
+				Code:    pq.ErrorCode("XX000"),
+				Message: `could not load library "/usr/lib/postgresql/13/lib/pg_cron.so": undefined symbol`,
+			},
+			want: want{t: TypeLibraryLoadFailed, ok: true},
+		},
+		"UnrecognisedPQError": {
+			reason: "Other PostgreSQL errors should not be classified",
+			err: &pq.Error{
+				Code:    pq.ErrorCode("42501"),
+				Message: "permission denied to create extension",
+			},
+			want: want{ok: false},
+		},
+		"NotPQError": {
+			reason: "Errors that did not come from PostgreSQL should not be classified",
+			err:    errors.New("could not open extension control file"),
+			want:   want{ok: false},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c, ok := classifyCreateError(tc.err)
+			if diff := cmp.Diff(tc.want.ok, ok); diff != "" {
+				t.Errorf("\n%s\nclassifyCreateError(...): -want ok, +got ok:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.t, c.Type); diff != "" {
+				t.Errorf("\n%s\nclassifyCreateError(...): -want type, +got type:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDiagnoseCreateError(t *testing.T) {
+	errBoom := errors.New("boom")
+	errNoVersion := &pq.Error{Code: "22023", Message: "version to install must be specified"}
+
+	defaultVersionMissing := func(missing bool, err error) func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+		return func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			*dest[0].(*bool) = missing
+			return err
+		}
+	}
+
+	type args struct {
+		db      xsql.DB
+		version *string
+		err     error
+	}
+
+	type want struct {
+		versionRequired bool
+		status          corev1.ConditionStatus
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoDefaultVersion": {
+			reason: "A VersionRequired condition should be set when the extension has no default version",
+			args: args{
+				db:  mockDB{MockScan: defaultVersionMissing(true, nil)},
+				err: errNoVersion,
+			},
+			want: want{versionRequired: true, status: corev1.ConditionTrue},
+		},
+		"HasDefaultVersion": {
+			reason: "No VersionRequired condition should be set when the extension has a default version",
+			args: args{
+				db:  mockDB{MockScan: defaultVersionMissing(false, nil)},
+				err: errNoVersion,
+			},
+			want: want{status: corev1.ConditionUnknown},
+		},
+		"NotAvailable": {
+			reason: "No VersionRequired condition should be set when the extension is not available at all",
+			args: args{
+				db:  mockDB{MockScan: defaultVersionMissing(false, sql.ErrNoRows)},
+				err: errNoVersion,
+			},
+			want: want{status: corev1.ConditionUnknown},
+		},
+		"VersionSpecified": {
+			reason: "The default version should not be checked when a version was specified",
+			args: args{
+				version: pointer.StringPtr("1.0"),
+				err:     errNoVersion,
+			},
+			want: want{status: corev1.ConditionUnknown},
+		},
+		"NotAServerError": {
+			reason: "The default version should not be checked when the error did not come from the server",
+			args: args{
+				err: errBoom,
+			},
+			want: want{status: corev1.ConditionUnknown},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.args.db}
+			cr := &v1alpha1.Extension{Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{Extension: "cool"}}}
+			err := e.diagnoseCreateError(context.Background(), cr, tc.args.version, tc.args.err)
+			if got := IsVersionRequired(err); got != tc.want.versionRequired {
+				t.Errorf("\n%s\nIsVersionRequired(...): want %t, got %t", tc.reason, tc.want.versionRequired, got)
+			}
+			if diff := cmp.Diff(tc.want.status, cr.GetCondition(TypeVersionRequired).Status); diff != "" {
+				t.Errorf("\n%s\ne.diagnoseCreateError(...): -want status, +got status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// TypeSchemaConflict is true while the objects of a relocatable extension are
+// spread across more than one schema.
+const TypeSchemaConflict xpv1.ConditionType = "SchemaConflict"
+
+// ReasonObjectsInOtherSchemas indicates some of an extension's objects are not
+// in the extension's schema.
+const ReasonObjectsInOtherSchemas xpv1.ConditionReason = "ObjectsInOtherSchemas"
+
+const (
+	errSelectConflicts = "cannot select schemas of extension objects"
+	errScanConflict    = "cannot scan schema of extension object"
+)
+
+// SchemaConflict returns a condition that indicates some of the extension's
+// objects are in the supplied schemas, rather than the extension's schema.
+func SchemaConflict(schema string, others []string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeSchemaConflict,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonObjectsInOtherSchemas,
+		Message: fmt.Sprintf("The extension is installed in schema %q, but some of its objects are in schemas %s. "+
+			"The objects were probably moved manually; move them back, or drop and recreate the extension.", schema, strings.Join(others, ", ")),
+	}
+}
+
+// NoSchemaConflict returns a condition that indicates all of the extension's
+// objects are in the extension's schema.
+func NoSchemaConflict() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeSchemaConflict,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonResolved,
+	}
+}
+
+// conflictQuery selects the schemas, other than the extension's own, that
+// contain the extension's relations, functions, or types.
+const conflictQuery = "SELECT DISTINCT n.nspname " +
+	"FROM pg_extension e " +
+	"JOIN pg_depend d ON d.refclassid = 'pg_extension'::regclass AND d.refobjid = e.oid AND d.deptype = 'e' " +
+	"JOIN (" +
+	"SELECT 'pg_class'::regclass, oid, relnamespace FROM pg_class " +
+	"UNION ALL SELECT 'pg_proc'::regclass, oid, pronamespace FROM pg_proc " +
+	"UNION ALL SELECT 'pg_type'::regclass, oid, typnamespace FROM pg_type" +
+	") o(classid, objid, nsp) ON o.classid = d.classid AND o.objid = d.objid " +
+	"JOIN pg_namespace n ON n.oid = o.nsp " +
+	"WHERE e.extname = $1 AND o.nsp <> e.extnamespace " +
+	"ORDER BY n.nspname"
+
+// observeConflicts reports whether the objects of a relocatable extension are
+// spread across more than one schema. PostgreSQL requires that all of a
+// relocatable extension's objects are in its schema, so this is only checked
+// for relocatable extensions; others may legitimately create objects in
+// several schemas.
+func (c *external) observeConflicts(ctx context.Context, cr *v1alpha1.Extension, schema sql.NullString, relocatable sql.NullBool) error {
+	if !relocatable.Valid || !relocatable.Bool || !schema.Valid {
+		return nil
+	}
+
+	rows, err := c.db.Query(ctx, xsql.Query{String: conflictQuery, Parameters: []interface{}{cr.Spec.ForProvider.Extension}})
+	if err != nil {
+		return errors.Wrap(err, errSelectConflicts)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var others []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return errors.Wrap(err, errScanConflict)
+		}
+		others = append(others, s)
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, errSelectConflicts)
+	}
+
+	switch {
+	case len(others) > 0:
+		cr.SetConditions(SchemaConflict(schema.String, others))
+	case cr.GetCondition(TypeSchemaConflict).Status == corev1.ConditionTrue:
+		cr.SetConditions(NoSchemaConflict())
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestObserveConflicts(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type args struct {
+		relocatable sql.NullBool
+		query       func(ctx context.Context, q xsql.Query) (*sql.Rows, error)
+		existing    corev1.ConditionStatus
+	}
+
+	type want struct {
+		cond    corev1.ConditionStatus
+		message string
+		err     error
+	}
+
+	rows := func(schemas ...string) func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+		return func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+			r := sqlmock.NewRows([]string{"nspname"})
+			for _, s := range schemas {
+				r.AddRow(s)
+			}
+			return mockRowsToSQLRows(r), nil
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Conflict": {
+			reason: "A conflict should be reported when a relocatable extension's objects are in other schemas",
+			args: args{
+				relocatable: sql.NullBool{Bool: true, Valid: true},
+				query:       rows("app", "legacy"),
+			},
+			want: want{
+				cond:    corev1.ConditionTrue,
+				message: SchemaConflict("public", []string{"app", "legacy"}).Message,
+			},
+		},
+		"NoConflict": {
+			reason: "A previously reported conflict should be resolved when all objects are in the extension's schema",
+			args: args{
+				relocatable: sql.NullBool{Bool: true, Valid: true},
+				query:       rows(),
+				existing:    corev1.ConditionTrue,
+			},
+			want: want{cond: corev1.ConditionFalse},
+		},
+		"NeverConflicted": {
+			reason: "No condition should be set when there has never been a conflict",
+			args: args{
+				relocatable: sql.NullBool{Bool: true, Valid: true},
+				query:       rows(),
+			},
+			want: want{cond: corev1.ConditionUnknown},
+		},
+		"NotRelocatable": {
+			reason: "Extensions that are not relocatable may legitimately use several schemas, and should not be checked",
+			args: args{
+				relocatable: sql.NullBool{Bool: false, Valid: true},
+				query: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+					t.Errorf("MockQuery: unexpected query: %s", q.String)
+					return nil, errBoom
+				},
+			},
+			want: want{cond: corev1.ConditionUnknown},
+		},
+		"ErrSelectConflicts": {
+			reason: "Errors selecting the schemas of the extension's objects should be returned",
+			args: args{
+				relocatable: sql.NullBool{Bool: true, Valid: true},
+				query:       func(ctx context.Context, q xsql.Query) (*sql.Rows, error) { return nil, errBoom },
+			},
+			want: want{cond: corev1.ConditionUnknown, err: errors.Wrap(errBoom, errSelectConflicts)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: mockDB{MockQuery: tc.args.query}}
+			cr := &v1alpha1.Extension{}
+			cr.Spec.ForProvider.Extension = "hstore"
+			if tc.args.existing == corev1.ConditionTrue {
+				cr.SetConditions(SchemaConflict("public", []string{"app"}))
+			}
+
+			err := e.observeConflicts(context.Background(), cr, sql.NullString{String: "public", Valid: true}, tc.args.relocatable)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.observeConflicts(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			got := cr.GetCondition(TypeSchemaConflict)
+			if diff := cmp.Diff(tc.want.cond, got.Status); diff != "" {
+				t.Errorf("\n%s\ne.observeConflicts(...): -want condition status, +got condition status:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.message, got.Message); diff != "" {
+				t.Errorf("\n%s\ne.observeConflicts(...): -want message, +got message:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
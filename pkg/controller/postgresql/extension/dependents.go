@@ -0,0 +1,132 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// TypeWaitingForDependents is true while an extension cannot be dropped
+// because objects that don't belong to it depend on it.
+const TypeWaitingForDependents xpv1.ConditionType = "WaitingForDependents"
+
+// ReasonDependentsExist indicates objects depend on the extension.
+const ReasonDependentsExist xpv1.ConditionReason = "DependentsExist"
+
+const (
+	errSelectDependents     = "cannot select objects that depend on extension"
+	errScanDependent        = "cannot scan object that depends on extension"
+	errWaitingForDependents = "cannot drop extension while objects depend on it: %s"
+
+	// maxReportedDependents limits how many dependents we list in a
+	// condition message.
+	maxReportedDependents = 10
+)
+
+// dependentsQuery selects a description of each object that depends on an
+// extension, or on one of its objects, but does not itself belong to the
+// extension. For example an index that uses one of the extension's operator
+// classes, or another extension that requires it. DROP EXTENSION fails while
+// any of these exist, unless it cascades.
+const dependentsQuery = "WITH objs AS (" +
+	"SELECT 'pg_extension'::regclass AS classid, e.oid AS objid FROM pg_extension e WHERE e.extname = $1 " +
+	"UNION SELECT m.classid, m.objid FROM pg_extension e " +
+	"JOIN pg_depend m ON m.refclassid = 'pg_extension'::regclass AND m.refobjid = e.oid AND m.deptype = 'e' " +
+	"WHERE e.extname = $1" +
+	") SELECT DISTINCT pg_describe_object(d.classid, d.objid, d.objsubid) " +
+	"FROM pg_depend d JOIN objs ON d.refclassid = objs.classid AND d.refobjid = objs.objid " +
+	"WHERE d.deptype = 'n' AND NOT EXISTS (SELECT 1 FROM objs WHERE objs.classid = d.classid AND objs.objid = d.objid) " +
+	"ORDER BY 1"
+
+// WaitingForDependents returns a condition that indicates the extension
+// cannot be dropped until the supplied objects no longer depend on it.
+func WaitingForDependents(dependents []string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeWaitingForDependents,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDependentsExist,
+		Message: fmt.Sprintf("The extension will be dropped once no objects depend on it. Objects that depend on it: %s.",
+			summarizeDependents(dependents)),
+	}
+}
+
+// NoDependents returns a condition that indicates no objects depend on the
+// extension.
+func NoDependents() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeWaitingForDependents,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonResolved,
+	}
+}
+
+// summarizeDependents lists at most maxReportedDependents dependents.
+func summarizeDependents(dependents []string) string {
+	if len(dependents) <= maxReportedDependents {
+		return strings.Join(dependents, ", ")
+	}
+	return fmt.Sprintf("%s, and %d more", strings.Join(dependents[:maxReportedDependents], ", "), len(dependents)-maxReportedDependents)
+}
+
+func (c *external) dependents(ctx context.Context, extension string) ([]string, error) {
+	rows, err := c.db.Query(ctx, xsql.Query{String: dependentsQuery, Parameters: []interface{}{extension}})
+	if err != nil {
+		return nil, errors.Wrap(err, errSelectDependents)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var deps []string
+	for rows.Next() {
+		var d string
+		if err := rows.Scan(&d); err != nil {
+			return nil, errors.Wrap(err, errScanDependent)
+		}
+		deps = append(deps, d)
+	}
+	return deps, errors.Wrap(rows.Err(), errSelectDependents)
+}
+
+// awaitDependents returns an error, and sets the WaitingForDependents
+// condition, while objects depend on the extension. The managed reconciler
+// retries the deletion until they're gone.
+func (c *external) awaitDependents(ctx context.Context, cr *v1alpha1.Extension) error {
+	deps, err := c.dependents(ctx, cr.Spec.ForProvider.Extension)
+	if err != nil {
+		return err
+	}
+	if len(deps) > 0 {
+		cr.SetConditions(WaitingForDependents(deps))
+		return errors.Errorf(errWaitingForDependents, summarizeDependents(deps))
+	}
+	if cr.GetCondition(TypeWaitingForDependents).Status == corev1.ConditionTrue {
+		cr.SetConditions(NoDependents())
+	}
+	return nil
+}
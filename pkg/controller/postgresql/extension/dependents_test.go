@@ -0,0 +1,134 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// queryNoDependents answers dependentsQuery, indicating nothing depends on
+// the extension.
+func queryNoDependents(_ context.Context, _ xsql.Query) (*sql.Rows, error) {
+	return mockRowsToSQLRows(sqlmock.NewRows([]string{"pg_describe_object"})), nil
+}
+
+func TestDeleteDependents(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		err     error
+		dropped bool
+		status  corev1.ConditionStatus
+	}
+
+	cases := map[string]struct {
+		reason     string
+		dependents []string
+		queryErr   error
+		waiting    bool
+		want       want
+	}{
+		"Blocked": {
+			reason:     "The extension should not be dropped while objects depend on it",
+			dependents: []string{"index widgets_tags_idx", "extension postgis_topology"},
+			want: want{
+				err:    errors.Errorf(errWaitingForDependents, "index widgets_tags_idx, extension postgis_topology"),
+				status: corev1.ConditionTrue,
+			},
+		},
+		"Unblocked": {
+			reason:  "The extension should be dropped once nothing depends on it, and the condition resolved",
+			waiting: true,
+			want:    want{dropped: true, status: corev1.ConditionFalse},
+		},
+		"NoDependents": {
+			reason: "The extension should be dropped without a condition if nothing ever depended on it",
+			want:   want{dropped: true, status: corev1.ConditionUnknown},
+		},
+		"ErrSelectDependents": {
+			reason:   "The extension should not be dropped if we can't tell whether objects depend on it",
+			queryErr: errBoom,
+			want:     want{err: errors.Wrap(errBoom, errSelectDependents), status: corev1.ConditionUnknown},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dropped := false
+			e := &external{db: mockDB{
+				MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+					if q.String != dependentsQuery {
+						return nil, errors.Errorf("unexpected query %q", q.String)
+					}
+					if diff := cmp.Diff([]interface{}{"hstore"}, q.Parameters); diff != "" {
+						t.Errorf("MockQuery: -want parameters, +got parameters:\n%s", diff)
+					}
+					rows := sqlmock.NewRows([]string{"pg_describe_object"})
+					for _, d := range tc.dependents {
+						rows.AddRow(d)
+					}
+					return mockRowsToSQLRows(rows), tc.queryErr
+				},
+				MockExec: func(ctx context.Context, q xsql.Query) error {
+					dropped = true
+					return nil
+				},
+			}}
+			cr := &v1alpha1.Extension{Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{Extension: "hstore"}}}
+			if tc.waiting {
+				cr.SetConditions(WaitingForDependents([]string{"index widgets_tags_idx"}))
+			}
+
+			err := e.Delete(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.dropped, dropped); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want dropped, +got dropped:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.status, cr.GetCondition(TypeWaitingForDependents).Status); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want condition status, +got condition status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSummarizeDependents(t *testing.T) {
+	var deps []string
+	for i := 0; i < maxReportedDependents+2; i++ {
+		deps = append(deps, fmt.Sprintf("index idx_%d", i))
+	}
+
+	got := summarizeDependents(deps)
+	want := "index idx_0, index idx_1, index idx_2, index idx_3, index idx_4, index idx_5, index idx_6, index idx_7, index idx_8, index idx_9, and 2 more"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("summarizeDependents(...): -want, +got:\n%s\n", diff)
+	}
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+// driftDiff returns a diff of the desired and observed parameters that are not
+// up to date. Parameters upToDate ignores are omitted, including session
+// parameters, which may be sensitive.
+func driftDiff(observed, desired v1alpha1.ExtensionParameters) string {
+	d := v1alpha1.ExtensionParameters{Extension: desired.Extension}
+	o := v1alpha1.ExtensionParameters{Extension: desired.Extension}
+	if !versionUpToDate(observed, desired) {
+		d.Version, o.Version = desired.Version, observed.Version
+	}
+	if !commentUpToDate(observed, desired) {
+		d.Comment, o.Comment = desired.Comment, observed.Comment
+	}
+	if !schemaUpToDate(observed, desired) {
+		d.Schema, o.Schema = desired.Schema, observed.Schema
+	}
+	return cmp.Diff(d, o)
+}
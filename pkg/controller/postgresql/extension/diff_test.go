@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+func TestDriftDiff(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		observed v1alpha1.ExtensionParameters
+		desired  v1alpha1.ExtensionParameters
+		contains []string
+		omits    []string
+	}{
+		"VersionMismatch": {
+			reason:   "The diff should identify the desired and observed versions when they differ",
+			observed: v1alpha1.ExtensionParameters{Extension: "hstore", Version: pointer.StringPtr("1.1")},
+			desired:  v1alpha1.ExtensionParameters{Extension: "hstore", Version: pointer.StringPtr("1.2")},
+			contains: []string{"Version", `"1.2"`, `"1.1"`},
+		},
+		"UpToDateFieldsOmitted": {
+			reason:   "Fields that are up to date, or that upToDate ignores, should be omitted from the diff",
+			observed: v1alpha1.ExtensionParameters{Extension: "hstore", Version: pointer.StringPtr("1.1"), Schema: pointer.StringPtr("public")},
+			desired:  v1alpha1.ExtensionParameters{Extension: "hstore", Version: pointer.StringPtr("1.1"), Comment: pointer.StringPtr("cool"), Database: pointer.StringPtr("db")},
+			contains: []string{"Comment", `"cool"`},
+			omits:    []string{`"1.1"`, `"public"`, `"db"`},
+		},
+		"SessionParametersOmitted": {
+			reason:   "Session parameters, which may be sensitive, should never appear in the diff",
+			observed: v1alpha1.ExtensionParameters{Extension: "hstore", Version: pointer.StringPtr("1.1")},
+			desired: v1alpha1.ExtensionParameters{
+				Extension:         "hstore",
+				Version:           pointer.StringPtr("1.2"),
+				SessionParameters: map[string]string{"pgaudit.role": "hunter2"},
+			},
+			contains: []string{"Version"},
+			omits:    []string{"pgaudit.role", "hunter2"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := driftDiff(tc.observed, tc.desired)
+			for _, s := range tc.contains {
+				if !strings.Contains(got, s) {
+					t.Errorf("\n%s\ndriftDiff(...): want diff to contain %q, got:\n%s", tc.reason, s, got)
+				}
+			}
+			for _, s := range tc.omits {
+				if strings.Contains(got, s) {
+					t.Errorf("\n%s\ndriftDiff(...): want diff to omit %q, got:\n%s", tc.reason, s, got)
+				}
+			}
+		})
+	}
+}
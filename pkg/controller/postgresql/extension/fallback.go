@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+// TypeVersionFallbackApplied is true while the server's default version of
+// the extension is installed because the desired version is not packaged.
+const TypeVersionFallbackApplied xpv1.ConditionType = "VersionFallbackApplied"
+
+// ReasonDefaultVersionInstalled indicates the server's default version of the
+// extension was installed in place of the desired version.
+const ReasonDefaultVersionInstalled xpv1.ConditionReason = "DefaultVersionInstalled"
+
+// VersionFallbackApplied returns a condition that indicates the server's
+// default version of the extension was installed because the supplied
+// version is not packaged.
+func VersionFallbackApplied(version string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeVersionFallbackApplied,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDefaultVersionInstalled,
+		Message: fmt.Sprintf("Version %s of the extension is not packaged on the database server. "+
+			"The default version was installed instead.", version),
+	}
+}
+
+// VersionFallbackResolved returns a condition that indicates the desired
+// version of the extension no longer differs from the fallback.
+func VersionFallbackResolved() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeVersionFallbackApplied,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonResolved,
+	}
+}
+
+// isVersionUnavailable returns true if the supplied error indicates that the
+// requested version of an extension is not packaged on the server.
+func isVersionUnavailable(err error) bool {
+	pqe := &pq.Error{}
+	if !errors.As(err, &pqe) {
+		return false
+	}
+	return strings.Contains(strings.ToLower(pqe.Message), "no installation script")
+}
+
+// createFallback installs the default version of the extension if the
+// supplied CREATE EXTENSION error indicates the supplied version is not
+// packaged, and the extension opts in to doing so. It returns false if it did
+// not attempt to install the default version.
+func (c *external) createFallback(ctx context.Context, cr *v1alpha1.Extension, version *string, err error) (bool, error) {
+	fb := cr.Spec.ForProvider.VersionFallback
+	if fb == nil || !*fb || version == nil || !isVersionUnavailable(err) {
+		return false, nil
+	}
+
+	if err := c.exec(ctx, cr, createQuery(c.quoter(), cr.Spec.ForProvider, nil), auditActionCreate); err != nil {
+		return true, c.diagnoseCreateError(ctx, cr, nil, err)
+	}
+
+	cr.Status.AtProvider.UnavailableVersion = cr.Spec.ForProvider.Version
+	cr.SetConditions(VersionFallbackApplied(*version))
+	return true, nil
+}
+
+// observeFallback treats the observed version of the extension as desired
+// while the desired version is one we previously fell back from, so that we
+// don't repeatedly try to update to a version that isn't packaged.
+func observeFallback(cr *v1alpha1.Extension, observed v1alpha1.ExtensionParameters, desired *v1alpha1.ExtensionParameters) {
+	uv := cr.Status.AtProvider.UnavailableVersion
+	if uv == nil {
+		return
+	}
+
+	if v := cr.Spec.ForProvider.Version; v != nil && *v == *uv {
+		desired.Version = observed.Version
+		return
+	}
+
+	cr.Status.AtProvider.UnavailableVersion = nil
+	cr.SetConditions(VersionFallbackResolved())
+}
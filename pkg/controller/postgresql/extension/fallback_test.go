@@ -0,0 +1,225 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestCreateFallback(t *testing.T) {
+	errBoom := errors.New("boom")
+	errUnavailable := &pq.Error{Code: "22023", Message: `extension "cool" has no installation script nor update path for version "9.9"`}
+	errListed := errors.Errorf(errVersionUnavailable, "9.9", "cool", "1.0, 1.1")
+
+	// exec fails any versioned CREATE EXTENSION with the supplied error.
+	exec := func(versioned, unversioned error) func(ctx context.Context, q xsql.Query) error {
+		return func(ctx context.Context, q xsql.Query) error {
+			if q.String == `CREATE EXTENSION IF NOT EXISTS "cool"` {
+				return unversioned
+			}
+			return versioned
+		}
+	}
+
+	type args struct {
+		exec     func(ctx context.Context, q xsql.Query) error
+		fallback *bool
+	}
+
+	type want struct {
+		err         error
+		unavailable *string
+		cond        corev1.ConditionStatus
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Strict": {
+			reason: "An unpackaged version should fail to install unless fallback is enabled",
+			args: args{
+				exec: exec(errUnavailable, nil),
+			},
+			want: want{
+				err:  errors.Wrap(errListed, errCreateExtension),
+				cond: corev1.ConditionUnknown,
+			},
+		},
+		"StrictExplicitly": {
+			reason: "An unpackaged version should fail to install when fallback is disabled",
+			args: args{
+				exec:     exec(errUnavailable, nil),
+				fallback: pointer.BoolPtr(false),
+			},
+			want: want{
+				err:  errors.Wrap(errListed, errCreateExtension),
+				cond: corev1.ConditionUnknown,
+			},
+		},
+		"Fallback": {
+			reason: "The default version should be installed when the desired version is not packaged",
+			args: args{
+				exec:     exec(errUnavailable, nil),
+				fallback: pointer.BoolPtr(true),
+			},
+			want: want{
+				unavailable: pointer.StringPtr("9.9"),
+				cond:        corev1.ConditionTrue,
+			},
+		},
+		"OtherError": {
+			reason: "Errors other than an unpackaged version should not cause a fallback",
+			args: args{
+				exec:     exec(errBoom, nil),
+				fallback: pointer.BoolPtr(true),
+			},
+			want: want{
+				err:  errors.Wrap(errBoom, errCreateExtension),
+				cond: corev1.ConditionUnknown,
+			},
+		},
+		"ErrFallback": {
+			reason: "Errors installing the default version should be returned",
+			args: args{
+				exec:     exec(errUnavailable, errBoom),
+				fallback: pointer.BoolPtr(true),
+			},
+			want: want{
+				err:  errors.Wrap(errBoom, errCreateExtension),
+				cond: corev1.ConditionUnknown,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: mockDB{
+				MockExec: tc.args.exec,
+				MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+					return mockRowsToSQLRows(sqlmock.NewRows([]string{"version"}).AddRow("1.1").AddRow("1.0")), nil
+				},
+			}}
+			cr := &v1alpha1.Extension{Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{
+				Extension:       "cool",
+				Version:         pointer.StringPtr("9.9"),
+				VersionFallback: tc.args.fallback,
+			}}}
+			_, err := e.Create(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.unavailable, cr.Status.AtProvider.UnavailableVersion); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want unavailable version, +got unavailable version:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cond, cr.GetCondition(TypeVersionFallbackApplied).Status); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want condition status, +got condition status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveFallback(t *testing.T) {
+	type args struct {
+		version     *string
+		unavailable *string
+	}
+
+	type want struct {
+		version     *string
+		unavailable *string
+		cond        corev1.ConditionStatus
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoFallback": {
+			reason: "The desired version should be unchanged if we never fell back",
+			args: args{
+				version: pointer.StringPtr("9.9"),
+			},
+			want: want{
+				version: pointer.StringPtr("9.9"),
+				cond:    corev1.ConditionUnknown,
+			},
+		},
+		"FellBack": {
+			reason: "The observed version should be desired while the desired version is the one we fell back from",
+			args: args{
+				version:     pointer.StringPtr("9.9"),
+				unavailable: pointer.StringPtr("9.9"),
+			},
+			want: want{
+				version:     pointer.StringPtr("1.0"),
+				unavailable: pointer.StringPtr("9.9"),
+				cond:        corev1.ConditionTrue,
+			},
+		},
+		"VersionChanged": {
+			reason: "The fallback should be resolved once a different version is desired",
+			args: args{
+				version:     pointer.StringPtr("1.1"),
+				unavailable: pointer.StringPtr("9.9"),
+			},
+			want: want{
+				version: pointer.StringPtr("1.1"),
+				cond:    corev1.ConditionFalse,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.Extension{}
+			cr.Spec.ForProvider.Version = tc.args.version
+			cr.Status.AtProvider.UnavailableVersion = tc.args.unavailable
+			if tc.args.unavailable != nil {
+				cr.SetConditions(VersionFallbackApplied(*tc.args.unavailable))
+			}
+
+			desired := cr.Spec.ForProvider
+			observeFallback(cr, v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.0")}, &desired)
+			if diff := cmp.Diff(tc.want.version, desired.Version); diff != "" {
+				t.Errorf("\n%s\nobserveFallback(...): -want version, +got version:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.unavailable, cr.Status.AtProvider.UnavailableVersion); diff != "" {
+				t.Errorf("\n%s\nobserveFallback(...): -want unavailable version, +got unavailable version:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cond, cr.GetCondition(TypeVersionFallbackApplied).Status); diff != "" {
+				t.Errorf("\n%s\nobserveFallback(...): -want condition status, +got condition status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
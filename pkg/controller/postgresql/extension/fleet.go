@@ -0,0 +1,440 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+const (
+	errSelectDatabases = "cannot select databases matching pattern"
+	errScanDatabase    = "cannot scan database name"
+	errFleetDatabase   = "database %q"
+)
+
+// TypePartiallyInstalled is true while the extension could not be installed
+// or updated in some of the databases matched by its pattern.
+const TypePartiallyInstalled xpv1.ConditionType = "PartiallyInstalled"
+
+// ReasonDatabasesFailed indicates an operation failed in some databases.
+const ReasonDatabasesFailed xpv1.ConditionReason = "DatabasesFailed"
+
+// PartiallyInstalled returns a condition that indicates the extension is
+// installed in only installed of total databases.
+func PartiallyInstalled(installed, total int) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypePartiallyInstalled,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDatabasesFailed,
+		Message: fmt.Sprintf("The extension is installed in %d/%d databases, and could not be installed or updated in the others. "+
+			"See status.atProvider.databases for the error encountered in each.", installed, total),
+	}
+}
+
+// ReasonNoMatchingDatabases indicates no allowed databases match the
+// extension's pattern.
+const ReasonNoMatchingDatabases xpv1.ConditionReason = "NoMatchingDatabases"
+
+// NoMatchingDatabases returns a condition that indicates the extension is not
+// available because no allowed databases match the supplied pattern.
+func NoMatchingDatabases(pattern string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonNoMatchingDatabases,
+		Message:            fmt.Sprintf("No allowed databases match pattern %q", pattern),
+	}
+}
+
+// FullyInstalled returns a condition that indicates the extension is installed
+// and up to date in every database.
+func FullyInstalled() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypePartiallyInstalled,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonResolved,
+	}
+}
+
+// A fleetError aggregates the errors encountered in each of several databases.
+type fleetError struct {
+	errs  []error
+	total int
+}
+
+func (e *fleetError) Error() string {
+	msgs := make([]string, len(e.errs))
+	for i := range e.errs {
+		msgs[i] = e.errs[i].Error()
+	}
+	return fmt.Sprintf("failed in %d/%d databases: %s", len(e.errs), e.total, strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the first error, so that it may be classified.
+func (e *fleetError) Unwrap() error { return e.errs[0] }
+
+// fleetErrors returns an error that aggregates the supplied errors, which
+// were encountered in some of total databases, or nil if there are none.
+func fleetErrors(errs []error, total int) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &fleetError{errs: errs, total: total}
+}
+
+// Actions reported as progress.
+const (
+	progressInstalled  = "installed in"
+	progressReconciled = "reconciled"
+	progressDropped    = "dropped from"
+	progressUpgraded   = "upgraded"
+)
+
+// reportProgress sets the progress of the supplied extension, and persists
+// it if persist is set.
+func reportProgress(ctx context.Context, persist func(ctx context.Context, cr *v1alpha1.Extension) error, cr *v1alpha1.Extension, msg string) {
+	cr.Status.AtProvider.Progress = &msg
+
+	// Progress is informational. The managed reconciler persists the final
+	// status, so there's no need to fail the operation if we can't persist
+	// an intermediate one.
+	if persist != nil {
+		_ = persist(ctx, cr)
+	}
+}
+
+// A fleetExternal manages an extension in every database that matches a
+// pattern. It delegates to an external client per database.
+type fleetExternal struct {
+	// db is connected to the default database, and used to list databases.
+	db xsql.DB
+
+	// forDatabase returns an external client for the supplied database.
+	forDatabase func(database string) *external
+
+	// allowed returns true if the ProviderConfig allows the supplied
+	// database to be targeted.
+	allowed func(database string) bool
+
+	// persist persists the status of the supplied extension, so that
+	// progress is visible while an operation is underway.
+	persist func(ctx context.Context, cr *v1alpha1.Extension) error
+}
+
+// progress reports that the supplied action has been taken in done of total
+// databases.
+func (c *fleetExternal) progress(ctx context.Context, cr *v1alpha1.Extension, action string, done, total int) {
+	reportProgress(ctx, c.persist, cr, fmt.Sprintf("%s %d/%d databases", action, done, total))
+}
+
+// databases returns the allowed databases that match the supplied pattern.
+// Templates and databases that don't allow connections are ignored.
+func (c *fleetExternal) databases(ctx context.Context, pattern string) ([]string, error) {
+	rows, err := c.db.Query(ctx, xsql.Query{
+		String:     "SELECT datname FROM pg_database WHERE datname LIKE $1 AND datallowconn AND NOT datistemplate ORDER BY datname",
+		Parameters: []interface{}{pattern},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errSelectDatabases)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	dbs := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, errors.Wrap(err, errScanDatabase)
+		}
+		if c.allowed(name) {
+			dbs = append(dbs, name)
+		}
+	}
+	return dbs, errors.Wrap(rows.Err(), errSelectDatabases)
+}
+
+// record records the result of installing or updating the extension in the
+// supplied database. It returns the supplied error, identifying the database.
+func record(cr *v1alpha1.Extension, database string, err error) error {
+	for i := range cr.Status.AtProvider.Databases {
+		obs := &cr.Status.AtProvider.Databases[i]
+		if obs.Database != database {
+			continue
+		}
+		if err != nil {
+			msg := err.Error()
+			obs.LastError = &msg
+			continue
+		}
+		obs.Installed, obs.UpToDate, obs.LastError = true, true, nil
+	}
+	return errors.Wrapf(err, errFleetDatabase, database)
+}
+
+// reportInstalled reports whether the extension could be installed in every
+// database, given the supplied errors.
+func reportInstalled(cr *v1alpha1.Extension, errs []error) {
+	if len(errs) == 0 {
+		return
+	}
+	installed := 0
+	for _, obs := range cr.Status.AtProvider.Databases {
+		if obs.Installed {
+			installed++
+		}
+	}
+	cr.SetConditions(PartiallyInstalled(installed, len(cr.Status.AtProvider.Databases)))
+}
+
+// reportAvailable reports whether the extension is available, given whether
+// it exists in any database and is up to date in every database.
+func reportAvailable(cr *v1alpha1.Extension, exists, upToDate bool) {
+	if exists {
+		cr.SetConditions(xpv1.Available())
+	}
+	if exists && upToDate {
+		cr.Status.AtProvider.Progress = nil
+		if cr.GetCondition(TypePartiallyInstalled).Status == corev1.ConditionTrue {
+			cr.SetConditions(FullyInstalled())
+		}
+	}
+}
+
+// forDatabaseCopy returns a copy of the supplied extension that targets the
+// supplied database, so that the per-database external client doesn't
+// modify the spec or status of the supplied extension.
+func forDatabaseCopy(cr *v1alpha1.Extension, database string) *v1alpha1.Extension {
+	cp := cr.DeepCopy()
+	cp.Spec.ForProvider.Database = &database
+	cp.Spec.ForProvider.DatabasePattern = nil
+	return cp
+}
+
+// A conditionMerger merges the conditions the per-database external clients
+// set on their copies of an extension back into the extension. A condition
+// that is true in any database is reported as true, identifying the first
+// database it was true in.
+type conditionMerger struct {
+	cr     *v1alpha1.Extension
+	merged map[xpv1.ConditionType]corev1.ConditionStatus
+}
+
+func newConditionMerger(cr *v1alpha1.Extension) *conditionMerger {
+	return &conditionMerger{cr: cr, merged: map[xpv1.ConditionType]corev1.ConditionStatus{}}
+}
+
+// merge the conditions of the supplied copy, which targets the supplied
+// database.
+func (m *conditionMerger) merge(database string, cp *v1alpha1.Extension) {
+	for _, c := range cp.Status.Conditions {
+		// The fleet reports its own readiness, and the managed reconciler
+		// reports whether it's synced.
+		if c.Type == xpv1.TypeReady || c.Type == xpv1.TypeSynced {
+			continue
+		}
+		// The copy inherited this condition from the extension.
+		if c.Equal(m.cr.GetCondition(c.Type)) {
+			continue
+		}
+		if s, ok := m.merged[c.Type]; ok && (s == corev1.ConditionTrue || c.Status != corev1.ConditionTrue) {
+			continue
+		}
+		if c.Message != "" {
+			c.Message = fmt.Sprintf("Database %q: %s", database, c.Message)
+		}
+		m.cr.SetConditions(c)
+		m.merged[c.Type] = c.Status
+	}
+}
+
+func (c *fleetExternal) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Extension)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotExtension)
+	}
+
+	dbs, err := c.databases(ctx, *cr.Spec.ForProvider.DatabasePattern)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	// There's nothing to create, update, or delete until a database matches.
+	// We report that the extension exists so that we aren't asked to create
+	// it, except when it's being deleted, so that its finalizer is removed.
+	if len(dbs) == 0 {
+		cr.Status.AtProvider.Databases = nil
+		cr.Status.AtProvider.PendingStatements = nil
+		cr.Status.AtProvider.Progress = nil
+		cr.SetConditions(NoMatchingDatabases(*cr.Spec.ForProvider.DatabasePattern))
+		return managed.ExternalObservation{ResourceExists: !meta.WasDeleted(cr), ResourceUpToDate: true}, nil
+	}
+
+	// Databases come and go, so we rebuild our observations from scratch,
+	// carrying over only the errors encountered in databases that are still
+	// not up to date.
+	lastErrors := map[string]*string{}
+	for _, obs := range cr.Status.AtProvider.Databases {
+		lastErrors[obs.Database] = obs.LastError
+	}
+	cr.Status.AtProvider.Databases = make([]v1alpha1.ExtensionDatabaseObservation, 0, len(dbs))
+	cr.Status.AtProvider.PendingStatements = nil
+	exists, utd := false, true
+	m := newConditionMerger(cr)
+	for _, name := range dbs {
+		cp := forDatabaseCopy(cr, name)
+		o, err := c.forDatabase(name).Observe(ctx, cp)
+		m.merge(name, cp)
+		if err != nil {
+			return managed.ExternalObservation{}, errors.Wrapf(err, errFleetDatabase, name)
+		}
+
+		obs := v1alpha1.ExtensionDatabaseObservation{
+			Database:  name,
+			Installed: o.ResourceExists,
+			UpToDate:  o.ResourceExists && o.ResourceUpToDate,
+		}
+		if o.ResourceExists {
+			obs.Version = cp.Status.AtProvider.InstalledVersion
+		}
+		if !obs.UpToDate {
+			obs.LastError = lastErrors[name]
+		}
+		cr.Status.AtProvider.Databases = append(cr.Status.AtProvider.Databases, obs)
+		cr.Status.AtProvider.PendingStatements = append(cr.Status.AtProvider.PendingStatements, cp.Status.AtProvider.PendingStatements...)
+
+		exists = exists || obs.Installed
+		utd = utd && obs.UpToDate
+	}
+
+	reportAvailable(cr, exists, utd)
+
+	// If the extension isn't installed in any database we'll be asked to
+	// create it. If it's installed in some we'll be asked to update it, and
+	// will install it in the rest.
+	return managed.ExternalObservation{ResourceExists: exists, ResourceUpToDate: utd}, nil
+}
+
+func (c *fleetExternal) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Extension)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotExtension)
+	}
+
+	pending := []string{}
+	for _, obs := range cr.Status.AtProvider.Databases {
+		if !obs.Installed {
+			pending = append(pending, obs.Database)
+		}
+	}
+
+	// A failure in one database doesn't stop us installing the extension in
+	// the others.
+	errs, done := []error{}, 0
+	m := newConditionMerger(cr)
+	for _, name := range pending {
+		cp := forDatabaseCopy(cr, name)
+		_, err := c.forDatabase(name).Create(ctx, cp)
+		m.merge(name, cp)
+		if err := record(cr, name, err); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		done++
+		c.progress(ctx, cr, progressInstalled, done, len(pending))
+	}
+	reportInstalled(cr, errs)
+	return managed.ExternalCreation{}, fleetErrors(errs, len(pending))
+}
+
+func (c *fleetExternal) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Extension)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotExtension)
+	}
+
+	pending := []v1alpha1.ExtensionDatabaseObservation{}
+	for _, obs := range cr.Status.AtProvider.Databases {
+		if !obs.Installed || !obs.UpToDate {
+			pending = append(pending, obs)
+		}
+	}
+
+	// A failure in one database doesn't stop us reconciling the extension in
+	// the others.
+	errs, done := []error{}, 0
+	m := newConditionMerger(cr)
+	for _, obs := range pending {
+		var err error
+		cp := forDatabaseCopy(cr, obs.Database)
+		if obs.Installed {
+			_, err = c.forDatabase(obs.Database).Update(ctx, cp)
+		} else {
+			_, err = c.forDatabase(obs.Database).Create(ctx, cp)
+		}
+		m.merge(obs.Database, cp)
+		if err := record(cr, obs.Database, err); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		done++
+		c.progress(ctx, cr, progressReconciled, done, len(pending))
+	}
+	reportInstalled(cr, errs)
+	return managed.ExternalUpdate{}, fleetErrors(errs, len(pending))
+}
+
+func (c *fleetExternal) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Extension)
+	if !ok {
+		return errors.New(errNotExtension)
+	}
+
+	pending := []string{}
+	for _, obs := range cr.Status.AtProvider.Databases {
+		if obs.Installed {
+			pending = append(pending, obs.Database)
+		}
+	}
+
+	m := newConditionMerger(cr)
+	for i, name := range pending {
+		cp := forDatabaseCopy(cr, name)
+		err := c.forDatabase(name).Delete(ctx, cp)
+		m.merge(name, cp)
+		if err != nil {
+			return errors.Wrapf(err, errFleetDatabase, name)
+		}
+		c.progress(ctx, cr, progressDropped, i+1, len(pending))
+	}
+	return nil
+}
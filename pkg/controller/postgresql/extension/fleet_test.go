@@ -0,0 +1,590 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// fleetDB returns a client for the named database, in which the extension is
+// either installed at the supplied version, or not installed if nil.
+func fleetDB(version *string, execs *[]string, database string) *external {
+	return &external{db: mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			if version == nil {
+				return scanAbsent(ctx, q, dest...)
+			}
+			*dest[0].(*string) = *version
+			return nil
+		},
+		MockExec: func(ctx context.Context, q xsql.Query) error {
+			*execs = append(*execs, database+": "+q.String)
+			return nil
+		},
+		MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+			*execs = append(*execs, database+": "+ql[0].String)
+			return nil
+		},
+	}}
+}
+
+func TestFleetDatabases(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		dbs []string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		db      xsql.DB
+		allowed func(string) bool
+		want    want
+	}{
+		"Allowed": {
+			reason: "Only matching databases the ProviderConfig allows should be returned",
+			db: mockDB{
+				MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+					if diff := cmp.Diff([]interface{}{"tenant_%"}, q.Parameters); diff != "" {
+						t.Errorf("MockQuery: -want, +got:\n%s", diff)
+					}
+					return mockRowsToSQLRows(sqlmock.NewRows([]string{"datname"}).AddRow("tenant_a").AddRow("tenant_b").AddRow("tenant_secret")), nil
+				},
+			},
+			allowed: func(db string) bool { return db != "tenant_secret" },
+			want:    want{dbs: []string{"tenant_a", "tenant_b"}},
+		},
+		"ErrSelect": {
+			reason: "Errors selecting databases should be returned",
+			db: mockDB{
+				MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) { return nil, errBoom },
+			},
+			want: want{err: errors.Wrap(errBoom, errSelectDatabases)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := fleetExternal{db: tc.db, allowed: tc.allowed}
+			got, err := e.databases(context.Background(), "tenant_%")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.databases(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.dbs, got); diff != "" {
+				t.Errorf("\n%s\ne.databases(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFleetReconcile(t *testing.T) {
+	// tenant_a is up to date, tenant_b has an old version, and tenant_c was
+	// created after the extension was first installed.
+	installed := map[string]*string{
+		"tenant_a": pointer.StringPtr("1.1"),
+		"tenant_b": pointer.StringPtr("1.0"),
+		"tenant_c": nil,
+	}
+
+	lister := mockDB{
+		MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+			return mockRowsToSQLRows(sqlmock.NewRows([]string{"datname"}).AddRow("tenant_a").AddRow("tenant_b").AddRow("tenant_c")), nil
+		},
+	}
+
+	execs := []string{}
+	e := &fleetExternal{
+		db:          lister,
+		forDatabase: func(database string) *external { return fleetDB(installed[database], &execs, database) },
+		allowed:     func(string) bool { return true },
+	}
+
+	cr := &v1alpha1.Extension{
+		Spec: v1alpha1.ExtensionSpec{
+			ForProvider: v1alpha1.ExtensionParameters{
+				Extension:       "hstore",
+				Version:         pointer.StringPtr("1.1"),
+				DatabasePattern: pointer.StringPtr("tenant_%"),
+			},
+		},
+	}
+
+	// Errors from a previous attempt should be carried over only for the
+	// databases that are still not up to date.
+	cr.Status.AtProvider.Databases = []v1alpha1.ExtensionDatabaseObservation{
+		{Database: "tenant_a", LastError: pointer.StringPtr("fixed")},
+		{Database: "tenant_b", LastError: pointer.StringPtr("boom")},
+	}
+
+	o, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): %s", err)
+	}
+	if diff := cmp.Diff(managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}, o); diff != "" {
+		t.Errorf("e.Observe(...): -want, +got:\n%s", diff)
+	}
+
+	wantObs := []v1alpha1.ExtensionDatabaseObservation{
+		{Database: "tenant_a", Installed: true, Version: pointer.StringPtr("1.1"), UpToDate: true},
+		{Database: "tenant_b", Installed: true, Version: pointer.StringPtr("1.0"), UpToDate: false, LastError: pointer.StringPtr("boom")},
+		{Database: "tenant_c", Installed: false, UpToDate: false},
+	}
+	if diff := cmp.Diff(wantObs, cr.Status.AtProvider.Databases); diff != "" {
+		t.Errorf("e.Observe(...): -want databases, +got databases:\n%s", diff)
+	}
+	if cr.Spec.ForProvider.Database != nil {
+		t.Errorf("e.Observe(...): per-database observation should not modify the spec")
+	}
+
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): %s", err)
+	}
+	wantExecs := []string{
+		`tenant_b: ALTER EXTENSION "hstore" UPDATE TO "1.1"`,
+		`tenant_c: CREATE EXTENSION IF NOT EXISTS "hstore" WITH VERSION "1.1"`,
+	}
+	if diff := cmp.Diff(wantExecs, execs); diff != "" {
+		t.Errorf("e.Update(...): -want statements, +got statements:\n%s", diff)
+	}
+}
+
+func TestFleetNoMatchingDatabases(t *testing.T) {
+	lister := mockDB{
+		MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+			return mockRowsToSQLRows(sqlmock.NewRows([]string{"datname"})), nil
+		},
+	}
+
+	cases := map[string]struct {
+		reason  string
+		deleted bool
+		want    managed.ExternalObservation
+	}{
+		"NoMatch": {
+			reason: "An extension whose pattern matches no databases should exist, so that we aren't asked to create it",
+			want:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+		},
+		"Deleting": {
+			reason:  "An extension whose pattern matches no databases should not exist when it's being deleted",
+			deleted: true,
+			want:    managed.ExternalObservation{ResourceExists: false, ResourceUpToDate: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &fleetExternal{
+				db: lister,
+				forDatabase: func(database string) *external {
+					t.Errorf("e.Observe(...): unexpected client for database %q", database)
+					return nil
+				},
+				allowed: func(string) bool { return true },
+			}
+
+			cr := &v1alpha1.Extension{}
+			cr.Spec.ForProvider.Extension = "hstore"
+			cr.Spec.ForProvider.DatabasePattern = pointer.StringPtr("tenant_%")
+			cr.Status.AtProvider.Databases = []v1alpha1.ExtensionDatabaseObservation{{Database: "tenant_a", Installed: true}}
+			cr.SetConditions(xpv1.Available())
+			if tc.deleted {
+				now := metav1.Now()
+				cr.SetDeletionTimestamp(&now)
+			}
+
+			o, err := e.Observe(context.Background(), cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, o); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(NoMatchingDatabases("tenant_%"), cr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want condition, +got condition:\n%s\n", tc.reason, diff)
+			}
+			if len(cr.Status.AtProvider.Databases) != 0 {
+				t.Errorf("\n%s\ne.Observe(...): want no databases, got %v", tc.reason, cr.Status.AtProvider.Databases)
+			}
+		})
+	}
+}
+
+func TestFleetObserveConditions(t *testing.T) {
+	lister := mockDB{
+		MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+			return mockRowsToSQLRows(sqlmock.NewRows([]string{"datname"}).AddRow("tenant_a").AddRow("tenant_b")), nil
+		},
+	}
+
+	// The connected role may not read the owner of the extension in
+	// tenant_b.
+	e := &fleetExternal{
+		db: lister,
+		forDatabase: func(database string) *external {
+			return &external{db: mockDB{
+				MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+					if database == "tenant_b" && q.String != observeQuery("", true) {
+						return &pq.Error{Code: "42501"}
+					}
+					*dest[0].(*string) = "1.1"
+					return nil
+				},
+			}}
+		},
+		allowed: func(string) bool { return true },
+	}
+
+	cr := &v1alpha1.Extension{}
+	cr.Spec.ForProvider.Extension = "hstore"
+	cr.Spec.ForProvider.Version = pointer.StringPtr("1.1")
+	cr.Spec.ForProvider.DatabasePattern = pointer.StringPtr("tenant_%")
+
+	if _, err := e.Observe(context.Background(), cr); err != nil {
+		t.Fatalf("e.Observe(...): %s", err)
+	}
+
+	want := OwnerUnreadable()
+	want.Message = `Database "tenant_b": ` + want.Message
+	if diff := cmp.Diff(want, cr.GetCondition(TypeOwnerUnreadable), test.EquateConditions()); diff != "" {
+		t.Errorf("e.Observe(...): -want condition, +got condition:\n%s", diff)
+	}
+}
+
+func TestFleetProgress(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	// Each operation is run against databases with these observations.
+	observed := []v1alpha1.ExtensionDatabaseObservation{
+		{Database: "tenant_a", Installed: true, UpToDate: true},
+		{Database: "tenant_b", Installed: false},
+		{Database: "tenant_c", Installed: true, UpToDate: false},
+		{Database: "tenant_d", Installed: false},
+	}
+
+	type want struct {
+		progress []string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fail   string
+		op     func(ctx context.Context, e *fleetExternal, cr *v1alpha1.Extension) error
+		want   want
+	}{
+		"Create": {
+			reason: "Progress should be reported as the extension is installed in each database",
+			op: func(ctx context.Context, e *fleetExternal, cr *v1alpha1.Extension) error {
+				_, err := e.Create(ctx, cr)
+				return err
+			},
+			want: want{progress: []string{"installed in 1/2 databases", "installed in 2/2 databases"}},
+		},
+		"Update": {
+			reason: "Progress should be reported as the extension is reconciled in each database that needs it",
+			op: func(ctx context.Context, e *fleetExternal, cr *v1alpha1.Extension) error {
+				_, err := e.Update(ctx, cr)
+				return err
+			},
+			want: want{progress: []string{"reconciled 1/3 databases", "reconciled 2/3 databases", "reconciled 3/3 databases"}},
+		},
+		"UpdateFailed": {
+			reason: "Progress should reflect the databases reconciled despite an error in another",
+			fail:   "tenant_c",
+			op: func(ctx context.Context, e *fleetExternal, cr *v1alpha1.Extension) error {
+				_, err := e.Update(ctx, cr)
+				return err
+			},
+			want: want{
+				progress: []string{"reconciled 1/3 databases", "reconciled 2/3 databases"},
+				err:      fleetErrors([]error{errors.Wrapf(errors.Wrap(errBoom, errUpdateExtension), errFleetDatabase, "tenant_c")}, 3),
+			},
+		},
+		"Delete": {
+			reason: "Progress should be reported as the extension is dropped from each database",
+			op: func(ctx context.Context, e *fleetExternal, cr *v1alpha1.Extension) error {
+				return e.Delete(ctx, cr)
+			},
+			want: want{progress: []string{"dropped from 1/2 databases", "dropped from 2/2 databases"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			persisted := []string{}
+			e := &fleetExternal{
+				forDatabase: func(database string) *external {
+					fail := func() error {
+						if database == tc.fail {
+							return errBoom
+						}
+						return nil
+					}
+					return &external{db: mockDB{
+						MockQuery:  queryNoDependents,
+						MockExec:   func(ctx context.Context, q xsql.Query) error { return fail() },
+						MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return fail() },
+					}}
+				},
+				persist: func(ctx context.Context, cr *v1alpha1.Extension) error {
+					persisted = append(persisted, *cr.Status.AtProvider.Progress)
+					return nil
+				},
+			}
+
+			cr := &v1alpha1.Extension{}
+			cr.Spec.ForProvider.Extension = "hstore"
+			cr.Spec.ForProvider.Version = pointer.StringPtr("1.1")
+			cr.Status.AtProvider.Databases = append([]v1alpha1.ExtensionDatabaseObservation{}, observed...)
+
+			err := tc.op(context.Background(), e, cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\n-want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.progress, persisted); diff != "" {
+				t.Errorf("\n%s\n-want persisted progress, +got persisted progress:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFleetIsolation(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	// Each operation is run against databases with these observations.
+	observed := []v1alpha1.ExtensionDatabaseObservation{
+		{Database: "tenant_a", Installed: true, UpToDate: true},
+		{Database: "tenant_b", Installed: false},
+		{Database: "tenant_c", Installed: false},
+		{Database: "tenant_d", Installed: true, UpToDate: false},
+	}
+
+	type want struct {
+		execs     []string
+		databases []v1alpha1.ExtensionDatabaseObservation
+		cond      corev1.ConditionStatus
+		message   string
+		err       error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fail   map[string]bool
+		op     func(ctx context.Context, e *fleetExternal, cr *v1alpha1.Extension) error
+		want   want
+	}{
+		"CreateMixed": {
+			reason: "A failure to install the extension in one database should not stop it being installed in the others",
+			fail:   map[string]bool{"tenant_b": true},
+			op: func(ctx context.Context, e *fleetExternal, cr *v1alpha1.Extension) error {
+				_, err := e.Create(ctx, cr)
+				return err
+			},
+			want: want{
+				execs: []string{
+					`tenant_b: CREATE EXTENSION IF NOT EXISTS "hstore" WITH VERSION "1.1"`,
+					`tenant_c: CREATE EXTENSION IF NOT EXISTS "hstore" WITH VERSION "1.1"`,
+				},
+				databases: []v1alpha1.ExtensionDatabaseObservation{
+					{Database: "tenant_a", Installed: true, UpToDate: true},
+					{Database: "tenant_b", Installed: false, LastError: pointer.StringPtr("cannot create extension: boom")},
+					{Database: "tenant_c", Installed: true, UpToDate: true},
+					{Database: "tenant_d", Installed: true, UpToDate: false},
+				},
+				cond:    corev1.ConditionTrue,
+				message: PartiallyInstalled(3, 4).Message,
+				err:     fleetErrors([]error{errors.Wrapf(errors.Wrap(errBoom, errCreateExtension), errFleetDatabase, "tenant_b")}, 2),
+			},
+		},
+		"UpdateMixed": {
+			reason: "Failures in some databases should not stop the extension being reconciled in the others",
+			fail:   map[string]bool{"tenant_b": true, "tenant_d": true},
+			op: func(ctx context.Context, e *fleetExternal, cr *v1alpha1.Extension) error {
+				_, err := e.Update(ctx, cr)
+				return err
+			},
+			want: want{
+				execs: []string{
+					`tenant_b: CREATE EXTENSION IF NOT EXISTS "hstore" WITH VERSION "1.1"`,
+					`tenant_c: CREATE EXTENSION IF NOT EXISTS "hstore" WITH VERSION "1.1"`,
+					`tenant_d: ALTER EXTENSION "hstore" UPDATE TO "1.1"`,
+				},
+				databases: []v1alpha1.ExtensionDatabaseObservation{
+					{Database: "tenant_a", Installed: true, UpToDate: true},
+					{Database: "tenant_b", Installed: false, LastError: pointer.StringPtr("cannot create extension: boom")},
+					{Database: "tenant_c", Installed: true, UpToDate: true},
+					{Database: "tenant_d", Installed: true, UpToDate: false, LastError: pointer.StringPtr("cannot update extension: boom")},
+				},
+				cond:    corev1.ConditionTrue,
+				message: PartiallyInstalled(3, 4).Message,
+				err: fleetErrors([]error{
+					errors.Wrapf(errors.Wrap(errBoom, errCreateExtension), errFleetDatabase, "tenant_b"),
+					errors.Wrapf(errors.Wrap(errBoom, errUpdateExtension), errFleetDatabase, "tenant_d"),
+				}, 3),
+			},
+		},
+		"UpdateSucceeded": {
+			reason: "No condition should be reported when the extension is reconciled in every database",
+			op: func(ctx context.Context, e *fleetExternal, cr *v1alpha1.Extension) error {
+				_, err := e.Update(ctx, cr)
+				return err
+			},
+			want: want{
+				execs: []string{
+					`tenant_b: CREATE EXTENSION IF NOT EXISTS "hstore" WITH VERSION "1.1"`,
+					`tenant_c: CREATE EXTENSION IF NOT EXISTS "hstore" WITH VERSION "1.1"`,
+					`tenant_d: ALTER EXTENSION "hstore" UPDATE TO "1.1"`,
+				},
+				databases: []v1alpha1.ExtensionDatabaseObservation{
+					{Database: "tenant_a", Installed: true, UpToDate: true},
+					{Database: "tenant_b", Installed: true, UpToDate: true},
+					{Database: "tenant_c", Installed: true, UpToDate: true},
+					{Database: "tenant_d", Installed: true, UpToDate: true},
+				},
+				cond: corev1.ConditionUnknown,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			execs := []string{}
+			e := &fleetExternal{
+				forDatabase: func(database string) *external {
+					run := func(q string) error {
+						execs = append(execs, database+": "+q)
+						if tc.fail[database] {
+							return errBoom
+						}
+						return nil
+					}
+					return &external{db: mockDB{
+						MockExec:   func(ctx context.Context, q xsql.Query) error { return run(q.String) },
+						MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return run(ql[0].String) },
+					}}
+				},
+			}
+
+			cr := &v1alpha1.Extension{}
+			cr.Spec.ForProvider.Extension = "hstore"
+			cr.Spec.ForProvider.Version = pointer.StringPtr("1.1")
+			cr.Status.AtProvider.Databases = append([]v1alpha1.ExtensionDatabaseObservation{}, observed...)
+
+			err := tc.op(context.Background(), e, cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\n-want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.execs, execs); diff != "" {
+				t.Errorf("\n%s\n-want statements, +got statements:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.databases, cr.Status.AtProvider.Databases); diff != "" {
+				t.Errorf("\n%s\n-want databases, +got databases:\n%s\n", tc.reason, diff)
+			}
+			got := cr.GetCondition(TypePartiallyInstalled)
+			if diff := cmp.Diff(tc.want.cond, got.Status); diff != "" {
+				t.Errorf("\n%s\n-want condition status, +got condition status:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.message, got.Message); diff != "" {
+				t.Errorf("\n%s\n-want condition message, +got condition message:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFleetRolloutConditions(t *testing.T) {
+	// The control file of the extension is missing from the server hosting
+	// tenant_b.
+	missing := &pq.Error{Code: "58P01", Message: `could not open extension control file "hstore.control"`}
+
+	observed := []v1alpha1.ExtensionDatabaseObservation{
+		{Database: "tenant_a", Installed: false},
+		{Database: "tenant_b", Installed: false},
+		{Database: "tenant_c", Installed: true, UpToDate: false},
+	}
+
+	cases := map[string]struct {
+		reason string
+		op     func(ctx context.Context, e *fleetExternal, cr *v1alpha1.Extension) error
+	}{
+		"Create": {
+			reason: "A condition set while installing the extension in one database should be reported, even if it's installed in the others",
+			op: func(ctx context.Context, e *fleetExternal, cr *v1alpha1.Extension) error {
+				_, err := e.Create(ctx, cr)
+				return err
+			},
+		},
+		"Update": {
+			reason: "A condition set while reconciling the extension in one database should be reported, even if it's reconciled in the others",
+			op: func(ctx context.Context, e *fleetExternal, cr *v1alpha1.Extension) error {
+				_, err := e.Update(ctx, cr)
+				return err
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &fleetExternal{
+				forDatabase: func(database string) *external {
+					run := func() error {
+						if database == "tenant_b" {
+							return missing
+						}
+						return nil
+					}
+					return &external{db: mockDB{
+						MockExec:   func(ctx context.Context, q xsql.Query) error { return run() },
+						MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return run() },
+					}}
+				},
+			}
+
+			cr := &v1alpha1.Extension{}
+			cr.Spec.ForProvider.Extension = "hstore"
+			cr.Spec.ForProvider.Version = pointer.StringPtr("1.1")
+			cr.Status.AtProvider.Databases = append([]v1alpha1.ExtensionDatabaseObservation{}, observed...)
+
+			if err := tc.op(context.Background(), e, cr); err == nil {
+				t.Errorf("\n%s\nwant error, got nil", tc.reason)
+			}
+
+			want := ControlFileMissing()
+			want.Message = `Database "tenant_b": ` + want.Message
+			if diff := cmp.Diff(want, cr.GetCondition(TypeControlFileMissing), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\n-want condition, +got condition:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
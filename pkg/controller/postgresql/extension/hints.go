@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// remediationHints suggest how to fix common errors, keyed by SQLSTATE.
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+var remediationHints = map[pq.ErrorCode]string{
+	"22023": "check that the desired version of the extension is packaged on the server; see pg_available_extension_versions",
+	"25001": "set spec.forProvider.noTransaction to true",
+	"28000": "check that the server's pg_hba.conf allows the provider to connect",
+	"28P01": "check the credentials in the ProviderConfig's connection secret",
+	"2BP01": "drop or detach the objects that depend on the extension before deleting it",
+	"3D000": "create the database, or check spec.forProvider.database",
+	"42501": "grant the ProviderConfig's role the privileges it needs, e.g. CREATE on the database, or use a superuser ProviderConfig",
+	"53300": "reduce the number of concurrent reconciles, or raise the server's max_connections",
+	"55P03": "another session holds a conflicting lock; retry later, or raise lock_timeout in spec.forProvider.sessionParameters",
+	"57014": "raise statement_timeout in spec.forProvider.sessionParameters",
+	"58P01": "install the package that provides the extension on the database server",
+}
+
+// A hintError is an error annotated with a remediation hint.
+type hintError struct {
+	err  error
+	hint string
+}
+
+func (e *hintError) Error() string { return e.err.Error() + " (hint: " + e.hint + ")" }
+func (e *hintError) Unwrap() error { return e.err }
+
+// withHint annotates the supplied error with a remediation hint if it was
+// caused by a PostgreSQL error with a SQLSTATE we have a hint for.
+func withHint(err error) error {
+	pqe := &pq.Error{}
+	if !errors.As(err, &pqe) {
+		return err
+	}
+	h, ok := remediationHints[pqe.Code]
+	if !ok {
+		return err
+	}
+	return &hintError{err: err, hint: h}
+}
+
+// hinted annotates the errors returned by an ExternalClient with remediation
+// hints, which the managed reconciler includes in its Synced condition.
+type hinted struct {
+	managed.ExternalClient
+}
+
+func (h *hinted) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	o, err := h.ExternalClient.Observe(ctx, mg)
+	return o, withHint(err)
+}
+
+func (h *hinted) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	c, err := h.ExternalClient.Create(ctx, mg)
+	return c, withHint(err)
+}
+
+func (h *hinted) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	u, err := h.ExternalClient.Update(ctx, mg)
+	return u, withHint(err)
+}
+
+func (h *hinted) Delete(ctx context.Context, mg resource.Managed) error {
+	return withHint(h.ExternalClient.Delete(ctx, mg))
+}
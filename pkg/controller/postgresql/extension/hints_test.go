@@ -0,0 +1,127 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+)
+
+func TestWithHint(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   string
+	}{
+		"InsufficientPrivilege": {
+			reason: "Insufficient privilege errors should suggest granting privileges",
+			err:    errors.Wrap(&pq.Error{Code: "42501", Message: "permission denied to create extension \"hstore\""}, errCreateExtension),
+			want: "cannot create extension: pq: permission denied to create extension \"hstore\" " +
+				"(hint: grant the ProviderConfig's role the privileges it needs, e.g. CREATE on the database, or use a superuser ProviderConfig)",
+		},
+		"UndefinedFile": {
+			reason: "Missing control file errors should suggest installing the extension's package",
+			err:    errors.Wrap(&pq.Error{Code: "58P01", Message: "could not open extension control file"}, errCreateExtension),
+			want:   "cannot create extension: pq: could not open extension control file (hint: install the package that provides the extension on the database server)",
+		},
+		"DependentObjectsStillExist": {
+			reason: "Dependent object errors should suggest dropping the dependent objects",
+			err:    errors.Wrap(&pq.Error{Code: "2BP01", Message: "cannot drop extension hstore because other objects depend on it"}, errDropExtension),
+			want: "cannot drop extension: pq: cannot drop extension hstore because other objects depend on it " +
+				"(hint: drop or detach the objects that depend on the extension before deleting it)",
+		},
+		"InvalidCatalog": {
+			reason: "Missing database errors should suggest creating the database",
+			err:    &pq.Error{Code: "3D000", Message: "database \"cool\" does not exist"},
+			want:   "pq: database \"cool\" does not exist (hint: create the database, or check spec.forProvider.database)",
+		},
+		"UnknownCode": {
+			reason: "Errors with a SQLSTATE we have no hint for should be unchanged",
+			err:    &pq.Error{Code: "XX000", Message: "internal error"},
+			want:   "pq: internal error",
+		},
+		"NotPQ": {
+			reason: "Errors that aren't from PostgreSQL should be unchanged",
+			err:    errors.New("boom"),
+			want:   "boom",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := withHint(tc.err)
+			if diff := cmp.Diff(tc.want, got.Error()); diff != "" {
+				t.Errorf("\n%s\nwithHint(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if !errors.Is(got, tc.err) {
+				t.Errorf("\n%s\nwithHint(...): want an error that wraps %q", tc.reason, tc.err)
+			}
+		})
+	}
+
+	if err := withHint(nil); err != nil {
+		t.Errorf("withHint(nil): want nil, got %q", err)
+	}
+}
+
+// A failingClient returns the supplied error from every method.
+type failingClient struct{ err error }
+
+func (c failingClient) Observe(context.Context, resource.Managed) (managed.ExternalObservation, error) {
+	return managed.ExternalObservation{}, c.err
+}
+func (c failingClient) Create(context.Context, resource.Managed) (managed.ExternalCreation, error) {
+	return managed.ExternalCreation{}, c.err
+}
+func (c failingClient) Update(context.Context, resource.Managed) (managed.ExternalUpdate, error) {
+	return managed.ExternalUpdate{}, c.err
+}
+func (c failingClient) Delete(context.Context, resource.Managed) error { return c.err }
+
+func TestHinted(t *testing.T) {
+	errTooMany := &pq.Error{Code: "53300", Message: "sorry, too many clients already"}
+	want := "pq: sorry, too many clients already (hint: reduce the number of concurrent reconciles, or raise the server's max_connections)"
+
+	h := &hinted{failingClient{err: errTooMany}}
+	cr := &v1alpha1.Extension{}
+	ctx := context.Background()
+
+	_, oerr := h.Observe(ctx, cr)
+	_, cerr := h.Create(ctx, cr)
+	_, uerr := h.Update(ctx, cr)
+	derr := h.Delete(ctx, cr)
+
+	for op, err := range map[string]error{"Observe": oerr, "Create": cerr, "Update": uerr, "Delete": derr} {
+		if diff := cmp.Diff(want, err.Error()); diff != "" {
+			t.Errorf("h.%s(...): -want error, +got error:\n%s\n", op, diff)
+		}
+		// Errors must still be classified by their SQLSTATE once hinted.
+		if !postgresql.IsTooManyConnections(err) {
+			t.Errorf("h.%s(...): want an error classified as too many connections", op)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// TypeIntegrityDriftDetected is true while the definitions of the extension's
+// functions do not match the expected hash.
+const TypeIntegrityDriftDetected xpv1.ConditionType = "IntegrityDriftDetected"
+
+// ReasonDefinitionHashMismatch indicates the observed hash of an extension's
+// function definitions differs from the expected hash.
+const ReasonDefinitionHashMismatch xpv1.ConditionReason = "DefinitionHashMismatch"
+
+const (
+	errSelectDefinitions = "cannot select extension function definitions"
+	errScanDefinition    = "cannot scan extension function definition"
+)
+
+// IntegrityDriftDetected returns a condition that indicates the definitions of
+// the extension's functions have the supplied hash, not the expected one.
+func IntegrityDriftDetected(expected, observed string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeIntegrityDriftDetected,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDefinitionHashMismatch,
+		Message: fmt.Sprintf("The definitions of the extension's functions hash to %s, not the expected %s. "+
+			"One or more functions may have been replaced since the extension was installed.", observed, expected),
+	}
+}
+
+// NoIntegrityDrift returns a condition that indicates the definitions of the
+// extension's functions match the expected hash.
+func NoIntegrityDrift() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeIntegrityDriftDetected,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonResolved,
+	}
+}
+
+// definitionsQuery selects the signature and definition of each function and
+// procedure that belongs to an extension. pg_get_functiondef does not support
+// aggregate or window functions, so they are omitted.
+const definitionsQuery = "SELECT p.oid::regprocedure::text, pg_get_functiondef(p.oid) " +
+	"FROM pg_extension e " +
+	"JOIN pg_depend d ON d.refclassid = 'pg_extension'::regclass AND d.refobjid = e.oid AND d.deptype = 'e' " +
+	"AND d.classid = 'pg_proc'::regclass " +
+	"JOIN pg_proc p ON p.oid = d.objid " +
+	"WHERE e.extname = $1 AND p.prokind IN ('f', 'p')"
+
+// definitionHash returns the hex encoded SHA-256 hash of the supplied function
+// definitions, keyed by signature. Definitions are hashed in signature order,
+// so the hash does not depend on the order in which they were read.
+func definitionHash(defs map[string]string) string {
+	sigs := make([]string, 0, len(defs))
+	for s := range defs {
+		sigs = append(sigs, s)
+	}
+	sort.Strings(sigs)
+
+	h := sha256.New()
+	for _, s := range sigs {
+		// Separate each field with a NUL, which can't appear in either.
+		_, _ = h.Write([]byte(s + "\x00" + defs[s] + "\x00"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// functionDefinitions returns the definitions of the supplied extension's
+// functions and procedures, keyed by signature.
+func (c *external) functionDefinitions(ctx context.Context, extension string) (map[string]string, error) {
+	rows, err := c.db.Query(ctx, xsql.Query{String: definitionsQuery, Parameters: []interface{}{extension}})
+	if err != nil {
+		return nil, errors.Wrap(err, errSelectDefinitions)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	defs := map[string]string{}
+	for rows.Next() {
+		var sig, def string
+		if err := rows.Scan(&sig, &def); err != nil {
+			return nil, errors.Wrap(err, errScanDefinition)
+		}
+		defs[sig] = def
+	}
+	return defs, errors.Wrap(rows.Err(), errSelectDefinitions)
+}
+
+// observeIntegrity reports the hash of the extension's function definitions,
+// and whether it matches the expected hash, if asked to.
+func (c *external) observeIntegrity(ctx context.Context, cr *v1alpha1.Extension) error {
+	cr.Status.AtProvider.DefinitionHash = nil
+	expected := cr.Spec.ForProvider.ExpectedDefinitionHash
+	if expected == nil {
+		return nil
+	}
+
+	defs, err := c.functionDefinitions(ctx, cr.Spec.ForProvider.Extension)
+	if err != nil {
+		return err
+	}
+	observed := definitionHash(defs)
+	cr.Status.AtProvider.DefinitionHash = &observed
+
+	switch {
+	case !strings.EqualFold(observed, *expected):
+		cr.SetConditions(IntegrityDriftDetected(*expected, observed))
+	case cr.GetCondition(TypeIntegrityDriftDetected).Status == corev1.ConditionTrue:
+		cr.SetConditions(NoIntegrityDrift())
+	}
+	return nil
+}
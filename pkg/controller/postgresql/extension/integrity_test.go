@@ -0,0 +1,174 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestDefinitionHash(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		defs   map[string]string
+		want   string
+	}{
+		"Empty": {
+			reason: "An extension with no functions should hash to the SHA-256 of nothing",
+			defs:   map[string]string{},
+			want:   "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		},
+		"One": {
+			reason: "A function's signature and definition should both be hashed, each followed by a NUL",
+			defs:   map[string]string{"f(integer)": "body"},
+			want:   "c2a8b283d48e924343be6833bfbe09ea0bcec0e82dd47498b38fa2150b1b0964",
+		},
+		"Several": {
+			reason: "Functions should be hashed in signature order",
+			defs:   map[string]string{"g(text)": "other", "f(integer)": "body"},
+			want:   "34b8f7ef342674c3b1b983783a38a9365da8f46a736e22c8a770161e1b0d4878",
+		},
+		"ChangedDefinition": {
+			reason: "Changing a function's definition should change the hash",
+			defs:   map[string]string{"f(integer)": "tampered", "g(text)": "other"},
+			want:   "d90a1994c28bc5f497e6592d7410034e849eee8b7454972bf102cd64f86fc762",
+		},
+		"MovedBoundary": {
+			reason: "Moving text between a signature and its definition should change the hash",
+			defs:   map[string]string{"f(integer)b": "ody", "g(text)": "other"},
+			want:   "5390b3c6150edf6057fa7c7ffe730c08dcc72218389b1ebc1cca255ef0f6d72c",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, definitionHash(tc.defs)); diff != "" {
+				t.Errorf("\n%s\ndefinitionHash(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveIntegrity(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	// The hash of a single function "f(integer)" with definition "body".
+	hash := "c2a8b283d48e924343be6833bfbe09ea0bcec0e82dd47498b38fa2150b1b0964"
+
+	definitions := func(def string) func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+		return func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+			return mockRowsToSQLRows(sqlmock.NewRows([]string{"signature", "definition"}).AddRow("f(integer)", def)), nil
+		}
+	}
+
+	type args struct {
+		expected *string
+		query    func(ctx context.Context, q xsql.Query) (*sql.Rows, error)
+		drifted  bool
+	}
+
+	type want struct {
+		hash *string
+		cond corev1.ConditionStatus
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Disabled": {
+			reason: "Function definitions should not be read unless an expected hash is supplied",
+			args: args{
+				query: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+					t.Errorf("MockQuery: unexpected query: %s", q.String)
+					return nil, errBoom
+				},
+			},
+			want: want{cond: corev1.ConditionUnknown},
+		},
+		"Match": {
+			reason: "No drift should be reported when the hash matches, regardless of case",
+			args: args{
+				expected: pointer.StringPtr("C2A8B283D48E924343BE6833BFBE09EA0BCEC0E82DD47498B38FA2150B1B0964"),
+				query:    definitions("body"),
+			},
+			want: want{hash: pointer.StringPtr(hash), cond: corev1.ConditionUnknown},
+		},
+		"Drift": {
+			reason: "Drift should be reported when the hash does not match",
+			args: args{
+				expected: pointer.StringPtr(hash),
+				query:    definitions("tampered"),
+			},
+			want: want{hash: pointer.StringPtr(definitionHash(map[string]string{"f(integer)": "tampered"})), cond: corev1.ConditionTrue},
+		},
+		"Resolved": {
+			reason: "Previously reported drift should be resolved once the hash matches",
+			args: args{
+				expected: pointer.StringPtr(hash),
+				query:    definitions("body"),
+				drifted:  true,
+			},
+			want: want{hash: pointer.StringPtr(hash), cond: corev1.ConditionFalse},
+		},
+		"ErrSelectDefinitions": {
+			reason: "Errors reading function definitions should be returned",
+			args: args{
+				expected: pointer.StringPtr(hash),
+				query:    func(ctx context.Context, q xsql.Query) (*sql.Rows, error) { return nil, errBoom },
+			},
+			want: want{cond: corev1.ConditionUnknown, err: errors.Wrap(errBoom, errSelectDefinitions)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: mockDB{MockQuery: tc.args.query}}
+			cr := &v1alpha1.Extension{}
+			cr.Spec.ForProvider.Extension = "custom"
+			cr.Spec.ForProvider.ExpectedDefinitionHash = tc.args.expected
+			if tc.args.drifted {
+				cr.SetConditions(IntegrityDriftDetected(hash, "stale"))
+			}
+
+			err := e.observeIntegrity(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.observeIntegrity(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.hash, cr.Status.AtProvider.DefinitionHash); diff != "" {
+				t.Errorf("\n%s\ne.observeIntegrity(...): -want hash, +got hash:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cond, cr.GetCondition(TypeIntegrityDriftDetected).Status); diff != "" {
+				t.Errorf("\n%s\ne.observeIntegrity(...): -want condition status, +got condition status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
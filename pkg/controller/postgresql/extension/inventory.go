@@ -0,0 +1,176 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+const (
+	errListExtensions   = "cannot list Extensions"
+	errMarshalInventory = "cannot marshal inventory entry"
+	errGetInventory     = "cannot get inventory ConfigMap"
+	errCreateInventory  = "cannot create inventory ConfigMap"
+	errUpdateInventory  = "cannot update inventory ConfigMap"
+
+	// inventoryAttempts is how many times we'll try to update the inventory
+	// ConfigMap when someone else updates it at the same time.
+	inventoryAttempts = 5
+)
+
+// An inventoryEntry summarises one Extension in the inventory ConfigMap.
+type inventoryEntry struct {
+	Extension string              `json:"extension"`
+	Version   *string             `json:"version,omitempty"`
+	Database  *string             `json:"database,omitempty"`
+	Databases []inventoryDatabase `json:"databases,omitempty"`
+	Ready     bool                `json:"ready"`
+	Synced    bool                `json:"synced"`
+}
+
+// An inventoryDatabase summarises an Extension in one of many databases.
+type inventoryDatabase struct {
+	Database string  `json:"database"`
+	Version  *string `json:"version,omitempty"`
+	UpToDate bool    `json:"upToDate"`
+}
+
+func newInventoryEntry(cr *v1alpha1.Extension) inventoryEntry {
+	e := inventoryEntry{
+		Extension: cr.Spec.ForProvider.Extension,
+		Version:   cr.Status.AtProvider.InstalledVersion,
+		Database:  cr.Spec.ForProvider.Database,
+		Ready:     cr.GetCondition(xpv1.TypeReady).Status == corev1.ConditionTrue,
+		Synced:    cr.GetCondition(xpv1.TypeSynced).Status == corev1.ConditionTrue,
+	}
+	for _, d := range cr.Status.AtProvider.Databases {
+		e.Databases = append(e.Databases, inventoryDatabase{Database: d.Database, Version: d.Version, UpToDate: d.UpToDate})
+	}
+	return e
+}
+
+// inventoryData returns the data of an inventory ConfigMap that summarises
+// the supplied Extensions. Each Extension is keyed by its name.
+func inventoryData(l *v1alpha1.ExtensionList) (map[string]string, error) {
+	data := make(map[string]string, len(l.Items))
+	for i := range l.Items {
+		cr := &l.Items[i]
+		j, err := json.Marshal(newInventoryEntry(cr))
+		if err != nil {
+			return nil, errors.Wrap(err, errMarshalInventory)
+		}
+		data[cr.GetName()] = string(j)
+	}
+	return data, nil
+}
+
+// SetupInventory adds a controller that maintains a ConfigMap summarising all
+// Extension managed resources, so that dashboards can query the state of the
+// fleet without listing them.
+func SetupInventory(mgr ctrl.Manager, cm types.NamespacedName, l logging.Logger) error {
+	name := "inventory/" + v1alpha1.ExtensionGroupKind
+
+	// Every Extension maps to the same request, so the inventory is always
+	// rebuilt from scratch by a single worker.
+	all := handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: cm}}
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		Watches(&source.Kind{Type: &v1alpha1.Extension{}}, all).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
+		Complete(&inventory{kube: mgr.GetClient(), reader: mgr.GetAPIReader(), log: l.WithValues("controller", name)})
+}
+
+// An inventory reconciles an inventory ConfigMap.
+type inventory struct {
+	kube client.Client
+
+	// reader is used to read the ConfigMap, so that we needn't cache every
+	// ConfigMap in the cluster.
+	reader client.Reader
+	log    logging.Logger
+}
+
+// Reconcile the inventory ConfigMap with the Extensions that currently exist.
+func (r *inventory) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	l := &v1alpha1.ExtensionList{}
+	if err := r.kube.List(ctx, l); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errListExtensions)
+	}
+
+	data, err := inventoryData(l)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// The ConfigMap may be updated by someone else between our read and our
+	// write, in which case we read it again and retry.
+	for i := 0; i < inventoryAttempts; i++ {
+		err = r.write(ctx, req.NamespacedName, data)
+		if !kerrors.IsConflict(errors.Cause(err)) && !kerrors.IsAlreadyExists(errors.Cause(err)) {
+			break
+		}
+		r.log.Debug("Inventory ConfigMap changed while updating it; retrying", "attempt", i+1)
+	}
+	return reconcile.Result{}, err
+}
+
+func (r *inventory) write(ctx context.Context, nn types.NamespacedName, data map[string]string) error {
+	cm := &corev1.ConfigMap{}
+	err := r.reader.Get(ctx, nn, cm)
+	if kerrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: nn.Namespace, Name: nn.Name},
+			Data:       data,
+		}
+		return errors.Wrap(r.kube.Create(ctx, cm), errCreateInventory)
+	}
+	if err != nil {
+		return errors.Wrap(err, errGetInventory)
+	}
+
+	// Avoid needless writes; every status update triggers a reconcile.
+	if reflect.DeepEqual(cm.Data, data) || (len(cm.Data) == 0 && len(data) == 0) {
+		return nil
+	}
+
+	// The resource version we read ensures the API server rejects our write
+	// if the ConfigMap changed in the meantime.
+	cm.Data = data
+	return errors.Wrap(r.kube.Update(ctx, cm), errUpdateInventory)
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+func TestInventoryData(t *testing.T) {
+	single := v1alpha1.Extension{
+		ObjectMeta: metav1.ObjectMeta{Name: "hstore"},
+		Spec: v1alpha1.ExtensionSpec{
+			ForProvider: v1alpha1.ExtensionParameters{Extension: "hstore", Database: pointer.StringPtr("example")},
+		},
+		Status: v1alpha1.ExtensionStatus{
+			AtProvider: v1alpha1.ExtensionObservation{InstalledVersion: pointer.StringPtr("1.7")},
+		},
+	}
+	single.SetConditions(xpv1.Available(), xpv1.ReconcileSuccess())
+
+	fleet := v1alpha1.Extension{
+		ObjectMeta: metav1.ObjectMeta{Name: "pgcrypto"},
+		Spec: v1alpha1.ExtensionSpec{
+			ForProvider: v1alpha1.ExtensionParameters{Extension: "pgcrypto"},
+		},
+		Status: v1alpha1.ExtensionStatus{
+			AtProvider: v1alpha1.ExtensionObservation{Databases: []v1alpha1.ExtensionDatabaseObservation{
+				{Database: "tenant_a", Installed: true, Version: pointer.StringPtr("1.3"), UpToDate: true},
+				{Database: "tenant_b"},
+			}},
+		},
+	}
+
+	cases := map[string]struct {
+		reason string
+		l      *v1alpha1.ExtensionList
+		want   map[string]string
+	}{
+		"Empty": {
+			reason: "An empty inventory should be returned when there are no Extensions",
+			l:      &v1alpha1.ExtensionList{},
+			want:   map[string]string{},
+		},
+		"Extensions": {
+			reason: "Each Extension should be summarised under its name",
+			l:      &v1alpha1.ExtensionList{Items: []v1alpha1.Extension{single, fleet}},
+			want: map[string]string{
+				"hstore":   `{"extension":"hstore","version":"1.7","database":"example","ready":true,"synced":true}`,
+				"pgcrypto": `{"extension":"pgcrypto","databases":[{"database":"tenant_a","version":"1.3","upToDate":true},{"database":"tenant_b","upToDate":false}],"ready":false,"synced":false}`,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := inventoryData(tc.l)
+			if err != nil {
+				t.Fatalf("\n%s\ninventoryData(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ninventoryData(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestInventoryReconcile(t *testing.T) {
+	errBoom := errors.New("boom")
+	nn := types.NamespacedName{Namespace: "crossplane-system", Name: "extensions"}
+	gr := schema.GroupResource{Resource: "configmaps"}
+
+	list := test.NewMockListFn(nil, func(o client.ObjectList) error {
+		o.(*v1alpha1.ExtensionList).Items = []v1alpha1.Extension{{
+			ObjectMeta: metav1.ObjectMeta{Name: "hstore"},
+			Spec:       v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{Extension: "hstore"}},
+		}}
+		return nil
+	})
+	summary := map[string]string{"hstore": `{"extension":"hstore","ready":false,"synced":false}`}
+
+	type want struct {
+		err    error
+		writes int
+		data   map[string]string
+	}
+
+	cases := map[string]struct {
+		reason string
+		kube   func(writes *int, data *map[string]string) *test.MockClient
+		want   want
+	}{
+		"ErrList": {
+			reason: "Errors listing Extensions should be returned",
+			kube: func(_ *int, _ *map[string]string) *test.MockClient {
+				return &test.MockClient{MockList: test.NewMockListFn(errBoom)}
+			},
+			want: want{err: errors.Wrap(errBoom, errListExtensions)},
+		},
+		"ErrGet": {
+			reason: "Errors getting the ConfigMap should be returned",
+			kube: func(_ *int, _ *map[string]string) *test.MockClient {
+				return &test.MockClient{MockList: list, MockGet: test.NewMockGetFn(errBoom)}
+			},
+			want: want{err: errors.Wrap(errBoom, errGetInventory)},
+		},
+		"CreateMissing": {
+			reason: "The ConfigMap should be created if it does not exist",
+			kube: func(writes *int, data *map[string]string) *test.MockClient {
+				return &test.MockClient{
+					MockList: list,
+					MockGet:  test.NewMockGetFn(kerrors.NewNotFound(gr, nn.Name)),
+					MockCreate: test.NewMockCreateFn(nil, func(o client.Object) error {
+						*writes++
+						*data = o.(*corev1.ConfigMap).Data
+						return nil
+					}),
+				}
+			},
+			want: want{writes: 1, data: summary},
+		},
+		"UpdateExisting": {
+			reason: "An existing ConfigMap should be updated to reflect the current Extensions",
+			kube: func(writes *int, data *map[string]string) *test.MockClient {
+				return &test.MockClient{
+					MockList: list,
+					MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+						o.(*corev1.ConfigMap).Data = map[string]string{"deleted": "{}"}
+						return nil
+					}),
+					MockUpdate: test.NewMockUpdateFn(nil, func(o client.Object) error {
+						*writes++
+						*data = o.(*corev1.ConfigMap).Data
+						return nil
+					}),
+				}
+			},
+			want: want{writes: 1, data: summary},
+		},
+		"Unchanged": {
+			reason: "The ConfigMap should not be written if it is already up to date",
+			kube: func(_ *int, _ *map[string]string) *test.MockClient {
+				return &test.MockClient{
+					MockList: list,
+					MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+						o.(*corev1.ConfigMap).Data = map[string]string{"hstore": `{"extension":"hstore","ready":false,"synced":false}`}
+						return nil
+					}),
+					MockUpdate: test.NewMockUpdateFn(errBoom),
+				}
+			},
+			want: want{},
+		},
+		"RetryConflict": {
+			reason: "The ConfigMap should be read and written again if it changed while we were updating it",
+			kube: func(writes *int, data *map[string]string) *test.MockClient {
+				return &test.MockClient{
+					MockList: list,
+					MockGet:  test.NewMockGetFn(nil),
+					MockUpdate: test.NewMockUpdateFn(nil, func(o client.Object) error {
+						*writes++
+						if *writes == 1 {
+							return kerrors.NewConflict(gr, nn.Name, errBoom)
+						}
+						*data = o.(*corev1.ConfigMap).Data
+						return nil
+					}),
+				}
+			},
+			want: want{writes: 2, data: summary},
+		},
+		"RetryCreateRace": {
+			reason: "The ConfigMap should be updated if someone else created it while we were creating it",
+			kube: func(writes *int, data *map[string]string) *test.MockClient {
+				created := false
+				return &test.MockClient{
+					MockList: list,
+					MockGet: func(_ context.Context, _ client.ObjectKey, _ client.Object) error {
+						if !created {
+							return kerrors.NewNotFound(gr, nn.Name)
+						}
+						return nil
+					},
+					MockCreate: test.NewMockCreateFn(nil, func(o client.Object) error {
+						created = true
+						return kerrors.NewAlreadyExists(gr, nn.Name)
+					}),
+					MockUpdate: test.NewMockUpdateFn(nil, func(o client.Object) error {
+						*writes++
+						*data = o.(*corev1.ConfigMap).Data
+						return nil
+					}),
+				}
+			},
+			want: want{writes: 1, data: summary},
+		},
+		"ErrConflictPersists": {
+			reason: "We should give up and return an error if the ConfigMap keeps changing",
+			kube: func(writes *int, _ *map[string]string) *test.MockClient {
+				return &test.MockClient{
+					MockList: list,
+					MockGet:  test.NewMockGetFn(nil),
+					MockUpdate: test.NewMockUpdateFn(nil, func(o client.Object) error {
+						*writes++
+						return kerrors.NewConflict(gr, nn.Name, errBoom)
+					}),
+				}
+			},
+			want: want{
+				err:    errors.Wrap(kerrors.NewConflict(gr, nn.Name, errBoom), errUpdateInventory),
+				writes: inventoryAttempts,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			writes := 0
+			var data map[string]string
+			kube := tc.kube(&writes, &data)
+			r := &inventory{kube: kube, reader: kube, log: logging.NewNopLogger()}
+
+			_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: nn})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.writes, writes); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want writes, +got writes:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, data); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want data, +got data:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxNameLength is the longest identifier PostgreSQL will store without
+// truncation, i.e. NAMEDATALEN - 1 bytes.
+const maxNameLength = 63
+
+const (
+	errNameTooLong  = "extension name %q is %d bytes long; names must not exceed %d bytes"
+	errNameReserved = "extension name %q is not valid: %s"
+)
+
+// validateExtensionName returns an error if PostgreSQL would truncate or
+// reject the supplied extension name. The rules mirror the server's own
+// check_valid_extension_name, which exist because an extension's name is used
+// to find its control and script files.
+func validateExtensionName(name string) error {
+	if len(name) > maxNameLength {
+		return errors.Errorf(errNameTooLong, name, len(name), maxNameLength)
+	}
+
+	switch {
+	case strings.Contains(name, "--"):
+		return errors.Errorf(errNameReserved, name, `names must not contain "--"`)
+	case strings.HasPrefix(name, "-") || strings.HasSuffix(name, "-"):
+		return errors.Errorf(errNameReserved, name, `names must not begin or end with "-"`)
+	case strings.ContainsAny(name, `/\`):
+		return errors.Errorf(errNameReserved, name, "names must not contain directory separators")
+	}
+
+	return nil
+}
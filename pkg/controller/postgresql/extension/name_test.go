@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestValidateExtensionName(t *testing.T) {
+	long := strings.Repeat("a", maxNameLength+1)
+
+	cases := map[string]struct {
+		reason string
+		name   string
+		want   error
+	}{
+		"Valid": {
+			reason: "A typical extension name should be valid",
+			name:   "pg_stat_statements",
+		},
+		"MaxLength": {
+			reason: "A name of exactly NAMEDATALEN - 1 bytes should be valid",
+			name:   strings.Repeat("a", maxNameLength),
+		},
+		"TooLong": {
+			reason: "A name longer than NAMEDATALEN - 1 bytes should be rejected rather than truncated",
+			name:   long,
+			want:   errors.Errorf(errNameTooLong, long, maxNameLength+1, maxNameLength),
+		},
+		"TooLongMultibyte": {
+			reason: "Length should be measured in bytes, not characters",
+			name:   strings.Repeat("é", 32),
+			want:   errors.Errorf(errNameTooLong, strings.Repeat("é", 32), 64, maxNameLength),
+		},
+		"DoubleDash": {
+			reason: "Names containing -- should be rejected",
+			name:   "hstore--1.0",
+			want:   errors.Errorf(errNameReserved, "hstore--1.0", `names must not contain "--"`),
+		},
+		"LeadingDash": {
+			reason: "Names beginning with - should be rejected",
+			name:   "-hstore",
+			want:   errors.Errorf(errNameReserved, "-hstore", `names must not begin or end with "-"`),
+		},
+		"TrailingDash": {
+			reason: "Names ending with - should be rejected",
+			name:   "hstore-",
+			want:   errors.Errorf(errNameReserved, "hstore-", `names must not begin or end with "-"`),
+		},
+		"DirectorySeparator": {
+			reason: "Names containing directory separators should be rejected",
+			name:   "../hstore",
+			want:   errors.Errorf(errNameReserved, "../hstore", "names must not contain directory separators"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := validateExtensionName(tc.name)
+			if diff := cmp.Diff(tc.want, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nvalidateExtensionName(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const pqActiveSQLTransaction = pq.ErrorCode("25001")
+
+// isTransactionBlockError returns true if the supplied error indicates a
+// statement cannot run inside a transaction block.
+func isTransactionBlockError(err error) bool {
+	pqe := &pq.Error{}
+	return errors.As(err, &pqe) && pqe.Code == pqActiveSQLTransaction
+}
+
+// execTx runs the supplied queries in a transaction. The queries instead run
+// in autocommit mode, in order, if the supplied extension opts out of
+// transactions, or if the server reports that one of them cannot run in a
+// transaction block. It returns true if the queries ran in autocommit mode.
+func (c *external) execTx(ctx context.Context, p v1alpha1.ExtensionParameters, ql []xsql.Query) (bool, error) {
+	if p.NoTransaction == nil || !*p.NoTransaction {
+		err := c.db.ExecTx(ctx, ql)
+		if !isTransactionBlockError(err) {
+			return false, err
+		}
+		// The transaction was rolled back, so it's safe to start over.
+	}
+
+	for _, q := range ql {
+		if err := c.db.Exec(ctx, q); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
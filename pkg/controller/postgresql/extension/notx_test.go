@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestExecTx(t *testing.T) {
+	errBoom := errors.New("boom")
+	errTxBlock := &pq.Error{Code: pqActiveSQLTransaction, Message: "ALTER SYSTEM cannot run inside a transaction block"}
+
+	type args struct {
+		noTransaction *bool
+		txErr         error
+		execErr       error
+	}
+
+	type want struct {
+		tx         []string
+		exec       []string
+		autocommit bool
+		err        error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Transaction": {
+			reason: "Queries should run in a transaction by default",
+			want:   want{tx: []string{"a", "b"}},
+		},
+		"NoTransaction": {
+			reason: "Queries should run in autocommit mode, in order, when the extension opts out of transactions",
+			args:   args{noTransaction: pointer.BoolPtr(true)},
+			want:   want{exec: []string{"a", "b"}, autocommit: true},
+		},
+		"AutoDetect": {
+			reason: "Queries should be retried in autocommit mode when they cannot run in a transaction block",
+			args:   args{txErr: errTxBlock},
+			want:   want{tx: []string{"a", "b"}, exec: []string{"a", "b"}, autocommit: true},
+		},
+		"ErrTransaction": {
+			reason: "Other errors running the transaction should be returned without a retry",
+			args:   args{txErr: errBoom},
+			want:   want{tx: []string{"a", "b"}, err: errBoom},
+		},
+		"ErrAutocommit": {
+			reason: "No further queries should run in autocommit mode once one fails",
+			args:   args{noTransaction: pointer.BoolPtr(true), execErr: errBoom},
+			want:   want{exec: []string{"a"}, autocommit: true, err: errBoom},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var tx, exec []string
+			e := external{db: mockDB{
+				MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+					for _, q := range ql {
+						tx = append(tx, q.String)
+					}
+					return tc.args.txErr
+				},
+				MockExec: func(ctx context.Context, q xsql.Query) error {
+					exec = append(exec, q.String)
+					return tc.args.execErr
+				},
+			}}
+
+			p := v1alpha1.ExtensionParameters{Extension: "hstore", NoTransaction: tc.args.noTransaction}
+			autocommit, err := e.execTx(context.Background(), p, []xsql.Query{{String: "a"}, {String: "b"}})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.execTx(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.autocommit, autocommit); diff != "" {
+				t.Errorf("\n%s\ne.execTx(...): -want autocommit, +got autocommit:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.tx, tx); diff != "" {
+				t.Errorf("\n%s\ne.execTx(...): -want transaction, +got transaction:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.exec, exec); diff != "" {
+				t.Errorf("\n%s\ne.execTx(...): -want autocommit statements, +got autocommit statements:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// TypeOwnerUnreadable is true while the role that owns the extension cannot
+// be read from any catalog the provider has access to.
+const TypeOwnerUnreadable xpv1.ConditionType = "OwnerUnreadable"
+
+// ReasonInsufficientPrivilege indicates the provider lacks the privilege to
+// read the catalogs that record an extension's owner.
+const ReasonInsufficientPrivilege xpv1.ConditionReason = "InsufficientPrivilege"
+
+// OwnerUnreadable returns a condition that indicates the owner of the
+// extension cannot be read.
+func OwnerUnreadable() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeOwnerUnreadable,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonInsufficientPrivilege,
+		Message:            "Neither pg_authid nor pg_roles is readable. The owner of the extension will not be reported.",
+	}
+}
+
+// OwnerReadable returns a condition that indicates the owner of the extension
+// can be read.
+func OwnerReadable() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeOwnerUnreadable,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonResolved,
+	}
+}
+
+// ownerSources are the catalogs from which we try to read the owner of an
+// extension, in order. pg_authid is authoritative, but is usually readable
+// only by superusers. pg_roles is a view of pg_authid that most roles can
+// read.
+var ownerSources = []string{"pg_authid", "pg_roles"}
+
+// observeQuery returns a query that selects the version, comment, owner,
+// schema, and relocatability of an extension. The owner is read from the
+// supplied catalog, or is NULL if no catalog is supplied. The comment, owner,
+// and schema are joined in so that observing them costs no extra round trip.
+// The version is an empty string unless versioned is true.
+func observeQuery(ownerSource string, versioned bool) string {
+	owner, join := "NULL", ""
+	if ownerSource != "" {
+		owner = "o.rolname"
+		join = "LEFT JOIN " + ownerSource + " o ON o.oid = e.extowner "
+	}
+	version := "''"
+	if versioned {
+		version = "e.extversion"
+	}
+	return "SELECT " +
+		version + ", " +
+		"d.description, " +
+		owner + ", " +
+		"n.nspname, " +
+		"e.extrelocatable " +
+		"FROM pg_extension e " +
+		"LEFT JOIN pg_description d " +
+		"ON d.objoid = e.oid AND d.classoid = 'pg_extension'::regclass " +
+		"LEFT JOIN pg_namespace n ON n.oid = e.extnamespace " +
+		join +
+		"WHERE e.extname = $1"
+}
+
+// scanExtension scans the version, comment, owner, schema, and relocatability
+// of the supplied extension into the supplied destinations. It falls back
+// through each of the ownerSources when the connected role may not read them,
+// and finally omits the owner. It returns false if the owner could not be
+// read. It also returns false if the server's pg_extension catalog has no
+// extversion column, in which case an empty version is scanned.
+func (c *external) scanExtension(ctx context.Context, extension string, dest ...interface{}) (readable, versioned bool, err error) {
+	readable, err = c.scanExtensionVersion(ctx, extension, true, dest...)
+	if !postgresql.IsUndefinedColumn(err) {
+		return readable, true, err
+	}
+	readable, err = c.scanExtensionVersion(ctx, extension, false, dest...)
+	return readable, false, err
+}
+
+func (c *external) scanExtensionVersion(ctx context.Context, extension string, versioned bool, dest ...interface{}) (bool, error) {
+	for _, src := range ownerSources {
+		err := c.db.Scan(ctx, xsql.Query{String: observeQuery(src, versioned), Parameters: []interface{}{extension}}, dest...)
+		if !postgresql.IsInsufficientPrivilege(err) {
+			return true, err
+		}
+	}
+	return false, c.db.Scan(ctx, xsql.Query{String: observeQuery("", versioned), Parameters: []interface{}{extension}}, dest...)
+}
+
+// observeOwner reports the owner of the extension, if it could be read.
+func observeOwner(cr *v1alpha1.Extension, readable bool, owner sql.NullString) {
+	cr.Status.AtProvider.Owner = nil
+	if !readable {
+		cr.SetConditions(OwnerUnreadable())
+		return
+	}
+	if owner.Valid {
+		cr.Status.AtProvider.Owner = &owner.String
+	}
+	if cr.GetCondition(TypeOwnerUnreadable).Status == corev1.ConditionTrue {
+		cr.SetConditions(OwnerReadable())
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestObserveOwner(t *testing.T) {
+	errBoom := errors.New("boom")
+	errDenied := &pq.Error{Code: "42501", Message: "permission denied for table pg_authid"}
+
+	// scan denies access to the supplied catalogs, and otherwise returns the
+	// supplied error after reading owner "cool" from any other catalog.
+	scan := func(err error, denied ...string) func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+		return func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			for _, d := range denied {
+				if strings.Contains(q.String, "JOIN "+d+" ") {
+					return errDenied
+				}
+			}
+			*dest[0].(*string) = "1.0"
+			if strings.Contains(q.String, "o.rolname") {
+				*dest[2].(*sql.NullString) = sql.NullString{String: "cool", Valid: true}
+			}
+			return err
+		}
+	}
+
+	type want struct {
+		owner *string
+		cond  corev1.ConditionStatus
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		scan   func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+		want   want
+	}{
+		"AuthID": {
+			reason: "The owner should be read from pg_authid when it is readable",
+			scan:   scan(nil),
+			want:   want{owner: pointer.StringPtr("cool"), cond: corev1.ConditionUnknown},
+		},
+		"Roles": {
+			reason: "The owner should be read from pg_roles when pg_authid is not readable",
+			scan:   scan(nil, "pg_authid"),
+			want:   want{owner: pointer.StringPtr("cool"), cond: corev1.ConditionUnknown},
+		},
+		"Unreadable": {
+			reason: "The owner should be omitted, and reported unreadable, when no catalog is readable",
+			scan:   scan(nil, "pg_authid", "pg_roles"),
+			want:   want{cond: corev1.ConditionTrue},
+		},
+		"ErrSelectExtension": {
+			reason: "Errors other than insufficient privilege should not cause a fallback",
+			scan:   scan(errBoom, "pg_roles"),
+			want:   want{cond: corev1.ConditionUnknown, err: errors.Wrap(errBoom, errSelectExtension)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: mockDB{MockScan: tc.scan}}
+			cr := &v1alpha1.Extension{Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{
+				Version: pointer.StringPtr("1.0"),
+			}}}
+			_, err := e.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.owner, cr.Status.AtProvider.Owner); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want owner, +got owner:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cond, cr.GetCondition(TypeOwnerUnreadable).Status); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want condition status, +got condition status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveQueryString(t *testing.T) {
+	want := "SELECT e.extversion, d.description, o.rolname, n.nspname, e.extrelocatable " +
+		"FROM pg_extension e " +
+		"LEFT JOIN pg_description d ON d.objoid = e.oid AND d.classoid = 'pg_extension'::regclass " +
+		"LEFT JOIN pg_namespace n ON n.oid = e.extnamespace " +
+		"LEFT JOIN pg_authid o ON o.oid = e.extowner " +
+		"WHERE e.extname = $1"
+
+	var got xsql.Query
+	var dest []interface{}
+	e := external{db: mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, d ...interface{}) error {
+			if q.String == catalogReadableQuery {
+				return scanAbsent(ctx, q, d...)
+			}
+			if got.String == "" {
+				got, dest = q, d
+			}
+			return sql.ErrNoRows
+		},
+	}}
+	cr := &v1alpha1.Extension{Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{Extension: "hstore"}}}
+	if _, err := e.Observe(context.Background(), cr); err != nil {
+		t.Fatalf("e.Observe(...): %s", err)
+	}
+
+	if diff := cmp.Diff(want, got.String); diff != "" {
+		t.Errorf("e.Observe(...): -want query, +got query:\n%s\n", diff)
+	}
+	if diff := cmp.Diff([]interface{}{"hstore"}, got.Parameters); diff != "" {
+		t.Errorf("e.Observe(...): -want parameters, +got parameters:\n%s\n", diff)
+	}
+
+	// Each selected column must be scanned into a destination of its type.
+	types := []interface{}{new(string), &sql.NullString{}, &sql.NullString{}, &sql.NullString{}, &sql.NullBool{}}
+	if len(dest) != len(types) {
+		t.Fatalf("e.Observe(...): want %d destinations, got %d", len(types), len(dest))
+	}
+	for i := range types {
+		if diff := cmp.Diff(types[i], dest[i]); diff != "" {
+			t.Errorf("e.Observe(...): destination %d: -want, +got:\n%s\n", i, diff)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// ReasonPlannedUpdate indicates the provider is about to update an extension
+// via intermediate versions.
+const ReasonPlannedUpdate event.Reason = "PlannedUpdate"
+
+const errSelectUpdatePlan = "cannot select extension update plan"
+
+// updatePlan returns the versions PostgreSQL will update the supplied
+// extension through, in order, to get from one version to another. The last
+// version is the target version. It returns nil if PostgreSQL knows no update
+// path between the versions.
+func (c *external) updatePlan(ctx context.Context, extension, from, to string) ([]string, error) {
+	// Paths are rendered like '1.0--1.1--1.2', starting with the source.
+	path := sql.NullString{}
+	err := c.db.Scan(ctx, xsql.Query{
+		String:     "SELECT path FROM pg_extension_update_paths($1) WHERE source = $2 AND target = $3",
+		Parameters: []interface{}{extension, from, to},
+	}, &path)
+	if xsql.IsNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errSelectUpdatePlan)
+	}
+	if !path.Valid {
+		return nil, nil
+	}
+	return strings.Split(path.String, "--")[1:], nil
+}
+
+// planQueries returns the sequence of updates that is equivalent to updating
+// the supplied extension through the supplied versions.
+func (c *external) planQueries(extension string, versions []string) []string {
+	steps := make([]string, len(versions))
+	for i, v := range versions {
+		steps[i] = updateQuery(c.quoter(), extension, v).String
+	}
+	return steps
+}
+
+// planUpdate returns the versions a multi-step update of the supplied
+// extension to the supplied version steps through. Planning is best effort;
+// nil is returned if the update can't be planned, or takes a single step, in
+// which case it should be made in one go.
+func (c *external) planUpdate(ctx context.Context, cr *v1alpha1.Extension, version string) []string {
+	from := cr.Status.AtProvider.InstalledVersion
+	if from == nil {
+		return nil
+	}
+
+	versions, err := c.updatePlan(ctx, cr.Spec.ForProvider.Extension, *from, version)
+	if err != nil {
+		c.logger().Debug("Cannot plan extension update", "error", err)
+		return nil
+	}
+
+	// A single step update is no more than the ALTER EXTENSION we're about to
+	// run.
+	if len(versions) < 2 {
+		return nil
+	}
+	return versions
+}
+
+// announceUpdatePlan records an event listing the steps of a multi-step
+// update of the supplied extension to the supplied version, which steps
+// through the supplied versions. Nothing is recorded if there are no steps,
+// or no recorder to record them.
+func (c *external) announceUpdatePlan(cr *v1alpha1.Extension, version string, versions []string) {
+	from := cr.Status.AtProvider.InstalledVersion
+	if c.record == nil || from == nil || len(versions) == 0 {
+		return
+	}
+	c.record.Event(cr, event.Normal(ReasonPlannedUpdate, fmt.Sprintf("Updating extension %s from version %s to %s in %d steps: %s",
+		cr.Spec.ForProvider.Extension, *from, version, len(versions), strings.Join(c.planQueries(cr.Spec.ForProvider.Extension, versions), "; "))))
+}
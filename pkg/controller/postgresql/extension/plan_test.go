@@ -0,0 +1,130 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// An eventRecorder records the events it is asked to record.
+type eventRecorder struct {
+	events []event.Event
+}
+
+func (r *eventRecorder) Event(_ runtime.Object, e event.Event) {
+	r.events = append(r.events, e)
+}
+
+func (r *eventRecorder) WithAnnotations(_ ...string) event.Recorder { return r }
+
+// scanNoUpdatePath mocks a server that knows no path to update an extension
+// between two versions.
+func scanNoUpdatePath(_ context.Context, _ xsql.Query, _ ...interface{}) error {
+	return sql.ErrNoRows
+}
+
+func TestAnnounceUpdatePlan(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		path   sql.NullString
+		err    error
+		want   []event.Event
+	}{
+		"MultiHop": {
+			reason: "The steps of a multi-hop update should be recorded in an event before it runs",
+			path:   sql.NullString{String: "1.0--1.1--1.2--1.3", Valid: true},
+			want: []event.Event{event.Normal(ReasonPlannedUpdate, `Updating extension hstore from version 1.0 to 1.3 in 3 steps: `+
+				`ALTER EXTENSION "hstore" UPDATE TO "1.1"; ALTER EXTENSION "hstore" UPDATE TO "1.2"; ALTER EXTENSION "hstore" UPDATE TO "1.3"`)},
+		},
+		"SingleHop": {
+			reason: "No event should be recorded for an update that takes a single step",
+			path:   sql.NullString{String: "1.0--1.3", Valid: true},
+		},
+		"NoPath": {
+			reason: "No event should be recorded when PostgreSQL knows no update path",
+			path:   sql.NullString{},
+		},
+		"ErrSelectUpdatePlan": {
+			reason: "No event should be recorded, and the update should proceed, if the update can't be planned",
+			err:    errBoom,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			updated := false
+			r := &eventRecorder{}
+			e := external{
+				db: mockDB{
+					MockScan: func(_ context.Context, q xsql.Query, dest ...interface{}) error {
+						want := xsql.Query{
+							String:     "SELECT path FROM pg_extension_update_paths($1) WHERE source = $2 AND target = $3",
+							Parameters: []interface{}{"hstore", "1.0", "1.3"},
+						}
+						if diff := cmp.Diff(want, q); diff != "" {
+							t.Errorf("\n%s\nMockScan: -want, +got:\n%s\n", tc.reason, diff)
+						}
+						if updated {
+							t.Errorf("\n%s\nMockScan: the update should be planned before it runs", tc.reason)
+						}
+						*dest[0].(*sql.NullString) = tc.path
+						return tc.err
+					},
+					MockExecTx: func(_ context.Context, _ []xsql.Query) error {
+						updated = true
+						return nil
+					},
+					MockExec: func(_ context.Context, _ xsql.Query) error { return nil },
+				},
+				record: r,
+			}
+			cr := &v1alpha1.Extension{
+				Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{
+					Extension: "hstore",
+					Version:   pointer.StringPtr("1.3"),
+				}},
+				Status: v1alpha1.ExtensionStatus{AtProvider: v1alpha1.ExtensionObservation{
+					InstalledVersion: pointer.StringPtr("1.0"),
+				}},
+			}
+
+			if _, err := e.Update(context.Background(), cr); err != nil {
+				t.Fatalf("\n%s\ne.Update(...): %s", tc.reason, err)
+			}
+			if !updated {
+				t.Errorf("\n%s\ne.Update(...): the extension should be updated", tc.reason)
+			}
+			if diff := cmp.Diff(tc.want, r.events); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want events, +got events:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
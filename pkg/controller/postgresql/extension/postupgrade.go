@@ -0,0 +1,146 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// TypePostUpgradeUpdateNeeded is true while the installed extension is older
+// than the server's default version of the extension.
+const TypePostUpgradeUpdateNeeded xpv1.ConditionType = "PostUpgradeUpdateNeeded"
+
+// ReasonVersionSkew indicates the installed extension is older than the
+// server's default version of the extension.
+const ReasonVersionSkew xpv1.ConditionReason = "VersionSkew"
+
+// PostUpgradeUpdateNeeded returns a condition that indicates the installed
+// version of the extension is older than the server's default version.
+func PostUpgradeUpdateNeeded(installed, def string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypePostUpgradeUpdateNeeded,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonVersionSkew,
+		Message: fmt.Sprintf("Installed version %s is older than the server's default version %s. "+
+			"This is common after a PostgreSQL major upgrade; run ALTER EXTENSION ... UPDATE, "+
+			"or set spec.forProvider.postUpgradeUpdatePolicy to Apply and unset spec.forProvider.version.", installed, def),
+	}
+}
+
+// PostUpgradeUpdateResolved returns a condition that indicates the installed
+// version of the extension is not older than the server's default version.
+func PostUpgradeUpdateResolved() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypePostUpgradeUpdateNeeded,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonResolved,
+	}
+}
+
+// versionSkew returns true if the default version is newer than the installed
+// version. Versions that are not semver-like are skewed if they differ.
+func versionSkew(installed, def string) bool {
+	iv, iok := parseVersion(installed)
+	dv, dok := parseVersion(def)
+	if !iok || !dok {
+		return installed != def
+	}
+	return dv.compare(iv) > 0
+}
+
+func (c *external) defaultVersion(ctx context.Context, extension string) (*string, error) {
+	def := sql.NullString{}
+	err := c.db.Scan(ctx, xsql.Query{
+		String:     "SELECT default_version FROM pg_available_extensions WHERE name = $1",
+		Parameters: []interface{}{extension},
+	}, &def)
+	if xsql.IsNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errSelectDefaultVersion)
+	}
+	if !def.Valid {
+		return nil, nil
+	}
+	return &def.String, nil
+}
+
+// observePostUpgrade reports whether the observed extension is older than
+// the server's default version, if asked to. The default version is recorded
+// in status, where postUpgradeTarget finds it.
+func (c *external) observePostUpgrade(ctx context.Context, cr *v1alpha1.Extension, observed v1alpha1.ExtensionParameters) error {
+	cr.Status.AtProvider.DefaultVersion = nil
+	if cr.Spec.ForProvider.PostUpgradeUpdatePolicy == nil || observed.Version == nil {
+		resolvePostUpgrade(cr)
+		return nil
+	}
+
+	def, err := c.defaultVersion(ctx, cr.Spec.ForProvider.Extension)
+	if err != nil {
+		return err
+	}
+	cr.Status.AtProvider.DefaultVersion = def
+
+	if def == nil || !versionSkew(*observed.Version, *def) {
+		resolvePostUpgrade(cr)
+		return nil
+	}
+	cr.SetConditions(PostUpgradeUpdateNeeded(*observed.Version, *def))
+	return nil
+}
+
+// appliesPostUpgrade returns true if the supplied parameters track the
+// server's default version of the extension per the Apply policy. Only
+// extensions without a desired version do; the desired version is never
+// overwritten.
+func appliesPostUpgrade(p v1alpha1.ExtensionParameters) bool {
+	return p.Version == nil && p.PostUpgradeUpdatePolicy != nil && *p.PostUpgradeUpdatePolicy == v1alpha1.PostUpgradeUpdateApply
+}
+
+// postUpgradeTarget returns the version the extension should be updated to
+// per the Apply policy, or nil if it shouldn't be. Observe records the
+// installed and default versions in status.
+func postUpgradeTarget(cr *v1alpha1.Extension) *string {
+	if !appliesPostUpgrade(cr.Spec.ForProvider) {
+		return nil
+	}
+	installed, def := cr.Status.AtProvider.InstalledVersion, cr.Status.AtProvider.DefaultVersion
+	if installed == nil || def == nil || !versionSkew(*installed, *def) {
+		return nil
+	}
+	return def
+}
+
+func resolvePostUpgrade(cr *v1alpha1.Extension) {
+	if cr.GetCondition(TypePostUpgradeUpdateNeeded).Status == corev1.ConditionTrue {
+		cr.SetConditions(PostUpgradeUpdateResolved())
+	}
+}
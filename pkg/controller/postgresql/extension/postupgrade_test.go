@@ -0,0 +1,298 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestVersionSkew(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		installed string
+		def       string
+		want      bool
+	}{
+		"Equal": {
+			reason:    "Versions that are equal are not skewed",
+			installed: "1.1",
+			def:       "1.1.0",
+			want:      false,
+		},
+		"DefaultNewer": {
+			reason:    "An installed version older than the default version is skewed",
+			installed: "2.5.3",
+			def:       "3.1",
+			want:      true,
+		},
+		"InstalledNewer": {
+			reason:    "An installed version newer than the default version was chosen deliberately",
+			installed: "1.2",
+			def:       "1.1",
+			want:      false,
+		},
+		"NotSemverDiffer": {
+			reason:    "Versions that are not semver-like are skewed if they differ",
+			installed: "1.0beta",
+			def:       "1.0",
+			want:      true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := versionSkew(tc.installed, tc.def)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nversionSkew(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObservePostUpgrade(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	scan := func(def *string, err error) func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+		return func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			if def != nil {
+				*dest[0].(*sql.NullString) = sql.NullString{String: *def, Valid: true}
+			}
+			return err
+		}
+	}
+
+	observe := pointer.StringPtr("1.0")
+	apply := v1alpha1.PostUpgradeUpdateApply
+	obs := v1alpha1.PostUpgradeUpdateObserve
+
+	type args struct {
+		db       xsql.DB
+		version  *string
+		policy   *v1alpha1.PostUpgradeUpdatePolicy
+		observed *string
+		skewed   bool
+	}
+
+	type want struct {
+		version *string
+		def     *string
+		cond    corev1.ConditionStatus
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoPolicy": {
+			reason: "Skew should not be checked unless a policy is set",
+			args: args{
+				version: observe,
+			},
+			want: want{version: observe, cond: corev1.ConditionUnknown},
+		},
+		"ErrSelectDefaultVersion": {
+			reason: "Errors selecting the default version should be returned",
+			args: args{
+				db:      mockDB{MockScan: scan(nil, errBoom)},
+				version: observe,
+				policy:  &obs,
+			},
+			want: want{version: observe, cond: corev1.ConditionUnknown, err: errors.Wrap(errBoom, errSelectDefaultVersion)},
+		},
+		"NoDefaultVersion": {
+			reason: "There can be no skew when the extension has no default version",
+			args: args{
+				db:      mockDB{MockScan: scan(nil, nil)},
+				version: observe,
+				policy:  &obs,
+			},
+			want: want{version: observe, cond: corev1.ConditionUnknown},
+		},
+		"NotAvailable": {
+			reason: "There can be no skew when the extension is not available",
+			args: args{
+				db:      mockDB{MockScan: scan(nil, sql.ErrNoRows)},
+				version: observe,
+				policy:  &obs,
+			},
+			want: want{version: observe, cond: corev1.ConditionUnknown},
+		},
+		"NoSkew": {
+			reason: "The default version should be reported when it is installed",
+			args: args{
+				db:      mockDB{MockScan: scan(pointer.StringPtr("1.0"), nil)},
+				version: observe,
+				policy:  &obs,
+			},
+			want: want{version: observe, def: pointer.StringPtr("1.0"), cond: corev1.ConditionUnknown},
+		},
+		"SkewObserved": {
+			reason: "Skew should be reported, but not applied, per the Observe policy",
+			args: args{
+				db:      mockDB{MockScan: scan(pointer.StringPtr("1.1"), nil)},
+				version: observe,
+				policy:  &obs,
+			},
+			want: want{version: observe, def: pointer.StringPtr("1.1"), cond: corev1.ConditionTrue},
+		},
+		"SkewApplyExplicitVersion": {
+			reason: "An explicit desired version should never be overwritten per the Apply policy",
+			args: args{
+				db:      mockDB{MockScan: scan(pointer.StringPtr("1.1"), nil)},
+				version: observe,
+				policy:  &apply,
+			},
+			want: want{version: observe, def: pointer.StringPtr("1.1"), cond: corev1.ConditionTrue},
+		},
+		"SkewApplyUnsetVersion": {
+			reason: "An unset desired version should be left unset per the Apply policy",
+			args: args{
+				db:     mockDB{MockScan: scan(pointer.StringPtr("1.1"), nil)},
+				policy: &apply,
+			},
+			want: want{def: pointer.StringPtr("1.1"), cond: corev1.ConditionTrue},
+		},
+		"SkewResolved": {
+			reason: "The condition should be resolved once the default version is installed",
+			args: args{
+				db:       mockDB{MockScan: scan(pointer.StringPtr("1.1"), nil)},
+				version:  pointer.StringPtr("1.1"),
+				policy:   &obs,
+				observed: pointer.StringPtr("1.1"),
+				skewed:   true,
+			},
+			want: want{version: pointer.StringPtr("1.1"), def: pointer.StringPtr("1.1"), cond: corev1.ConditionFalse},
+		},
+		"SkewConstraint": {
+			reason: "Extensions whose version is a constraint should not be updated automatically",
+			args: args{
+				db:      mockDB{MockScan: scan(pointer.StringPtr("1.1"), nil)},
+				version: pointer.StringPtr("<1.1"),
+				policy:  &apply,
+			},
+			want: want{version: pointer.StringPtr("<1.1"), def: pointer.StringPtr("1.1"), cond: corev1.ConditionTrue},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.args.db}
+			cr := &v1alpha1.Extension{Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{
+				Version:                 tc.args.version,
+				PostUpgradeUpdatePolicy: tc.args.policy,
+			}}}
+			if tc.args.skewed {
+				cr.SetConditions(PostUpgradeUpdateNeeded("1.0", "1.1"))
+			}
+			observed := observe
+			if tc.args.observed != nil {
+				observed = tc.args.observed
+			}
+			err := e.observePostUpgrade(context.Background(), cr, v1alpha1.ExtensionParameters{Version: observed})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.observePostUpgrade(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.version, cr.Spec.ForProvider.Version); diff != "" {
+				t.Errorf("\n%s\ne.observePostUpgrade(...): -want version, +got version:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.def, cr.Status.AtProvider.DefaultVersion); diff != "" {
+				t.Errorf("\n%s\ne.observePostUpgrade(...): -want default version, +got default version:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cond, cr.GetCondition(TypePostUpgradeUpdateNeeded).Status); diff != "" {
+				t.Errorf("\n%s\ne.observePostUpgrade(...): -want condition status, +got condition status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPostUpgradeTarget(t *testing.T) {
+	apply := v1alpha1.PostUpgradeUpdateApply
+	obs := v1alpha1.PostUpgradeUpdateObserve
+
+	cases := map[string]struct {
+		reason    string
+		version   *string
+		policy    *v1alpha1.PostUpgradeUpdatePolicy
+		installed *string
+		def       *string
+		want      *string
+	}{
+		"Apply": {
+			reason:    "An extension without a desired version should be updated to a newer default version per the Apply policy",
+			policy:    &apply,
+			installed: pointer.StringPtr("1.0"),
+			def:       pointer.StringPtr("1.1"),
+			want:      pointer.StringPtr("1.1"),
+		},
+		"ExplicitVersion": {
+			reason:    "An extension with a desired version should not be updated to the default version",
+			version:   pointer.StringPtr("1.0"),
+			policy:    &apply,
+			installed: pointer.StringPtr("1.0"),
+			def:       pointer.StringPtr("1.1"),
+		},
+		"Observe": {
+			reason:    "An extension should not be updated to the default version per the Observe policy",
+			policy:    &obs,
+			installed: pointer.StringPtr("1.0"),
+			def:       pointer.StringPtr("1.1"),
+		},
+		"NoSkew": {
+			reason:    "An extension that is already at the default version should not be updated",
+			policy:    &apply,
+			installed: pointer.StringPtr("1.1"),
+			def:       pointer.StringPtr("1.1"),
+		},
+		"NoDefaultVersion": {
+			reason:    "An extension without a default version should not be updated",
+			policy:    &apply,
+			installed: pointer.StringPtr("1.0"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.Extension{
+				Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{
+					Version:                 tc.version,
+					PostUpgradeUpdatePolicy: tc.policy,
+				}},
+				Status: v1alpha1.ExtensionStatus{AtProvider: v1alpha1.ExtensionObservation{
+					InstalledVersion: tc.installed,
+					DefaultVersion:   tc.def,
+				}},
+			}
+			if diff := cmp.Diff(tc.want, postUpgradeTarget(cr)); diff != "" {
+				t.Errorf("\n%s\npostUpgradeTarget(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
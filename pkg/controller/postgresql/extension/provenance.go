@@ -0,0 +1,98 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+// Event reasons that record whether the provider created an extension, or
+// adopted one that already existed.
+const (
+	ReasonCreatedExtension event.Reason = "CreatedExtension"
+	ReasonAdoptedExtension event.Reason = "AdoptedExtension"
+)
+
+// Values of the origin label of the extensions metric.
+const (
+	originCreated = "created"
+	originAdopted = "adopted"
+)
+
+// extensions counts the extensions the provider has created or adopted.
+var extensions = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "provider_sql_postgresql_extensions_total",
+	Help: "Number of PostgreSQL extensions the provider created, or adopted because they already existed.",
+}, []string{"origin"})
+
+func init() {
+	metrics.Registry.MustRegister(extensions)
+}
+
+// provenance records whether the extension a managed resource represents was
+// created by the provider, or already existed when the provider first
+// observed it.
+type provenance struct {
+	managed.ExternalClient
+	record event.Recorder
+}
+
+func (p *provenance) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	o, err := p.ExternalClient.Observe(ctx, mg)
+	cr, ok := mg.(*v1alpha1.Extension)
+	if err != nil || !ok {
+		return o, err
+	}
+
+	if !o.ResourceExists {
+		// If the extension reappears without us creating it, it was adopted.
+		cr.Status.AtProvider.Adopted = nil
+		return o, nil
+	}
+
+	// We set Adopted when we create an extension, so if it's unset the
+	// extension existed before we did anything.
+	if cr.Status.AtProvider.Adopted == nil {
+		cr.Status.AtProvider.Adopted = pointer.BoolPtr(true)
+		p.record.Event(cr, event.Normal(ReasonAdoptedExtension, "Adopted existing extension "+cr.Spec.ForProvider.Extension))
+		extensions.WithLabelValues(originAdopted).Inc()
+	}
+	return o, nil
+}
+
+func (p *provenance) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	c, err := p.ExternalClient.Create(ctx, mg)
+	cr, ok := mg.(*v1alpha1.Extension)
+	if err != nil || !ok {
+		return c, err
+	}
+
+	cr.Status.AtProvider.Adopted = pointer.BoolPtr(false)
+	p.record.Event(cr, event.Normal(ReasonCreatedExtension, "Created extension "+cr.Spec.ForProvider.Extension))
+	extensions.WithLabelValues(originCreated).Inc()
+	return c, nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+// reasonRecorder records the reasons of the events it is asked to record.
+type reasonRecorder struct {
+	reasons []event.Reason
+}
+
+func (r *reasonRecorder) Event(_ runtime.Object, e event.Event) {
+	r.reasons = append(r.reasons, e.Reason)
+}
+
+func (r *reasonRecorder) WithAnnotations(_ ...string) event.Recorder { return r }
+
+func TestProvenance(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		err     error
+		adopted *bool
+		reasons []event.Reason
+		created float64
+		adopts  float64
+	}
+
+	cases := map[string]struct {
+		reason  string
+		ec      managed.ExternalClient
+		adopted *bool
+		create  bool
+		want    want
+	}{
+		"ObservePreExisting": {
+			reason: "An extension that exists before we created it should be recorded as adopted",
+			ec: &managed.ExternalClientFns{
+				ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{ResourceExists: true}, nil
+				},
+			},
+			want: want{adopted: pointer.BoolPtr(true), reasons: []event.Reason{ReasonAdoptedExtension}, adopts: 1},
+		},
+		"ObserveCreated": {
+			reason: "An extension we created should not be recorded as adopted when it is observed",
+			ec: &managed.ExternalClientFns{
+				ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{ResourceExists: true}, nil
+				},
+			},
+			adopted: pointer.BoolPtr(false),
+			want:    want{adopted: pointer.BoolPtr(false)},
+		},
+		"ObserveAlreadyAdopted": {
+			reason: "An extension should only be recorded as adopted once",
+			ec: &managed.ExternalClientFns{
+				ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{ResourceExists: true}, nil
+				},
+			},
+			adopted: pointer.BoolPtr(true),
+			want:    want{adopted: pointer.BoolPtr(true)},
+		},
+		"ObserveMissing": {
+			reason: "Provenance should be forgotten when the extension does not exist",
+			ec: &managed.ExternalClientFns{
+				ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{ResourceExists: false}, nil
+				},
+			},
+			adopted: pointer.BoolPtr(false),
+			want:    want{},
+		},
+		"ObserveError": {
+			reason: "Nothing should be recorded when the extension cannot be observed",
+			ec: &managed.ExternalClientFns{
+				ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{}, errBoom
+				},
+			},
+			want: want{err: errBoom},
+		},
+		"Create": {
+			reason: "An extension we create should be recorded as created",
+			ec: &managed.ExternalClientFns{
+				CreateFn: func(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+					return managed.ExternalCreation{}, nil
+				},
+			},
+			create: true,
+			want:   want{adopted: pointer.BoolPtr(false), reasons: []event.Reason{ReasonCreatedExtension}, created: 1},
+		},
+		"CreateError": {
+			reason: "Nothing should be recorded when the extension cannot be created",
+			ec: &managed.ExternalClientFns{
+				CreateFn: func(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+					return managed.ExternalCreation{}, errBoom
+				},
+			},
+			create: true,
+			want:   want{err: errBoom},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			created := testutil.ToFloat64(extensions.WithLabelValues(originCreated))
+			adopted := testutil.ToFloat64(extensions.WithLabelValues(originAdopted))
+
+			rec := &reasonRecorder{}
+			p := &provenance{ExternalClient: tc.ec, record: rec}
+			cr := &v1alpha1.Extension{
+				Spec:   v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{Extension: "hstore"}},
+				Status: v1alpha1.ExtensionStatus{AtProvider: v1alpha1.ExtensionObservation{Adopted: tc.adopted}},
+			}
+
+			var err error
+			if tc.create {
+				_, err = p.Create(context.Background(), cr)
+			} else {
+				_, err = p.Observe(context.Background(), cr)
+			}
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\np(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.adopted, cr.Status.AtProvider.Adopted); diff != "" {
+				t.Errorf("\n%s\np(...): -want adopted, +got adopted:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.reasons, rec.reasons); diff != "" {
+				t.Errorf("\n%s\np(...): -want events, +got events:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.created, testutil.ToFloat64(extensions.WithLabelValues(originCreated))-created); diff != "" {
+				t.Errorf("\n%s\np(...): -want created metric, +got created metric:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.adopts, testutil.ToFloat64(extensions.WithLabelValues(originAdopted))-adopted); diff != "" {
+				t.Errorf("\n%s\np(...): -want adopted metric, +got adopted metric:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
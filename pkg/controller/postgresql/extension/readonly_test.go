@@ -0,0 +1,74 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestReadOnlyObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	// db fails any statement, because only the read only client should be
+	// used to observe.
+	db := mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			t.Errorf("MockScan: unexpected scan using read-write client: %s", q.String)
+			return errBoom
+		},
+		MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+			t.Errorf("MockQuery: unexpected query using read-write client: %s", q.String)
+			return nil, errBoom
+		},
+	}
+
+	scans := 0
+	observe := mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			scans++
+			return scanAbsent(ctx, q, dest...)
+		},
+	}
+
+	e := &external{db: db, observe: observe}
+	cr := &v1alpha1.Extension{Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{
+		Extension: "hstore",
+		Version:   new(string),
+	}}}
+
+	o, err := e.Observe(context.Background(), cr)
+	if diff := cmp.Diff(nil, err, test.EquateErrors()); diff != "" {
+		t.Errorf("e.Observe(...): -want error, +got error:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(managed.ExternalObservation{ResourceExists: false}, o); diff != "" {
+		t.Errorf("e.Observe(...): -want, +got:\n%s\n", diff)
+	}
+	if scans == 0 {
+		t.Errorf("e.Observe(...): want the extension to be observed using the read only client")
+	}
+}
@@ -18,12 +18,12 @@ package extension
 
 import (
 	"context"
+	"database/sql"
 	"strings"
+	"time"
 
-	"github.com/lib/pq"
 	"github.com/pkg/errors"
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -37,127 +37,418 @@ import (
 	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/options"
 )
 
 const (
-	errTrackPCUsage = "cannot track ProviderConfig usage"
-	errGetPC        = "cannot get ProviderConfig"
-	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
-	errGetSecret    = "cannot get credentials Secret"
+	errSessionParameterNotAllowed = "session parameter %q is not allowed"
 
-	errNotExtension    = "managed resource is not a Extension custom resource"
-	errSelectExtension = "cannot select extension"
-	errCreateExtension = "cannot create extension"
-	errDropExtension   = "cannot drop extension"
+	errNotExtension     = "managed resource is not a Extension custom resource"
+	errSelectExtension  = "cannot select extension"
+	errCreateExtension  = "cannot create extension"
+	errUpdateExtension  = "cannot update extension"
+	errCommentExtension = "cannot comment on extension"
+	errDropExtension    = "cannot drop extension"
+	errPing             = "cannot connect to PostgreSQL server"
 
 	maxConcurrency = 5
 )
 
 // Setup adds a controller that reconciles Extension managed resources.
-func Setup(mgr ctrl.Manager, l logging.Logger) error {
+func Setup(mgr ctrl.Manager, o options.Options) error {
 	name := managed.ControllerName(v1alpha1.ExtensionGroupKind)
 
+	// Clients are cached, so they keep their connections open for reuse.
+	db := func(creds map[string][]byte, database string, params map[string]string, po ...postgresql.Option) xsql.DB {
+		return o.DB(xsql.NewRetryingDB(postgresql.NewPooled(creds, database, append(po, postgresql.WithRuntimeParameters(params))...), postgresql.IsTransient))
+	}
+
+	rec, err := o.Recorder(mgr, v1alpha1.ExtensionGroupVersionKind, func() resource.ProviderConfig { return &v1alpha1.ProviderConfig{} }, event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))
+	if err != nil {
+		return err
+	}
+
+	newObj := func() resource.Managed { return &v1alpha1.Extension{} }
 	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1alpha1.ExtensionGroupVersionKind),
-		managed.WithExternalConnecter(&connector{kube: mgr.GetClient(), usage: t, newDB: postgresql.New}),
-		managed.WithLogger(l.WithValues("controller", name)),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		managed.WithExternalConnecter(o.ExternalConnecter(options.NewConnectionLimitConnecter(&connector{kube: mgr.GetClient(), usage: t, newDB: db, ddl: o.DDLRateLimiter, record: rec, log: o.Logger.WithValues("controller", name), dbs: newDBCache(), slow: o.SlowOperationThreshold}, postgresql.IsTooManyConnections))),
+		managed.WithFinalizer(options.NewReleasingFinalizer(resource.NewAPIFinalizer(mgr.GetClient(), options.ManagedFinalizer), mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(rec))
 
-	return ctrl.NewControllerManagedBy(mgr).
-		Named(name).
-		For(&v1alpha1.Extension{}).
+	if err := o.For(ctrl.NewControllerManagedBy(mgr).Named(name), &v1alpha1.Extension{}).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: maxConcurrency,
 		}).
-		Complete(r)
+		Complete(options.NewRepeatedFailureReconciler(
+			options.NewIntervalReconciler(
+				options.NewDatabaseAllowlistReconciler(
+					options.NewConnectionLimitReconciler(r, mgr.GetClient(), newObj, o.ConnectionLimitBackoff),
+					mgr.GetClient(), newObj, checkDatabase(postgresql.NewConnector(mgr.GetClient(), nil)), postgresql.IsDatabaseNotAllowed, time.Minute),
+				mgr.GetClient(), newObj, o.ObserveInterval, o.CreateRetryInterval),
+			mgr.GetClient(), newObj, o.RepeatedFailureBackoff, options.MaxRepeatedFailureBackoff)); err != nil {
+		return err
+	}
+
+	if o.ExtensionInventory == nil {
+		return nil
+	}
+	return SetupInventory(mgr, *o.ExtensionInventory, o.Logger)
+}
+
+// checkDatabase returns a function that returns an error if the ProviderConfig
+// of an Extension does not allow the database it targets. Extensions that
+// target many databases are always allowed; they skip disallowed databases.
+func checkDatabase(c *postgresql.Connector) func(ctx context.Context, mg resource.Managed) error {
+	return func(ctx context.Context, mg resource.Managed) error {
+		cr, ok := mg.(*v1alpha1.Extension)
+		if !ok {
+			return errors.New(errNotExtension)
+		}
+		if cr.Spec.ForProvider.DatabasePattern != nil {
+			return nil
+		}
+		return c.CheckDatabase(ctx, mg, targetDatabase(cr.Spec.ForProvider))
+	}
+}
+
+// targetDatabase returns the database the supplied extension targets, or an
+// empty string if it targets the default database.
+func targetDatabase(p v1alpha1.ExtensionParameters) string {
+	if p.Database == nil {
+		return ""
+	}
+	return *p.Database
 }
 
 type connector struct {
-	kube  client.Client
-	usage resource.Tracker
-	newDB func(creds map[string][]byte, database string) xsql.DB
+	kube   client.Client
+	usage  resource.Tracker
+	newDB  func(creds map[string][]byte, database string, params map[string]string, o ...postgresql.Option) xsql.DB
+	ddl    *options.DDLRateLimiter
+	record event.Recorder
+	log    logging.Logger
+
+	// dbs caches the clients newDB returns, if set.
+	dbs *dbCache
+
+	// slow is how long an operation may take before a warning is logged and
+	// recorded. Slow operations are not warned about when it is zero.
+	slow time.Duration
 }
 
-func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) { //nolint:gocyclo
+	// NOTE(negz): This is only a little over our cyclomatic complexity limit,
+	// and more readable as one function than it would be split up.
+
 	cr, ok := mg.(*v1alpha1.Extension)
 	if !ok {
 		return nil, errors.New(errNotExtension)
 	}
 
-	if err := c.usage.Track(ctx, mg); err != nil {
-		return nil, errors.Wrap(err, errTrackPCUsage)
+	// Reject names PostgreSQL would truncate or refuse before we connect.
+	if err := validateExtensionName(cr.Spec.ForProvider.Extension); err != nil {
+		return nil, err
 	}
 
-	// ProviderConfigReference could theoretically be nil, but in practice the
-	// DefaultProviderConfig initializer will set it before we get here.
-	pc := &v1alpha1.ProviderConfig{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
-		return nil, errors.Wrap(err, errGetPC)
+	conn, err := postgresql.NewConnector(c.kube, c.usage).Resolve(ctx, mg,
+		postgresql.AllowDatabase(targetDatabase(cr.Spec.ForProvider)),
+		func(_ *v1alpha1.ProviderConfig) error {
+			return validateSessionParameters(cr.Spec.ForProvider.SessionParameters)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	pc := conn.ProviderConfig
+
+	// Extensions that target many databases filter them by the allowlist
+	// instead.
+	if cr.Spec.ForProvider.DatabasePattern == nil {
+		if err := conn.CheckDatabase(targetDatabase(cr.Spec.ForProvider)); err != nil {
+			return nil, err
+		}
 	}
 
-	// We don't need to check the credentials source because we currently only
-	// support one source (PostgreSQLConnectionSecret), which is required and
-	// enforced by the ProviderConfig schema.
-	ref := pc.Spec.Credentials.ConnectionSecretRef
-	if ref == nil {
-		return nil, errors.New(errNoSecretRef)
+	// Fail fast, with a clear error, if the server can't be connected to at
+	// all. The managed reconciler reports the error in the resource's
+	// ReconcileError condition.
+	if err := c.ping(ctx, conn, cr.Spec.ForProvider); err != nil {
+		return nil, err
 	}
 
-	s := &corev1.Secret{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
-		return nil, errors.Wrap(err, errGetSecret)
+	// All resources using this ProviderConfig share a DDL rate limit.
+	ops, burst := pc.Spec.DDLRateLimit.Rate()
+
+	record := c.record
+	if record == nil {
+		record = event.NewNopRecorder()
+	}
+
+	forDatabase := func(database string) *external {
+		e := &external{db: c.ddl.DB(pc.GetName(), ops, burst, c.db(conn, database, cr.Spec.ForProvider.SessionParameters, false)), audit: pc.Spec.Audit, kube: c.kube, log: c.log, record: record}
+		if ro := pc.Spec.ReadOnlyObserve; ro != nil && *ro {
+			// Observing never changes the server, so it isn't rate limited.
+			e.observe = c.db(conn, database, cr.Spec.ForProvider.SessionParameters, true)
+		}
+		return e
+	}
+
+	persist := func(ctx context.Context, cr *v1alpha1.Extension) error { return c.kube.Status().Update(ctx, cr) }
+
+	if cr.Spec.ForProvider.DatabasePattern != nil {
+		return c.warnSlow(&hinted{&fleetExternal{
+			db:          c.ddl.DB(pc.GetName(), ops, burst, c.db(conn, "", nil, false)),
+			forDatabase: forDatabase,
+			allowed:     pc.Spec.AllowsDatabase,
+			persist:     persist,
+		}}, record), nil
 	}
 
 	// We do not want to create an extension on the default DB
 	// if the user was expecting a database name to be resolved.
-	if cr.Spec.ForProvider.Database != nil {
-		return &external{db: c.newDB(s.Data, *cr.Spec.ForProvider.Database)}, nil
+	e := forDatabase(targetDatabase(cr.Spec.ForProvider))
+	e.persist = persist
+	return c.warnSlow(&hinted{&provenance{ExternalClient: &schemaRecorder{ExternalClient: e, kube: c.kube}, record: record}}, record), nil
+}
+
+// pingQuery is a trivial query used to check that a server can be connected
+// to.
+const pingQuery = "SELECT 1"
+
+// ping returns an error if the database the supplied extension targets can't
+// run a trivial query, for example because the credentials are wrong or the
+// server is down. An extension that targets many databases pings the
+// default database.
+func (c *connector) ping(ctx context.Context, conn *postgresql.Connection, p v1alpha1.ExtensionParameters) error {
+	database, params := "", p.SessionParameters
+	switch {
+	case p.DatabasePattern != nil:
+		params = nil
+	case p.Database != nil:
+		database = *p.Database
 	}
+	one := 0
+	return errors.Wrap(c.db(conn, database, params, false).Scan(ctx, xsql.Query{String: pingQuery}, &one), errPing)
+}
 
-	return &external{db: c.newDB(s.Data, "")}, nil
+// warnSlow wraps the supplied client so that it warns about operations that
+// are slower than the connector's slow operation threshold, if it has one.
+func (c *connector) warnSlow(ec managed.ExternalClient, record event.Recorder) managed.ExternalClient {
+	if c.slow == 0 {
+		return ec
+	}
+	log := c.log
+	if log == nil {
+		log = logging.NewNopLogger()
+	}
+	return &slowWarner{ExternalClient: ec, threshold: c.slow, log: log, record: record, now: time.Now}
 }
 
-type external struct{ db xsql.DB }
+// db returns a client that connects to the supplied database with the
+// supplied session parameters, reusing a cached client if there is one. The
+// client's sessions are read only if readOnly is true.
+func (c *connector) db(conn *postgresql.Connection, database string, params map[string]string, readOnly bool) xsql.DB {
+	po := conn.Options()
+	if readOnly {
+		po = append(po, postgresql.WithReadOnlyDeferrable())
+	}
+	database = conn.DatabaseOrDefault(database)
+	newDB := func() xsql.DB { return c.newDB(conn.Credentials, database, params, po...) }
+	if c.dbs == nil {
+		return newDB()
+	}
+	return c.dbs.Get(newDBKey(conn.ProviderConfig.GetName(), conn.Version, database, params, readOnly), newDB)
+}
+
+// sessionParametersAllowed are the run-time parameters an Extension may set.
+// They're limited to parameters that tune resource usage, so that an Extension
+// can't otherwise change the behaviour of the session that manages it.
+var sessionParametersAllowed = map[string]bool{
+	"maintenance_work_mem":             true,
+	"work_mem":                         true,
+	"temp_buffers":                     true,
+	"statement_timeout":                true,
+	"lock_timeout":                     true,
+	"max_parallel_maintenance_workers": true,
+}
+
+func validateSessionParameters(params map[string]string) error {
+	for n := range params {
+		if !sessionParametersAllowed[n] {
+			return errors.Errorf(errSessionParameterNotAllowed, n)
+		}
+	}
+	return nil
+}
+
+type external struct {
+	db    xsql.DB
+	audit *v1alpha1.AuditConfig
+
+	// quote is used to quote identifiers and literals. DefaultQuoter is used
+	// when it is nil.
+	quote postgresql.Quoter
 
-func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	// kube is used to record a snapshot of the extension's objects before
+	// it is dropped.
+	kube client.Client
+
+	// observe is used in place of db to observe the extension, if set.
+	observe xsql.DB
+
+	// log is used to log why an extension is not up to date. Nothing is
+	// logged when it is nil.
+	log logging.Logger
+
+	// record is used to record the steps of planned updates. Nothing is
+	// recorded when it is nil.
+	record event.Recorder
+
+	// persist persists the status of the supplied extension, so that the
+	// progress of a multi-step update is visible while it's underway.
+	// Progress is not persisted when it is nil.
+	persist func(ctx context.Context, cr *v1alpha1.Extension) error
+}
+
+func (c *external) quoter() postgresql.Quoter {
+	if c.quote == nil {
+		return postgresql.DefaultQuoter
+	}
+	return c.quote
+}
+
+func (c *external) logger() logging.Logger {
+	if c.log == nil {
+		return logging.NewNopLogger()
+	}
+	return c.log
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) { //nolint:gocyclo
 	cr, ok := mg.(*v1alpha1.Extension)
 	if !ok {
 		return managed.ExternalObservation{}, errors.New(errNotExtension)
 	}
 
+	if c.observe != nil {
+		ro := *c
+		ro.db, ro.observe = c.observe, nil
+		return ro.Observe(ctx, mg)
+	}
+
 	// If the Extension exists, it will have all of these properties.
 	observed := v1alpha1.ExtensionParameters{
 		Version: new(string),
 	}
 
-	query := "SELECT " +
-		"extversion " +
-		"FROM pg_extension " +
-		"WHERE extname = $1"
-
-	err := c.db.Scan(ctx, xsql.Query{
-		String:     query,
-		Parameters: []interface{}{cr.Spec.ForProvider.Extension},
-	},
+	comment := sql.NullString{}
+	owner := sql.NullString{}
+	schema := sql.NullString{}
+	relocatable := sql.NullBool{}
+	readable, versioned, err := c.scanExtension(ctx, cr.Spec.ForProvider.Extension,
 		observed.Version,
+		&comment,
+		&owner,
+		&schema,
+		&relocatable,
 	)
 
 	// If the database we try to connect on does not exist then
 	// there cannot be an extension on that database either.
-	if xsql.IsNoRows(err) || postgresql.IsInvalidCatalog(err) {
+	absent := postgresql.IsInvalidCatalog(err)
+
+	// An extension we cannot see is not necessarily absent. Treating it as
+	// absent would cause us to try to create it over and over.
+	if xsql.IsNoRows(err) {
+		if err := c.confirmAbsent(ctx, cr.Spec.ForProvider.Extension); err != nil {
+			return managed.ExternalObservation{}, err
+		}
+		absent = true
+	}
+
+	if absent {
+		cr.Status.AtProvider.InstalledVersion = nil
+		cr.Status.AtProvider.Owner = nil
+		observeSchema(cr, sql.NullString{}, sql.NullBool{})
+		cr.Status.AtProvider.DefinitionHash = nil
+		cr.Status.AtProvider.InstalledDependencies = nil
+		cr.Status.AtProvider.PendingStatements = c.previewCreate(ctx, cr.Spec.ForProvider)
 		return managed.ExternalObservation{ResourceExists: false}, nil
 	}
 	if err != nil {
 		return managed.ExternalObservation{}, errors.Wrap(err, errSelectExtension)
 	}
 
+	if comment.Valid {
+		observed.Comment = &comment.String
+	}
+	if schema.Valid {
+		observed.Schema = &schema.String
+	}
+	observeVersioned(cr, versioned, &observed)
+	cr.Status.AtProvider.InstalledVersion = observed.Version
+	observeOwner(cr, readable, owner)
+	observeSchema(cr, schema, relocatable)
+	if err := c.observeConflicts(ctx, cr, schema, relocatable); err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	if err := c.observeIntegrity(ctx, cr); err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	if err := c.observeRestart(ctx, cr); err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	li := lateInit(observed, &cr.Spec.ForProvider)
+	lis, err := c.lateInitSchema(ctx, cr, schema)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	if err := c.observePostUpgrade(ctx, cr, observed); err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	// The desired version may be a constraint, in which case we compare the
+	// observed version to the best version that satisfies it.
+	desired := cr.Spec.ForProvider
+	if !versioned {
+		// A version we can't observe can't drift, so it isn't reconciled.
+		desired.Version = nil
+	} else if desired.Version, err = c.targetVersion(ctx, desired); err != nil {
+		return managed.ExternalObservation{}, err
+	} else if desired.Version == nil {
+		desired.Version = postUpgradeTarget(cr)
+	}
+	if desired.Schema, err = c.desiredSchema(ctx, desired); err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	observeFallback(cr, observed, &desired)
+
 	cr.SetConditions(xpv1.Available())
+	clearDiagnostics(cr)
+
+	utd := upToDate(observed, desired)
+	if !utd {
+		c.logger().Debug("Extension is not up to date", "name", cr.GetName(), "diff", driftDiff(observed, desired))
+	} else {
+		cr.Status.AtProvider.Progress = nil
+	}
+	cr.Status.AtProvider.PendingStatements = nil
+	for _, q := range driftQueries(c.quoter(), observed, desired) {
+		cr.Status.AtProvider.PendingStatements = append(cr.Status.AtProvider.PendingStatements, q.String)
+	}
+
+	if err := c.observeUpdatePath(ctx, cr, observed, desired); err != nil {
+		return managed.ExternalObservation{}, err
+	}
 
 	return managed.ExternalObservation{
 		ResourceExists:          true,
-		ResourceLateInitialized: lateInit(observed, &cr.Spec.ForProvider),
-		ResourceUpToDate:        upToDate(observed, cr.Spec.ForProvider),
+		ResourceLateInitialized: li || lis,
+		ResourceUpToDate:        utd,
 	}, nil
 }
 
@@ -167,24 +458,91 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalCreation{}, errors.New(errNotExtension)
 	}
 
-	var b strings.Builder
-	b.WriteString("CREATE EXTENSION IF NOT EXISTS ")
-	b.WriteString(pq.QuoteIdentifier(cr.Spec.ForProvider.Extension))
+	missing, err := c.missingRequirements(ctx, cr.Spec.ForProvider.Requires)
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateExtension)
+	}
+	if len(missing) > 0 {
+		return managed.ExternalCreation{}, errors.Errorf(errRequiredMissing, missing)
+	}
+
+	v, err := c.targetVersion(ctx, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateExtension)
+	}
 
-	if cr.Spec.ForProvider.Version != nil {
-		b.WriteString(" WITH VERSION ")
-		b.WriteString(pq.QuoteIdentifier(*cr.Spec.ForProvider.Version))
+	installed, err := c.cascadeBaseline(ctx, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateExtension)
 	}
 
-	return managed.ExternalCreation{}, errors.Wrap(c.db.Exec(ctx, xsql.Query{String: b.String()}), errCreateExtension)
+	if err := c.exec(ctx, cr, createQuery(c.quoter(), cr.Spec.ForProvider, v), auditActionCreate); err != nil {
+		ok, ferr := c.createFallback(ctx, cr, v, err)
+		if !ok {
+			return managed.ExternalCreation{}, c.diagnoseCreateError(ctx, cr, v, err)
+		}
+		if ferr != nil {
+			return managed.ExternalCreation{}, ferr
+		}
+	}
+
+	return managed.ExternalCreation{}, c.observeInstalledDependencies(ctx, cr, installed)
 }
 
-func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) { //nolint:gocyclo
-	_, ok := mg.(*v1alpha1.Extension)
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Extension)
 	if !ok {
 		return managed.ExternalUpdate{}, errors.New(errNotExtension)
 	}
 
+	u, err := c.update(ctx, cr)
+
+	// The extension may have been dropped since we observed it. The next
+	// Observe will find it absent and create it, so there's nothing to report.
+	if postgresql.IsUndefinedObject(err) {
+		c.logger().Debug("Extension vanished during update", "name", cr.GetName(), "error", err)
+		return managed.ExternalUpdate{}, nil
+	}
+	return u, err
+}
+
+func (c *external) update(ctx context.Context, cr *v1alpha1.Extension) (managed.ExternalUpdate, error) { //nolint:gocyclo
+	v, err := c.targetVersion(ctx, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateExtension)
+	}
+	if v == nil {
+		v = postUpgradeTarget(cr)
+	}
+	// Observe records the installed version just before we're called.
+	installed := v1alpha1.ExtensionParameters{Version: cr.Status.AtProvider.InstalledVersion}
+	if !versionUpToDate(installed, v1alpha1.ExtensionParameters{Version: v}) {
+		if err := c.updateSteps(ctx, cr, *v); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateExtension)
+		}
+	}
+
+	if cm := cr.Spec.ForProvider.Comment; cm != nil {
+		if err := c.db.Exec(ctx, commentQuery(c.quoter(), cr.Spec.ForProvider.Extension, *cm)); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errCommentExtension)
+		}
+	}
+
+	// Setting the schema an extension is already in does nothing, even if the
+	// extension isn't relocatable.
+	s, err := c.desiredSchema(ctx, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateExtension)
+	}
+	if s != nil {
+		if err := checkRelocatable(cr.Spec.ForProvider.Extension, cr.Status.AtProvider, *s); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+		if err := c.db.Exec(ctx, setSchemaQuery(c.quoter(), cr.Spec.ForProvider.Extension, *s)); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errSetSchema)
+		}
+	}
+
 	return managed.ExternalUpdate{}, nil
 }
 
@@ -194,24 +552,162 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 		return errors.New(errNotExtension)
 	}
 
-	err := c.db.Exec(ctx, xsql.Query{String: "DROP EXTENSION IF EXISTS " + pq.QuoteIdentifier(cr.Spec.ForProvider.Extension)})
-	return errors.Wrap(err, errDropExtension)
+	// An orphaned extension is left in the database. The managed reconciler
+	// doesn't call Delete for orphaned resources, but we don't rely on that.
+	if cr.GetDeletionPolicy() == xpv1.DeletionOrphan {
+		restartRequired.DeleteLabelValues(cr.GetName())
+		return nil
+	}
+
+	// DROP EXTENSION would fail while objects depend on the extension, so we
+	// wait for them to be dropped rather than snapshot an extension we can't
+	// drop yet. Dropping with CASCADE drops them too, so there's no need.
+	if dropPolicy(cr.Spec.ForProvider) != v1alpha1.DropCascade {
+		if err := c.awaitDependents(ctx, cr); err != nil {
+			return err
+		}
+	}
+
+	if err := c.snapshot(ctx, cr); err != nil {
+		return errors.Wrap(err, errSnapshot)
+	}
+
+	if err := c.exec(ctx, cr, dropQuery(c.quoter(), cr.Spec.ForProvider), auditActionDrop); err != nil {
+		return errors.Wrap(err, errDropExtension)
+	}
+
+	if err := c.verifyDropped(ctx, cr); err != nil {
+		return err
+	}
+
+	// A dropped extension can't be waiting for a restart.
+	restartRequired.DeleteLabelValues(cr.GetName())
+	return nil
+}
+
+// dropPolicy returns the supplied extension's drop policy, which defaults to
+// Restrict.
+func dropPolicy(p v1alpha1.ExtensionParameters) v1alpha1.DropPolicy {
+	if p.DropPolicy == nil {
+		return v1alpha1.DropRestrict
+	}
+	return *p.DropPolicy
+}
+
+// dropQuery returns the query used to drop an extension. PostgreSQL refuses
+// to drop an extension that other objects depend on unless CASCADE is
+// specified.
+func dropQuery(q postgresql.Quoter, p v1alpha1.ExtensionParameters) xsql.Query {
+	s := "DROP EXTENSION IF EXISTS " + q.QuoteIdentifier(p.Extension)
+	if dropPolicy(p) == v1alpha1.DropCascade {
+		s += " CASCADE"
+	}
+	return xsql.Query{String: s}
+}
+
+// previewCreate returns the statements Create would run. The preview is
+// best-effort; if we can't determine which version would be installed Create
+// will surface the error.
+func (c *external) previewCreate(ctx context.Context, p v1alpha1.ExtensionParameters) []string {
+	v, err := c.targetVersion(ctx, p)
+	if err != nil {
+		return nil
+	}
+	return []string{createQuery(c.quoter(), p, v).String}
+}
+
+// createQuery returns the query used to create an extension. It uses IF NOT
+// EXISTS so that Create is idempotent; an extension that was installed
+// between Observe and Create is adopted, and its version reconciled by the
+// next Observe.
+func createQuery(q postgresql.Quoter, p v1alpha1.ExtensionParameters, version *string) xsql.Query {
+	var b strings.Builder
+	b.WriteString("CREATE EXTENSION IF NOT EXISTS ")
+	b.WriteString(q.QuoteIdentifier(p.Extension))
+
+	if p.Schema != nil || version != nil {
+		b.WriteString(" WITH")
+	}
+
+	if p.Schema != nil {
+		b.WriteString(" SCHEMA ")
+		b.WriteString(q.QuoteIdentifier(*p.Schema))
+	}
+
+	if version != nil {
+		b.WriteString(" VERSION ")
+		b.WriteString(q.QuoteIdentifier(*version))
+	}
+
+	if p.Cascade != nil && *p.Cascade {
+		b.WriteString(" CASCADE")
+	}
+
+	return xsql.Query{String: b.String()}
+}
+
+func updateQuery(q postgresql.Quoter, extension, version string) xsql.Query {
+	return xsql.Query{String: "ALTER EXTENSION " + q.QuoteIdentifier(extension) + " UPDATE TO " + q.QuoteIdentifier(version)}
+}
+
+func commentQuery(q postgresql.Quoter, extension, comment string) xsql.Query {
+	// COMMENT does not support parameters, so the comment must be quoted.
+	return xsql.Query{String: "COMMENT ON EXTENSION " + q.QuoteIdentifier(extension) + " IS " + q.QuoteLiteral(comment)}
+}
+
+// driftQueries returns the queries that would bring the observed extension to
+// its desired state.
+func driftQueries(q postgresql.Quoter, observed, desired v1alpha1.ExtensionParameters) []xsql.Query {
+	var ql []xsql.Query
+	if !versionUpToDate(observed, desired) {
+		ql = append(ql, updateQuery(q, desired.Extension, *desired.Version))
+	}
+	if !commentUpToDate(observed, desired) {
+		ql = append(ql, commentQuery(q, desired.Extension, *desired.Comment))
+	}
+	if !schemaUpToDate(observed, desired) {
+		ql = append(ql, setSchemaQuery(q, desired.Extension, *desired.Schema))
+	}
+	return ql
 }
 
 func upToDate(observed, desired v1alpha1.ExtensionParameters) bool {
+	return versionUpToDate(observed, desired) && commentUpToDate(observed, desired) && schemaUpToDate(observed, desired)
+}
+
+func versionUpToDate(observed, desired v1alpha1.ExtensionParameters) bool {
 	if desired.Version == nil || (observed.Version != nil && *desired.Version == *observed.Version) {
 		return true
 	}
 	return false
 }
 
+// commentUpToDate treats an observed NULL comment as equivalent to an empty
+// one, because setting a comment to the empty string removes it.
+func commentUpToDate(observed, desired v1alpha1.ExtensionParameters) bool {
+	if desired.Comment == nil {
+		return true
+	}
+	return pointer.StringPtrDerefOr(observed.Comment, "") == *desired.Comment
+}
+
+// lateInit fills only the desired parameters that are unset. An explicit
+// version is authoritative; an extension that was updated out-of-band is
+// reported as not up to date and updated back to it.
 func lateInit(observed v1alpha1.ExtensionParameters, desired *v1alpha1.ExtensionParameters) bool {
 	li := false
 
-	if desired.Version == nil && observed.Version != nil {
+	// An extension that tracks the server's default version per the Apply
+	// post-upgrade policy must not be pinned to the version it has now.
+	if desired.Version == nil && observed.Version != nil && !appliesPostUpgrade(*desired) {
 		desired.Version = observed.Version
 		li = true
 	}
 
+	if desired.Comment == nil && observed.Comment != nil {
+		desired.Comment = observed.Comment
+		li = true
+	}
+
 	return li
 }
@@ -19,19 +19,27 @@ package extension
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
 	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
 )
 
@@ -39,6 +47,7 @@ type mockDB struct {
 	MockExec                 func(ctx context.Context, q xsql.Query) error
 	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
 	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockQuery                func(ctx context.Context, q xsql.Query) (*sql.Rows, error)
 	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
 }
 
@@ -52,19 +61,30 @@ func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) err
 	return m.MockScan(ctx, q, dest...)
 }
 func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
-	return &sql.Rows{}, nil
+	return m.MockQuery(ctx, q)
 }
 func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
 	return m.MockGetConnectionDetails(username, password)
 }
 
+// pingableDB returns a DB that answers the query Connect uses to check that
+// the server can be connected to.
+func pingableDB() mockDB {
+	return mockDB{MockScan: func(_ context.Context, q xsql.Query, _ ...interface{}) error {
+		if q.String != pingQuery {
+			return errors.Errorf("unexpected query %q", q.String)
+		}
+		return nil
+	}}
+}
+
 func TestConnect(t *testing.T) {
 	errBoom := errors.New("boom")
 
 	type fields struct {
 		kube  client.Client
 		usage resource.Tracker
-		newDB func(creds map[string][]byte, database string) xsql.DB
+		newDB func(creds map[string][]byte, database string, params map[string]string, o ...postgresql.Option) xsql.DB
 	}
 
 	type args struct {
@@ -85,6 +105,22 @@ func TestConnect(t *testing.T) {
 			},
 			want: errors.New(errNotExtension),
 		},
+		"ErrInvalidExtensionName": {
+			reason: "An error should be returned before we do anything else if the extension name is invalid",
+			fields: fields{
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return errBoom }),
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Extension: "hstore--1.0",
+						},
+					},
+				},
+			},
+			want: errors.Errorf(errNameReserved, "hstore--1.0", `names must not contain "--"`),
+		},
 		"ErrTrackProviderConfigUsage": {
 			reason: "An error should be returned if we can't track our ProviderConfig usage",
 			fields: fields{
@@ -93,7 +129,7 @@ func TestConnect(t *testing.T) {
 			args: args{
 				mg: &v1alpha1.Extension{},
 			},
-			want: errors.Wrap(errBoom, errTrackPCUsage),
+			want: errors.Wrap(errBoom, "cannot track ProviderConfig usage"),
 		},
 		"ErrGetProviderConfig": {
 			reason: "An error should be returned if we can't get our ProviderConfig",
@@ -112,7 +148,7 @@ func TestConnect(t *testing.T) {
 					},
 				},
 			},
-			want: errors.Wrap(errBoom, errGetPC),
+			want: errors.Wrap(errBoom, "cannot get ProviderConfig"),
 		},
 		"ErrMissingConnectionSecret": {
 			reason: "An error should be returned if our ProviderConfig doesn't specify a connection secret",
@@ -134,7 +170,7 @@ func TestConnect(t *testing.T) {
 					},
 				},
 			},
-			want: errors.New(errNoSecretRef),
+			want: errors.New("ProviderConfig does not reference a credentials Secret"),
 		},
 		"ErrGetConnectionSecret": {
 			reason: "An error should be returned if we can't get our ProviderConfig's connection secret",
@@ -161,229 +197,1112 @@ func TestConnect(t *testing.T) {
 					},
 				},
 			},
-			want: errors.Wrap(errBoom, errGetSecret),
-		},
-	}
-
-	for name, tc := range cases {
-		t.Run(name, func(t *testing.T) {
-			e := &connector{kube: tc.fields.kube, usage: tc.fields.usage, newDB: tc.fields.newDB}
-			_, err := e.Connect(tc.args.ctx, tc.args.mg)
-			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
-				t.Errorf("\n%s\ne.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
-			}
-		})
-	}
-}
-
-func TestObserve(t *testing.T) {
-	errBoom := errors.New("boom")
-
-	type fields struct {
-		db xsql.DB
-	}
-
-	type args struct {
-		ctx context.Context
-		mg  resource.Managed
-	}
-
-	type want struct {
-		o   managed.ExternalObservation
-		err error
-	}
-
-	cases := map[string]struct {
-		reason string
-		fields fields
-		args   args
-		want   want
-	}{
-		"ErrNotExtension": {
-			reason: "An error should be returned if the managed resource is not a Extension",
-			args: args{
-				mg: nil,
-			},
-			want: want{
-				err: errors.New(errNotExtension),
-			},
+			want: errors.Wrap(errBoom, "cannot get credentials Secret"),
 		},
-		"ErrNoExtension": {
-			reason: "We should return ResourceExists: false when no extension is found",
+		"ErrDatabaseNotAllowed": {
+			reason: "An error should be returned if the extension targets a database the ProviderConfig does not allow",
 			fields: fields{
-				db: mockDB{
-					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return sql.ErrNoRows },
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						if o, ok := obj.(*v1alpha1.ProviderConfig); ok {
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+							o.Spec.AllowedDatabases = []string{"allowed"}
+						}
+						return nil
+					}),
 				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
 			},
 			args: args{
-				mg: &v1alpha1.Extension{},
-			},
-			want: want{
-				o: managed.ExternalObservation{ResourceExists: false},
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+						ForProvider: v1alpha1.ExtensionParameters{
+							Database: pointer.StringPtr("disallowed"),
+						},
+					},
+				},
 			},
+			want: &postgresql.DatabaseNotAllowedError{Database: "disallowed"},
 		},
-		"ErrSelectExtension": {
-			reason: "We should return any errors encountered while trying to select the extension",
+		"DatabaseAllowed": {
+			reason: "No error should be returned if the extension targets a database the ProviderConfig allows",
 			fields: fields{
-				db: mockDB{
-					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						if o, ok := obj.(*v1alpha1.ProviderConfig); ok {
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+							o.Spec.AllowedDatabases = []string{"allowed"}
+						}
+						return nil
+					}),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+				newDB: func(creds map[string][]byte, database string, params map[string]string, o ...postgresql.Option) xsql.DB {
+					return pingableDB()
 				},
 			},
 			args: args{
 				mg: &v1alpha1.Extension{
 					Spec: v1alpha1.ExtensionSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
 						ForProvider: v1alpha1.ExtensionParameters{
-							Version: new(string),
+							Database: pointer.StringPtr("allowed"),
 						},
 					},
 				},
 			},
-			want: want{
-				err: errors.Wrap(errBoom, errSelectExtension),
-			},
+			want: nil,
 		},
-		"Success": {
-			reason: "We should return no error if we can successfully select our extension",
+		"ErrSessionParameterNotAllowed": {
+			reason: "An error should be returned if the extension sets a session parameter that is not allowed",
 			fields: fields{
-				db: mockDB{
-					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						if o, ok := obj.(*v1alpha1.ProviderConfig); ok {
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+						}
+						return nil
+					}),
 				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
 			},
 			args: args{
 				mg: &v1alpha1.Extension{
 					Spec: v1alpha1.ExtensionSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
 						ForProvider: v1alpha1.ExtensionParameters{
-							Version: new(string),
+							SessionParameters: map[string]string{"search_path": "evil"},
 						},
 					},
 				},
 			},
-			want: want{
-				o: managed.ExternalObservation{
-					ResourceExists:          true,
-					ResourceUpToDate:        true,
-					ResourceLateInitialized: false,
-				},
-				err: nil,
-			},
+			want: errors.Errorf(errSessionParameterNotAllowed, "search_path"),
 		},
-		"SuccessLateInit": {
-			reason: "No error should be returned via lateInit when version is provided",
+		"SessionParameters": {
+			reason: "Allowed session parameters should be passed to the database client",
 			fields: fields{
-				db: mockDB{
-					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
-						bv := dest[0].(*string)
-						*bv = "blah"
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						if o, ok := obj.(*v1alpha1.ProviderConfig); ok {
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+						}
 						return nil
-					},
+					}),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+				newDB: func(creds map[string][]byte, database string, params map[string]string, o ...postgresql.Option) xsql.DB {
+					want := map[string]string{"maintenance_work_mem": "1GB"}
+					if diff := cmp.Diff(want, params); diff != "" {
+						t.Errorf("newDB(...): -want params, +got params:\n%s\n", diff)
+					}
+					return pingableDB()
 				},
 			},
 			args: args{
 				mg: &v1alpha1.Extension{
 					Spec: v1alpha1.ExtensionSpec{
-						ForProvider: v1alpha1.ExtensionParameters{},
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+						ForProvider: v1alpha1.ExtensionParameters{
+							SessionParameters: map[string]string{"maintenance_work_mem": "1GB"},
+						},
 					},
 				},
 			},
-			want: want{
-				o: managed.ExternalObservation{
-					ResourceExists:          true,
-					ResourceUpToDate:        true,
-					ResourceLateInitialized: true,
-				},
-			},
-		},
-	}
-
-	for name, tc := range cases {
-		t.Run(name, func(t *testing.T) {
-			e := external{db: tc.fields.db}
-			got, err := e.Observe(tc.args.ctx, tc.args.mg)
-			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
-				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
-			}
-			if diff := cmp.Diff(tc.want.o, got); diff != "" {
-				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
-			}
-		})
-	}
-}
-
-func TestCreate(t *testing.T) {
-	errBoom := errors.New("boom")
-
-	type fields struct {
-		db xsql.DB
-	}
-
-	type args struct {
-		ctx context.Context
-		mg  resource.Managed
-	}
-
-	type want struct {
-		c   managed.ExternalCreation
-		err error
-	}
-
-	cases := map[string]struct {
-		reason string
-		fields fields
-		args   args
-		want   want
-	}{
-		"ErrNotExtension": {
-			reason: "An error should be returned if the managed resource is not a Extension",
-			args: args{
-				mg: nil,
-			},
-			want: want{
-				err: errors.New(errNotExtension),
-			},
+			want: nil,
 		},
-		"ErrExec": {
-			reason: "Any errors encountered while creating the extension should be returned",
+		"CredentialKeys": {
+			reason: "Connection details stored under custom keys should be passed to the database client under the standard keys",
 			fields: fields{
-				db: &mockDB{
-					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						switch o := obj.(type) {
+						case *v1alpha1.ProviderConfig:
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+							o.Spec.Credentials.Keys = &v1alpha1.CredentialKeys{Username: pointer.StringPtr("user"), Password: pointer.StringPtr("pass")}
+						case *corev1.Secret:
+							o.Data = map[string][]byte{"user": []byte("cool"), "pass": []byte("secret")}
+						}
+						return nil
+					}),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+				newDB: func(creds map[string][]byte, database string, params map[string]string, o ...postgresql.Option) xsql.DB {
+					if got := string(creds[xpv1.ResourceCredentialsSecretUserKey]); got != "cool" {
+						t.Errorf("newDB(...): want username %q, got %q", "cool", got)
+					}
+					if got := string(creds[xpv1.ResourceCredentialsSecretPasswordKey]); got != "secret" {
+						t.Errorf("newDB(...): want password %q, got %q", "secret", got)
+					}
+					return pingableDB()
 				},
 			},
 			args: args{
-				mg: &v1alpha1.Extension{},
-			},
-			want: want{
-				err: errors.Wrap(errBoom, errCreateExtension),
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+					},
+				},
 			},
+			want: nil,
 		},
-		"Success": {
-			reason: "No error should be returned when we successfully create a extension",
+		"CredentialKeysDatabase": {
+			reason: "Resources that don't specify a database should connect to the database held under the configured Database key",
 			fields: fields{
-				db: &mockDB{
-					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						switch o := obj.(type) {
+						case *v1alpha1.ProviderConfig:
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+							o.Spec.Credentials.Keys = &v1alpha1.CredentialKeys{Database: pointer.StringPtr("dbname")}
+						case *corev1.Secret:
+							o.Data = map[string][]byte{"dbname": []byte("cool")}
+						}
+						return nil
+					}),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+				newDB: func(creds map[string][]byte, database string, params map[string]string, o ...postgresql.Option) xsql.DB {
+					if database != "cool" {
+						t.Errorf("newDB(...): want database %q, got %q", "cool", database)
+					}
+					return pingableDB()
 				},
 			},
 			args: args{
 				mg: &v1alpha1.Extension{
 					Spec: v1alpha1.ExtensionSpec{
-						ForProvider: v1alpha1.ExtensionParameters{
-							Version: new(string),
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
 						},
 					},
 				},
 			},
-			want: want{
-				err: nil,
-			},
+			want: nil,
 		},
-	}
-
-	for name, tc := range cases {
-		t.Run(name, func(t *testing.T) {
-			e := external{db: tc.fields.db}
-			got, err := e.Create(tc.args.ctx, tc.args.mg)
-			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
-				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
-			}
+		"PerResourceDatabase": {
+			reason: "The extension's database should override the database held in the credentials Secret",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						switch o := obj.(type) {
+						case *v1alpha1.ProviderConfig:
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+							o.Spec.Credentials.Keys = &v1alpha1.CredentialKeys{Database: pointer.StringPtr("dbname")}
+						case *corev1.Secret:
+							o.Data = map[string][]byte{"dbname": []byte("default")}
+						}
+						return nil
+					}),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+				newDB: func(creds map[string][]byte, database string, params map[string]string, o ...postgresql.Option) xsql.DB {
+					if database != "cool" {
+						t.Errorf("newDB(...): want database %q, got %q", "cool", database)
+					}
+					return pingableDB()
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+						ForProvider: v1alpha1.ExtensionParameters{
+							Database: pointer.StringPtr("cool"),
+						},
+					},
+				},
+			},
+			want: nil,
+		},
+		"ServerCertVerified": {
+			reason: "No error should be returned if the server presents the pinned certificate",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						if o, ok := obj.(*v1alpha1.ProviderConfig); ok {
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+							o.Spec.ServerCertFingerprint = pointer.StringPtr("cool")
+						}
+						return nil
+					}),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+				newDB: func(creds map[string][]byte, database string, params map[string]string, o ...postgresql.Option) xsql.DB {
+					return pingableDB()
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+					},
+				},
+			},
+			want: nil,
+		},
+		"ErrPing": {
+			reason: "An error should be returned if the server can't run a trivial query, for example because the credentials are wrong",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						if o, ok := obj.(*v1alpha1.ProviderConfig); ok {
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+						}
+						return nil
+					}),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+				newDB: func(creds map[string][]byte, database string, params map[string]string, o ...postgresql.Option) xsql.DB {
+					return mockDB{MockScan: func(_ context.Context, _ xsql.Query, _ ...interface{}) error { return errBoom }}
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+					},
+				},
+			},
+			want: errors.Wrap(errBoom, errPing),
+		},
+		"ErrPingDatabasePattern": {
+			reason: "An extension that targets many databases should ping the default database",
+			fields: fields{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						if o, ok := obj.(*v1alpha1.ProviderConfig); ok {
+							o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+						}
+						return nil
+					}),
+				},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+				newDB: func(creds map[string][]byte, database string, params map[string]string, o ...postgresql.Option) xsql.DB {
+					if database != "" || params != nil {
+						t.Errorf("newDB(...): want the default database without session parameters, got %q and %v", database, params)
+					}
+					return mockDB{MockScan: func(_ context.Context, _ xsql.Query, _ ...interface{}) error { return errBoom }}
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ResourceSpec: xpv1.ResourceSpec{
+							ProviderConfigReference: &xpv1.Reference{},
+						},
+						ForProvider: v1alpha1.ExtensionParameters{
+							DatabasePattern:   pointer.StringPtr("app_*"),
+							SessionParameters: map[string]string{"work_mem": "64MB"},
+						},
+					},
+				},
+			},
+			want: errors.Wrap(errBoom, errPing),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &connector{kube: tc.fields.kube, usage: tc.fields.usage, newDB: tc.fields.newDB}
+			_, err := e.Connect(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConnectKeepsUsage(t *testing.T) {
+	errUnreachable := errors.New("dial tcp: connection refused")
+	now := metav1.Now()
+
+	tracked := false
+	kube := &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			if o, ok := obj.(*v1alpha1.ProviderConfig); ok {
+				o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+			}
+			return nil
+		}),
+		MockDelete: func(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+			t.Errorf("the usage of an extension should not be released until its finalizer is removed")
+			return nil
+		},
+	}
+	track := resource.TrackerFn(func(_ context.Context, _ resource.Managed) error {
+		tracked = true
+		return nil
+	})
+	unreachable := mockDB{
+		MockExec:   func(_ context.Context, _ xsql.Query) error { return errUnreachable },
+		MockExecTx: func(_ context.Context, _ []xsql.Query) error { return errUnreachable },
+		MockScan:   func(_ context.Context, _ xsql.Query, _ ...interface{}) error { return errUnreachable },
+		MockQuery:  func(_ context.Context, _ xsql.Query) (*sql.Rows, error) { return nil, errUnreachable },
+	}
+
+	e := &connector{
+		kube:  kube,
+		usage: track,
+		newDB: func(_ map[string][]byte, _ string, _ map[string]string, _ ...postgresql.Option) xsql.DB {
+			return unreachable
+		},
+	}
+
+	// An extension that is being deleted, but whose server is unreachable,
+	// still uses its ProviderConfig.
+	cr := &v1alpha1.Extension{
+		ObjectMeta: metav1.ObjectMeta{UID: "cool-uid", DeletionTimestamp: &now},
+		Spec: v1alpha1.ExtensionSpec{
+			ResourceSpec: xpv1.ResourceSpec{ProviderConfigReference: &xpv1.Reference{Name: "cool"}},
+			ForProvider:  v1alpha1.ExtensionParameters{Extension: "cool"},
+		},
+	}
+
+	_, err := e.Connect(context.Background(), cr)
+	if diff := cmp.Diff(errors.Wrap(errUnreachable, errPing), err, test.EquateErrors()); diff != "" {
+		t.Errorf("e.Connect(...): -want error connecting to an unreachable server, +got error:\n%s", diff)
+	}
+	if !tracked {
+		t.Errorf("e.Connect(...): the usage of an extension that is being deleted should be tracked")
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db xsql.DB
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotExtension": {
+			reason: "An error should be returned if the managed resource is not a Extension",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotExtension),
+			},
+		},
+		"ErrNoExtension": {
+			reason: "We should return ResourceExists: false when no extension is found",
+			fields: fields{
+				db: mockDB{
+					MockScan: scanAbsent,
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{},
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"ErrSelectExtension": {
+			reason: "We should return any errors encountered while trying to select the extension",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Version: new(string),
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSelectExtension),
+			},
+		},
+		"Success": {
+			reason: "We should return no error if we can successfully select our extension",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return nil },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Version: new(string),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:          true,
+					ResourceUpToDate:        true,
+					ResourceLateInitialized: false,
+				},
+				err: nil,
+			},
+		},
+		"VersionConstraintUpgradeAvailable": {
+			reason: "We should report the extension needs an update when a newer version satisfies the constraint",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						bv := dest[0].(*string)
+						*bv = "1.1"
+						return nil
+					},
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						return mockRowsToSQLRows(sqlmock.NewRows([]string{"version"}).AddRow("1.0").AddRow("1.1").AddRow("1.2").AddRow("2.0")), nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Version: pointer.StringPtr(">=1.1,<2.0"),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
+		"VersionConstraintSatisfied": {
+			reason: "We should report the extension is up to date when it is at the best version satisfying the constraint",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						bv := dest[0].(*string)
+						*bv = "1.2"
+						return nil
+					},
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						return mockRowsToSQLRows(sqlmock.NewRows([]string{"version"}).AddRow("1.1").AddRow("1.2").AddRow("2.0")), nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Version: pointer.StringPtr(">=1.1,<2.0"),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+			},
+		},
+		"CommentInConsolidatedQuery": {
+			reason: "The comment should be selected in the same query as the version, and drift reported",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						if !strings.Contains(q.String, "d.description") || !strings.Contains(q.String, "LEFT JOIN pg_description") {
+							t.Errorf("MockScan: query does not select the comment: %s", q.String)
+						}
+						if len(dest) != 5 {
+							t.Fatalf("MockScan: want 5 destinations, got %d", len(dest))
+						}
+						*dest[0].(*string) = "1.0"
+						*dest[1].(*sql.NullString) = sql.NullString{String: "old", Valid: true}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Version: pointer.StringPtr("1.0"),
+							Comment: pointer.StringPtr("new"),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
+		"NullComment": {
+			reason: "A NULL comment should be considered equivalent to an empty comment",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "1.0"
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Version: pointer.StringPtr("1.0"),
+							Comment: pointer.StringPtr(""),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+			},
+		},
+		"SuccessLateInit": {
+			reason: "No error should be returned via lateInit when version is provided",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						bv := dest[0].(*string)
+						*bv = "blah"
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:          true,
+					ResourceUpToDate:        true,
+					ResourceLateInitialized: true,
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObservePendingStatements(t *testing.T) {
+	type fields struct {
+		db xsql.DB
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		mg     *v1alpha1.Extension
+		want   []string
+	}{
+		"Create": {
+			reason: "The CREATE statement should be previewed when the extension does not exist",
+			fields: fields{
+				db: mockDB{
+					MockScan: scanAbsent,
+				},
+			},
+			mg: &v1alpha1.Extension{
+				Spec: v1alpha1.ExtensionSpec{
+					ForProvider: v1alpha1.ExtensionParameters{
+						Extension: "hstore",
+						Version:   pointer.StringPtr("1.1"),
+					},
+				},
+			},
+			want: []string{`CREATE EXTENSION IF NOT EXISTS "hstore" WITH VERSION "1.1"`},
+		},
+		"VersionUpgrade": {
+			reason: "The ALTER statement should be previewed when the extension's version has drifted",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						bv := dest[0].(*string)
+						*bv = "1.1"
+						return nil
+					},
+				},
+			},
+			mg: &v1alpha1.Extension{
+				Spec: v1alpha1.ExtensionSpec{
+					ForProvider: v1alpha1.ExtensionParameters{
+						Extension: "hstore",
+						Version:   pointer.StringPtr("1.2"),
+					},
+				},
+			},
+			want: []string{`ALTER EXTENSION "hstore" UPDATE TO "1.2"`},
+		},
+		"CommentDrift": {
+			reason: "The COMMENT statement should be previewed when the comment has drifted",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						*dest[0].(*string) = "1.2"
+						return nil
+					},
+				},
+			},
+			mg: &v1alpha1.Extension{
+				Spec: v1alpha1.ExtensionSpec{
+					ForProvider: v1alpha1.ExtensionParameters{
+						Extension: "hstore",
+						Version:   pointer.StringPtr("1.2"),
+						Comment:   pointer.StringPtr("it's cool"),
+					},
+				},
+			},
+			want: []string{`COMMENT ON EXTENSION "hstore" IS 'it''s cool'`},
+		},
+		"UpToDate": {
+			reason: "No statements should be previewed when the extension is up to date",
+			fields: fields{
+				db: mockDB{
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						bv := dest[0].(*string)
+						*bv = "1.2"
+						return nil
+					},
+				},
+			},
+			mg: &v1alpha1.Extension{
+				Spec: v1alpha1.ExtensionSpec{
+					ForProvider: v1alpha1.ExtensionParameters{
+						Extension: "hstore",
+						Version:   pointer.StringPtr("1.2"),
+					},
+				},
+				Status: v1alpha1.ExtensionStatus{
+					AtProvider: v1alpha1.ExtensionObservation{
+						PendingStatements: []string{`ALTER EXTENSION "hstore" UPDATE TO "1.2"`},
+					},
+				},
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db}
+			if _, err := e.Observe(context.Background(), tc.mg); err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): unexpected error: %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, tc.mg.Status.AtProvider.PendingStatements); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want pending statements, +got pending statements:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveInstallation(t *testing.T) {
+	cr := &v1alpha1.Extension{
+		Spec: v1alpha1.ExtensionSpec{
+			ForProvider: v1alpha1.ExtensionParameters{
+				Extension: "postgis",
+			},
+		},
+	}
+	e := external{db: mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			if q.String != observeQuery("pg_authid", true) {
+				t.Errorf("e.Observe(...): unexpected query %q", q.String)
+			}
+			*dest[0].(*string) = "3.1.4"
+			*dest[2].(*sql.NullString) = sql.NullString{String: "rdsadmin", Valid: true}
+			*dest[3].(*sql.NullString) = sql.NullString{String: "gis", Valid: true}
+			*dest[4].(*sql.NullBool) = sql.NullBool{Bool: false, Valid: true}
+			return nil
+		},
+	}}
+
+	if _, err := e.Observe(context.Background(), cr); err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %s", err)
+	}
+
+	want := v1alpha1.ExtensionObservation{
+		InstalledVersion: pointer.StringPtr("3.1.4"),
+		Owner:            pointer.StringPtr("rdsadmin"),
+		Schema:           pointer.StringPtr("gis"),
+		Relocatable:      pointer.BoolPtr(false),
+	}
+	if diff := cmp.Diff(want, cr.Status.AtProvider); diff != "" {
+		t.Errorf("The installed version, owner, schema, and relocatability of the extension should be reported\ne.Observe(...): -want, +got:\n%s\n", diff)
+	}
+}
+
+func TestObserveVersionDrift(t *testing.T) {
+	// A DBA updated the extension out-of-band.
+	cr := &v1alpha1.Extension{
+		Spec: v1alpha1.ExtensionSpec{
+			ForProvider: v1alpha1.ExtensionParameters{
+				Extension: "hstore",
+				Version:   pointer.StringPtr("1.1"),
+			},
+		},
+	}
+	e := external{db: mockDB{
+		MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			*dest[0].(*string) = "1.2"
+			return nil
+		},
+	}}
+
+	got, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %s", err)
+	}
+
+	reason := "An explicitly desired version should be authoritative, and not be late initialized from the installed version"
+	want := managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", reason, diff)
+	}
+	if diff := cmp.Diff(pointer.StringPtr("1.1"), cr.Spec.ForProvider.Version); diff != "" {
+		t.Errorf("\n%s\ne.Observe(...): -want version, +got version:\n%s\n", reason, diff)
+	}
+	if diff := cmp.Diff([]string{`ALTER EXTENSION "hstore" UPDATE TO "1.1"`}, cr.Status.AtProvider.PendingStatements); diff != "" {
+		t.Errorf("\n%s\ne.Observe(...): -want pending statements, +got pending statements:\n%s\n", reason, diff)
+	}
+}
+
+func TestLateInit(t *testing.T) {
+	apply := v1alpha1.PostUpgradeUpdateApply
+
+	type want struct {
+		desired v1alpha1.ExtensionParameters
+		li      bool
+	}
+
+	cases := map[string]struct {
+		reason   string
+		observed v1alpha1.ExtensionParameters
+		desired  v1alpha1.ExtensionParameters
+		want     want
+	}{
+		"VersionUnset": {
+			reason:   "The version should be late initialized when the spec leaves it unset",
+			observed: v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.2")},
+			want: want{
+				desired: v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.2")},
+				li:      true,
+			},
+		},
+		"VersionExplicit": {
+			reason:   "An explicit version should never be overridden by the installed version",
+			observed: v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.2")},
+			desired:  v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.1")},
+			want: want{
+				desired: v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.1")},
+				li:      false,
+			},
+		},
+		"VersionTracksDefault": {
+			reason:   "The version should not be late initialized when it tracks the default version per the Apply policy",
+			observed: v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.2")},
+			desired:  v1alpha1.ExtensionParameters{PostUpgradeUpdatePolicy: &apply},
+			want: want{
+				desired: v1alpha1.ExtensionParameters{PostUpgradeUpdatePolicy: &apply},
+				li:      false,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			li := lateInit(tc.observed, &tc.desired)
+			if diff := cmp.Diff(tc.want.li, li); diff != "" {
+				t.Errorf("\n%s\nlateInit(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.desired, tc.desired); diff != "" {
+				t.Errorf("\n%s\nlateInit(...): -want desired, +got desired:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		db    xsql.DB
+		audit *v1alpha1.AuditConfig
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		c   managed.ExternalCreation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"ErrNotExtension": {
+			reason: "An error should be returned if the managed resource is not a Extension",
+			args: args{
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotExtension),
+			},
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while creating the extension should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return errBoom },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errCreateExtension),
+			},
+		},
+		"Success": {
+			reason: "No error should be returned when we successfully create a extension",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Version: new(string),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"Schema": {
+			reason: "The extension should be created in its schema, if one is specified",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if want := `CREATE EXTENSION IF NOT EXISTS "hstore" WITH SCHEMA "extensions"`; q.String != want {
+							return errors.Errorf("unexpected query %q, want %q", q.String, want)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Extension: "hstore",
+							Schema:    pointer.StringPtr("extensions"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"SchemaAndVersion": {
+			reason: "The schema should precede the version when both are specified",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if want := `CREATE EXTENSION IF NOT EXISTS "hstore" WITH SCHEMA "extensions" VERSION "1.8"`; q.String != want {
+							return errors.Errorf("unexpected query %q, want %q", q.String, want)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Extension: "hstore",
+							Schema:    pointer.StringPtr("extensions"),
+							Version:   pointer.StringPtr("1.8"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"ErrRequiredMissing": {
+			reason: "The extension should not be created until the extensions it requires are installed",
+			fields: fields{
+				db: &mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						return mockRowsToSQLRows(sqlmock.NewRows([]string{"extname"}).AddRow("postgis")), nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Extension: "postgis_topology",
+							Requires:  []string{"postgis", "fuzzystrmatch"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Errorf(errRequiredMissing, []string{"fuzzystrmatch"}),
+			},
+		},
+		"RequiredInstalled": {
+			reason: "The extension should be created once the extensions it requires are installed",
+			fields: fields{
+				db: &mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						return mockRowsToSQLRows(sqlmock.NewRows([]string{"extname"}).AddRow("postgis")), nil
+					},
+					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Extension: "postgis_topology",
+							Requires:  []string{"postgis"},
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"AuditInSameTransaction": {
+			reason: "The audit record should be inserted in the same transaction as the extension is created",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						want := []xsql.Query{
+							{String: `CREATE EXTENSION IF NOT EXISTS "cool"`},
+							{
+								String:     `INSERT INTO "audit"."changes" ("uid", "action", "created_at") VALUES ($1, $2, now())`,
+								Parameters: []interface{}{"cool-uid", auditActionCreate},
+							},
+						}
+						if diff := cmp.Diff(want, ql); diff != "" {
+							t.Errorf("MockExecTx: -want, +got:\n%s\n", diff)
+						}
+						return nil
+					},
+				},
+				audit: &v1alpha1.AuditConfig{
+					Table:     pointer.StringPtr("audit.changes"),
+					UIDColumn: pointer.StringPtr("uid"),
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					ObjectMeta: metav1.ObjectMeta{UID: "cool-uid"},
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Extension: "cool",
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"ErrAuditTransaction": {
+			reason: "Errors from the transaction that creates the extension and inserts the audit record should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return errBoom },
+				},
+				audit: &v1alpha1.AuditConfig{},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errCreateExtension),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.fields.db, audit: tc.fields.audit}
+			got, err := e.Create(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
 			if diff := cmp.Diff(tc.want.c, got); diff != "" {
 				t.Errorf("\n%s\ne.Create(...): -want, +got:\n%s\n", tc.reason, diff)
 			}
@@ -392,6 +1311,8 @@ func TestCreate(t *testing.T) {
 }
 
 func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
 	type fields struct {
 		db xsql.DB
 	}
@@ -415,17 +1336,216 @@ func TestUpdate(t *testing.T) {
 		"ErrNotExtension": {
 			reason: "An error should be returned if the managed resource is not a Extension",
 			args: args{
-				mg: nil,
+				mg: nil,
+			},
+			want: want{
+				err: errors.New(errNotExtension),
+			},
+		},
+		"VersionConstraint": {
+			reason: "We should update to the best version that satisfies a version constraint",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						if want := `ALTER EXTENSION "hstore" UPDATE TO "1.2"`; ql[0].String != want {
+							return errors.Errorf("unexpected query %q, want %q", ql[0].String, want)
+						}
+						return nil
+					},
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						return mockRowsToSQLRows(sqlmock.NewRows([]string{"version"}).AddRow("1.1").AddRow("1.2").AddRow("2.0")), nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Extension: "hstore",
+							Version:   pointer.StringPtr(">=1.1,<2.0"),
+						},
+					},
+				},
 			},
 			want: want{
-				err: errors.New(errNotExtension),
+				err: nil,
+			},
+		},
+		"VersionUnchanged": {
+			reason: "We should not update an extension that is already at its desired version",
+			fields: fields{
+				db: &mockDB{
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						return errors.Errorf("unexpected queries %v", ql)
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Extension: "hstore",
+							Version:   pointer.StringPtr("1.1"),
+						},
+					},
+					Status: v1alpha1.ExtensionStatus{
+						AtProvider: v1alpha1.ExtensionObservation{InstalledVersion: pointer.StringPtr("1.1")},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"VersionChanged": {
+			reason: "We should update an extension that is not at its desired version",
+			fields: fields{
+				db: &mockDB{
+					MockScan: scanNoUpdatePath,
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						if want := `ALTER EXTENSION "hstore" UPDATE TO "1.2"`; ql[0].String != want {
+							return errors.Errorf("unexpected query %q, want %q", ql[0].String, want)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Extension: "hstore",
+							Version:   pointer.StringPtr("1.2"),
+						},
+					},
+					Status: v1alpha1.ExtensionStatus{
+						AtProvider: v1alpha1.ExtensionObservation{InstalledVersion: pointer.StringPtr("1.1")},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"VersionDrifted": {
+			reason: "We should revert an extension that was updated out-of-band to its desired version",
+			fields: fields{
+				db: &mockDB{
+					MockScan: scanNoUpdatePath,
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						if want := `ALTER EXTENSION "hstore" UPDATE TO "1.1"`; ql[0].String != want {
+							return errors.Errorf("unexpected query %q, want %q", ql[0].String, want)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Extension: "hstore",
+							Version:   pointer.StringPtr("1.1"),
+						},
+					},
+					Status: v1alpha1.ExtensionStatus{
+						AtProvider: v1alpha1.ExtensionObservation{InstalledVersion: pointer.StringPtr("1.2")},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"Vanished": {
+			reason: "No error should be returned if the extension was dropped after it was observed",
+			fields: fields{
+				db: &mockDB{
+					MockScan: scanNoUpdatePath,
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						return &pq.Error{Code: "42704", Message: `extension "hstore" does not exist`}
+					},
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						return errors.Errorf("unexpected query %q", q.String)
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Extension: "hstore",
+							Version:   pointer.StringPtr("1.2"),
+							Comment:   pointer.StringPtr("cool"),
+						},
+					},
+					Status: v1alpha1.ExtensionStatus{
+						AtProvider: v1alpha1.ExtensionObservation{InstalledVersion: pointer.StringPtr("1.1")},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"ErrComment": {
+			reason: "Errors setting the extension's comment should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.HasPrefix(q.String, "COMMENT") {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Extension: "hstore",
+							Comment:   pointer.StringPtr("cool"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errCommentExtension),
+			},
+		},
+		"ErrSetSchema": {
+			reason: "Errors setting the extension's schema should be returned",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if strings.Contains(q.String, "SET SCHEMA") {
+							return errBoom
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Extension: "hstore",
+							Schema:    pointer.StringPtr("extensions"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errSetSchema),
 			},
 		},
 		"Success": {
 			reason: "No error should be returned when we successfully update a extension",
 			fields: fields{
 				db: &mockDB{
-					MockExec: func(ctx context.Context, q xsql.Query) error { return nil },
+					MockExec:   func(ctx context.Context, q xsql.Query) error { return nil },
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error { return nil },
 				},
 			},
 			args: args{
@@ -459,9 +1579,12 @@ func TestUpdate(t *testing.T) {
 
 func TestDelete(t *testing.T) {
 	errBoom := errors.New("boom")
+	restrict := v1alpha1.DropRestrict
+	cascade := v1alpha1.DropCascade
 
 	type fields struct {
-		db xsql.DB
+		db    xsql.DB
+		audit *v1alpha1.AuditConfig
 	}
 
 	type args struct {
@@ -486,6 +1609,7 @@ func TestDelete(t *testing.T) {
 			reason: "Errors dropping a extension should be returned",
 			fields: fields{
 				db: &mockDB{
+					MockQuery: queryNoDependents,
 					MockExec: func(ctx context.Context, q xsql.Query) error {
 						return errBoom
 					},
@@ -496,11 +1620,125 @@ func TestDelete(t *testing.T) {
 			},
 			want: errors.Wrap(errBoom, errDropExtension),
 		},
+		"Orphan": {
+			reason: "No SQL should be executed when the extension's deletion policy is Orphan",
+			fields: fields{
+				db: &mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						return nil, errors.Errorf("unexpected query %q", q.String)
+					},
+					MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+						return errors.Errorf("unexpected query %q", q.String)
+					},
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						return errors.Errorf("unexpected query %q", q.String)
+					},
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						return errors.Errorf("unexpected queries %v", ql)
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ResourceSpec: xpv1.ResourceSpec{DeletionPolicy: xpv1.DeletionOrphan},
+						ForProvider:  v1alpha1.ExtensionParameters{Extension: "cool"},
+					},
+				},
+			},
+			want: nil,
+		},
+		"Restrict": {
+			reason: "An extension should be dropped without CASCADE when its drop policy is Restrict",
+			fields: fields{
+				db: &mockDB{
+					MockQuery: queryNoDependents,
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if diff := cmp.Diff(`DROP EXTENSION IF EXISTS "cool"`, q.String); diff != "" {
+							t.Errorf("MockExec: -want, +got:\n%s\n", diff)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Extension:  "cool",
+							DropPolicy: &restrict,
+						},
+					},
+				},
+			},
+			want: nil,
+		},
+		"Cascade": {
+			reason: "An extension should be dropped with CASCADE, without waiting for dependents, when its drop policy is Cascade",
+			fields: fields{
+				db: &mockDB{
+					MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+						t.Errorf("MockQuery: dependents should not be queried when dropping with CASCADE")
+						return nil, errBoom
+					},
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						if diff := cmp.Diff(`DROP EXTENSION IF EXISTS "cool" CASCADE`, q.String); diff != "" {
+							t.Errorf("MockExec: -want, +got:\n%s\n", diff)
+						}
+						return nil
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Extension:  "cool",
+							DropPolicy: &cascade,
+						},
+					},
+				},
+			},
+			want: nil,
+		},
+		"AuditInSameTransaction": {
+			reason: "The audit record should be inserted in the same transaction as the extension is dropped",
+			fields: fields{
+				db: &mockDB{
+					MockQuery: queryNoDependents,
+					MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+						want := []xsql.Query{
+							{String: `DROP EXTENSION IF EXISTS "cool"`},
+							{
+								String:     `INSERT INTO "crossplane_audit" ("resource_uid", "action", "created_at") VALUES ($1, $2, now())`,
+								Parameters: []interface{}{"cool-uid", auditActionDrop},
+							},
+						}
+						if diff := cmp.Diff(want, ql); diff != "" {
+							t.Errorf("MockExecTx: -want, +got:\n%s\n", diff)
+						}
+						return nil
+					},
+				},
+				audit: &v1alpha1.AuditConfig{},
+			},
+			args: args{
+				mg: &v1alpha1.Extension{
+					ObjectMeta: metav1.ObjectMeta{UID: "cool-uid"},
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{
+							Extension: "cool",
+						},
+					},
+				},
+			},
+			want: nil,
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := external{db: tc.fields.db}
+			e := external{db: tc.fields.db, audit: tc.fields.audit}
 			err := e.Delete(tc.args.ctx, tc.args.mg)
 			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
@@ -508,3 +1746,165 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+func mockRowsToSQLRows(mockRows *sqlmock.Rows) *sql.Rows {
+	db, mock, _ := sqlmock.New()
+	mock.ExpectQuery("select").WillReturnRows(mockRows)
+	rows, err := db.Query("select")
+	if err != nil {
+		return nil
+	}
+	return rows
+}
+
+// A bracketQuoter is a sample alternate postgresql.Quoter.
+type bracketQuoter struct{}
+
+func (bracketQuoter) QuoteIdentifier(name string) string { return "[" + name + "]" }
+func (bracketQuoter) QuoteLiteral(literal string) string { return "<" + literal + ">" }
+
+func TestAliasedExtension(t *testing.T) {
+	// The resource's name and external name differ from the extension's.
+	cr := &v1alpha1.Extension{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "alias",
+			Annotations: map[string]string{meta.AnnotationKeyExternalName: "alias"},
+		},
+		Spec: v1alpha1.ExtensionSpec{
+			ForProvider: v1alpha1.ExtensionParameters{
+				Extension:  "cool",
+				Version:    pointer.StringPtr("1.0"),
+				DropPolicy: func() *v1alpha1.DropPolicy { p := v1alpha1.DropCascade; return &p }(),
+			},
+		},
+	}
+
+	queries := []xsql.Query{}
+	db := mockDB{
+		MockExec: func(_ context.Context, q xsql.Query) error {
+			queries = append(queries, q)
+			return nil
+		},
+		MockScan: func(_ context.Context, q xsql.Query, dest ...interface{}) error {
+			queries = append(queries, q)
+			if q.String == catalogReadableQuery {
+				*dest[0].(*bool) = true
+				return nil
+			}
+			return sql.ErrNoRows
+		},
+		MockQuery: func(_ context.Context, q xsql.Query) (*sql.Rows, error) {
+			queries = append(queries, q)
+			return nil, sql.ErrNoRows
+		},
+	}
+	e := external{db: db}
+
+	if _, err := e.Observe(context.Background(), cr); err != nil {
+		t.Errorf("e.Observe(...): %v", err)
+	}
+	if _, err := e.Create(context.Background(), cr); err != nil {
+		t.Errorf("e.Create(...): %v", err)
+	}
+	if err := e.Delete(context.Background(), cr); err != nil {
+		t.Errorf("e.Delete(...): %v", err)
+	}
+
+	// Every operation should identify the extension by its spec name.
+	statements := []string{}
+	for _, q := range queries {
+		if strings.Contains(q.String, "alias") || strings.Contains(fmt.Sprint(q.Parameters...), "alias") {
+			t.Errorf("query %q with parameters %v: want the extension's name, not the resource's external name", q.String, q.Parameters)
+		}
+		if strings.Contains(q.String, "EXTENSION") {
+			statements = append(statements, q.String)
+		}
+	}
+	want := []string{
+		`CREATE EXTENSION IF NOT EXISTS "cool" WITH VERSION "1.0"`,
+		`DROP EXTENSION IF EXISTS "cool" CASCADE`,
+	}
+	if diff := cmp.Diff(want, statements); diff != "" {
+		t.Errorf("Observe, Create, and Delete: -want statements, +got:\n%s", diff)
+	}
+}
+
+func TestQuoter(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		quote  postgresql.Quoter
+		op     func(ctx context.Context, e *external, cr *v1alpha1.Extension) error
+		want   []string
+	}{
+		"DefaultCreate": {
+			reason: "The default quoter should be used when none is supplied",
+			op: func(ctx context.Context, e *external, cr *v1alpha1.Extension) error {
+				_, err := e.Create(ctx, cr)
+				return err
+			},
+			want: []string{`CREATE EXTENSION IF NOT EXISTS "hstore" WITH VERSION "1.0"`},
+		},
+		"AlternateCreate": {
+			reason: "The supplied quoter should be used to quote identifiers and literals when creating",
+			quote:  bracketQuoter{},
+			op: func(ctx context.Context, e *external, cr *v1alpha1.Extension) error {
+				_, err := e.Create(ctx, cr)
+				return err
+			},
+			want: []string{"CREATE EXTENSION IF NOT EXISTS [hstore] WITH VERSION [1.0]"},
+		},
+		"AlternateUpdate": {
+			reason: "The supplied quoter should be used to quote identifiers and literals when updating",
+			quote:  bracketQuoter{},
+			op: func(ctx context.Context, e *external, cr *v1alpha1.Extension) error {
+				_, err := e.Update(ctx, cr)
+				return err
+			},
+			want: []string{
+				"ALTER EXTENSION [hstore] UPDATE TO [1.0]",
+				"DO $verify$ BEGIN IF (SELECT extversion FROM pg_extension WHERE extname = <hstore>) IS DISTINCT FROM <1.0> THEN " +
+					"RAISE EXCEPTION 'extension % is not at version % after update', <hstore>, <1.0> " +
+					"USING HINT = <crossplane: extension update not verified>; END IF; END $verify$",
+				"COMMENT ON EXTENSION [hstore] IS <cool>",
+			},
+		},
+		"AlternateDelete": {
+			reason: "The supplied quoter should be used to quote identifiers when deleting",
+			quote:  bracketQuoter{},
+			op: func(ctx context.Context, e *external, cr *v1alpha1.Extension) error {
+				return e.Delete(ctx, cr)
+			},
+			want: []string{"DROP EXTENSION IF EXISTS [hstore]"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := []string{}
+			e := &external{quote: tc.quote, db: mockDB{
+				MockQuery: queryNoDependents,
+				MockExec: func(ctx context.Context, q xsql.Query) error {
+					got = append(got, q.String)
+					return nil
+				},
+				MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+					for _, q := range ql {
+						got = append(got, q.String)
+					}
+					return nil
+				},
+			}}
+			cr := &v1alpha1.Extension{Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{
+				Extension: "hstore",
+				Version:   pointer.StringPtr("1.0"),
+				Comment:   pointer.StringPtr("cool"),
+			}}}
+			if err := tc.op(context.Background(), e, cr); err != nil {
+				t.Fatalf("\n%s\n%s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\n-want statements, +got statements:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+const (
+	errSelectRequired   = "cannot select required extensions"
+	errRequiredMissing  = "required extensions are not installed: %v"
+	errScanRequiredName = "cannot scan required extension name"
+)
+
+// missingRequirements returns those of the supplied extensions that are not
+// installed.
+func (c *external) missingRequirements(ctx context.Context, requires []string) ([]string, error) {
+	if len(requires) == 0 {
+		return nil, nil
+	}
+
+	rows, err := c.db.Query(ctx, xsql.Query{
+		String:     "SELECT extname FROM pg_extension WHERE extname = ANY($1)",
+		Parameters: []interface{}{pq.Array(requires)},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errSelectRequired)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	installed := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, errors.Wrap(err, errScanRequiredName)
+		}
+		installed[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errSelectRequired)
+	}
+
+	var missing []string
+	for _, r := range requires {
+		if !installed[r] {
+			missing = append(missing, r)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// TypeRestartRequired is true while an extension cannot take effect until the
+// server is restarted, because its library is not preloaded.
+const TypeRestartRequired xpv1.ConditionType = "RestartRequired"
+
+// Reasons for the RestartRequired condition.
+const (
+	ReasonNotPreloaded   xpv1.ConditionReason = "NotPreloaded"
+	ReasonPendingRestart xpv1.ConditionReason = "PendingRestart"
+)
+
+const errSelectPreload = "cannot select shared_preload_libraries"
+
+// preloadQuery selects the libraries the server preloaded when it started, and
+// whether the setting has since been changed in a way that will only take
+// effect once the server is restarted.
+const preloadQuery = "SELECT current_setting('shared_preload_libraries'), " +
+	"COALESCE((SELECT pending_restart FROM pg_settings WHERE name = 'shared_preload_libraries'), false)"
+
+// restartRequired reports the extensions that are waiting for a restart.
+var restartRequired = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "provider_sql_postgresql_extension_restart_required",
+	Help: "Whether a PostgreSQL extension cannot take effect until its server is restarted.",
+}, []string{"name"})
+
+func init() {
+	metrics.Registry.MustRegister(restartRequired)
+}
+
+// RestartRequired returns a condition that indicates the supplied library must
+// be preloaded, which requires the server to be restarted.
+func RestartRequired(library string, pending bool) xpv1.Condition {
+	c := xpv1.Condition{
+		Type:               TypeRestartRequired,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonNotPreloaded,
+		Message: fmt.Sprintf("The extension's library %q is not preloaded. "+
+			"Add it to shared_preload_libraries and restart the database server.", library),
+	}
+	if pending {
+		c.Reason = ReasonPendingRestart
+		c.Message = fmt.Sprintf("The extension's library %q is not preloaded, and shared_preload_libraries has changed. "+
+			"Restart the database server for the change to take effect.", library)
+	}
+	return c
+}
+
+// preloadRequired returns a condition that indicates the extension could not
+// be created because its library must be preloaded.
+func preloadRequired(message string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeRestartRequired,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonNotPreloaded,
+		Message:            "The extension's library must be preloaded: " + message + ". Add it to shared_preload_libraries and restart the database server.",
+	}
+}
+
+// NoRestartRequired returns a condition that indicates the extension's
+// library is preloaded.
+func NoRestartRequired() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeRestartRequired,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonResolved,
+	}
+}
+
+// isPreloadError returns true if the supplied error message or hint says an
+// extension's library must be preloaded.
+func isPreloadError(message, hint string) bool {
+	return strings.Contains(strings.ToLower(message+" "+hint), "shared_preload_libraries")
+}
+
+// preloaded returns true if the supplied library appears in the supplied
+// value of shared_preload_libraries. Libraries may be quoted, and may be
+// specified by path, with or without a file extension.
+func preloaded(setting, library string) bool {
+	for _, l := range strings.Split(setting, ",") {
+		l = path.Base(strings.Trim(strings.TrimSpace(l), `"`))
+		if strings.TrimSuffix(l, path.Ext(l)) == library || l == library {
+			return true
+		}
+	}
+	return false
+}
+
+// observeRestart reports whether the extension is waiting for the server to
+// be restarted. Extensions that don't name their preload library are assumed
+// to be active once they exist.
+func (c *external) observeRestart(ctx context.Context, cr *v1alpha1.Extension) error {
+	lib := cr.Spec.ForProvider.PreloadLibrary
+	if lib == nil {
+		resolveRestart(cr)
+		return nil
+	}
+
+	var setting string
+	var pending bool
+	if err := c.db.Scan(ctx, xsql.Query{String: preloadQuery}, &setting, &pending); err != nil {
+		return errors.Wrap(err, errSelectPreload)
+	}
+
+	if preloaded(setting, *lib) {
+		resolveRestart(cr)
+		return nil
+	}
+	setRestartRequired(cr, RestartRequired(*lib, pending))
+	return nil
+}
+
+func setRestartRequired(cr *v1alpha1.Extension, cond xpv1.Condition) {
+	cr.SetConditions(cond)
+	restartRequired.WithLabelValues(cr.GetName()).Set(1)
+}
+
+func resolveRestart(cr *v1alpha1.Extension) {
+	restartRequired.DeleteLabelValues(cr.GetName())
+	if cr.GetCondition(TypeRestartRequired).Status == corev1.ConditionTrue {
+		cr.SetConditions(NoRestartRequired())
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestPreloaded(t *testing.T) {
+	cases := map[string]struct {
+		setting string
+		want    bool
+	}{
+		"Empty":     {setting: "", want: false},
+		"Listed":    {setting: "auto_explain, pg_stat_statements", want: true},
+		"Quoted":    {setting: `"pg_stat_statements"`, want: true},
+		"Path":      {setting: "$libdir/pg_stat_statements.so", want: true},
+		"NotListed": {setting: "auto_explain,pg_cron", want: false},
+		"Prefix":    {setting: "pg_stat_statements_extra", want: false},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := preloaded(tc.setting, "pg_stat_statements"); got != tc.want {
+				t.Errorf("preloaded(%q, ...): want %t, got %t", tc.setting, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestObserveRestart(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	scan := func(setting string, pending bool) func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+		return func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			*dest[0].(*string) = setting
+			*dest[1].(*bool) = pending
+			return nil
+		}
+	}
+
+	type want struct {
+		status corev1.ConditionStatus
+		reason xpv1.ConditionReason
+		metric float64
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason   string
+		library  *string
+		existing bool
+		scan     func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+		want     want
+	}{
+		"NotPreloaded": {
+			reason:  "A restart should be required when the extension's library is not preloaded",
+			library: pointer.StringPtr("pg_stat_statements"),
+			scan:    scan("auto_explain", false),
+			want:    want{status: corev1.ConditionTrue, reason: ReasonNotPreloaded, metric: 1},
+		},
+		"PendingRestart": {
+			reason:  "A pending restart should be reported when shared_preload_libraries has changed",
+			library: pointer.StringPtr("pg_stat_statements"),
+			scan:    scan("auto_explain", true),
+			want:    want{status: corev1.ConditionTrue, reason: ReasonPendingRestart, metric: 1},
+		},
+		"Preloaded": {
+			reason:   "The condition should be resolved once the extension's library is preloaded",
+			library:  pointer.StringPtr("pg_stat_statements"),
+			existing: true,
+			scan:     scan("auto_explain,pg_stat_statements", false),
+			want:     want{status: corev1.ConditionFalse, reason: ReasonResolved},
+		},
+		"NeverRequired": {
+			reason:  "No condition should be set when a restart has never been required",
+			library: pointer.StringPtr("pg_stat_statements"),
+			scan:    scan("pg_stat_statements", false),
+			want:    want{status: corev1.ConditionUnknown},
+		},
+		"NoLibrary": {
+			reason:   "The condition should be resolved once an extension that doesn't name its library exists",
+			existing: true,
+			scan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+				t.Errorf("MockScan: unexpected scan: %s", q.String)
+				return errBoom
+			},
+			want: want{status: corev1.ConditionFalse, reason: ReasonResolved},
+		},
+		"ErrSelectPreload": {
+			reason:  "Errors selecting shared_preload_libraries should be returned",
+			library: pointer.StringPtr("pg_stat_statements"),
+			scan:    func(ctx context.Context, q xsql.Query, dest ...interface{}) error { return errBoom },
+			want:    want{status: corev1.ConditionUnknown, err: errors.Wrap(errBoom, errSelectPreload)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{db: mockDB{MockScan: tc.scan}}
+			cr := &v1alpha1.Extension{
+				ObjectMeta: metav1.ObjectMeta{Name: "restart-" + name},
+				Spec:       v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{PreloadLibrary: tc.library}},
+			}
+			if tc.existing {
+				setRestartRequired(cr, RestartRequired("pg_stat_statements", false))
+			}
+
+			err := e.observeRestart(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.observeRestart(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			got := cr.GetCondition(TypeRestartRequired)
+			if diff := cmp.Diff(tc.want.status, got.Status); diff != "" {
+				t.Errorf("\n%s\ne.observeRestart(...): -want status, +got status:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.reason, got.Reason); diff != "" {
+				t.Errorf("\n%s\ne.observeRestart(...): -want reason, +got reason:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.metric, testutil.ToFloat64(restartRequired.WithLabelValues(cr.GetName()))); diff != "" {
+				t.Errorf("\n%s\ne.observeRestart(...): -want metric, +got metric:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDiagnosePreloadRequired(t *testing.T) {
+	err := &pq.Error{
+		Code:    "0A000",
+		Message: `extension "timescaledb" must be preloaded`,
+		Hint:    "Please preload the timescaledb library via shared_preload_libraries.",
+	}
+
+	e := &external{db: mockDB{}}
+	cr := &v1alpha1.Extension{ObjectMeta: metav1.ObjectMeta{Name: "timescaledb"}}
+	_ = e.diagnoseCreateError(context.Background(), cr, pointer.StringPtr("2.0"), err)
+
+	got := cr.GetCondition(TypeRestartRequired)
+	if diff := cmp.Diff(corev1.ConditionTrue, got.Status); diff != "" {
+		t.Errorf("e.diagnoseCreateError(...): -want status, +got status:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(1.0, testutil.ToFloat64(restartRequired.WithLabelValues(cr.GetName()))); diff != "" {
+		t.Errorf("e.diagnoseCreateError(...): -want metric, +got metric:\n%s\n", diff)
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// AnnotationKeySchema records whether the provider created an extension in a
+// schema that was explicitly requested, or let it land in the default schema.
+// PostgreSQL doesn't record this, so we can't tell the two apart otherwise.
+const AnnotationKeySchema = "postgresql.sql.crossplane.io/schema"
+
+// Values of the AnnotationKeySchema annotation.
+const (
+	SchemaDefault  = "Default"
+	SchemaExplicit = "Explicit"
+)
+
+const (
+	errSetSchema           = "cannot set extension schema"
+	errRecordSchema        = "cannot record whether extension schema was explicit"
+	errSelectDefaultSchema = "cannot select default schema"
+	errNotRelocatable      = "extension %q is not relocatable, so it cannot be moved from schema %q to %q; drop and recreate it to change its schema"
+)
+
+// defaultSchemaQuery selects the first existing schema in the search path,
+// which is where CREATE EXTENSION installs an extension by default.
+const defaultSchemaQuery = "SELECT current_schema()"
+
+// observeSchema reports the schema that contains the extension, and whether
+// the extension may be relocated to another schema. Both are omitted when
+// they could not be observed.
+func observeSchema(cr *v1alpha1.Extension, schema sql.NullString, relocatable sql.NullBool) {
+	cr.Status.AtProvider.Schema = nil
+	if schema.Valid {
+		cr.Status.AtProvider.Schema = &schema.String
+	}
+	cr.Status.AtProvider.Relocatable = nil
+	if relocatable.Valid {
+		cr.Status.AtProvider.Relocatable = &relocatable.Bool
+	}
+}
+
+// checkRelocatable returns an error if the observed extension would need to be
+// relocated to the supplied schema, but is not relocatable. PostgreSQL would
+// reject the ALTER EXTENSION ... SET SCHEMA, so we return a clearer error.
+func checkRelocatable(extension string, o v1alpha1.ExtensionObservation, schema string) error {
+	if o.Relocatable == nil || *o.Relocatable || o.Schema == nil || *o.Schema == schema {
+		return nil
+	}
+	return errors.Errorf(errNotRelocatable, extension, *o.Schema, schema)
+}
+
+// schemaChoice returns how the supplied parameters choose the schema an
+// extension is created in.
+func schemaChoice(p v1alpha1.ExtensionParameters) string {
+	if p.Schema == nil {
+		return SchemaDefault
+	}
+	return SchemaExplicit
+}
+
+// defaultSchema selects the schema an extension created without a schema
+// would be installed in. It returns nil if no schema in the search path
+// exists.
+func (c *external) defaultSchema(ctx context.Context) (*string, error) {
+	s := sql.NullString{}
+	if err := c.db.Scan(ctx, xsql.Query{String: defaultSchemaQuery}, &s); err != nil {
+		return nil, errors.Wrap(err, errSelectDefaultSchema)
+	}
+	if !s.Valid {
+		return nil, nil
+	}
+	return &s.String, nil
+}
+
+func unmanagedSchemaPolicy(p v1alpha1.ExtensionParameters) v1alpha1.UnmanagedSchemaPolicy {
+	if p.UnmanagedSchemaPolicy == nil {
+		return v1alpha1.UnmanagedSchemaIgnore
+	}
+	return *p.UnmanagedSchemaPolicy
+}
+
+// lateInitSchema late initializes the desired schema to the observed schema
+// if the Adopt policy applies, and the extension is not in the default
+// schema. We never adopt the schema of an extension we created in the default
+// schema; adopting wherever it landed would report drift if it was later
+// moved.
+func (c *external) lateInitSchema(ctx context.Context, cr *v1alpha1.Extension, schema sql.NullString) (bool, error) {
+	if cr.Spec.ForProvider.Schema != nil || !schema.Valid {
+		return false, nil
+	}
+	if unmanagedSchemaPolicy(cr.Spec.ForProvider) != v1alpha1.UnmanagedSchemaAdopt {
+		return false, nil
+	}
+	if cr.GetAnnotations()[AnnotationKeySchema] == SchemaDefault {
+		return false, nil
+	}
+	def, err := c.defaultSchema(ctx)
+	if err != nil || (def != nil && *def == schema.String) {
+		return false, err
+	}
+	s := schema.String
+	cr.Spec.ForProvider.Schema = &s
+	return true, nil
+}
+
+// desiredSchema returns the schema the extension should be in, or nil if its
+// schema is not managed. An extension without a schema should be in the
+// default schema if the Drift policy applies.
+func (c *external) desiredSchema(ctx context.Context, p v1alpha1.ExtensionParameters) (*string, error) {
+	if p.Schema != nil || unmanagedSchemaPolicy(p) != v1alpha1.UnmanagedSchemaDrift {
+		return p.Schema, nil
+	}
+	return c.defaultSchema(ctx)
+}
+
+// schemaUpToDate returns true if the extension is in its desired schema, or
+// if it has no desired schema.
+func schemaUpToDate(observed, desired v1alpha1.ExtensionParameters) bool {
+	if desired.Schema == nil {
+		return true
+	}
+	return observed.Schema != nil && *observed.Schema == *desired.Schema
+}
+
+func setSchemaQuery(q postgresql.Quoter, extension, schema string) xsql.Query {
+	return xsql.Query{String: "ALTER EXTENSION " + q.QuoteIdentifier(extension) + " SET SCHEMA " + q.QuoteIdentifier(schema)}
+}
+
+// schemaRecorder records whether the extension a managed resource represents
+// was created in an explicitly requested schema. The managed reconciler
+// doesn't persist annotations set by Create, so we persist it ourselves.
+type schemaRecorder struct {
+	managed.ExternalClient
+	kube client.Client
+}
+
+func (r *schemaRecorder) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	c, err := r.ExternalClient.Create(ctx, mg)
+	cr, ok := mg.(*v1alpha1.Extension)
+	if err != nil || !ok {
+		return c, err
+	}
+
+	// Patching overwrites the status Create reported with the status the API
+	// server has stored, so we restore it afterward.
+	base := cr.DeepCopy()
+	status := cr.Status.DeepCopy()
+	meta.AddAnnotations(cr, map[string]string{AnnotationKeySchema: schemaChoice(cr.Spec.ForProvider)})
+	err = r.kube.Patch(ctx, cr, client.MergeFrom(base))
+	cr.Status = *status
+	return c, errors.Wrap(err, errRecordSchema)
+}
@@ -0,0 +1,442 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestObserveSchema(t *testing.T) {
+	type want struct {
+		schema      *string
+		relocatable *bool
+	}
+
+	cases := map[string]struct {
+		reason      string
+		installed   bool
+		schema      string
+		relocatable bool
+		want        want
+	}{
+		"Relocatable": {
+			reason:      "The schema of a relocatable extension should be reported, along with the fact it may be relocated",
+			installed:   true,
+			schema:      "public",
+			relocatable: true,
+			want:        want{schema: pointer.StringPtr("public"), relocatable: pointer.BoolPtr(true)},
+		},
+		"NotRelocatable": {
+			reason:      "The fixed schema of an extension that is not relocatable should be reported",
+			installed:   true,
+			schema:      "pg_catalog",
+			relocatable: false,
+			want:        want{schema: pointer.StringPtr("pg_catalog"), relocatable: pointer.BoolPtr(false)},
+		},
+		"NotInstalled": {
+			reason: "Neither schema nor relocatability should be reported for an extension that is not installed",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: mockDB{
+				MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+					if q.String == catalogReadableQuery {
+						return scanAbsent(ctx, q, dest...)
+					}
+					if !strings.Contains(q.String, "n.nspname, e.extrelocatable") {
+						t.Errorf("MockScan: query does not select the schema: %s", q.String)
+					}
+					if !tc.installed {
+						return sql.ErrNoRows
+					}
+					*dest[0].(*string) = "1.0"
+					*dest[3].(*sql.NullString) = sql.NullString{String: tc.schema, Valid: true}
+					*dest[4].(*sql.NullBool) = sql.NullBool{Bool: tc.relocatable, Valid: true}
+					return nil
+				},
+				MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+					return mockRowsToSQLRows(sqlmock.NewRows([]string{"nspname"})), nil
+				},
+			}}
+			cr := &v1alpha1.Extension{Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{
+				Extension: "hstore",
+				Version:   pointer.StringPtr("1.0"),
+			}}}
+			cr.Status.AtProvider.Schema = pointer.StringPtr("stale")
+			cr.Status.AtProvider.Relocatable = pointer.BoolPtr(true)
+
+			if _, err := e.Observe(context.Background(), cr); err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.schema, cr.Status.AtProvider.Schema); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want schema, +got schema:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.relocatable, cr.Status.AtProvider.Relocatable); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want relocatable, +got relocatable:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveSchemaChoice(t *testing.T) {
+	drift := v1alpha1.UnmanagedSchemaDrift
+	adopt := v1alpha1.UnmanagedSchemaAdopt
+
+	type want struct {
+		schema   *string
+		li       bool
+		upToDate bool
+		pending  []string
+	}
+
+	cases := map[string]struct {
+		reason     string
+		annotation string
+		schema     *string
+		policy     *v1alpha1.UnmanagedSchemaPolicy
+		observed   string
+		want       want
+	}{
+		"CreatedInDefaultSchema": {
+			reason:     "An extension we created in the default schema should not have its schema managed",
+			annotation: SchemaDefault,
+			observed:   "public",
+			want:       want{upToDate: true},
+		},
+		"CreatedInDefaultSchemaThenRequested": {
+			reason:     "A schema requested after an extension was created in the default schema should be managed",
+			annotation: SchemaDefault,
+			schema:     pointer.StringPtr("extensions"),
+			observed:   "public",
+			want: want{
+				schema:  pointer.StringPtr("extensions"),
+				pending: []string{`ALTER EXTENSION "hstore" SET SCHEMA "extensions"`},
+			},
+		},
+		"CreatedInExplicitSchema": {
+			reason:     "An extension we explicitly created in a schema should be up to date while it remains there",
+			annotation: SchemaExplicit,
+			schema:     pointer.StringPtr("public"),
+			observed:   "public",
+			want:       want{schema: pointer.StringPtr("public"), upToDate: true},
+		},
+		"IgnorePolicy": {
+			reason:   "By default an extension in a non-default schema should not have its schema managed",
+			observed: "extensions",
+			want:     want{upToDate: true},
+		},
+		"DriftPolicy": {
+			reason:   "An extension in a non-default schema should be moved to the default schema under the Drift policy",
+			policy:   &drift,
+			observed: "extensions",
+			want: want{
+				pending: []string{`ALTER EXTENSION "hstore" SET SCHEMA "public"`},
+			},
+		},
+		"DriftPolicyInDefaultSchema": {
+			reason:   "An extension in the default schema should be up to date under the Drift policy",
+			policy:   &drift,
+			observed: "public",
+			want:     want{upToDate: true},
+		},
+		"AdoptPolicy": {
+			reason:   "The schema of an extension in a non-default schema should be late initialized under the Adopt policy",
+			policy:   &adopt,
+			observed: "extensions",
+			want:     want{schema: pointer.StringPtr("extensions"), li: true, upToDate: true},
+		},
+		"AdoptPolicyInDefaultSchema": {
+			reason:   "The schema of an extension in the default schema should not be late initialized under the Adopt policy",
+			policy:   &adopt,
+			observed: "public",
+			want:     want{upToDate: true},
+		},
+		"AdoptPolicyCreatedInDefaultSchema": {
+			reason:     "The schema of an extension we created in the default schema should never be adopted",
+			annotation: SchemaDefault,
+			policy:     &adopt,
+			observed:   "extensions",
+			want:       want{upToDate: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: mockDB{
+				MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+					switch {
+					case q.String == defaultSchemaQuery:
+						*dest[0].(*sql.NullString) = sql.NullString{String: "public", Valid: true}
+						return nil
+					case q.String == catalogReadableQuery || !strings.Contains(q.String, "n.nspname, e.extrelocatable"):
+						return scanAbsent(ctx, q, dest...)
+					}
+					*dest[0].(*string) = "1.0"
+					*dest[3].(*sql.NullString) = sql.NullString{String: tc.observed, Valid: true}
+					*dest[4].(*sql.NullBool) = sql.NullBool{Bool: true, Valid: true}
+					return nil
+				},
+				MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+					return mockRowsToSQLRows(sqlmock.NewRows([]string{"nspname"})), nil
+				},
+			}}
+			cr := &v1alpha1.Extension{Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{
+				Extension:             "hstore",
+				Version:               pointer.StringPtr("1.0"),
+				Schema:                tc.schema,
+				UnmanagedSchemaPolicy: tc.policy,
+			}}}
+			if tc.annotation != "" {
+				cr.SetAnnotations(map[string]string{AnnotationKeySchema: tc.annotation})
+			}
+
+			o, err := e.Observe(context.Background(), cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.schema, cr.Spec.ForProvider.Schema); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want spec schema, +got spec schema:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.li, o.ResourceLateInitialized); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want late initialized, +got late initialized:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.upToDate, o.ResourceUpToDate); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want up to date, +got up to date:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.pending, cr.Status.AtProvider.PendingStatements); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want pending statements, +got pending statements:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdateUnmanagedSchema(t *testing.T) {
+	drift := v1alpha1.UnmanagedSchemaDrift
+
+	cases := map[string]struct {
+		reason string
+		policy *v1alpha1.UnmanagedSchemaPolicy
+		want   []string
+	}{
+		"IgnorePolicy": {
+			reason: "An extension without a schema should not be moved by default",
+		},
+		"DriftPolicy": {
+			reason: "An extension without a schema should be moved to the default schema under the Drift policy",
+			policy: &drift,
+			want:   []string{`ALTER EXTENSION "hstore" SET SCHEMA "public"`},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			e := external{db: mockDB{
+				MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+					if q.String != defaultSchemaQuery {
+						return errors.Errorf("unexpected query %q", q.String)
+					}
+					*dest[0].(*sql.NullString) = sql.NullString{String: "public", Valid: true}
+					return nil
+				},
+				MockExec: func(ctx context.Context, q xsql.Query) error {
+					got = append(got, q.String)
+					return nil
+				},
+			}}
+			cr := &v1alpha1.Extension{Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{
+				Extension:             "hstore",
+				UnmanagedSchemaPolicy: tc.policy,
+			}}}
+
+			if _, err := e.Update(context.Background(), cr); err != nil {
+				t.Fatalf("\n%s\ne.Update(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want statements, +got statements:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdateRelocatable(t *testing.T) {
+	type want struct {
+		err   error
+		execs []string
+	}
+
+	cases := map[string]struct {
+		reason      string
+		schema      *string
+		relocatable *bool
+		want        want
+	}{
+		"Relocatable": {
+			reason:      "A relocatable extension should be moved to its desired schema",
+			schema:      pointer.StringPtr("public"),
+			relocatable: pointer.BoolPtr(true),
+			want:        want{execs: []string{`ALTER EXTENSION "hstore" SET SCHEMA "extensions"`}},
+		},
+		"NotRelocatable": {
+			reason:      "An error should be returned rather than trying to move an extension that is not relocatable",
+			schema:      pointer.StringPtr("public"),
+			relocatable: pointer.BoolPtr(false),
+			want:        want{err: errors.Errorf(errNotRelocatable, "hstore", "public", "extensions")},
+		},
+		"NotRelocatableInSchema": {
+			reason:      "An extension that is not relocatable but is already in its desired schema should not be an error",
+			schema:      pointer.StringPtr("extensions"),
+			relocatable: pointer.BoolPtr(false),
+			want:        want{execs: []string{`ALTER EXTENSION "hstore" SET SCHEMA "extensions"`}},
+		},
+		"NotObserved": {
+			reason: "We should try to move an extension whose relocatability we couldn't observe, and let PostgreSQL decide",
+			want:   want{execs: []string{`ALTER EXTENSION "hstore" SET SCHEMA "extensions"`}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			e := external{db: mockDB{
+				MockExec: func(ctx context.Context, q xsql.Query) error {
+					got = append(got, q.String)
+					return nil
+				},
+			}}
+			cr := &v1alpha1.Extension{
+				Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{
+					Extension: "hstore",
+					Schema:    pointer.StringPtr("extensions"),
+				}},
+				Status: v1alpha1.ExtensionStatus{AtProvider: v1alpha1.ExtensionObservation{
+					Schema:      tc.schema,
+					Relocatable: tc.relocatable,
+				}},
+			}
+
+			_, err := e.Update(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.execs, got); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want statements, +got statements:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSchemaRecorder(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		err        error
+		annotation string
+		patched    bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		schema *string
+		create error
+		patch  error
+		want   want
+	}{
+		"CreatedInDefaultSchema": {
+			reason: "An extension created without a schema should be recorded as in the default schema",
+			want:   want{annotation: SchemaDefault, patched: true},
+		},
+		"CreatedInExplicitSchema": {
+			reason: "An extension created with a schema should be recorded as explicitly placed",
+			schema: pointer.StringPtr("public"),
+			want:   want{annotation: SchemaExplicit, patched: true},
+		},
+		"CreateError": {
+			reason: "Nothing should be recorded when the extension cannot be created",
+			create: errBoom,
+			want:   want{err: errBoom},
+		},
+		"PatchError": {
+			reason: "An error should be returned if the choice cannot be recorded",
+			patch:  errBoom,
+			want:   want{err: errors.Wrap(errBoom, errRecordSchema), annotation: SchemaDefault, patched: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			patched := false
+			r := &schemaRecorder{
+				ExternalClient: &managed.ExternalClientFns{
+					CreateFn: func(_ context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+						if tc.create == nil {
+							mg.(*v1alpha1.Extension).Status.AtProvider.InstalledDependencies = []string{"plpgsql"}
+						}
+						return managed.ExternalCreation{}, tc.create
+					},
+				},
+				kube: &test.MockClient{
+					MockPatch: func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+						patched = true
+						// The API server returns the status it has stored.
+						obj.(*v1alpha1.Extension).Status = v1alpha1.ExtensionStatus{}
+						return tc.patch
+					},
+				},
+			}
+			cr := &v1alpha1.Extension{
+				ObjectMeta: metav1.ObjectMeta{Name: "cool"},
+				Spec:       v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{Extension: "hstore", Schema: tc.schema}},
+			}
+
+			_, err := r.Create(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nr.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.patched, patched); diff != "" {
+				t.Errorf("\n%s\nr.Create(...): -want patched, +got patched:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.annotation, cr.GetAnnotations()[AnnotationKeySchema]); diff != "" {
+				t.Errorf("\n%s\nr.Create(...): -want annotation, +got annotation:\n%s\n", tc.reason, diff)
+			}
+			if tc.want.patched {
+				if diff := cmp.Diff([]string{"plpgsql"}, cr.Status.AtProvider.InstalledDependencies); diff != "" {
+					t.Errorf("\n%s\nr.Create(...): status reported by Create should be preserved: -want, +got:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
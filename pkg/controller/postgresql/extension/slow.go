@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// ReasonSlowOperation records that an operation on an extension took longer
+// than the slow operation threshold.
+const ReasonSlowOperation event.Reason = "SlowOperation"
+
+// slowWarner warns when an operation on an extension takes longer than a
+// threshold, so that operators can spot performance problems such as a very
+// large extension install. Operations are timed whether or not they succeed.
+type slowWarner struct {
+	managed.ExternalClient
+	threshold time.Duration
+	log       logging.Logger
+	record    event.Recorder
+
+	// now is time.Now unless overridden in tests.
+	now func() time.Time
+}
+
+// warn logs and records an event if the supplied operation, which started at
+// the supplied time, has taken longer than the threshold.
+func (s *slowWarner) warn(mg resource.Managed, op string, start time.Time) {
+	d := s.now().Sub(start)
+	if d <= s.threshold {
+		return
+	}
+	s.log.Info("Slow operation", "name", mg.GetName(), "operation", op, "duration", d.String(), "threshold", s.threshold.String())
+	s.record.Event(mg, event.Normal(ReasonSlowOperation, fmt.Sprintf("%s took %s, longer than the slow operation threshold of %s", op, d.Round(time.Millisecond), s.threshold)))
+}
+
+func (s *slowWarner) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	defer s.warn(mg, "Observe", s.now())
+	return s.ExternalClient.Observe(ctx, mg)
+}
+
+func (s *slowWarner) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	defer s.warn(mg, "Create", s.now())
+	return s.ExternalClient.Create(ctx, mg)
+}
+
+func (s *slowWarner) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	defer s.warn(mg, "Update", s.now())
+	return s.ExternalClient.Update(ctx, mg)
+}
+
+func (s *slowWarner) Delete(ctx context.Context, mg resource.Managed) error {
+	defer s.warn(mg, "Delete", s.now())
+	return s.ExternalClient.Delete(ctx, mg)
+}
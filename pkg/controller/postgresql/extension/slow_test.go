@@ -0,0 +1,94 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+func TestSlowWarner(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		err     error
+		reasons []event.Reason
+	}
+
+	cases := map[string]struct {
+		reason string
+		took   time.Duration
+		err    error
+		want   want
+	}{
+		"Fast": {
+			reason: "No warning should be recorded for an operation within the threshold",
+			took:   time.Second,
+			want:   want{},
+		},
+		"Slow": {
+			reason: "A warning should be recorded for an operation that exceeds the threshold",
+			took:   2 * time.Minute,
+			want:   want{reasons: []event.Reason{ReasonSlowOperation}},
+		},
+		"SlowError": {
+			reason: "A warning should be recorded for a slow operation that fails, and its error returned",
+			took:   2 * time.Minute,
+			err:    errBoom,
+			want:   want{err: errBoom, reasons: []event.Reason{ReasonSlowOperation}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			clock := time.Now()
+			rec := &reasonRecorder{}
+			s := &slowWarner{
+				ExternalClient: &managed.ExternalClientFns{
+					CreateFn: func(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+						clock = clock.Add(tc.took)
+						return managed.ExternalCreation{}, tc.err
+					},
+				},
+				threshold: time.Minute,
+				log:       logging.NewNopLogger(),
+				record:    rec,
+				now:       func() time.Time { return clock },
+			}
+
+			_, err := s.Create(context.Background(), &v1alpha1.Extension{})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ns.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.reasons, rec.reasons); diff != "" {
+				t.Errorf("\n%s\ns.Create(...): -want event reasons, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
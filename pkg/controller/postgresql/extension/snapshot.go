@@ -0,0 +1,160 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"regexp"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+const (
+	errSelectMembers   = "cannot select extension objects"
+	errScanMember      = "cannot scan extension object"
+	errMarshalSnapshot = "cannot marshal snapshot of extension objects"
+	errGetSnapshot     = "cannot get snapshot ConfigMap"
+	errWriteSnapshot   = "cannot write snapshot ConfigMap"
+	errSnapshot        = "cannot snapshot extension objects before dropping extension"
+)
+
+// membersQuery selects a description of each object that belongs to an
+// extension, and its DDL if it is a function, procedure, or view. The DDL of
+// other objects, for example types and operators, is not available from the
+// catalog.
+const membersQuery = "SELECT pg_describe_object(d.classid, d.objid, 0), " +
+	"CASE d.classid " +
+	"WHEN 'pg_proc'::regclass THEN (SELECT pg_get_functiondef(p.oid) FROM pg_proc p WHERE p.oid = d.objid AND p.prokind IN ('f', 'p')) " +
+	"WHEN 'pg_class'::regclass THEN (SELECT 'CREATE ' || CASE c.relkind WHEN 'm' THEN 'MATERIALIZED ' ELSE '' END || 'VIEW ' || c.oid::regclass || ' AS ' || pg_get_viewdef(c.oid) " +
+	"FROM pg_class c WHERE c.oid = d.objid AND c.relkind IN ('v', 'm')) " +
+	"END " +
+	"FROM pg_extension e " +
+	"JOIN pg_depend d ON d.refclassid = 'pg_extension'::regclass AND d.refobjid = e.oid AND d.deptype = 'e' " +
+	"WHERE e.extname = $1 " +
+	"ORDER BY 1"
+
+// A snapshot records the objects that belonged to an extension when it was
+// dropped.
+type snapshot struct {
+	Extension string           `json:"extension"`
+	Database  *string          `json:"database,omitempty"`
+	Taken     metav1.Time      `json:"taken"`
+	Objects   []snapshotObject `json:"objects"`
+}
+
+// A snapshotObject is one object that belonged to an extension.
+type snapshotObject struct {
+	Object string  `json:"object"`
+	DDL    *string `json:"ddl,omitempty"`
+}
+
+func (c *external) members(ctx context.Context, extension string) ([]snapshotObject, error) {
+	rows, err := c.db.Query(ctx, xsql.Query{String: membersQuery, Parameters: []interface{}{extension}})
+	if err != nil {
+		return nil, errors.Wrap(err, errSelectMembers)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var objs []snapshotObject
+	for rows.Next() {
+		var o snapshotObject
+		ddl := sql.NullString{}
+		if err := rows.Scan(&o.Object, &ddl); err != nil {
+			return nil, errors.Wrap(err, errScanMember)
+		}
+		if ddl.Valid {
+			o.DDL = &ddl.String
+		}
+		objs = append(objs, o)
+	}
+	return objs, errors.Wrap(rows.Err(), errSelectMembers)
+}
+
+// invalidKeyChars are those that may not appear in a ConfigMap key.
+var invalidKeyChars = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+
+// snapshotKey returns the ConfigMap key under which the snapshot of the
+// supplied Extension is stored. Extensions that span several databases are
+// snapshotted once per database.
+func snapshotKey(cr *v1alpha1.Extension) string {
+	k := cr.GetName()
+	if db := cr.Spec.ForProvider.Database; db != nil && *db != "" {
+		k += "." + invalidKeyChars.ReplaceAllString(*db, "_")
+	}
+	return k + ".json"
+}
+
+// snapshot records the objects that belong to the supplied Extension in its
+// snapshot ConfigMap, if it has one. Nothing is recorded if the extension has
+// no objects, for example because it does not exist.
+func (c *external) snapshot(ctx context.Context, cr *v1alpha1.Extension) error {
+	ref := cr.Spec.ForProvider.DropSnapshotConfigMapRef
+	if ref == nil {
+		return nil
+	}
+
+	objs, err := c.members(ctx, cr.Spec.ForProvider.Extension)
+	if err != nil || len(objs) == 0 {
+		return err
+	}
+
+	j, err := json.Marshal(snapshot{
+		Extension: cr.Spec.ForProvider.Extension,
+		Database:  cr.Spec.ForProvider.Database,
+		Taken:     metav1.Now(),
+		Objects:   objs,
+	})
+	if err != nil {
+		return errors.Wrap(err, errMarshalSnapshot)
+	}
+
+	return writeSnapshot(ctx, c.kube, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, snapshotKey(cr), string(j))
+}
+
+// writeSnapshot adds the supplied key to the ConfigMap, creating it if it
+// does not exist. Any other keys are preserved, so that one ConfigMap may hold
+// the snapshots of many extensions.
+func writeSnapshot(ctx context.Context, kube client.Client, nn types.NamespacedName, key, value string) error {
+	cm := &corev1.ConfigMap{}
+	err := kube.Get(ctx, nn, cm)
+	if kerrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: nn.Namespace, Name: nn.Name},
+			Data:       map[string]string{key: value},
+		}
+		return errors.Wrap(kube.Create(ctx, cm), errWriteSnapshot)
+	}
+	if err != nil {
+		return errors.Wrap(err, errGetSnapshot)
+	}
+
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[key] = value
+	return errors.Wrap(kube.Update(ctx, cm), errWriteSnapshot)
+}
@@ -0,0 +1,284 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestMembers(t *testing.T) {
+	errBoom := errors.New("boom")
+	def := "CREATE OR REPLACE FUNCTION public.hstore_in(cstring) ..."
+
+	type want struct {
+		objs []snapshotObject
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		query  func(ctx context.Context, q xsql.Query) (*sql.Rows, error)
+		want   want
+	}{
+		"Members": {
+			reason: "Each of the extension's objects should be returned, with its DDL if available",
+			query: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+				return mockRowsToSQLRows(sqlmock.NewRows([]string{"object", "ddl"}).
+					AddRow("function hstore_in(cstring)", def).
+					AddRow("type hstore", nil)), nil
+			},
+			want: want{objs: []snapshotObject{
+				{Object: "function hstore_in(cstring)", DDL: &def},
+				{Object: "type hstore"},
+			}},
+		},
+		"ErrSelectMembers": {
+			reason: "Errors selecting the extension's objects should be returned",
+			query:  func(ctx context.Context, q xsql.Query) (*sql.Rows, error) { return nil, errBoom },
+			want:   want{err: errors.Wrap(errBoom, errSelectMembers)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{db: mockDB{MockQuery: tc.query}}
+			got, err := e.members(context.Background(), "hstore")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.members(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.objs, got); diff != "" {
+				t.Errorf("\n%s\ne.members(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSnapshotKey(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		database *string
+		want     string
+	}{
+		"DefaultDatabase": {
+			reason: "The key should be named for the Extension when no database is specified",
+			want:   "hstore.json",
+		},
+		"Database": {
+			reason:   "The key should include the database, with any characters a key may not contain replaced",
+			database: pointer.StringPtr("tenant a/1"),
+			want:     "hstore.tenant_a_1.json",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.Extension{
+				ObjectMeta: metav1.ObjectMeta{Name: "hstore"},
+				Spec:       v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{Database: tc.database}},
+			}
+			if diff := cmp.Diff(tc.want, snapshotKey(cr)); diff != "" {
+				t.Errorf("\n%s\nsnapshotKey(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	errBoom := errors.New("boom")
+	gr := schema.GroupResource{Resource: "configmaps"}
+	ref := &v1alpha1.ConfigMapReference{Namespace: "crossplane-system", Name: "snapshots"}
+
+	members := func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+		return mockRowsToSQLRows(sqlmock.NewRows([]string{"object", "ddl"}).AddRow("type hstore", nil)), nil
+	}
+	none := func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+		return mockRowsToSQLRows(sqlmock.NewRows([]string{"object", "ddl"})), nil
+	}
+
+	type want struct {
+		err  error
+		data map[string]string
+	}
+
+	cases := map[string]struct {
+		reason string
+		ref    *v1alpha1.ConfigMapReference
+		query  func(ctx context.Context, q xsql.Query) (*sql.Rows, error)
+		kube   func(data *map[string]string) client.Client
+		want   want
+	}{
+		"Disabled": {
+			reason: "Nothing should be recorded when no snapshot ConfigMap is referenced",
+			query: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+				t.Errorf("MockQuery: unexpected query: %s", q.String)
+				return nil, errBoom
+			},
+			kube: func(_ *map[string]string) client.Client { return &test.MockClient{} },
+		},
+		"NoObjects": {
+			reason: "Nothing should be recorded when the extension has no objects",
+			ref:    ref,
+			query:  none,
+			kube:   func(_ *map[string]string) client.Client { return &test.MockClient{} },
+		},
+		"ErrMembers": {
+			reason: "Errors enumerating the extension's objects should be returned",
+			ref:    ref,
+			query:  func(ctx context.Context, q xsql.Query) (*sql.Rows, error) { return nil, errBoom },
+			kube:   func(_ *map[string]string) client.Client { return &test.MockClient{} },
+			want:   want{err: errors.Wrap(errBoom, errSelectMembers)},
+		},
+		"ErrGet": {
+			reason: "Errors getting the snapshot ConfigMap should be returned",
+			ref:    ref,
+			query:  members,
+			kube: func(_ *map[string]string) client.Client {
+				return &test.MockClient{MockGet: test.NewMockGetFn(errBoom)}
+			},
+			want: want{err: errors.Wrap(errBoom, errGetSnapshot)},
+		},
+		"Create": {
+			reason: "The snapshot ConfigMap should be created if it does not exist",
+			ref:    ref,
+			query:  members,
+			kube: func(data *map[string]string) client.Client {
+				return &test.MockClient{
+					MockGet: test.NewMockGetFn(kerrors.NewNotFound(gr, ref.Name)),
+					MockCreate: test.NewMockCreateFn(nil, func(o client.Object) error {
+						*data = o.(*corev1.ConfigMap).Data
+						return nil
+					}),
+				}
+			},
+			want: want{data: map[string]string{"hstore.json": ""}},
+		},
+		"Update": {
+			reason: "The snapshot should be added to an existing ConfigMap, preserving other snapshots",
+			ref:    ref,
+			query:  members,
+			kube: func(data *map[string]string) client.Client {
+				return &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+						o.(*corev1.ConfigMap).Data = map[string]string{"other.json": "{}"}
+						return nil
+					}),
+					MockUpdate: test.NewMockUpdateFn(nil, func(o client.Object) error {
+						*data = o.(*corev1.ConfigMap).Data
+						return nil
+					}),
+				}
+			},
+			want: want{data: map[string]string{"hstore.json": "", "other.json": "{}"}},
+		},
+		"ErrWrite": {
+			reason: "Errors writing the snapshot ConfigMap should be returned",
+			ref:    ref,
+			query:  members,
+			kube: func(_ *map[string]string) client.Client {
+				return &test.MockClient{
+					MockGet:    test.NewMockGetFn(nil),
+					MockUpdate: test.NewMockUpdateFn(errBoom),
+				}
+			},
+			want: want{err: errors.Wrap(errBoom, errWriteSnapshot)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var data map[string]string
+			e := &external{db: mockDB{MockQuery: tc.query}, kube: tc.kube(&data)}
+			cr := &v1alpha1.Extension{
+				ObjectMeta: metav1.ObjectMeta{Name: "hstore"},
+				Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{
+					Extension:                "hstore",
+					DropSnapshotConfigMapRef: tc.ref,
+				}},
+			}
+
+			err := e.snapshot(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.snapshot(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+
+			// The snapshot itself includes the time it was taken, so we
+			// check it separately.
+			if diff := cmp.Diff(tc.want.data, data, cmpopts.IgnoreMapEntries(func(k, _ string) bool { return k == "hstore.json" })); diff != "" {
+				t.Errorf("\n%s\ne.snapshot(...): -want data, +got data:\n%s\n", tc.reason, diff)
+			}
+			if _, ok := tc.want.data["hstore.json"]; !ok {
+				return
+			}
+			got := snapshot{}
+			if err := json.Unmarshal([]byte(data["hstore.json"]), &got); err != nil {
+				t.Fatalf("\n%s\njson.Unmarshal(...): %v", tc.reason, err)
+			}
+			want := snapshot{Extension: "hstore", Objects: []snapshotObject{{Object: "type hstore"}}}
+			if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(snapshot{}, "Taken")); diff != "" {
+				t.Errorf("\n%s\ne.snapshot(...): -want snapshot, +got snapshot:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDeleteSnapshot(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	e := &external{
+		db: mockDB{
+			MockQuery: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+				if q.String == dependentsQuery {
+					return queryNoDependents(ctx, q)
+				}
+				return nil, errBoom
+			},
+			MockExec: func(ctx context.Context, q xsql.Query) error {
+				t.Errorf("MockExec: the extension should not be dropped when it cannot be snapshotted: %s", q.String)
+				return nil
+			},
+		},
+		kube: &test.MockClient{},
+	}
+	cr := &v1alpha1.Extension{Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{
+		Extension:                "hstore",
+		DropSnapshotConfigMapRef: &v1alpha1.ConfigMapReference{Namespace: "crossplane-system", Name: "snapshots"},
+	}}}
+
+	want := errors.Wrap(errors.Wrap(errBoom, errSelectMembers), errSnapshot)
+	if diff := cmp.Diff(want, e.Delete(context.Background(), cr), test.EquateErrors()); diff != "" {
+		t.Errorf("\ne.Delete(...): -want error, +got error:\n%s\n", diff)
+	}
+}
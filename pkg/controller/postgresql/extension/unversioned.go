@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+)
+
+// TypeVersionUnknown is true while the server does not record the versions of
+// its extensions, for example because it predates the extversion column of
+// pg_extension.
+const TypeVersionUnknown xpv1.ConditionType = "VersionUnknown"
+
+// ReasonUnversionedCatalog indicates the server's pg_extension catalog has no
+// extversion column.
+const ReasonUnversionedCatalog xpv1.ConditionReason = "UnversionedCatalog"
+
+// VersionUnknown returns a condition that indicates the version of the
+// extension cannot be observed.
+func VersionUnknown() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeVersionUnknown,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonUnversionedCatalog,
+		Message:            "The server's pg_extension catalog has no extversion column. The version of the extension will not be reported or reconciled.",
+	}
+}
+
+// VersionKnown returns a condition that indicates the version of the extension
+// can be observed.
+func VersionKnown() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeVersionUnknown,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonResolved,
+	}
+}
+
+// observeVersioned reports whether the version of the extension could be
+// observed, and clears the observed version if it could not.
+func observeVersioned(cr *v1alpha1.Extension, versioned bool, observed *v1alpha1.ExtensionParameters) {
+	if !versioned {
+		observed.Version = nil
+		cr.SetConditions(VersionUnknown())
+		return
+	}
+	if cr.GetCondition(TypeVersionUnknown).Status == corev1.ConditionTrue {
+		cr.SetConditions(VersionKnown())
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql/xsqltest"
+)
+
+func TestObserveUnversioned(t *testing.T) {
+	type want struct {
+		o         managed.ExternalObservation
+		installed *string
+		version   *string
+		condition corev1.ConditionStatus
+	}
+
+	cases := map[string]struct {
+		reason    string
+		versioned bool
+		cr        *v1alpha1.Extension
+		want      want
+	}{
+		"OldServer": {
+			reason: "An extension on a server without extversion should exist and be up to date despite a desired version, and its version should be reported unknown",
+			cr: &v1alpha1.Extension{
+				Spec: v1alpha1.ExtensionSpec{
+					ForProvider: v1alpha1.ExtensionParameters{Extension: "hstore", Version: pointer.StringPtr("1.1")},
+				},
+			},
+			want: want{
+				o:         managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				version:   pointer.StringPtr("1.1"),
+				condition: corev1.ConditionTrue,
+			},
+		},
+		"OldServerNoLateInit": {
+			reason: "The desired version should not be late initialized on a server without extversion",
+			cr: &v1alpha1.Extension{
+				Spec: v1alpha1.ExtensionSpec{
+					ForProvider: v1alpha1.ExtensionParameters{Extension: "hstore"},
+				},
+			},
+			want: want{
+				o:         managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				condition: corev1.ConditionTrue,
+			},
+		},
+		"ServerUpgraded": {
+			reason: "The unknown version condition should be resolved once the version can be observed",
+			cr: func() *v1alpha1.Extension {
+				cr := &v1alpha1.Extension{
+					Spec: v1alpha1.ExtensionSpec{
+						ForProvider: v1alpha1.ExtensionParameters{Extension: "hstore", Version: pointer.StringPtr("1.1")},
+					},
+				}
+				cr.SetConditions(VersionUnknown())
+				return cr
+			}(),
+			versioned: true,
+			want: want{
+				o:         managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				installed: pointer.StringPtr("1.1"),
+				version:   pointer.StringPtr("1.1"),
+				condition: corev1.ConditionFalse,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			versioned := xsqltest.Result{Values: []interface{}{"1.1"}}
+			if !tc.versioned {
+				versioned = xsqltest.Result{Err: &pq.Error{Code: "42703"}}
+			}
+			db := xsqltest.New().
+				On(exactly(observeQuery("pg_authid", true)), versioned).
+				On(exactly(observeQuery("pg_authid", false)), xsqltest.Result{Values: []interface{}{""}})
+			e := external{db: db}
+
+			o, err := e.Observe(context.Background(), tc.cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): unexpected error: %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.o, o); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.installed, tc.cr.Status.AtProvider.InstalledVersion); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want installed version, +got installed version:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.version, tc.cr.Spec.ForProvider.Version); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want version, +got version:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.condition, tc.cr.GetCondition(TypeVersionUnknown).Status); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want VersionUnknown status, +got:\n%s\n", tc.reason, diff)
+			}
+			want := []string{observeQuery("pg_authid", true)}
+			if !tc.versioned {
+				want = append(want, observeQuery("pg_authid", false))
+			}
+			db.AssertStatements(t, want...)
+			if len(tc.cr.Status.AtProvider.PendingStatements) > 0 {
+				t.Errorf("\n%s\ne.Observe(...): want no pending statements, got %v", tc.reason, tc.cr.Status.AtProvider.PendingStatements)
+			}
+		})
+	}
+}
+
+// exactly returns a pattern that matches only the supplied query.
+func exactly(query string) string {
+	return "^" + regexp.QuoteMeta(query) + "$"
+}
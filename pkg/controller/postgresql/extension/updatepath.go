@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+const errSelectUpdatePath = "cannot select extension update path"
+
+// updatePathAvailable returns true if PostgreSQL knows a (possibly indirect)
+// update path between the supplied versions of the supplied extension.
+func (c *external) updatePathAvailable(ctx context.Context, extension, from, to string) (bool, error) {
+	if from == to {
+		return true, nil
+	}
+
+	// pg_extension_update_paths returns a row for every pair of known
+	// versions; path is NULL when there's no way to get from one to another.
+	var available bool
+	err := c.db.Scan(ctx, xsql.Query{
+		String:     "SELECT path IS NOT NULL FROM pg_extension_update_paths($1) WHERE source = $2 AND target = $3",
+		Parameters: []interface{}{extension, from, to},
+	}, &available)
+	if xsql.IsNoRows(err) {
+		return false, nil
+	}
+	return available, errors.Wrap(err, errSelectUpdatePath)
+}
+
+// observeUpdatePath reports whether the observed extension can be updated to
+// the desired version, if asked to.
+func (c *external) observeUpdatePath(ctx context.Context, cr *v1alpha1.Extension, observed, desired v1alpha1.ExtensionParameters) error {
+	cr.Status.AtProvider.UpdatePathAvailable = nil
+	if desired.ObserveUpdatePath == nil || !*desired.ObserveUpdatePath || desired.Version == nil || observed.Version == nil {
+		return nil
+	}
+
+	available, err := c.updatePathAvailable(ctx, desired.Extension, *observed.Version, *desired.Version)
+	if err != nil {
+		return err
+	}
+	cr.Status.AtProvider.UpdatePathAvailable = &available
+	return nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestObserveUpdatePath(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	scan := func(available bool, err error) func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+		return func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			*dest[0].(*bool) = available
+			return err
+		}
+	}
+
+	type args struct {
+		db       xsql.DB
+		observed v1alpha1.ExtensionParameters
+		desired  v1alpha1.ExtensionParameters
+	}
+
+	type want struct {
+		available *bool
+		err       error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NotRequested": {
+			reason: "The update path should not be reported unless requested",
+			args: args{
+				observed: v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.0")},
+				desired:  v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.1")},
+			},
+			want: want{available: nil},
+		},
+		"NoDesiredVersion": {
+			reason: "The update path should not be reported when no version is desired",
+			args: args{
+				observed: v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.0")},
+				desired:  v1alpha1.ExtensionParameters{ObserveUpdatePath: pointer.BoolPtr(true)},
+			},
+			want: want{available: nil},
+		},
+		"SameVersion": {
+			reason: "An update path is trivially available when the extension is at the desired version",
+			args: args{
+				observed: v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.0")},
+				desired:  v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.0"), ObserveUpdatePath: pointer.BoolPtr(true)},
+			},
+			want: want{available: pointer.BoolPtr(true)},
+		},
+		"PathAvailable": {
+			reason: "An update path should be reported when PostgreSQL knows one",
+			args: args{
+				db:       mockDB{MockScan: scan(true, nil)},
+				observed: v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.0")},
+				desired:  v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.1"), ObserveUpdatePath: pointer.BoolPtr(true)},
+			},
+			want: want{available: pointer.BoolPtr(true)},
+		},
+		"NullPath": {
+			reason: "No update path should be reported when PostgreSQL's path is NULL",
+			args: args{
+				db:       mockDB{MockScan: scan(false, nil)},
+				observed: v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.0")},
+				desired:  v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.1"), ObserveUpdatePath: pointer.BoolPtr(true)},
+			},
+			want: want{available: pointer.BoolPtr(false)},
+		},
+		"UnknownVersion": {
+			reason: "No update path should be reported when PostgreSQL doesn't know the desired version",
+			args: args{
+				db:       mockDB{MockScan: scan(false, sql.ErrNoRows)},
+				observed: v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.0")},
+				desired:  v1alpha1.ExtensionParameters{Version: pointer.StringPtr("9.9"), ObserveUpdatePath: pointer.BoolPtr(true)},
+			},
+			want: want{available: pointer.BoolPtr(false)},
+		},
+		"ErrSelectUpdatePath": {
+			reason: "Errors selecting the update path should be returned",
+			args: args{
+				db:       mockDB{MockScan: scan(false, errBoom)},
+				observed: v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.0")},
+				desired:  v1alpha1.ExtensionParameters{Version: pointer.StringPtr("1.1"), ObserveUpdatePath: pointer.BoolPtr(true)},
+			},
+			want: want{err: errors.Wrap(errBoom, errSelectUpdatePath)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.args.db}
+			cr := &v1alpha1.Extension{}
+			err := e.observeUpdatePath(context.Background(), cr, tc.args.observed, tc.args.desired)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.observeUpdatePath(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.available, cr.Status.AtProvider.UpdatePathAvailable); diff != "" {
+				t.Errorf("\n%s\ne.observeUpdatePath(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
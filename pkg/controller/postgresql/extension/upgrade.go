@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+const (
+	errUpdateNotVerified           = "extension is not at version %q after update; the update was rolled back"
+	errUpdateNotVerifiedAutocommit = "extension is not at version %q after update"
+	errUpdateStep                  = "cannot update extension to version %q (step %d/%d)"
+)
+
+// hintUpdateNotVerified identifies the error raised by verifyUpdateQuery.
+const hintUpdateNotVerified = "crossplane: extension update not verified"
+
+// verifyUpdateQuery returns a statement that raises an error, and thus rolls
+// back the transaction in which it runs, unless the supplied extension is at
+// the supplied version.
+func verifyUpdateQuery(q postgresql.Quoter, extension, version string) xsql.Query {
+	// DO does not support parameters, so the extension and version must be
+	// quoted.
+	e, v := q.QuoteLiteral(extension), q.QuoteLiteral(version)
+	return xsql.Query{String: "DO $verify$ BEGIN " +
+		"IF (SELECT extversion FROM pg_extension WHERE extname = " + e + ") IS DISTINCT FROM " + v + " THEN " +
+		"RAISE EXCEPTION 'extension % is not at version % after update', " + e + ", " + v + " " +
+		"USING HINT = " + q.QuoteLiteral(hintUpdateNotVerified) + "; " +
+		"END IF; END $verify$"}
+}
+
+// isUpdateNotVerified returns true if the supplied error was raised by
+// verifyUpdateQuery.
+func isUpdateNotVerified(err error) bool {
+	pqe := &pq.Error{}
+	return errors.As(err, &pqe) && pqe.Hint == hintUpdateNotVerified
+}
+
+// updateVersion updates the supplied extension to the supplied version. The
+// version is verified in the transaction that updates it, so that an update
+// that silently does nothing is rolled back and reported. An update that runs
+// in autocommit mode is verified, but cannot be rolled back.
+func (c *external) updateVersion(ctx context.Context, p v1alpha1.ExtensionParameters, version string) error {
+	autocommit, err := c.execTx(ctx, p, []xsql.Query{updateQuery(c.quoter(), p.Extension, version), verifyUpdateQuery(c.quoter(), p.Extension, version)})
+	switch {
+	case isUpdateNotVerified(err) && autocommit:
+		return errors.Errorf(errUpdateNotVerifiedAutocommit, version)
+	case isUpdateNotVerified(err):
+		return errors.Errorf(errUpdateNotVerified, version)
+	}
+	return err
+}
+
+// updateSteps updates the supplied extension to the supplied version. A
+// multi-step update is made one step of its plan at a time, reporting
+// progress as each step completes, because PostgreSQL would otherwise run
+// every step in a single statement that shows no progress until it's done.
+// Each step is verified and committed on its own, so an update that fails
+// part way through resumes from the last step that succeeded.
+func (c *external) updateSteps(ctx context.Context, cr *v1alpha1.Extension, version string) error {
+	steps := c.planUpdate(ctx, cr, version)
+	c.announceUpdatePlan(cr, version, steps)
+	if len(steps) == 0 {
+		return c.updateVersion(ctx, cr.Spec.ForProvider, version)
+	}
+	for i, v := range steps {
+		if err := c.updateVersion(ctx, cr.Spec.ForProvider, v); err != nil {
+			return errors.Wrapf(err, errUpdateStep, v, i+1, len(steps))
+		}
+		reportProgress(ctx, c.persist, cr, fmt.Sprintf("%s %d/%d steps", progressUpgraded, i+1, len(steps)))
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestUpdateVersion(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		queries []string
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   want
+	}{
+		"Verified": {
+			reason: "The update should be verified in the same transaction before it is committed",
+			want: want{
+				queries: []string{
+					`ALTER EXTENSION "hstore" UPDATE TO "1.2"`,
+					"DO $verify$ BEGIN " +
+						"IF (SELECT extversion FROM pg_extension WHERE extname = 'hstore') IS DISTINCT FROM '1.2' THEN " +
+						"RAISE EXCEPTION 'extension % is not at version % after update', 'hstore', '1.2' " +
+						"USING HINT = 'crossplane: extension update not verified'; " +
+						"END IF; END $verify$",
+				},
+			},
+		},
+		"NotVerified": {
+			reason: "An update that did not take effect should be reported as such",
+			err:    &pq.Error{Message: "extension hstore is not at version 1.2 after update", Hint: hintUpdateNotVerified},
+			want: want{
+				err: errors.Errorf(errUpdateNotVerified, "1.2"),
+			},
+		},
+		"ErrUpdate": {
+			reason: "Other errors should be returned unchanged",
+			err:    errBoom,
+			want: want{
+				err: errBoom,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var queries []string
+			e := external{db: mockDB{MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+				for _, q := range ql {
+					queries = append(queries, q.String)
+				}
+				return tc.err
+			}}}
+
+			err := e.updateVersion(context.Background(), v1alpha1.ExtensionParameters{Extension: "hstore"}, "1.2")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.updateVersion(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if tc.want.queries == nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.queries, queries); diff != "" {
+				t.Errorf("\n%s\ne.updateVersion(...): -want queries, +got queries:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdateSteps(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		updates  []string
+		progress []string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		path   sql.NullString
+		fail   string
+		record event.Recorder
+		want   want
+	}{
+		"MultiStep": {
+			reason: "A multi-step update should be made one step at a time, reporting progress as each step completes",
+			path:   sql.NullString{String: "1.0--1.1--1.2--1.3", Valid: true},
+			record: &eventRecorder{},
+			want: want{
+				updates: []string{
+					`ALTER EXTENSION "hstore" UPDATE TO "1.1"`,
+					`ALTER EXTENSION "hstore" UPDATE TO "1.2"`,
+					`ALTER EXTENSION "hstore" UPDATE TO "1.3"`,
+				},
+				progress: []string{"upgraded 1/3 steps", "upgraded 2/3 steps", "upgraded 3/3 steps"},
+			},
+		},
+		"ErrStep": {
+			reason: "A multi-step update should stop at the step that failed, reporting the steps that completed",
+			path:   sql.NullString{String: "1.0--1.1--1.2--1.3", Valid: true},
+			record: &eventRecorder{},
+			fail:   "1.2",
+			want: want{
+				updates: []string{
+					`ALTER EXTENSION "hstore" UPDATE TO "1.1"`,
+					`ALTER EXTENSION "hstore" UPDATE TO "1.2"`,
+				},
+				progress: []string{"upgraded 1/3 steps"},
+				err:      errors.Wrapf(errBoom, errUpdateStep, "1.2", 2, 3),
+			},
+		},
+		"NoRecorder": {
+			reason: "A multi-step update should be made one step at a time even if there's no recorder to announce it",
+			path:   sql.NullString{String: "1.0--1.1--1.2--1.3", Valid: true},
+			want: want{
+				updates: []string{
+					`ALTER EXTENSION "hstore" UPDATE TO "1.1"`,
+					`ALTER EXTENSION "hstore" UPDATE TO "1.2"`,
+					`ALTER EXTENSION "hstore" UPDATE TO "1.3"`,
+				},
+				progress: []string{"upgraded 1/3 steps", "upgraded 2/3 steps", "upgraded 3/3 steps"},
+			},
+		},
+		"SingleStep": {
+			reason: "A single step update should be made in one go, without reporting progress",
+			path:   sql.NullString{String: "1.0--1.3", Valid: true},
+			record: &eventRecorder{},
+			want: want{
+				updates: []string{`ALTER EXTENSION "hstore" UPDATE TO "1.3"`},
+			},
+		},
+		"Unplanned": {
+			reason: "An update that can't be planned should be made in one go, without reporting progress",
+			path:   sql.NullString{},
+			record: &eventRecorder{},
+			want: want{
+				updates: []string{`ALTER EXTENSION "hstore" UPDATE TO "1.3"`},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var updates, progress []string
+			e := external{
+				db: mockDB{
+					MockScan: func(_ context.Context, _ xsql.Query, dest ...interface{}) error {
+						*dest[0].(*sql.NullString) = tc.path
+						return nil
+					},
+					MockExecTx: func(_ context.Context, ql []xsql.Query) error {
+						updates = append(updates, ql[0].String)
+						if tc.fail != "" && strings.HasSuffix(ql[0].String, `"`+tc.fail+`"`) {
+							return errBoom
+						}
+						return nil
+					},
+				},
+				record: tc.record,
+				persist: func(_ context.Context, cr *v1alpha1.Extension) error {
+					progress = append(progress, *cr.Status.AtProvider.Progress)
+					return nil
+				},
+			}
+			cr := &v1alpha1.Extension{
+				Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{Extension: "hstore"}},
+				Status: v1alpha1.ExtensionStatus{AtProvider: v1alpha1.ExtensionObservation{
+					InstalledVersion: pointer.StringPtr("1.0"),
+				}},
+			}
+
+			err := e.updateSteps(context.Background(), cr, "1.3")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.updateSteps(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.updates, updates); diff != "" {
+				t.Errorf("\n%s\ne.updateSteps(...): -want updates, +got updates:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.progress, progress); diff != "" {
+				t.Errorf("\n%s\ne.updateSteps(...): -want persisted progress, +got persisted progress:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+// TypeDropIncomplete is true while an extension that was dropped is still
+// installed.
+const TypeDropIncomplete xpv1.ConditionType = "DropIncomplete"
+
+// ReasonStillInstalled indicates the extension was found in pg_extension
+// after it was dropped.
+const ReasonStillInstalled xpv1.ConditionReason = "StillInstalled"
+
+const (
+	errVerifyDrop     = "cannot verify extension was dropped"
+	errStillInstalled = "extension is still installed after it was dropped"
+)
+
+// existsQuery selects whether an extension is installed.
+const existsQuery = "SELECT EXISTS(SELECT 1 FROM pg_extension WHERE extname = $1)"
+
+// DropIncomplete returns a condition that indicates the extension is still
+// installed after it was dropped.
+func DropIncomplete() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDropIncomplete,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonStillInstalled,
+		Message:            "The extension was dropped, but is still installed. The provider will try to drop it again.",
+	}
+}
+
+// verifyDropped returns an error, and sets the DropIncomplete condition, if
+// the supplied extension should be verified to have been dropped but is
+// still installed. The managed reconciler retries the deletion, and keeps
+// the resource's finalizer, until it's gone.
+func (c *external) verifyDropped(ctx context.Context, cr *v1alpha1.Extension) error {
+	p := cr.Spec.ForProvider
+	if p.VerifyDrop == nil || !*p.VerifyDrop {
+		return nil
+	}
+
+	installed := false
+	if err := c.db.Scan(ctx, xsql.Query{String: existsQuery, Parameters: []interface{}{p.Extension}}, &installed); err != nil {
+		return errors.Wrap(err, errVerifyDrop)
+	}
+	if installed {
+		cr.SetConditions(DropIncomplete())
+		return errors.New(errStillInstalled)
+	}
+	return nil
+}
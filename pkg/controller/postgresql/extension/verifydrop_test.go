@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestDeleteVerifyDrop(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		err      error
+		verified bool
+		status   corev1.ConditionStatus
+	}
+
+	cases := map[string]struct {
+		reason    string
+		verify    *bool
+		installed bool
+		scanErr   error
+		want      want
+	}{
+		"NoVerify": {
+			reason: "The extension should not be looked up after it's dropped unless verification is enabled",
+			want:   want{status: corev1.ConditionUnknown},
+		},
+		"Gone": {
+			reason: "Deletion should succeed if the extension is gone after it's dropped",
+			verify: pointer.BoolPtr(true),
+			want:   want{verified: true, status: corev1.ConditionUnknown},
+		},
+		"StillPresent": {
+			reason:    "Deletion should fail, and the DropIncomplete condition be set, if the extension is still installed after it's dropped",
+			verify:    pointer.BoolPtr(true),
+			installed: true,
+			want:      want{err: errors.New(errStillInstalled), verified: true, status: corev1.ConditionTrue},
+		},
+		"ErrVerify": {
+			reason:  "Deletion should fail if we can't tell whether the extension is gone",
+			verify:  pointer.BoolPtr(true),
+			scanErr: errBoom,
+			want:    want{err: errors.Wrap(errBoom, errVerifyDrop), verified: true, status: corev1.ConditionUnknown},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			verified := false
+			e := &external{db: mockDB{
+				MockQuery: queryNoDependents,
+				MockExec:  func(ctx context.Context, q xsql.Query) error { return nil },
+				MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+					if q.String != existsQuery {
+						return errors.Errorf("unexpected query %q", q.String)
+					}
+					if diff := cmp.Diff([]interface{}{"hstore"}, q.Parameters); diff != "" {
+						t.Errorf("MockScan: -want parameters, +got parameters:\n%s", diff)
+					}
+					verified = true
+					*dest[0].(*bool) = tc.installed
+					return tc.scanErr
+				},
+			}}
+			cr := &v1alpha1.Extension{Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{Extension: "hstore", VerifyDrop: tc.verify}}}
+
+			err := e.Delete(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.verified, verified); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want verified, +got verified:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.status, cr.GetCondition(TypeDropIncomplete).Status); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want condition status, +got condition status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+const (
+	errSelectVersions     = "cannot select available extension versions"
+	errInvalidConstraint  = "invalid version constraint"
+	errNoMatchingVersion  = "no available extension version matches constraint"
+	errVersionUnavailable = "version %q of extension %q is not available; available versions are %s"
+)
+
+// A version is a semver-like extension version, e.g. 1.2 or 2.5.3.
+type version []int
+
+func parseVersion(s string) (version, bool) {
+	parts := strings.Split(strings.TrimSpace(s), ".")
+	v := make(version, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+// compare returns -1, 0, or 1 if v is less than, equal to, or greater than o.
+// Missing components are treated as zero, such that 1.1 is equal to 1.1.0.
+func (v version) compare(o version) int {
+	for i := 0; i < len(v) || i < len(o); i++ {
+		a, b := 0, 0
+		if i < len(v) {
+			a = v[i]
+		}
+		if i < len(o) {
+			b = o[i]
+		}
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	}
+	return 0
+}
+
+type term struct {
+	op string
+	v  version
+}
+
+func (t term) matches(v version) bool {
+	c := v.compare(t.v)
+	switch t.op {
+	case ">=":
+		return c >= 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case "<":
+		return c < 0
+	case "!=":
+		return c != 0
+	default:
+		return c == 0
+	}
+}
+
+// isConstraint returns true if the supplied version is a constraint
+// expression like '>=1.1,<2.0' rather than a literal version.
+func isConstraint(v string) bool {
+	return strings.ContainsAny(v, "<>=!,")
+}
+
+func parseConstraint(c string) ([]term, error) {
+	terms := []term{}
+	for _, raw := range strings.Split(c, ",") {
+		raw = strings.TrimSpace(raw)
+		t := term{op: "="}
+		for _, op := range []string{">=", "<=", "!=", ">", "<", "="} {
+			if strings.HasPrefix(raw, op) {
+				t.op = op
+				raw = strings.TrimPrefix(raw, op)
+				break
+			}
+		}
+		v, ok := parseVersion(raw)
+		if !ok {
+			return nil, errors.Errorf("%s %q", errInvalidConstraint, c)
+		}
+		t.v = v
+		terms = append(terms, t)
+	}
+	return terms, nil
+}
+
+// resolveConstraint returns the highest of the available versions that
+// satisfies the supplied constraint. Available versions that are not
+// semver-like cannot be compared, and are thus never selected.
+func resolveConstraint(c string, available []string) (string, error) {
+	terms, err := parseConstraint(c)
+	if err != nil {
+		return "", err
+	}
+
+	var best string
+	var bestv version
+	for _, a := range available {
+		v, ok := parseVersion(a)
+		if !ok {
+			continue
+		}
+		match := true
+		for _, t := range terms {
+			match = match && t.matches(v)
+		}
+		if match && (bestv == nil || v.compare(bestv) > 0) {
+			best, bestv = a, v
+		}
+	}
+
+	if bestv == nil {
+		return "", errors.Errorf("%s %q", errNoMatchingVersion, c)
+	}
+	return best, nil
+}
+
+func (c *external) availableVersions(ctx context.Context, extension string) ([]string, error) {
+	rows, err := c.db.Query(ctx, xsql.Query{
+		String:     "SELECT version FROM pg_available_extension_versions WHERE name = $1",
+		Parameters: []interface{}{extension},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errSelectVersions)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	versions := []string{}
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, errors.Wrap(err, errSelectVersions)
+		}
+		versions = append(versions, v)
+	}
+	return versions, errors.Wrap(rows.Err(), errSelectVersions)
+}
+
+// targetVersion returns the version of the extension that should be
+// installed, resolving any version constraint against the versions available
+// on the server. It returns nil if no version was specified.
+func (c *external) targetVersion(ctx context.Context, p v1alpha1.ExtensionParameters) (*string, error) {
+	if p.Version == nil || !isConstraint(*p.Version) {
+		return p.Version, nil
+	}
+
+	available, err := c.availableVersions(ctx, p.Extension)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := resolveConstraint(*p.Version, available)
+	return &v, err
+}
+
+// diagnoseUnavailableVersion returns an error that lists the versions of the
+// extension that are packaged on the server, and sets the VersionUnavailable
+// condition, given a CREATE EXTENSION error caused by the supplied version not
+// being packaged. The supplied error is returned if the available versions
+// can't be determined.
+func (c *external) diagnoseUnavailableVersion(ctx context.Context, cr *v1alpha1.Extension, version string, err error) error {
+	available, aerr := c.availableVersions(ctx, cr.Spec.ForProvider.Extension)
+	if aerr != nil || len(available) == 0 {
+		return err
+	}
+	sort.Strings(available)
+	cr.SetConditions(VersionUnavailable(version, available))
+	return errors.Errorf(errVersionUnavailable, version, cr.Spec.ForProvider.Extension, strings.Join(available, ", "))
+}
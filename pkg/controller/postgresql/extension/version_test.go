@@ -0,0 +1,172 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+func TestResolveConstraint(t *testing.T) {
+	type args struct {
+		constraint string
+		available  []string
+	}
+
+	type want struct {
+		version string
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"HighestMatch": {
+			reason: "The highest available version satisfying all terms should be selected",
+			args: args{
+				constraint: ">=1.1,<2.0",
+				available:  []string{"1.0", "1.1", "1.10", "1.2", "2.0"},
+			},
+			want: want{version: "1.10"},
+		},
+		"Exact": {
+			reason: "An exact constraint should match an equivalent version",
+			args: args{
+				constraint: "=1.1",
+				available:  []string{"1.0", "1.1.0", "1.2"},
+			},
+			want: want{version: "1.1.0"},
+		},
+		"NonSemver": {
+			reason: "Available versions that are not semver-like should be ignored",
+			args: args{
+				constraint: ">=1.0",
+				available:  []string{"1.3", "1.4beta1", "unpackaged"},
+			},
+			want: want{version: "1.3"},
+		},
+		"NoMatch": {
+			reason: "An error should be returned if no available version satisfies the constraint",
+			args: args{
+				constraint: ">2.0",
+				available:  []string{"1.0", "2.0"},
+			},
+			want: want{err: errors.Errorf("%s %q", errNoMatchingVersion, ">2.0")},
+		},
+		"Invalid": {
+			reason: "An error should be returned if the constraint cannot be parsed",
+			args: args{
+				constraint: ">=one",
+				available:  []string{"1.0"},
+			},
+			want: want{err: errors.Errorf("%s %q", errInvalidConstraint, ">=one")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := resolveConstraint(tc.args.constraint, tc.args.available)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nresolveConstraint(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.version, got); diff != "" {
+				t.Errorf("\n%s\nresolveConstraint(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDiagnoseUnavailableVersion(t *testing.T) {
+	errBoom := errors.New("boom")
+	errCreate := &pq.Error{Code: "22023", Message: `extension "hstore" has no installation script nor update path for version "9.9"`}
+
+	type want struct {
+		err     error
+		cond    corev1.ConditionStatus
+		message string
+	}
+
+	cases := map[string]struct {
+		reason string
+		query  func(ctx context.Context, q xsql.Query) (*sql.Rows, error)
+		want   want
+	}{
+		"Available": {
+			reason: "The versions packaged on the server should be listed in the error and condition",
+			query: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+				return mockRowsToSQLRows(sqlmock.NewRows([]string{"version"}).AddRow("1.7").AddRow("1.4").AddRow("1.6")), nil
+			},
+			want: want{
+				err:     errors.Errorf(errVersionUnavailable, "9.9", "hstore", "1.4, 1.6, 1.7"),
+				cond:    corev1.ConditionTrue,
+				message: VersionUnavailable("9.9", []string{"1.4", "1.6", "1.7"}).Message,
+			},
+		},
+		"NoneAvailable": {
+			reason: "The CREATE EXTENSION error should be returned if the extension is not packaged at all",
+			query: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+				return mockRowsToSQLRows(sqlmock.NewRows([]string{"version"})), nil
+			},
+			want: want{
+				err:  errCreate,
+				cond: corev1.ConditionUnknown,
+			},
+		},
+		"ErrSelectVersions": {
+			reason: "The CREATE EXTENSION error should be returned if the available versions cannot be selected",
+			query: func(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+				return nil, errBoom
+			},
+			want: want{
+				err:  errCreate,
+				cond: corev1.ConditionUnknown,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: mockDB{MockQuery: tc.query}}
+			cr := &v1alpha1.Extension{Spec: v1alpha1.ExtensionSpec{ForProvider: v1alpha1.ExtensionParameters{Extension: "hstore"}}}
+			err := e.diagnoseUnavailableVersion(context.Background(), cr, "9.9", errCreate)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.diagnoseUnavailableVersion(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			got := cr.GetCondition(TypeVersionUnavailable)
+			if diff := cmp.Diff(tc.want.cond, got.Status); diff != "" {
+				t.Errorf("\n%s\ne.diagnoseUnavailableVersion(...): -want condition status, +got condition status:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.message, got.Message); diff != "" {
+				t.Errorf("\n%s\ne.diagnoseUnavailableVersion(...): -want condition message, +got condition message:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
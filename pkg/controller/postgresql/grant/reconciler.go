@@ -24,29 +24,22 @@ import (
 
 	"github.com/lib/pq"
 	"github.com/pkg/errors"
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
-	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/options"
 )
 
 const (
-	errTrackPCUsage = "cannot track ProviderConfig usage"
-	errGetPC        = "cannot get ProviderConfig"
-	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
-	errGetSecret    = "cannot get credentials Secret"
-
 	errNotGrant     = "managed resource is not a Grant custom resource"
 	errSelectGrant  = "cannot select grant"
 	errCreateGrant  = "cannot create grant"
@@ -64,16 +57,25 @@ const (
 )
 
 // Setup adds a controller that reconciles Grant managed resources.
-func Setup(mgr ctrl.Manager, l logging.Logger) error {
+func Setup(mgr ctrl.Manager, o options.Options) error {
 	name := managed.ControllerName(v1alpha1.GrantGroupKind)
 
+	db := func(creds map[string][]byte, database string, po ...postgresql.Option) xsql.DB {
+		return o.DB(xsql.NewRetryingDB(postgresql.New(creds, database, po...), postgresql.IsTransient))
+	}
+
+	rec, err := o.Recorder(mgr, v1alpha1.GrantGroupVersionKind, func() resource.ProviderConfig { return &v1alpha1.ProviderConfig{} }, event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))
+	if err != nil {
+		return err
+	}
+
 	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1alpha1.GrantGroupVersionKind),
-		managed.WithExternalConnecter(&connector{kube: mgr.GetClient(), usage: t, newDB: postgresql.New}),
-		managed.WithLogger(l.WithValues("controller", name)),
+		managed.WithExternalConnecter(o.ExternalConnecter(options.NewConnectionLimitConnecter(&connector{kube: mgr.GetClient(), usage: t, newDB: db, ddl: o.DDLRateLimiter}, postgresql.IsTooManyConnections))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithPollInterval(10*time.Minute),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		managed.WithRecorder(rec))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
@@ -81,46 +83,32 @@ func Setup(mgr ctrl.Manager, l logging.Logger) error {
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: maxConcurrency,
 		}).
-		Complete(r)
+		Complete(options.NewConnectionLimitReconciler(r, mgr.GetClient(), func() resource.Managed { return &v1alpha1.Grant{} }, o.ConnectionLimitBackoff))
 }
 
 type connector struct {
 	kube  client.Client
 	usage resource.Tracker
-	newDB func(creds map[string][]byte, database string) xsql.DB
+	newDB func(creds map[string][]byte, database string, o ...postgresql.Option) xsql.DB
+	ddl   *options.DDLRateLimiter
 }
 
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
-	cr, ok := mg.(*v1alpha1.Grant)
-	if !ok {
+	if _, ok := mg.(*v1alpha1.Grant); !ok {
 		return nil, errors.New(errNotGrant)
 	}
 
-	if err := c.usage.Track(ctx, mg); err != nil {
-		return nil, errors.Wrap(err, errTrackPCUsage)
-	}
-
-	// ProviderConfigReference could theoretically be nil, but in practice the
-	// DefaultProviderConfig initializer will set it before we get here.
-	pc := &v1alpha1.ProviderConfig{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
-		return nil, errors.Wrap(err, errGetPC)
+	conn, err := postgresql.NewConnector(c.kube, c.usage).Resolve(ctx, mg)
+	if err != nil {
+		return nil, err
 	}
+	pc, creds := conn.ProviderConfig, conn.Credentials
 
-	// We don't need to check the credentials source because we currently only
-	// support one source (PostgreSQLConnectionSecret), which is required and
-	// enforced by the ProviderConfig schema.
-	ref := pc.Spec.Credentials.ConnectionSecretRef
-	if ref == nil {
-		return nil, errors.New(errNoSecretRef)
-	}
+	// All resources using this ProviderConfig share a DDL rate limit.
+	ops, burst := pc.Spec.DDLRateLimit.Rate()
 
-	s := &corev1.Secret{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
-		return nil, errors.Wrap(err, errGetSecret)
-	}
 	return &external{
-		db:   c.newDB(s.Data, ""),
+		db:   c.ddl.DB(pc.GetName(), ops, burst, c.newDB(creds, conn.DatabaseOrDefault(""), conn.Options()...)),
 		kube: c.kube,
 	}, nil
 }
@@ -0,0 +1,135 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package role
+
+import (
+	"context"
+	"sort"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+const (
+	errSelectMemberOf = "cannot select role memberships"
+	errScanMemberOf   = "cannot scan role membership"
+	errGrantMemberOf  = "cannot grant role membership"
+	errRevokeMemberOf = "cannot revoke role membership"
+)
+
+// memberOfQuery selects the roles a role is a member of.
+const memberOfQuery = "SELECT g.rolname FROM pg_auth_members m " +
+	"JOIN pg_roles g ON g.oid = m.roleid " +
+	"JOIN pg_roles r ON r.oid = m.member " +
+	"WHERE r.rolname = $1"
+
+// memberOf returns the roles the supplied role is a member of, in order.
+func (c *external) memberOf(ctx context.Context, role string) ([]string, error) {
+	rows, err := c.db.Query(ctx, xsql.Query{String: memberOfQuery, Parameters: []interface{}{role}})
+	if err != nil {
+		return nil, errors.Wrap(err, errSelectMemberOf)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	groups := []string{}
+	for rows.Next() {
+		var g string
+		if err := rows.Scan(&g); err != nil {
+			return nil, errors.Wrap(err, errScanMemberOf)
+		}
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errSelectMemberOf)
+	}
+	sort.Strings(groups)
+	return groups, nil
+}
+
+// observeMemberOf returns the roles the supplied role is a member of, or nil
+// if its memberships are not managed.
+func (c *external) observeMemberOf(ctx context.Context, cr *v1alpha1.Role) ([]string, error) {
+	if cr.Spec.ForProvider.MemberOf == nil {
+		return nil, nil
+	}
+	return c.memberOf(ctx, meta.GetExternalName(cr))
+}
+
+// diffMemberOf returns the roles of desired that are missing from observed,
+// and the roles of observed that are missing from desired, in order.
+func diffMemberOf(observed, desired []string) (grant, revoke []string) {
+	o := map[string]bool{}
+	for _, g := range observed {
+		o[g] = true
+	}
+	d := map[string]bool{}
+	for _, g := range desired {
+		d[g] = true
+		if !o[g] {
+			grant = append(grant, g)
+		}
+	}
+	for _, g := range observed {
+		if !d[g] {
+			revoke = append(revoke, g)
+		}
+	}
+	sort.Strings(grant)
+	sort.Strings(revoke)
+	return grant, revoke
+}
+
+// memberOfUpToDate returns true if the supplied observed memberships match the
+// supplied desired memberships. Memberships are always up to date when the
+// desired memberships are unset, because they're not managed.
+func memberOfUpToDate(observed, desired []string) bool {
+	if desired == nil {
+		return true
+	}
+	grant, revoke := diffMemberOf(observed, desired)
+	return len(grant) == 0 && len(revoke) == 0
+}
+
+// updateMemberOf grants the supplied role membership of the desired roles it's
+// missing from, and revokes its membership of any other roles.
+func (c *external) updateMemberOf(ctx context.Context, role string, desired []string) error {
+	if desired == nil {
+		return nil
+	}
+	observed, err := c.memberOf(ctx, role)
+	if err != nil {
+		return err
+	}
+	grant, revoke := diffMemberOf(observed, desired)
+	r := pq.QuoteIdentifier(role)
+	for _, g := range grant {
+		if err := c.db.Exec(ctx, xsql.Query{String: "GRANT " + pq.QuoteIdentifier(g) + " TO " + r}); err != nil {
+			return errors.Wrap(err, errGrantMemberOf)
+		}
+	}
+	for _, g := range revoke {
+		if err := c.db.Exec(ctx, xsql.Query{String: "REVOKE " + pq.QuoteIdentifier(g) + " FROM " + r}); err != nil {
+			return errors.Wrap(err, errRevokeMemberOf)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package role
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql/xsqltest"
+)
+
+// memberOfRows returns rows listing the supplied roles.
+func memberOfRows(roles ...string) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"rolname"})
+	for _, r := range roles {
+		rows.AddRow(r)
+	}
+	return rows
+}
+
+func memberOfRole(memberOf []string) *v1alpha1.Role {
+	return &v1alpha1.Role{
+		ObjectMeta: v1.ObjectMeta{
+			Annotations: map[string]string{meta.AnnotationKeyExternalName: "app"},
+		},
+		Spec: v1alpha1.RoleSpec{
+			ForProvider: v1alpha1.RoleParameters{MemberOf: memberOf},
+		},
+	}
+}
+
+func TestUpdateMemberOf(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		err        error
+		statements []string
+	}
+
+	cases := map[string]struct {
+		reason   string
+		memberOf []string
+		observed xsqltest.Result
+		want     want
+	}{
+		"Unmanaged": {
+			reason:   "Memberships should not be observed or changed when memberOf is unset",
+			observed: xsqltest.Result{Rows: memberOfRows("app_writers")},
+			want:     want{statements: []string{}},
+		},
+		"Add": {
+			reason:   "The role should be granted membership of the roles it's missing from",
+			memberOf: []string{"app_writers", "app_readers"},
+			observed: xsqltest.Result{Rows: memberOfRows("app_readers")},
+			want: want{statements: []string{
+				memberOfQuery,
+				`GRANT "app_writers" TO "app"`,
+			}},
+		},
+		"Remove": {
+			reason:   "The role's membership of roles it shouldn't be a member of should be revoked",
+			memberOf: []string{},
+			observed: xsqltest.Result{Rows: memberOfRows("app_writers", "app_readers")},
+			want: want{statements: []string{
+				memberOfQuery,
+				`REVOKE "app_readers" FROM "app"`,
+				`REVOKE "app_writers" FROM "app"`,
+			}},
+		},
+		"NoChange": {
+			reason:   "No memberships should be granted or revoked when they're as desired",
+			memberOf: []string{"app_readers"},
+			observed: xsqltest.Result{Rows: memberOfRows("app_readers")},
+			want:     want{statements: []string{memberOfQuery}},
+		},
+		"ErrSelect": {
+			reason:   "Errors selecting the role's memberships should be returned",
+			memberOf: []string{"app_readers"},
+			observed: xsqltest.Result{Err: errBoom},
+			want: want{
+				err:        errors.Wrap(errBoom, errSelectMemberOf),
+				statements: []string{memberOfQuery},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			db := xsqltest.New().
+				On(regexp.QuoteMeta(memberOfQuery), tc.observed).
+				On("^(GRANT|REVOKE) ", xsqltest.Result{})
+			e := external{db: db}
+
+			_, err := e.Update(context.Background(), memberOfRole(tc.memberOf))
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			db.AssertStatements(t, tc.want.statements...)
+		})
+	}
+}
+
+func TestObserveMemberOf(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		memberOf []string
+		observed []string
+		want     bool
+	}{
+		"Unmanaged": {
+			reason:   "A role whose memberships aren't managed should be up to date",
+			observed: []string{"app_writers"},
+			want:     true,
+		},
+		"UpToDate": {
+			reason:   "A role that is a member of exactly the desired roles should be up to date",
+			memberOf: []string{"app_writers", "app_readers"},
+			observed: []string{"app_readers", "app_writers"},
+			want:     true,
+		},
+		"Missing": {
+			reason:   "A role that is missing from a desired role should not be up to date",
+			memberOf: []string{"app_writers", "app_readers"},
+			observed: []string{"app_readers"},
+			want:     false,
+		},
+		"Extra": {
+			reason:   "A role that is a member of an undesired role should not be up to date",
+			memberOf: []string{},
+			observed: []string{"app_readers"},
+			want:     false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			db := xsqltest.New().
+				On(regexp.QuoteMeta(memberOfQuery), xsqltest.Result{Rows: memberOfRows(tc.observed...)}).
+				On("FROM pg_roles WHERE rolname = \\$1$", xsqltest.Result{})
+			e := external{db: db}
+
+			o, err := e.Observe(context.Background(), memberOfRole(tc.memberOf))
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): unexpected error: %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, o.ResourceUpToDate); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want ResourceUpToDate, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -24,15 +24,12 @@ import (
 
 	"github.com/lib/pq"
 	"github.com/pkg/errors"
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
-	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/password"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
@@ -41,36 +38,42 @@ import (
 	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/options"
 )
 
 const (
-	errTrackPCUsage = "cannot track ProviderConfig usage"
-	errGetPC        = "cannot get ProviderConfig"
-	errNoSecretRef  = "ProviderConfig does not reference a credentials Secret"
-	errGetSecret    = "cannot get credentials Secret"
-
 	errNotRole                 = "managed resource is not a Role custom resource"
 	errSelectRole              = "cannot select role"
 	errCreateRole              = "cannot create role"
 	errDropRole                = "cannot drop role"
 	errUpdateRole              = "cannot update role"
 	errGetPasswordSecretFailed = "cannot get password secret"
+	errGetConnectionSecret     = "cannot get connection secret"
 	errComparePrivileges       = "cannot compare desired and observed privileges"
 
 	maxConcurrency = 5
 )
 
 // Setup adds a controller that reconciles Role managed resources.
-func Setup(mgr ctrl.Manager, l logging.Logger) error {
+func Setup(mgr ctrl.Manager, o options.Options) error {
 	name := managed.ControllerName(v1alpha1.RoleGroupKind)
 
+	db := func(creds map[string][]byte, database string, po ...postgresql.Option) xsql.DB {
+		return o.DB(xsql.NewRetryingDB(postgresql.New(creds, database, po...), postgresql.IsTransient))
+	}
+
+	rec, err := o.Recorder(mgr, v1alpha1.RoleGroupVersionKind, func() resource.ProviderConfig { return &v1alpha1.ProviderConfig{} }, event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))
+	if err != nil {
+		return err
+	}
+
 	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1alpha1.RoleGroupVersionKind),
-		managed.WithExternalConnecter(&connector{kube: mgr.GetClient(), usage: t, newDB: postgresql.New}),
-		managed.WithLogger(l.WithValues("controller", name)),
+		managed.WithExternalConnecter(o.ExternalConnecter(options.NewConnectionLimitConnecter(&connector{kube: mgr.GetClient(), usage: t, newDB: db, ddl: o.DDLRateLimiter}, postgresql.IsTooManyConnections))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithPollInterval(10*time.Minute),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		managed.WithRecorder(rec))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
@@ -78,47 +81,32 @@ func Setup(mgr ctrl.Manager, l logging.Logger) error {
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: maxConcurrency,
 		}).
-		Complete(r)
+		Complete(options.NewConnectionLimitReconciler(r, mgr.GetClient(), func() resource.Managed { return &v1alpha1.Role{} }, o.ConnectionLimitBackoff))
 }
 
 type connector struct {
 	kube  client.Client
 	usage resource.Tracker
-	newDB func(creds map[string][]byte, database string) xsql.DB
+	newDB func(creds map[string][]byte, database string, o ...postgresql.Option) xsql.DB
+	ddl   *options.DDLRateLimiter
 }
 
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
-	cr, ok := mg.(*v1alpha1.Role)
-	if !ok {
+	if _, ok := mg.(*v1alpha1.Role); !ok {
 		return nil, errors.New(errNotRole)
 	}
 
-	if err := c.usage.Track(ctx, mg); err != nil {
-		return nil, errors.Wrap(err, errTrackPCUsage)
-	}
-
-	// ProviderConfigReference could theoretically be nil, but in practice the
-	// DefaultProviderConfig initializer will set it before we get here.
-	pc := &v1alpha1.ProviderConfig{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
-		return nil, errors.Wrap(err, errGetPC)
-	}
-
-	// We don't need to check the credentials source because we currently only
-	// support one source (PostgreSQLConnectionSecret), which is required and
-	// enforced by the ProviderConfig schema.
-	ref := pc.Spec.Credentials.ConnectionSecretRef
-	if ref == nil {
-		return nil, errors.New(errNoSecretRef)
+	conn, err := postgresql.NewConnector(c.kube, c.usage).Resolve(ctx, mg)
+	if err != nil {
+		return nil, err
 	}
+	pc, creds := conn.ProviderConfig, conn.Credentials
 
-	s := &corev1.Secret{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
-		return nil, errors.Wrap(err, errGetSecret)
-	}
+	// All resources using this ProviderConfig share a DDL rate limit.
+	ops, burst := pc.Spec.DDLRateLimit.Rate()
 
 	return &external{
-		db:   c.newDB(s.Data, ""),
+		db:   c.ddl.DB(pc.GetName(), ops, burst, c.newDB(creds, conn.DatabaseOrDefault(""), conn.Options()...)),
 		kube: c.kube,
 	}, nil
 }
@@ -206,9 +194,19 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		"rolcanlogin, " +
 		"rolreplication, " +
 		"rolbypassrls, " +
-		"rolconnlimit " +
+		"rolconnlimit, " +
+		validUntilColumn + " " +
 		"FROM pg_roles WHERE rolname = $1"
 
+	// We can't tell whether an invalid validUntil is up to date.
+	if vu := cr.Spec.ForProvider.ValidUntil; vu != nil {
+		if _, err := normalizeValidUntil(*vu); err != nil {
+			return managed.ExternalObservation{}, err
+		}
+	}
+
+	var validUntil *float64
+
 	err := c.db.Scan(ctx,
 		xsql.Query{
 			String: query,
@@ -224,6 +222,7 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		&observed.Privileges.Replication,
 		&observed.Privileges.BypassRls,
 		&observed.ConnectionLimit,
+		&validUntil,
 	)
 
 	if xsql.IsNoRows(err) {
@@ -232,6 +231,12 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	if err != nil {
 		return managed.ExternalObservation{}, errors.Wrap(err, errSelectRole)
 	}
+	vu := observedValidUntil(validUntil)
+	observed.ValidUntil = &vu
+
+	if observed.MemberOf, err = c.observeMemberOf(ctx, cr); err != nil {
+		return managed.ExternalObservation{}, err
+	}
 
 	_, pwdChanged, err := c.getPassword(ctx, cr)
 	if err != nil {
@@ -261,6 +266,11 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 	crn := pq.QuoteIdentifier(meta.GetExternalName(cr))
 	privs := privilegesToClauses(cr.Spec.ForProvider.Privileges)
 
+	opts, err := roleOptions(cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
 	pw, _, err := c.getPassword(ctx, cr)
 	if err != nil {
 		return managed.ExternalCreation{}, err
@@ -280,7 +290,7 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 			"CREATE ROLE %s PASSWORD %s %s",
 			crn,
 			pq.QuoteLiteral(pw),
-			strings.Join(privs, " "),
+			strings.Join(append(privs, opts...), " "),
 		),
 	}); err != nil {
 		return managed.ExternalCreation{}, errors.Wrap(err, errCreateRole)
@@ -350,6 +360,22 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		}
 	}
 
+	if vu := cr.Spec.ForProvider.ValidUntil; vu != nil {
+		n, err := normalizeValidUntil(*vu)
+		if err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+		if err := c.db.Exec(ctx, xsql.Query{
+			String: fmt.Sprintf("ALTER ROLE %s VALID UNTIL %s", crn, pq.QuoteLiteral(n)),
+		}); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateRole)
+		}
+	}
+
+	if err := c.updateMemberOf(ctx, meta.GetExternalName(cr), cr.Spec.ForProvider.MemberOf); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
 	// Only update connection details if password is changed
 	if pwchanged {
 		return managed.ExternalUpdate{
@@ -371,29 +397,76 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 	return errors.Wrap(err, errDropRole)
 }
 
+// roleOptions returns the clauses that set the supplied parameters' connection
+// limit and password expiry, if any.
+func roleOptions(p v1alpha1.RoleParameters) ([]string, error) {
+	// Never copy unvalidated user input to these clauses. They're passed
+	// directly into the query.
+	out := []string{}
+	if p.ConnectionLimit != nil {
+		out = append(out, fmt.Sprintf("CONNECTION LIMIT %d", int64(*p.ConnectionLimit)))
+	}
+	if p.ValidUntil != nil {
+		n, err := normalizeValidUntil(*p.ValidUntil)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, "VALID UNTIL "+pq.QuoteLiteral(n))
+	}
+	return out, nil
+}
+
+// int32Equal returns true if the supplied pointers are both nil, or point to
+// equal values.
+func int32Equal(a, b *int32) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// boolEqual returns true if the supplied pointers are both nil, or point to
+// equal values.
+func boolEqual(a, b *bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 func upToDate(observed *v1alpha1.RoleParameters, desired *v1alpha1.RoleParameters) bool {
-	if observed.ConnectionLimit != desired.ConnectionLimit {
+	if !int32Equal(observed.ConnectionLimit, desired.ConnectionLimit) {
+		return false
+	}
+	if !validUntilUpToDate(observed.ValidUntil, desired.ValidUntil) {
 		return false
 	}
-	if observed.Privileges.SuperUser != desired.Privileges.SuperUser {
+	if !memberOfUpToDate(observed.MemberOf, desired.MemberOf) {
 		return false
 	}
-	if observed.Privileges.Inherit != desired.Privileges.Inherit {
+	return privilegesUpToDate(observed.Privileges, desired.Privileges)
+}
+
+func privilegesUpToDate(observed, desired v1alpha1.RolePrivilege) bool {
+	if !boolEqual(observed.SuperUser, desired.SuperUser) {
 		return false
 	}
-	if observed.Privileges.CreateDb != desired.Privileges.CreateDb {
+	if !boolEqual(observed.Inherit, desired.Inherit) {
 		return false
 	}
-	if observed.Privileges.CreateRole != desired.Privileges.CreateRole {
+	if !boolEqual(observed.CreateDb, desired.CreateDb) {
 		return false
 	}
-	if observed.Privileges.Login != desired.Privileges.Login {
+	if !boolEqual(observed.CreateRole, desired.CreateRole) {
 		return false
 	}
-	if observed.Privileges.Replication != desired.Privileges.Replication {
+	if !boolEqual(observed.Login, desired.Login) {
 		return false
 	}
-	if observed.Privileges.BypassRls != desired.Privileges.BypassRls {
+	if !boolEqual(observed.Replication, desired.Replication) {
+		return false
+	}
+	if !boolEqual(observed.BypassRls, desired.BypassRls) {
 		return false
 	}
 	return true
@@ -434,6 +507,10 @@ func lateInit(observed *v1alpha1.RoleParameters, desired *v1alpha1.RoleParameter
 		desired.ConnectionLimit = observed.ConnectionLimit
 		li = true
 	}
+	if desired.ValidUntil == nil {
+		desired.ValidUntil = observed.ValidUntil
+		li = true
+	}
 
 	return li
 }
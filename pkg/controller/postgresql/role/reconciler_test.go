@@ -20,6 +20,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
@@ -28,7 +29,9 @@ import (
 	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -38,7 +41,10 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
 	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql/xsqltest"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/options"
 )
 
 type mockDB struct {
@@ -74,7 +80,7 @@ func TestConnect(t *testing.T) {
 	type fields struct {
 		kube  client.Client
 		usage resource.Tracker
-		newDB func(creds map[string][]byte, database string) xsql.DB
+		newDB func(creds map[string][]byte, database string, o ...postgresql.Option) xsql.DB
 	}
 
 	type args struct {
@@ -103,7 +109,7 @@ func TestConnect(t *testing.T) {
 			args: args{
 				mg: &v1alpha1.Role{},
 			},
-			want: errors.Wrap(errBoom, errTrackPCUsage),
+			want: errors.Wrap(errBoom, "cannot track ProviderConfig usage"),
 		},
 		"ErrGetProviderConfig": {
 			reason: "An error should be returned if we can't get our ProviderConfig",
@@ -122,7 +128,7 @@ func TestConnect(t *testing.T) {
 					},
 				},
 			},
-			want: errors.Wrap(errBoom, errGetPC),
+			want: errors.Wrap(errBoom, "cannot get ProviderConfig"),
 		},
 		"ErrMissingConnectionSecret": {
 			reason: "An error should be returned if our ProviderConfig doesn't specify a connection secret",
@@ -144,7 +150,7 @@ func TestConnect(t *testing.T) {
 					},
 				},
 			},
-			want: errors.New(errNoSecretRef),
+			want: errors.New("ProviderConfig does not reference a credentials Secret"),
 		},
 		"ErrGetConnectionSecret": {
 			reason: "An error should be returned if we can't get our ProviderConfig's connection secret",
@@ -171,7 +177,7 @@ func TestConnect(t *testing.T) {
 					},
 				},
 			},
-			want: errors.Wrap(errBoom, errGetSecret),
+			want: errors.Wrap(errBoom, "cannot get credentials Secret"),
 		},
 	}
 
@@ -186,6 +192,34 @@ func TestConnect(t *testing.T) {
 	}
 }
 
+// observedPrivileges returns the privileges scanRole observes.
+func observedPrivileges() v1alpha1.RolePrivilege {
+	f := false
+	return v1alpha1.RolePrivilege{
+		SuperUser:   &f,
+		Inherit:     &f,
+		CreateDb:    &f,
+		CreateRole:  &f,
+		Login:       &f,
+		Replication: &f,
+		BypassRls:   &f,
+	}
+}
+
+// scanRole returns a scan function that observes a role with no privileges,
+// the supplied connection limit, and a password that expires at the supplied
+// seconds since the Unix epoch.
+func scanRole(connLimit int32, validUntil *float64) func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+		for _, d := range dest[:7] {
+			*d.(**bool) = new(bool)
+		}
+		*dest[7].(**int32) = &connLimit
+		*dest[8].(**float64) = validUntil
+		return nil
+	}
+}
+
 func TestObserve(t *testing.T) {
 	errBoom := errors.New("boom")
 
@@ -273,6 +307,93 @@ func TestObserve(t *testing.T) {
 				err: nil,
 			},
 		},
+		"UpToDate": {
+			reason: "We should return ResourceUpToDate=true if the connection limit and expiry match, even in another time zone",
+			fields: fields{
+				db: mockDB{MockScan: scanRole(10, pointer.Float64Ptr(1893456000))},
+			},
+			args: args{
+				mg: &v1alpha1.Role{
+					Spec: v1alpha1.RoleSpec{
+						ForProvider: v1alpha1.RoleParameters{
+							Privileges:      observedPrivileges(),
+							ConnectionLimit: pointer.Int32Ptr(10),
+							ValidUntil:      pointer.StringPtr("2029-12-31T19:00:00-05:00"),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+			},
+		},
+		"ConnectionLimitDrift": {
+			reason: "We should return ResourceUpToDate=false if the connection limit was changed",
+			fields: fields{
+				db: mockDB{MockScan: scanRole(5, pointer.Float64Ptr(1893456000))},
+			},
+			args: args{
+				mg: &v1alpha1.Role{
+					Spec: v1alpha1.RoleSpec{
+						ForProvider: v1alpha1.RoleParameters{
+							Privileges:      observedPrivileges(),
+							ConnectionLimit: pointer.Int32Ptr(10),
+							ValidUntil:      pointer.StringPtr("2030-01-01T00:00:00Z"),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
+		"ValidUntilDrift": {
+			reason: "We should return ResourceUpToDate=false if the password's expiry was changed",
+			fields: fields{
+				db: mockDB{MockScan: scanRole(10, nil)},
+			},
+			args: args{
+				mg: &v1alpha1.Role{
+					Spec: v1alpha1.RoleSpec{
+						ForProvider: v1alpha1.RoleParameters{
+							Privileges:      observedPrivileges(),
+							ConnectionLimit: pointer.Int32Ptr(10),
+							ValidUntil:      pointer.StringPtr("2030-01-01T00:00:00Z"),
+						},
+					},
+				},
+			},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
+		"ErrParseValidUntil": {
+			reason: "We should return an error if the password's expiry can't be parsed",
+			fields: fields{
+				db: mockDB{MockScan: scanRole(10, nil)},
+			},
+			args: args{
+				mg: &v1alpha1.Role{
+					Spec: v1alpha1.RoleSpec{
+						ForProvider: v1alpha1.RoleParameters{
+							ValidUntil: pointer.StringPtr("tomorrow"),
+						},
+					},
+				},
+			},
+			want: want{
+				err: func() error { _, err := normalizeValidUntil("tomorrow"); return err }(),
+			},
+		},
 		"PasswordChanged": {
 			reason: "We should return ResourceUpToDate=false if the password changed",
 			fields: fields{
@@ -501,6 +622,85 @@ func TestCreate(t *testing.T) {
 	}
 }
 
+func TestCreateQuery(t *testing.T) {
+	yes, no := true, false
+
+	cases := map[string]struct {
+		reason string
+		params v1alpha1.RoleParameters
+		want   string
+	}{
+		"Defaults": {
+			reason: "Unset privileges and options should be left to the server's defaults",
+			want:   `CREATE ROLE "example" PASSWORD 'test1234' `,
+		},
+		"Login": {
+			reason: "A role that may log in should be created with LOGIN",
+			params: v1alpha1.RoleParameters{
+				Privileges: v1alpha1.RolePrivilege{Login: &yes},
+			},
+			want: `CREATE ROLE "example" PASSWORD 'test1234' LOGIN`,
+		},
+		"NoLogin": {
+			reason: "A role that may not log in should be created with NOLOGIN",
+			params: v1alpha1.RoleParameters{
+				Privileges: v1alpha1.RolePrivilege{Login: &no},
+			},
+			want: `CREATE ROLE "example" PASSWORD 'test1234' NOLOGIN`,
+		},
+		"Everything": {
+			reason: "Privileges should be rendered in a stable order, followed by options",
+			params: v1alpha1.RoleParameters{
+				ConnectionLimit: pointer.Int32Ptr(10),
+				ValidUntil:      pointer.StringPtr("infinity"),
+				Privileges: v1alpha1.RolePrivilege{
+					SuperUser:   &no,
+					Inherit:     &yes,
+					CreateDb:    &yes,
+					CreateRole:  &no,
+					Login:       &yes,
+					Replication: &no,
+					BypassRls:   &no,
+				},
+			},
+			want: `CREATE ROLE "example" PASSWORD 'test1234' NOSUPERUSER INHERIT CREATEDB NOCREATEROLE LOGIN NOREPLICATION NOBYPASSRLS CONNECTION LIMIT 10 VALID UNTIL 'infinity'`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			db := xsqltest.New().On("^CREATE ROLE ", xsqltest.Result{})
+			e := external{
+				db: db,
+				kube: &test.MockClient{
+					MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+						s := corev1.Secret{Data: map[string][]byte{"password": []byte("test1234")}}
+						s.DeepCopyInto(obj.(*corev1.Secret))
+						return nil
+					},
+				},
+			}
+			tc.params.PasswordSecretRef = &xpv1.SecretKeySelector{
+				SecretReference: xpv1.SecretReference{Name: "example"},
+				Key:             "password",
+			}
+			cr := &v1alpha1.Role{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+				},
+				Spec: v1alpha1.RoleSpec{ForProvider: tc.params},
+			}
+
+			if _, err := e.Create(context.Background(), cr); err != nil {
+				t.Fatalf("\n%s\ne.Create(...): unexpected error: %s", tc.reason, err)
+			}
+			if diff := cmp.Diff([]string{tc.want}, db.Statements()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want statements, +got statements:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestUpdate(t *testing.T) {
 	errBoom := errors.New("boom")
 
@@ -813,6 +1013,36 @@ func TestUpdate(t *testing.T) {
 				err: nil,
 			},
 		},
+		"UpdateConnectionLimitAndValidUntil": {
+			reason: "The connection limit and password expiry should be set, with the expiry in UTC",
+			fields: fields{
+				db: &mockDB{
+					MockExec: func(ctx context.Context, q xsql.Query) error {
+						switch q.String {
+						case `ALTER ROLE "example" CONNECTION LIMIT 10`, `ALTER ROLE "example" VALID UNTIL '2030-01-01T00:00:00Z'`:
+							return nil
+						}
+						return errors.Errorf("unexpected query %q", q.String)
+					},
+				},
+			},
+			args: args{
+				mg: &v1alpha1.Role{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							meta.AnnotationKeyExternalName: "example",
+						},
+					},
+					Spec: v1alpha1.RoleSpec{
+						ForProvider: v1alpha1.RoleParameters{
+							ConnectionLimit: pointer.Int32Ptr(10),
+							ValidUntil:      pointer.StringPtr("2030-01-01T09:00:00+09:00"),
+						},
+					},
+				},
+			},
+			want: want{},
+		},
 		"ErrComparePrivs": {
 			reason: "We should error if observed privilege list is shorter than desired privilege list",
 			fields: fields{
@@ -886,6 +1116,214 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestUpdatePasswordQuery(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		err        error
+		statements []string
+		c          managed.ExternalUpdate
+	}
+
+	cases := map[string]struct {
+		reason     string
+		source     string
+		connection func(obj client.Object) error
+		want       want
+	}{
+		"Rotated": {
+			reason:     "A password that differs from the one in the connection secret should be set, escaped, and published",
+			source:     "it's-new",
+			connection: connectionSecret("old"),
+			want: want{
+				statements: []string{`ALTER ROLE "example" PASSWORD 'it''s-new'`},
+				c: managed.ExternalUpdate{
+					ConnectionDetails: managed.ConnectionDetails{
+						xpv1.ResourceCredentialsSecretUserKey:     []byte("example"),
+						xpv1.ResourceCredentialsSecretPasswordKey: []byte("it's-new"),
+						xpv1.ResourceCredentialsSecretEndpointKey: []byte("localhost"),
+						xpv1.ResourceCredentialsSecretPortKey:     []byte("5432"),
+					},
+				},
+			},
+		},
+		"NotYetPublished": {
+			reason:     "The password should be set and published if the connection secret doesn't exist yet",
+			source:     "new",
+			connection: func(_ client.Object) error { return kerrors.NewNotFound(schema.GroupResource{}, "connection-secret") },
+			want: want{
+				statements: []string{`ALTER ROLE "example" PASSWORD 'new'`},
+				c: managed.ExternalUpdate{
+					ConnectionDetails: managed.ConnectionDetails{
+						xpv1.ResourceCredentialsSecretUserKey:     []byte("example"),
+						xpv1.ResourceCredentialsSecretPasswordKey: []byte("new"),
+						xpv1.ResourceCredentialsSecretEndpointKey: []byte("localhost"),
+						xpv1.ResourceCredentialsSecretPortKey:     []byte("5432"),
+					},
+				},
+			},
+		},
+		"Unchanged": {
+			reason:     "A password that matches the one in the connection secret should be neither set nor published",
+			source:     "same",
+			connection: connectionSecret("same"),
+			want:       want{statements: []string{}},
+		},
+		"ErrGetConnectionSecret": {
+			reason:     "Errors reading the connection secret should be returned",
+			source:     "new",
+			connection: func(_ client.Object) error { return errBoom },
+			want: want{
+				err:        errors.Wrap(errBoom, errGetConnectionSecret),
+				statements: []string{},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			db := xsqltest.New().On("^ALTER ROLE ", xsqltest.Result{})
+			e := external{
+				db: &recordingDB{DB: db, mockDB: mockDB{}},
+				kube: &test.MockClient{
+					MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+						if key.Name == "connection-secret" {
+							return tc.connection(obj)
+						}
+						s := corev1.Secret{Data: map[string][]byte{"password": []byte(tc.source)}}
+						s.DeepCopyInto(obj.(*corev1.Secret))
+						return nil
+					},
+				},
+			}
+			cr := &v1alpha1.Role{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+				},
+				Spec: v1alpha1.RoleSpec{
+					ResourceSpec: xpv1.ResourceSpec{
+						WriteConnectionSecretToReference: &xpv1.SecretReference{Name: "connection-secret"},
+					},
+					ForProvider: v1alpha1.RoleParameters{
+						PasswordSecretRef: &xpv1.SecretKeySelector{
+							SecretReference: xpv1.SecretReference{Name: "source"},
+							Key:             "password",
+						},
+					},
+				},
+			}
+
+			got, err := e.Update(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, got); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			db.AssertStatements(t, tc.want.statements...)
+
+			// The password must never reach the logs.
+			for _, q := range db.Queries() {
+				if s, _ := xsql.Redact(q); strings.Contains(s, tc.source) {
+					t.Errorf("\n%s\nxsql.Redact(...): logged statement %q contains the password", tc.reason, s)
+				}
+			}
+		})
+	}
+}
+
+// connectionSecret returns a function that populates a connection secret with
+// the supplied password.
+func connectionSecret(pw string) func(obj client.Object) error {
+	return func(obj client.Object) error {
+		s := corev1.Secret{Data: map[string][]byte{xpv1.ResourceCredentialsSecretPasswordKey: []byte(pw)}}
+		s.DeepCopyInto(obj.(*corev1.Secret))
+		return nil
+	}
+}
+
+// A recordingDB records queries using an xsqltest.DB, but returns the
+// connection details of a mockDB.
+type recordingDB struct {
+	*xsqltest.DB
+	mockDB mockDB
+}
+
+func (d *recordingDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return d.mockDB.GetConnectionDetails(username, password)
+}
+
+func TestDecisionsRedactPassword(t *testing.T) {
+	decisions := []options.Decision{}
+	o := options.Options{Decisions: options.DecisionSinkFn(func(d options.Decision) error {
+		decisions = append(decisions, d)
+		return nil
+	})}
+
+	e := &external{
+		db: o.DB(&recordingDB{DB: xsqltest.New().On("^(CREATE|ALTER) ROLE ", xsqltest.Result{}), mockDB: mockDB{}}),
+		kube: &test.MockClient{
+			MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+				if key.Name == "connection-secret" {
+					return connectionSecret("old-s3cret")(obj)
+				}
+				s := corev1.Secret{Data: map[string][]byte{"password": []byte("new-s3cret")}}
+				s.DeepCopyInto(obj.(*corev1.Secret))
+				return nil
+			},
+		},
+	}
+	c := options.NewDecisionConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+		return e, nil
+	}), o.Decisions)
+
+	cr := &v1alpha1.Role{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "example",
+			Annotations: map[string]string{meta.AnnotationKeyExternalName: "example"},
+		},
+		Spec: v1alpha1.RoleSpec{
+			ResourceSpec: xpv1.ResourceSpec{
+				WriteConnectionSecretToReference: &xpv1.SecretReference{Name: "connection-secret"},
+			},
+			ForProvider: v1alpha1.RoleParameters{
+				PasswordSecretRef: &xpv1.SecretKeySelector{
+					SecretReference: xpv1.SecretReference{Name: "source"},
+					Key:             "password",
+				},
+			},
+		},
+	}
+
+	ec, err := c.Connect(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("c.Connect(...): %s", err)
+	}
+	if _, err := ec.Create(context.Background(), cr); err != nil {
+		t.Fatalf("ec.Create(...): %s", err)
+	}
+	if _, err := ec.Update(context.Background(), cr); err != nil {
+		t.Fatalf("ec.Update(...): %s", err)
+	}
+
+	want := [][]string{
+		{`CREATE ROLE "example" PASSWORD '<redacted>' `},
+		{`ALTER ROLE "example" PASSWORD '<redacted>'`},
+	}
+	got := [][]string{}
+	for _, d := range decisions {
+		got = append(got, d.SQL)
+		for _, stmt := range d.SQL {
+			if strings.Contains(stmt, "s3cret") {
+				t.Errorf("%s decision: recorded statement %q contains the password", d.Action, stmt)
+			}
+		}
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("decisions: -want SQL, +got SQL:\n%s\n", diff)
+	}
+}
+
 func TestDelete(t *testing.T) {
 	errBoom := errors.New("boom")
 
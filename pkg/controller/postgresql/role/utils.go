@@ -56,7 +56,7 @@ func (c *external) getPassword(ctx context.Context, role *v1alpha1.Role) (newPwd
 	// the output secret may not exist yet, so we can skip returning an
 	// error if the error is NotFound
 	if err := c.kube.Get(ctx, nn, s); resource.IgnoreNotFound(err) != nil {
-		return "", false, err
+		return "", false, errors.Wrap(err, errGetConnectionSecret)
 	}
 	// if newPwd was set to some value, compare value in output secret with
 	// newPwd
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package role
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const errParseValidUntil = "cannot parse validUntil: must be an RFC 3339 timestamp or 'infinity'"
+
+// validUntilInfinity is the VALID UNTIL value of a password that never
+// expires.
+const validUntilInfinity = "infinity"
+
+// validUntilColumn selects when a role's password expires as seconds since the
+// Unix epoch, which unlike a timestamp doesn't depend on the session's time
+// zone. It is NULL if the password never expires.
+const validUntilColumn = "CASE WHEN isfinite(rolvaliduntil) THEN extract(epoch FROM rolvaliduntil) END"
+
+// normalizeValidUntil returns the supplied VALID UNTIL value in UTC at second
+// precision, so that values may be compared as strings. PostgreSQL interprets
+// the normalized value the same way regardless of the server's time zone.
+func normalizeValidUntil(s string) (string, error) {
+	if s == validUntilInfinity {
+		return s, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return "", errors.Wrap(err, errParseValidUntil)
+	}
+	return t.UTC().Truncate(time.Second).Format(time.RFC3339), nil
+}
+
+// observedValidUntil returns the normalized VALID UNTIL value of a password
+// that expires at the supplied seconds since the Unix epoch, or never if nil.
+func observedValidUntil(epoch *float64) string {
+	if epoch == nil {
+		return validUntilInfinity
+	}
+	return time.Unix(int64(math.Floor(*epoch)), 0).UTC().Format(time.RFC3339)
+}
+
+// validUntilUpToDate returns true if the supplied normalized observed value
+// matches the supplied desired value.
+func validUntilUpToDate(observed, desired *string) bool {
+	if desired == nil {
+		return true
+	}
+	d, err := normalizeValidUntil(*desired)
+	if err != nil {
+		return false
+	}
+	return observed != nil && *observed == d
+}
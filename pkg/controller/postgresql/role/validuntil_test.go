@@ -0,0 +1,153 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package role
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/pointer"
+)
+
+func TestNormalizeValidUntil(t *testing.T) {
+	type want struct {
+		s   string
+		err bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		s      string
+		want   want
+	}{
+		"UTC": {
+			reason: "UTC timestamps should be unchanged",
+			s:      "2030-01-01T00:00:00Z",
+			want:   want{s: "2030-01-01T00:00:00Z"},
+		},
+		"Offset": {
+			reason: "Timestamps with a UTC offset should be converted to UTC",
+			s:      "2030-01-01T09:30:00+09:30",
+			want:   want{s: "2030-01-01T00:00:00Z"},
+		},
+		"OffsetAcrossDays": {
+			reason: "Timestamps with a negative UTC offset should be converted to UTC, even if that changes their date",
+			s:      "2029-12-31T19:00:00-05:00",
+			want:   want{s: "2030-01-01T00:00:00Z"},
+		},
+		"FractionalSeconds": {
+			reason: "Fractional seconds should be truncated",
+			s:      "2030-01-01T00:00:00.999Z",
+			want:   want{s: "2030-01-01T00:00:00Z"},
+		},
+		"Infinity": {
+			reason: "Passwords that never expire should be unchanged",
+			s:      "infinity",
+			want:   want{s: "infinity"},
+		},
+		"Invalid": {
+			reason: "Timestamps that aren't RFC 3339 should be rejected",
+			s:      "2030-01-01 00:00:00",
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := normalizeValidUntil(tc.s)
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nnormalizeValidUntil(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.s, got); diff != "" {
+				t.Errorf("\n%s\nnormalizeValidUntil(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObservedValidUntil(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		epoch  *float64
+		want   string
+	}{
+		"Never": {
+			reason: "A password with no expiry should never expire",
+			want:   "infinity",
+		},
+		"Expires": {
+			reason: "An expiry should be converted to a UTC timestamp at second precision",
+			epoch:  func() *float64 { f := 1893456000.5; return &f }(),
+			want:   "2030-01-01T00:00:00Z",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, observedValidUntil(tc.epoch)); diff != "" {
+				t.Errorf("\n%s\nobservedValidUntil(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidUntilUpToDate(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		observed *string
+		desired  *string
+		want     bool
+	}{
+		"Unmanaged": {
+			reason:   "An expiry that isn't specified should always be up to date",
+			observed: pointer.StringPtr("2030-01-01T00:00:00Z"),
+			want:     true,
+		},
+		"SameInstantDifferentZone": {
+			reason:   "The same instant expressed in another time zone should be up to date",
+			observed: pointer.StringPtr("2030-01-01T00:00:00Z"),
+			desired:  pointer.StringPtr("2030-01-01T01:00:00+01:00"),
+			want:     true,
+		},
+		"Expired": {
+			reason:   "A different expiry should not be up to date",
+			observed: pointer.StringPtr("2030-01-01T00:00:00Z"),
+			desired:  pointer.StringPtr("2031-01-01T00:00:00Z"),
+			want:     false,
+		},
+		"NeverExpires": {
+			reason:   "A password that should never expire but does should not be up to date",
+			observed: pointer.StringPtr("2030-01-01T00:00:00Z"),
+			desired:  pointer.StringPtr("infinity"),
+			want:     false,
+		},
+		"Invalid": {
+			reason:   "An invalid expiry should not be up to date",
+			observed: pointer.StringPtr("2030-01-01T00:00:00Z"),
+			desired:  pointer.StringPtr("soon"),
+			want:     false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := validUntilUpToDate(tc.observed, tc.desired); got != tc.want {
+				t.Errorf("\n%s\nvalidUntilUpToDate(...): want %t, got %t", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
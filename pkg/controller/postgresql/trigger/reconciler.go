@@ -0,0 +1,379 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/options"
+)
+
+const (
+	errNotTrigger       = "managed resource is not a Trigger custom resource"
+	errInvalidWhen      = "invalid when condition"
+	errTableNotFound    = "table %s does not exist"
+	errFunctionNotFound = "function %s() does not exist"
+	errSelectTrigger    = "cannot select trigger"
+	errCreateTrigger    = "cannot create trigger"
+	errEnableTrigger    = "cannot enable or disable trigger"
+	errDropTrigger      = "cannot drop trigger"
+
+	maxConcurrency = 5
+)
+
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const pqUndefinedTable = pq.ErrorCode("42P01")
+
+// Setup adds a controller that reconciles Trigger managed resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := managed.ControllerName(v1alpha1.TriggerGroupKind)
+
+	db := func(creds map[string][]byte, database string, po ...postgresql.Option) xsql.DB {
+		return o.DB(xsql.NewRetryingDB(postgresql.New(creds, database, po...), postgresql.IsTransient))
+	}
+
+	rec, err := o.Recorder(mgr, v1alpha1.TriggerGroupVersionKind, func() resource.ProviderConfig { return &v1alpha1.ProviderConfig{} }, event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))
+	if err != nil {
+		return err
+	}
+
+	newObj := func() resource.Managed { return &v1alpha1.Trigger{} }
+	t := resource.NewProviderConfigUsageTracker(mgr.GetClient(), &v1alpha1.ProviderConfigUsage{})
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.TriggerGroupVersionKind),
+		managed.WithExternalConnecter(o.ExternalConnecter(options.NewConnectionLimitConnecter(&connector{kube: mgr.GetClient(), usage: t, newDB: db, ddl: o.DDLRateLimiter}, postgresql.IsTooManyConnections))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(10*time.Minute),
+		managed.WithRecorder(rec))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.Trigger{}).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrency,
+		}).
+		Complete(options.NewDatabaseAllowlistReconciler(
+			options.NewConnectionLimitReconciler(r, mgr.GetClient(), newObj, o.ConnectionLimitBackoff),
+			mgr.GetClient(), newObj, checkDatabase(postgresql.NewConnector(mgr.GetClient(), nil)), postgresql.IsDatabaseNotAllowed, 10*time.Minute))
+}
+
+// checkDatabase returns a function that returns an error if the ProviderConfig
+// of a Trigger does not allow the database it targets.
+func checkDatabase(c *postgresql.Connector) func(ctx context.Context, mg resource.Managed) error {
+	return func(ctx context.Context, mg resource.Managed) error {
+		cr, ok := mg.(*v1alpha1.Trigger)
+		if !ok {
+			return errors.New(errNotTrigger)
+		}
+		database := ""
+		if cr.Spec.ForProvider.Database != nil {
+			database = *cr.Spec.ForProvider.Database
+		}
+		return c.CheckDatabase(ctx, mg, database)
+	}
+}
+
+type connector struct {
+	kube  client.Client
+	usage resource.Tracker
+	newDB func(creds map[string][]byte, database string, o ...postgresql.Option) xsql.DB
+	ddl   *options.DDLRateLimiter
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Trigger)
+	if !ok {
+		return nil, errors.New(errNotTrigger)
+	}
+
+	database := ""
+	if cr.Spec.ForProvider.Database != nil {
+		database = *cr.Spec.ForProvider.Database
+	}
+
+	conn, err := postgresql.NewConnector(c.kube, c.usage).Resolve(ctx, mg, postgresql.AllowDatabase(database))
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.CheckDatabase(database); err != nil {
+		return nil, err
+	}
+	pc, creds := conn.ProviderConfig, conn.Credentials
+
+	// All resources using this ProviderConfig share a DDL rate limit.
+	ops, burst := pc.Spec.DDLRateLimit.Rate()
+
+	return &external{db: c.ddl.DB(pc.GetName(), ops, burst, c.newDB(creds, conn.DatabaseOrDefault(database), conn.Options()...))}, nil
+}
+
+type external struct{ db xsql.DB }
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Trigger)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotTrigger)
+	}
+
+	// If the trigger exists, it will have all of these properties. Triggers
+	// are disabled when tgenabled is 'D'; they're enabled in some form
+	// (origin, replica, or always) otherwise.
+	observed := v1alpha1.TriggerParameters{
+		Enabled: new(bool),
+	}
+
+	// to_regclass returns NULL rather than raising an error if the table does
+	// not exist, so a missing table simply matches no rows.
+	query := "SELECT t.tgenabled <> 'D' " +
+		"FROM pg_trigger t " +
+		"WHERE t.tgname = $1 AND t.tgrelid = to_regclass($2) AND NOT t.tgisinternal"
+
+	err := c.db.Scan(ctx, xsql.Query{
+		String:     query,
+		Parameters: []interface{}{meta.GetExternalName(cr), tableIdentifier(cr.Spec.ForProvider)},
+	}, observed.Enabled)
+
+	// A trigger cannot exist if its database or table does not.
+	if xsql.IsNoRows(err) || postgresql.IsInvalidCatalog(err) || isUndefinedTable(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSelectTrigger)
+	}
+
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists: true,
+
+		// NOTE(negz): The ordering is important here. We want to late init any
+		// values that weren't supplied before we determine if an update is
+		// required.
+		ResourceLateInitialized: lateInit(observed, &cr.Spec.ForProvider),
+		ResourceUpToDate:        upToDate(observed, cr.Spec.ForProvider),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Trigger)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotTrigger)
+	}
+
+	p := cr.Spec.ForProvider
+	if p.When != nil {
+		if err := validateWhen(*p.When); err != nil {
+			return managed.ExternalCreation{}, errors.Wrap(err, errInvalidWhen)
+		}
+	}
+
+	// CREATE TRIGGER would fail anyway, but with a less helpful error.
+	exists, err := postgresql.Exists(ctx, c.db, postgresql.Relation, tableIdentifier(p))
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateTrigger)
+	}
+	if !exists {
+		return managed.ExternalCreation{}, errors.Errorf(errTableNotFound, tableIdentifier(p))
+	}
+	exists, err = postgresql.Exists(ctx, c.db, postgresql.FunctionSignature, functionIdentifier(p)+"()")
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateTrigger)
+	}
+	if !exists {
+		return managed.ExternalCreation{}, errors.Errorf(errFunctionNotFound, functionIdentifier(p))
+	}
+
+	ql := []xsql.Query{createQuery(meta.GetExternalName(cr), p)}
+	if e := cr.Spec.ForProvider.Enabled; e != nil && !*e {
+		ql = append(ql, enableQuery(meta.GetExternalName(cr), cr.Spec.ForProvider, false))
+	}
+
+	err = c.db.ExecTx(ctx, ql)
+	return managed.ExternalCreation{}, errors.Wrap(err, errCreateTrigger)
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Trigger)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotTrigger)
+	}
+
+	// Enabled is the only field that may be updated.
+	if cr.Spec.ForProvider.Enabled == nil {
+		return managed.ExternalUpdate{}, nil
+	}
+
+	err := c.db.Exec(ctx, enableQuery(meta.GetExternalName(cr), cr.Spec.ForProvider, *cr.Spec.ForProvider.Enabled))
+	return managed.ExternalUpdate{}, errors.Wrap(err, errEnableTrigger)
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Trigger)
+	if !ok {
+		return errors.New(errNotTrigger)
+	}
+
+	err := c.db.Exec(ctx, xsql.Query{String: "DROP TRIGGER IF EXISTS " + pq.QuoteIdentifier(meta.GetExternalName(cr)) + " ON " + tableIdentifier(cr.Spec.ForProvider)})
+
+	// Dropping the table drops its triggers too.
+	if isUndefinedTable(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDropTrigger)
+}
+
+// isUndefinedTable returns true if passed a pq error indicating that a table
+// does not exist.
+func isUndefinedTable(err error) bool {
+	pqe := &pq.Error{}
+	return errors.As(err, &pqe) && pqe.Code == pqUndefinedTable
+}
+
+// tableIdentifier returns the quoted, optionally schema qualified, name of the
+// trigger's table.
+func tableIdentifier(p v1alpha1.TriggerParameters) string {
+	return postgresql.QualifiedName(p.Schema, p.Table)
+}
+
+// functionIdentifier returns the quoted, optionally schema qualified, name of
+// the trigger's function.
+func functionIdentifier(p v1alpha1.TriggerParameters) string {
+	return postgresql.QualifiedName(p.FunctionSchema, p.Function)
+}
+
+// validateWhen returns an error if the supplied WHEN condition could escape
+// the parentheses it is spliced into, or terminate the CREATE TRIGGER
+// statement. Quoted literals and identifiers are skipped, but dollar quoting
+// and comments are rejected outright.
+func validateWhen(when string) error { //nolint:gocyclo
+	// NOTE(negz): This is a small lexer, so it has a lot of branches.
+	depth := 0
+	for i := 0; i < len(when); i++ {
+		switch c := when[i]; {
+		case c == '\'' || c == '"':
+			// An E'...' literal may escape its quotes with a backslash.
+			escapes := c == '\'' && i > 0 && (when[i-1] == 'E' || when[i-1] == 'e')
+			j := i + 1
+			for ; j < len(when); j++ {
+				if escapes && when[j] == '\\' {
+					j++
+					continue
+				}
+				if when[j] != c {
+					continue
+				}
+				// A doubled quote is an escaped quote.
+				if j+1 < len(when) && when[j+1] == c {
+					j++
+					continue
+				}
+				break
+			}
+			if j >= len(when) {
+				return errors.New("unterminated quoted literal or identifier")
+			}
+			i = j
+		case c == ';':
+			return errors.New("semicolons are not allowed")
+		case c == '$':
+			return errors.New("dollar quoted literals are not allowed")
+		case strings.HasPrefix(when[i:], "--") || strings.HasPrefix(when[i:], "/*"):
+			return errors.New("comments are not allowed")
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth < 0 {
+				return errors.New("unbalanced parentheses")
+			}
+		}
+	}
+	if depth != 0 {
+		return errors.New("unbalanced parentheses")
+	}
+	return nil
+}
+
+func createQuery(name string, p v1alpha1.TriggerParameters) xsql.Query {
+	events := make([]string, len(p.Events))
+	for i, e := range p.Events {
+		events[i] = string(e)
+	}
+
+	level := v1alpha1.TriggerForEachStatement
+	if p.ForEach != nil {
+		level = *p.ForEach
+	}
+
+	var b strings.Builder
+	b.WriteString("CREATE TRIGGER ")
+	b.WriteString(pq.QuoteIdentifier(name))
+	b.WriteString(" " + string(p.Timing) + " ")
+	b.WriteString(strings.Join(events, " OR "))
+	b.WriteString(" ON " + tableIdentifier(p))
+	b.WriteString(" FOR EACH " + string(level))
+
+	if p.When != nil {
+		b.WriteString(" WHEN (" + *p.When + ")")
+	}
+
+	// Trigger function arguments are always string literals.
+	args := make([]string, len(p.Arguments))
+	for i, a := range p.Arguments {
+		args[i] = pq.QuoteLiteral(a)
+	}
+	b.WriteString(" EXECUTE FUNCTION " + functionIdentifier(p) + "(" + strings.Join(args, ", ") + ")")
+
+	return xsql.Query{String: b.String()}
+}
+
+func enableQuery(name string, p v1alpha1.TriggerParameters, enabled bool) xsql.Query {
+	action := "ENABLE"
+	if !enabled {
+		action = "DISABLE"
+	}
+	return xsql.Query{String: "ALTER TABLE " + tableIdentifier(p) + " " + action + " TRIGGER " + pq.QuoteIdentifier(name)}
+}
+
+func upToDate(observed, desired v1alpha1.TriggerParameters) bool {
+	// Enabled is the only field that may drift; the others are immutable.
+	return desired.Enabled == nil || *desired.Enabled == *observed.Enabled
+}
+
+func lateInit(observed v1alpha1.TriggerParameters, desired *v1alpha1.TriggerParameters) bool {
+	if desired.Enabled == nil {
+		desired.Enabled = observed.Enabled
+		return true
+	}
+	return false
+}
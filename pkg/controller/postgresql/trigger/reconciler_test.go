@@ -0,0 +1,579 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-sql/apis/postgresql/v1alpha1"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/postgresql"
+	"github.com/crossplane-contrib/provider-sql/pkg/clients/xsql"
+)
+
+type mockDB struct {
+	MockExec                 func(ctx context.Context, q xsql.Query) error
+	MockExecTx               func(ctx context.Context, ql []xsql.Query) error
+	MockScan                 func(ctx context.Context, q xsql.Query, dest ...interface{}) error
+	MockGetConnectionDetails func(username, password string) managed.ConnectionDetails
+}
+
+func (m mockDB) Exec(ctx context.Context, q xsql.Query) error {
+	return m.MockExec(ctx, q)
+}
+func (m mockDB) ExecTx(ctx context.Context, ql []xsql.Query) error {
+	return m.MockExecTx(ctx, ql)
+}
+func (m mockDB) Scan(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+	return m.MockScan(ctx, q, dest...)
+}
+func (m mockDB) Query(ctx context.Context, q xsql.Query) (*sql.Rows, error) {
+	return &sql.Rows{}, nil
+}
+func (m mockDB) GetConnectionDetails(username, password string) managed.ConnectionDetails {
+	return m.MockGetConnectionDetails(username, password)
+}
+
+func trigger(name string, p v1alpha1.TriggerParameters) *v1alpha1.Trigger {
+	cr := &v1alpha1.Trigger{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha1.TriggerSpec{
+			ResourceSpec: xpv1.ResourceSpec{ProviderConfigReference: &xpv1.Reference{}},
+			ForProvider:  p,
+		},
+	}
+	meta.SetExternalName(cr, name)
+	return cr
+}
+
+func TestConnect(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type fields struct {
+		kube  client.Client
+		usage resource.Tracker
+		newDB func(creds map[string][]byte, database string, o ...postgresql.Option) xsql.DB
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		mg     resource.Managed
+		want   error
+	}{
+		"ErrNotTrigger": {
+			reason: "An error should be returned if the managed resource is not a *Trigger",
+			want:   errors.New(errNotTrigger),
+		},
+		"ErrTrackProviderConfigUsage": {
+			reason: "An error should be returned if we can't track our ProviderConfig usage",
+			fields: fields{
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return errBoom }),
+			},
+			mg:   &v1alpha1.Trigger{},
+			want: errors.Wrap(errBoom, "cannot track ProviderConfig usage"),
+		},
+		"ErrGetProviderConfig": {
+			reason: "An error should be returned if we can't get our ProviderConfig",
+			fields: fields{
+				kube:  &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			mg:   trigger("cool", v1alpha1.TriggerParameters{}),
+			want: errors.Wrap(errBoom, "cannot get ProviderConfig"),
+		},
+		"ErrDatabaseNotAllowed": {
+			reason: "An error should be returned if our ProviderConfig does not allow the trigger's database",
+			fields: fields{
+				kube: &test.MockClient{MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					if o, ok := obj.(*v1alpha1.ProviderConfig); ok {
+						o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+						o.Spec.AllowedDatabases = []string{"other"}
+					}
+					return nil
+				})},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			mg:   trigger("cool", v1alpha1.TriggerParameters{Database: pointer.StringPtr("cooldb")}),
+			want: &postgresql.DatabaseNotAllowedError{Database: "cooldb"},
+		},
+		"ErrDefaultDatabaseNotAllowed": {
+			reason: "An error should be returned if our ProviderConfig does not allow the default database a trigger without a database would use",
+			fields: fields{
+				kube: &test.MockClient{MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					switch o := obj.(type) {
+					case *v1alpha1.ProviderConfig:
+						o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+						o.Spec.AllowedDatabases = []string{"cooldb"}
+					case *corev1.Secret:
+						o.Data = map[string][]byte{xpv1.ResourceCredentialsSecretUserKey: []byte("postgres")}
+					}
+					return nil
+				})},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			mg:   trigger("cool", v1alpha1.TriggerParameters{}),
+			want: &postgresql.DatabaseNotAllowedError{Database: "postgres"},
+		},
+		"ErrGetConnectionSecret": {
+			reason: "An error should be returned if we can't get our ProviderConfig's connection secret",
+			fields: fields{
+				kube: &test.MockClient{MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					switch o := obj.(type) {
+					case *v1alpha1.ProviderConfig:
+						o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+					case *corev1.Secret:
+						return errBoom
+					}
+					return nil
+				})},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+			},
+			mg:   trigger("cool", v1alpha1.TriggerParameters{}),
+			want: errors.Wrap(errBoom, "cannot get credentials Secret"),
+		},
+		"Success": {
+			reason: "We should connect to the trigger's database",
+			fields: fields{
+				kube: &test.MockClient{MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					if o, ok := obj.(*v1alpha1.ProviderConfig); ok {
+						o.Spec.Credentials.ConnectionSecretRef = &xpv1.SecretReference{}
+					}
+					return nil
+				})},
+				usage: resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error { return nil }),
+				newDB: func(creds map[string][]byte, database string, o ...postgresql.Option) xsql.DB {
+					if database != "cooldb" {
+						t.Errorf("newDB(...): want database %q, got %q", "cooldb", database)
+					}
+					return mockDB{}
+				},
+			},
+			mg: trigger("cool", v1alpha1.TriggerParameters{Database: pointer.StringPtr("cooldb")}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &connector{kube: tc.fields.kube, usage: tc.fields.usage, newDB: tc.fields.newDB}
+			_, err := e.Connect(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	scan := func(enabled bool, err error) func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+		return func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+			if diff := cmp.Diff([]interface{}{"cool", `"public"."orders"`}, q.Parameters); diff != "" {
+				t.Errorf("MockScan: -want parameters, +got parameters:\n%s\n", diff)
+			}
+			*dest[0].(*bool) = enabled
+			return err
+		}
+	}
+
+	type want struct {
+		o       managed.ExternalObservation
+		enabled *bool
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		db     xsql.DB
+		mg     resource.Managed
+		want   want
+	}{
+		"ErrNotTrigger": {
+			reason: "An error should be returned if the managed resource is not a *Trigger",
+			want:   want{err: errors.New(errNotTrigger)},
+		},
+		"NotFound": {
+			reason: "We should report the trigger does not exist when no row is returned",
+			db:     mockDB{MockScan: scan(false, sql.ErrNoRows)},
+			mg:     trigger("cool", v1alpha1.TriggerParameters{Table: "orders", Schema: pointer.StringPtr("public")}),
+			want:   want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"TableNotFound": {
+			reason: "We should report the trigger does not exist when its table does not exist",
+			db:     mockDB{MockScan: scan(false, &pq.Error{Code: pqUndefinedTable})},
+			mg:     trigger("cool", v1alpha1.TriggerParameters{Table: "orders", Schema: pointer.StringPtr("public")}),
+			want:   want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"ErrSelectTrigger": {
+			reason: "We should return any errors encountered while selecting the trigger",
+			db:     mockDB{MockScan: scan(false, errBoom)},
+			mg:     trigger("cool", v1alpha1.TriggerParameters{Table: "orders", Schema: pointer.StringPtr("public")}),
+			want:   want{err: errors.Wrap(errBoom, errSelectTrigger)},
+		},
+		"LateInitialized": {
+			reason: "Whether the trigger is enabled should be late initialized",
+			db:     mockDB{MockScan: scan(true, nil)},
+			mg:     trigger("cool", v1alpha1.TriggerParameters{Table: "orders", Schema: pointer.StringPtr("public")}),
+			want: want{
+				o:       managed.ExternalObservation{ResourceExists: true, ResourceLateInitialized: true, ResourceUpToDate: true},
+				enabled: pointer.BoolPtr(true),
+			},
+		},
+		"Disabled": {
+			reason: "We should report drift when a trigger that should be enabled is disabled",
+			db:     mockDB{MockScan: scan(false, nil)},
+			mg:     trigger("cool", v1alpha1.TriggerParameters{Table: "orders", Schema: pointer.StringPtr("public"), Enabled: pointer.BoolPtr(true)}),
+			want: want{
+				o:       managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				enabled: pointer.BoolPtr(true),
+			},
+		},
+		"Enabled": {
+			reason: "We should report drift when a trigger that should be disabled is enabled",
+			db:     mockDB{MockScan: scan(true, nil)},
+			mg:     trigger("cool", v1alpha1.TriggerParameters{Table: "orders", Schema: pointer.StringPtr("public"), Enabled: pointer.BoolPtr(false)}),
+			want: want{
+				o:       managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				enabled: pointer.BoolPtr(false),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: tc.db}
+			got, err := e.Observe(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if cr, ok := tc.mg.(*v1alpha1.Trigger); ok {
+				if diff := cmp.Diff(tc.want.enabled, cr.Spec.ForProvider.Enabled); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want enabled, +got enabled:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	level := v1alpha1.TriggerForEachRow
+
+	cases := map[string]struct {
+		reason  string
+		mg      resource.Managed
+		missing string
+		want    []xsql.Query
+		err     error
+	}{
+		"ErrNotTrigger": {
+			reason: "An error should be returned if the managed resource is not a *Trigger",
+			err:    errors.New(errNotTrigger),
+		},
+		"ErrInvalidWhen": {
+			reason: "A when condition that could escape its parentheses should be rejected",
+			mg: trigger("cool", v1alpha1.TriggerParameters{
+				Table:    "orders",
+				Timing:   v1alpha1.TriggerBefore,
+				Events:   []v1alpha1.TriggerEvent{v1alpha1.TriggerUpdate},
+				When:     pointer.StringPtr("true) EXECUTE FUNCTION evil() --"),
+				Function: "notify",
+			}),
+			err: errors.Wrap(errors.New("unbalanced parentheses"), errInvalidWhen),
+		},
+		"ErrTableNotFound": {
+			reason: "An error should be returned if the trigger's table does not exist",
+			mg: trigger("cool", v1alpha1.TriggerParameters{
+				Table:    "orders",
+				Timing:   v1alpha1.TriggerBefore,
+				Events:   []v1alpha1.TriggerEvent{v1alpha1.TriggerTruncate},
+				Function: "notify",
+			}),
+			missing: `"orders"`,
+			err:     errors.Errorf(errTableNotFound, `"orders"`),
+		},
+		"ErrFunctionNotFound": {
+			reason: "An error should be returned if the trigger's function does not exist",
+			mg: trigger("cool", v1alpha1.TriggerParameters{
+				Table:          "orders",
+				Timing:         v1alpha1.TriggerBefore,
+				Events:         []v1alpha1.TriggerEvent{v1alpha1.TriggerTruncate},
+				Function:       "notify",
+				FunctionSchema: pointer.StringPtr("audit"),
+			}),
+			missing: `"audit"."notify"()`,
+			err:     errors.Errorf(errFunctionNotFound, `"audit"."notify"`),
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while creating the trigger should be returned",
+			mg: trigger("cool", v1alpha1.TriggerParameters{
+				Table:    "orders",
+				Timing:   v1alpha1.TriggerBefore,
+				Events:   []v1alpha1.TriggerEvent{v1alpha1.TriggerTruncate},
+				Function: "boom",
+			}),
+			want: []xsql.Query{
+				{String: `CREATE TRIGGER "cool" BEFORE TRUNCATE ON "orders" FOR EACH STATEMENT EXECUTE FUNCTION "boom"()`},
+			},
+			err: errors.Wrap(errBoom, errCreateTrigger),
+		},
+		"Minimal": {
+			reason: "A statement level trigger should be created by default",
+			mg: trigger("cool", v1alpha1.TriggerParameters{
+				Table:    "orders",
+				Timing:   v1alpha1.TriggerBefore,
+				Events:   []v1alpha1.TriggerEvent{v1alpha1.TriggerTruncate},
+				Function: "notify",
+			}),
+			want: []xsql.Query{
+				{String: `CREATE TRIGGER "cool" BEFORE TRUNCATE ON "orders" FOR EACH STATEMENT EXECUTE FUNCTION "notify"()`},
+			},
+		},
+		"Complete": {
+			reason: "All of the trigger's parameters should be reflected in the CREATE statement",
+			mg: trigger("cool", v1alpha1.TriggerParameters{
+				Table:          "orders",
+				Schema:         pointer.StringPtr("shop"),
+				Timing:         v1alpha1.TriggerAfter,
+				Events:         []v1alpha1.TriggerEvent{v1alpha1.TriggerInsert, v1alpha1.TriggerUpdate},
+				ForEach:        &level,
+				When:           pointer.StringPtr("OLD.* IS DISTINCT FROM NEW.*"),
+				Function:       "log_change",
+				FunctionSchema: pointer.StringPtr("audit.v1"),
+				Arguments:      []string{"orders", "it's"},
+			}),
+			want: []xsql.Query{
+				{String: `CREATE TRIGGER "cool" AFTER INSERT OR UPDATE ON "shop"."orders" FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*) EXECUTE FUNCTION "audit.v1"."log_change"('orders', 'it''s')`},
+			},
+		},
+		"Disabled": {
+			reason: "A trigger that should be disabled should be disabled in the transaction that creates it",
+			mg: trigger("cool", v1alpha1.TriggerParameters{
+				Table:    "orders",
+				Timing:   v1alpha1.TriggerBefore,
+				Events:   []v1alpha1.TriggerEvent{v1alpha1.TriggerDelete},
+				Function: "notify",
+				Enabled:  pointer.BoolPtr(false),
+			}),
+			want: []xsql.Query{
+				{String: `CREATE TRIGGER "cool" BEFORE DELETE ON "orders" FOR EACH STATEMENT EXECUTE FUNCTION "notify"()`},
+				{String: `ALTER TABLE "orders" DISABLE TRIGGER "cool"`},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: mockDB{
+				MockScan: func(ctx context.Context, q xsql.Query, dest ...interface{}) error {
+					*dest[0].(*bool) = q.Parameters[0] != tc.missing
+					return nil
+				},
+				MockExecTx: func(ctx context.Context, ql []xsql.Query) error {
+					if diff := cmp.Diff(tc.want, ql); diff != "" {
+						t.Errorf("\n%s\nMockExecTx: -want, +got:\n%s\n", tc.reason, diff)
+					}
+					if tc.err != nil {
+						return errBoom
+					}
+					return nil
+				},
+			}}
+			_, err := e.Create(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidateWhen(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		when   string
+		want   error
+	}{
+		"Valid": {
+			reason: "A single expression should be valid",
+			when:   "(OLD.status IS DISTINCT FROM NEW.status) AND NEW.note <> ')'",
+		},
+		"EscapedQuotes": {
+			reason: "Parentheses in literals with escaped quotes should be ignored",
+			when:   `NEW.note IN ('it''s (', E'\' (', "odd "" (")`,
+		},
+		"Semicolon": {
+			reason: "A semicolon could end the CREATE TRIGGER statement",
+			when:   "true; DROP TABLE orders",
+			want:   errors.New("semicolons are not allowed"),
+		},
+		"Unbalanced": {
+			reason: "A closing parenthesis could end the WHEN clause",
+			when:   "true) OR (true",
+			want:   errors.New("unbalanced parentheses"),
+		},
+		"Unclosed": {
+			reason: "An unclosed parenthesis should be rejected",
+			when:   "(true",
+			want:   errors.New("unbalanced parentheses"),
+		},
+		"Comment": {
+			reason: "A comment could hide the rest of the CREATE TRIGGER statement",
+			when:   "true /* ) */",
+			want:   errors.New("comments are not allowed"),
+		},
+		"DollarQuoted": {
+			reason: "Dollar quoted literals are not understood, so should be rejected",
+			when:   "NEW.note = $$)$$",
+			want:   errors.New("dollar quoted literals are not allowed"),
+		},
+		"Unterminated": {
+			reason: "An unterminated literal should be rejected",
+			when:   "NEW.note = ')",
+			want:   errors.New("unterminated quoted literal or identifier"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := validateWhen(tc.when)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nvalidateWhen(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		mg     resource.Managed
+		want   *xsql.Query
+		err    error
+	}{
+		"ErrNotTrigger": {
+			reason: "An error should be returned if the managed resource is not a *Trigger",
+			err:    errors.New(errNotTrigger),
+		},
+		"NoEnabled": {
+			reason: "Nothing should be updated when we don't know whether the trigger should be enabled",
+			mg:     trigger("cool", v1alpha1.TriggerParameters{Table: "orders"}),
+		},
+		"Enable": {
+			reason: "A trigger that should be enabled should be enabled",
+			mg:     trigger("cool", v1alpha1.TriggerParameters{Table: "orders", Enabled: pointer.BoolPtr(true)}),
+			want:   &xsql.Query{String: `ALTER TABLE "orders" ENABLE TRIGGER "cool"`},
+		},
+		"Disable": {
+			reason: "A trigger that should be disabled should be disabled",
+			mg:     trigger("cool", v1alpha1.TriggerParameters{Table: "orders", Schema: pointer.StringPtr("shop"), Enabled: pointer.BoolPtr(false)}),
+			want:   &xsql.Query{String: `ALTER TABLE "shop"."orders" DISABLE TRIGGER "cool"`},
+		},
+		"ErrExec": {
+			reason: "Any errors encountered while enabling or disabling the trigger should be returned",
+			mg:     trigger("cool", v1alpha1.TriggerParameters{Table: "orders", Enabled: pointer.BoolPtr(true)}),
+			want:   &xsql.Query{String: `ALTER TABLE "orders" ENABLE TRIGGER "cool"`},
+			err:    errors.Wrap(errBoom, errEnableTrigger),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: mockDB{MockExec: func(ctx context.Context, q xsql.Query) error {
+				if diff := cmp.Diff(tc.want, &q); diff != "" {
+					t.Errorf("\n%s\nMockExec: -want, +got:\n%s\n", tc.reason, diff)
+				}
+				if tc.err != nil {
+					return errBoom
+				}
+				return nil
+			}}}
+			_, err := e.Update(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		mg     resource.Managed
+		err    error
+		want   error
+	}{
+		"ErrNotTrigger": {
+			reason: "An error should be returned if the managed resource is not a *Trigger",
+			want:   errors.New(errNotTrigger),
+		},
+		"ErrDropTrigger": {
+			reason: "Errors dropping a trigger should be returned",
+			mg:     trigger("cool", v1alpha1.TriggerParameters{Table: "orders"}),
+			err:    errBoom,
+			want:   errors.Wrap(errBoom, errDropTrigger),
+		},
+		"TableDropped": {
+			reason: "No error should be returned if the trigger's table was dropped",
+			mg:     trigger("cool", v1alpha1.TriggerParameters{Table: "orders"}),
+			err:    &pq.Error{Code: pqUndefinedTable},
+		},
+		"Success": {
+			reason: "No error should be returned if the trigger was dropped",
+			mg:     trigger("cool", v1alpha1.TriggerParameters{Table: "orders"}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{db: mockDB{MockExec: func(ctx context.Context, q xsql.Query) error {
+				want := `DROP TRIGGER IF EXISTS "cool" ON "orders"`
+				if q.String != want {
+					t.Errorf("\n%s\nMockExec: want %q, got %q", tc.reason, want, q.String)
+				}
+				return tc.err
+			}}}
+			err := e.Delete(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -19,20 +19,19 @@ package controller
 import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
-	"github.com/crossplane/crossplane-runtime/pkg/logging"
-
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/mysql"
+	"github.com/crossplane-contrib/provider-sql/pkg/controller/options"
 	"github.com/crossplane-contrib/provider-sql/pkg/controller/postgresql"
 )
 
-// Setup creates all PostgreSQL controllers with the supplied logger and adds
+// Setup creates all PostgreSQL controllers with the supplied options and adds
 // them to the supplied manager.
-func Setup(mgr ctrl.Manager, l logging.Logger) error {
-	for _, setup := range []func(ctrl.Manager, logging.Logger) error{
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	for _, setup := range []func(ctrl.Manager, options.Options) error{
 		mysql.Setup,
 		postgresql.Setup,
 	} {
-		if err := setup(mgr, l); err != nil {
+		if err := setup(mgr, o); err != nil {
 			return err
 		}
 	}